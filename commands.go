@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/services"
+	"os"
+	"time"
+)
+
+// commandTimeout bounds a one-off command
+const commandTimeout = time.Minute
+
+// runCommand runs a one-off command against the database instead of serving. Commands act with admin authority,
+// and as the owner of the organization given with -org, since whoever runs them already has access to the database.
+func runCommand(ctx context.Context, contextService *services.ContextService, name string, args []string) error {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	organizationId := flags.String("org", "", "organization to act within")
+	actor := flags.String("as", "system", "username recorded in the audit log")
+
+	var run func(ctx context.Context) error
+	switch name {
+	case "export-course":
+		output := flags.String("o", "", "file to write the archive to, course-<id>.zip by default")
+		flags.Usage = func() {
+			fmt.Fprintln(flags.Output(), "Usage: export-course [-org id] [-as username] [-o file] <course id>")
+			flags.PrintDefaults()
+		}
+		run = func(ctx context.Context) error {
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("expected a course id")
+			}
+			courseId := flags.Arg(0)
+			archive, err := controller.ExportCourse(ctx, contextService, courseId)
+			if err != nil {
+				return err
+			}
+			path := *output
+			if path == "" {
+				path = fmt.Sprintf("course-%s.zip", courseId)
+			}
+			if err := os.WriteFile(path, archive, 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("Exported course %s to %s\n", courseId, path)
+			return nil
+		}
+	case "import-course":
+		dryRun := flags.Bool("dry-run", false, "validate the archive and report conflicts without importing")
+		flags.Usage = func() {
+			fmt.Fprintln(flags.Output(), "Usage: import-course [-org id] [-as username] [-dry-run] <archive>")
+			flags.PrintDefaults()
+		}
+		run = func(ctx context.Context) error {
+			if flags.NArg() != 1 {
+				flags.Usage()
+				return fmt.Errorf("expected an archive")
+			}
+			data, err := os.ReadFile(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			archive, err := controller.ParseCourseArchive(data)
+			if err != nil {
+				return err
+			}
+			result, err := controller.ImportCourse(ctx, contextService, archive, *dryRun)
+			if err != nil {
+				return err
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(result)
+		}
+	default:
+		return fmt.Errorf("unknown command '%s', expected export-course or import-course", name)
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	ctx = services.WithActor(ctx, *actor)
+	ctx = services.WithRole(ctx, models.RoleAdmin)
+	if *organizationId != "" {
+		ctx = services.WithTenant(ctx, *organizationId, models.OrgRoleOwner)
+	}
+	return run(ctx)
+}
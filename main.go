@@ -7,12 +7,15 @@ import (
 	"net/http"
 	api "orkidslearning/src/api"
 	"orkidslearning/src/config"
+	"orkidslearning/src/controller"
 	"orkidslearning/src/database"
+	models "orkidslearning/src/models/database"
 	"orkidslearning/src/services"
 	"orkidslearning/src/telemetry"
 	"os"
 	"os/signal"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -23,6 +26,10 @@ import (
 
 var serviceName = "orkidslearning"
 
+// minRequestConnections is how many database connections are left for request handlers at the least, on top of
+// those the background workers can hold
+const minRequestConnections = 10
+
 func main() {
 	// Graceful shutdown handling
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -56,11 +63,37 @@ func main() {
 		}
 	}()
 
+	// Background job queues and how many workers each runs
+	queues := []services.QueueConfig{
+		{Name: models.JobQueueDefault, Concurrency: 4, Timeout: time.Minute, MaxAttempts: 5},
+		{Name: models.JobQueueExports, Concurrency: 2, Timeout: 10 * time.Minute, MaxAttempts: 3},
+		{Name: models.JobQueueImports, Concurrency: 1, Timeout: 30 * time.Minute, MaxAttempts: 3},
+		{Name: models.JobQueueEmail, Concurrency: 4, Timeout: time.Minute, MaxAttempts: 8},
+		{Name: models.JobQueueWebhooks, Concurrency: 4, Timeout: time.Minute, MaxAttempts: 10},
+	}
+
 	// connect to postgres
 	port, err := strconv.ParseUint(env.PostgresPort, 10, 16)
 	if err != nil {
 		log.Fatalf("Invalid port number: %v", err)
 	}
+	maxConnections, err := strconv.Atoi(env.PostgresMaxConnections)
+	if err != nil {
+		log.Fatalf("Invalid maximum number of connections: %v", err)
+	}
+	// Every job worker may hold a connection and the stream listener keeps one, and requests must still get some
+	workers := 0
+	for _, queue := range queues {
+		workers += queue.Concurrency
+	}
+	if minConnections := workers + 1 + minRequestConnections; maxConnections < minConnections {
+		log.Fatalf("POSTGRES_MAX_CONNECTIONS is %d, but %d job workers, the stream listener and requests need at least %d",
+			maxConnections, workers, minConnections)
+	}
+	acquireTimeout, err := time.ParseDuration(env.PostgresAcquireTimeout)
+	if err != nil {
+		log.Fatalf("Invalid connection acquire timeout: %v", err)
+	}
 	connConfig := pgx.ConnConfig{
 		Host:     env.PostgresHost,
 		Port:     uint16(port),
@@ -68,7 +101,7 @@ func main() {
 		Password: env.PostgresPassword,
 		Database: env.PostgresDB,
 	}
-	conn, err := database.NewPostgresDatabase(ctx, connConfig)
+	conn, err := database.NewPostgresDatabase(ctx, connConfig, maxConnections, acquireTimeout)
 	if err != nil {
 		log.Fatalf("Failed to connect to the database: %v", err)
 	}
@@ -77,10 +110,64 @@ func main() {
 			log.Printf("Failed to disconnect from the database: %v", disconnectErr)
 		}
 	}()
+	if err := conn.Migrate(); err != nil {
+		log.Fatalf("Failed to migrate the database: %v", err)
+	}
 
 	// Initialize services
 	jwtService := services.NewJWTService(env.JWTSecretKey, env.JWTExpirationTime)
-	contextService := services.NewContextService(db, jwtService, conn)
+	accountService := services.NewAccountService(env.AccountDeletionGrace, env.DataExportLifetime)
+	jobService := services.NewJobService(conn, queues)
+	mailer := services.NewMailer(env.MailDriver, env.MailFrom, env.SMTPHost, env.SMTPPort, env.SMTPUsername, env.SMTPPassword, env.MailDir)
+	mailService := services.NewMailService(mailer, env.FrontendURL)
+	streamService := services.NewStreamService(conn, []string{env.FrontendURL})
+	webhookService := services.NewWebhookService(env.WebhookAllowPrivate)
+	moderationService := services.NewModerationService(env.PostRateLimit, env.PostRateWindow)
+	eventBus := services.NewEventBus(4)
+	contextService := services.NewContextService(db, jwtService, conn, accountService, jobService, mailService, streamService, webhookService, eventBus, moderationService)
+
+	// React to domain events
+	controller.RegisterEventSubscribers(contextService)
+	eventBus.Start()
+
+	// Run a one-off command, such as import-course, instead of serving when one is given
+	if len(os.Args) > 1 {
+		if err := runCommand(ctx, contextService, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("Command %s failed: %v", os.Args[1], err)
+		}
+		drainCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if drainErr := eventBus.Drain(drainCtx); drainErr != nil {
+			log.Printf("Error draining event subscribers: %v", drainErr)
+		}
+		return
+	}
+
+	// Run background jobs until shutdown
+	controller.RegisterJobHandlers(contextService)
+	jobService.Start(ctx)
+
+	// Fan events out to connected clients
+	streamService.Start(ctx)
+
+	// Background loops run until shutdown, and publish events, so they are waited for before the event bus drains
+	var loops sync.WaitGroup
+	runLoop := func(loop func()) {
+		loops.Add(1)
+		go func() {
+			defer loops.Done()
+			loop()
+		}()
+	}
+
+	// Purge accounts whose deletion grace period has elapsed
+	runLoop(func() { controller.RunAccountPurger(ctx, contextService, time.Hour) })
+
+	// Relay webhook events from the outbox
+	runLoop(func() { controller.RunWebhookRelay(ctx, contextService, 2*time.Second) })
+
+	// Publish approved courses whose scheduled publish time has come
+	runLoop(func() { controller.RunCoursePublisher(ctx, contextService, time.Minute) })
 
 	// Create a Gin router
 	router := gin.New()
@@ -105,6 +192,8 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		Handler:      router,
 	}
+	// Streaming requests never finish on their own, so end them when shutting down
+	srv.RegisterOnShutdown(streamService.Close)
 
 	// Start the HTTP server
 	srvErr := make(chan error, 1)
@@ -119,8 +208,9 @@ func main() {
 		log.Printf("Server error: %v", err)
 	case <-ctx.Done():
 		log.Println("Shutting down gracefully...")
-		stop()
 	}
+	// Stop the background loops, jobs and streams however serving ended
+	stop()
 
 	// Gracefully shut down the server
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -128,4 +218,27 @@ func main() {
 	if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
 		log.Printf("Error during server shutdown: %v", shutdownErr)
 	}
+
+	// Let running jobs finish; jobs cut short by the deadline fail their attempt and are retried later
+	if drainErr := jobService.Drain(shutdownCtx); drainErr != nil {
+		log.Printf("Error draining background jobs: %v", drainErr)
+	}
+
+	// Wait for the background loops to finish what they were doing
+	loopsDone := make(chan struct{})
+	go func() {
+		loops.Wait()
+		close(loopsDone)
+	}()
+	select {
+	case <-loopsDone:
+	case <-shutdownCtx.Done():
+		log.Printf("Error stopping background loops: %v", shutdownCtx.Err())
+	}
+
+	// Requests, jobs and loops are done publishing, so let the asynchronous subscribers catch up. Anything still
+	// publishing after a timeout above is not delivered to them.
+	if drainErr := eventBus.Drain(shutdownCtx); drainErr != nil {
+		log.Printf("Error draining event subscribers: %v", drainErr)
+	}
 }
@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
 
+	models "orkidslearning/src/models/database"
 	"orkidslearning/src/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // JWTAuthMiddleware checks the validity of the token
@@ -22,17 +25,51 @@ func JWTAuthMiddleware(jwtService *services.JWTService) gin.HandlerFunc {
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		token, err := jwtService.ValidateToken(tokenString)
-		if err != nil || !token.Valid {
-			log.Println("Unauthorized 2")
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			c.Abort()
+		authenticate(c, jwtService, strings.TrimPrefix(authHeader, "Bearer "), "")
+	}
+}
+
+// StreamAuthMiddleware also lets clients that cannot set headers, such as EventSource and WebSocket in browsers, pass
+// a stream ticket in the ticket query parameter. Tickets are short-lived, unlike the token, which therefore never
+// appears in URLs.
+func StreamAuthMiddleware(jwtService *services.JWTService) gin.HandlerFunc {
+	headerAuth := JWTAuthMiddleware(jwtService)
+	return func(c *gin.Context) {
+		ticket := c.Query("ticket")
+		if ticket == "" {
+			headerAuth(c)
 			return
 		}
+		authenticate(c, jwtService, ticket, services.TokenPurposeStream)
+	}
+}
 
-		c.Next()
+// authenticate checks a token issued for the given purpose, the empty purpose being that of an ordinary token, and
+// exposes the authenticated user to handlers
+func authenticate(c *gin.Context, jwtService *services.JWTService, tokenString string, purpose string) {
+	token, err := jwtService.ValidateToken(tokenString)
+	if err != nil || !token.Valid {
+		log.Println("Unauthorized 2")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
 	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	username, _ := claims["username"].(string)
+	tokenPurpose, _ := claims["purpose"].(string)
+	if !ok || username == "" || tokenPurpose != purpose {
+		log.Println("Unauthorized 3")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
+	}
+	c.Set("username", username)
+	organizationId, _ := claims["org"].(string)
+	c.Set("organizationId", organizationId)
+	c.Request = c.Request.WithContext(services.WithActor(c.Request.Context(), username))
+
+	c.Next()
 }
 
 func LoggerMiddleware() gin.HandlerFunc {
@@ -42,9 +79,72 @@ func LoggerMiddleware() gin.HandlerFunc {
 	}
 }
 
+// RequestOriginMiddleware records the client address and user agent in the request context for auditing
+func RequestOriginMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := services.WithRequestOrigin(c.Request.Context(), c.ClientIP(), c.Request.UserAgent())
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
 func InjectContextService(contextService *services.ContextService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Set("contextService", contextService)
 		c.Next()
 	}
 }
+
+// ActiveUserMiddleware loads the authenticated user's role and rejects accounts that may not use the API
+func ActiveUserMiddleware(contextService *services.ContextService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		account, err := contextService.GetPostgres().GetUserAccountByUsername(c.GetString("username"))
+		if err != nil {
+			log.Println("Unauthorized 4")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		if account.Status != models.StatusActive {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is " + account.Status})
+			c.Abort()
+			return
+		}
+
+		if account.PasswordResetRequired {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Password reset required"})
+			c.Abort()
+			return
+		}
+
+		c.Set("role", account.Role)
+		c.Request = c.Request.WithContext(services.WithRole(c.Request.Context(), account.Role))
+
+		// Scope the request to the organization in the token, as long as the user still belongs to it
+		if organizationId := c.GetString("organizationId"); organizationId != "" {
+			orgRole, err := contextService.GetPostgres().GetOrganizationRole(organizationId, account.Username)
+			if err != nil {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of this organization"})
+				c.Abort()
+				return
+			}
+			c.Set("orgRole", orgRole)
+			c.Request = c.Request.WithContext(services.WithTenant(c.Request.Context(), organizationId, orgRole))
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole only lets through users holding one of the given roles
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(roles, c.GetString("role")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
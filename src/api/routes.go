@@ -1,6 +1,7 @@
 package api
 
 import (
+	models "orkidslearning/src/models/database"
 	"orkidslearning/src/router"
 	"orkidslearning/src/services"
 
@@ -11,6 +12,7 @@ import (
 func InitializeRoutes(router *gin.Engine, contextService *services.ContextService) {
 
 	router.Use(LoggerMiddleware())
+	router.Use(RequestOriginMiddleware())
 
 	// Public routes
 	public := router.Group("/api/public")
@@ -26,7 +28,25 @@ func InitializeRoutes(router *gin.Engine, contextService *services.ContextServic
 	protected := router.Group("api")
 	protected.Use(JWTAuthMiddleware(contextService.GetJWTService()))
 	protected.Use(InjectContextService(contextService))
+	protected.Use(ActiveUserMiddleware(contextService))
 	initializeProtectedRoutes(protected)
+
+	// Streaming routes, which also accept a stream ticket as a query parameter
+	stream := router.Group("api")
+	stream.Use(StreamAuthMiddleware(contextService.GetJWTService()))
+	stream.Use(InjectContextService(contextService))
+	stream.Use(ActiveUserMiddleware(contextService))
+	initializeStreamRoutes(stream)
+
+	// Admin routes
+	admin := protected.Group("/admin")
+	admin.Use(RequireRole(models.RoleAdmin))
+	initializeAdminRoutes(admin)
+
+	// Editorial routes, for the reviewers approving courses for publication
+	editorial := protected.Group("/editorial")
+	editorial.Use(RequireRole(models.RoleReviewer, models.RoleAdmin))
+	initializeEditorialRoutes(editorial)
 }
 
 // initializePublicRoutes defines public routes
@@ -35,18 +55,158 @@ func initializePublicRoutes(public *gin.RouterGroup) {
 		ctx.JSON(200, gin.H{"message": "Welcome to the Gin server with MongoDB!"})
 	})
 	public.GET("/courses", router.GetAllCourses)
+	public.GET("/courses/:id/reviews", router.GetCourseReviews)
+	public.GET("/taxonomy", router.GetTaxonomy)
 }
 
 // initializeAuthRoutes defines authentication routes
 func initializeAuthRoutes(auth *gin.RouterGroup) {
 	auth.POST("/signup", router.SignupHandler)
 	auth.POST("/login", router.LoginHandler)
+	auth.POST("/reset-password", router.ResetPasswordHandler)
 }
 
 // initializeProtectedRoutes defines protected routes
 func initializeProtectedRoutes(protected *gin.RouterGroup) {
+	protected.GET("/courses", router.GetAllCourses)
+	protected.GET("/taxonomy", router.GetTaxonomy)
 	protected.POST("/courses", router.AddCourse)
 	protected.POST("/courses/:id", router.GetCourseById)
 	protected.POST("/courses/enroll/:id", router.EnrollInCourse)
 	protected.POST("/courses/unenroll/:id", router.UnenrollFromCourse)
+	protected.GET("/courses/:id/cohorts", router.GetCourseCohorts)
+	protected.POST("/courses/:id/cohorts", router.AddCohort)
+	protected.GET("/courses/:id/lessons", router.GetCourseLessons)
+	protected.POST("/courses/:id/lessons", router.AddLesson)
+	protected.PUT("/courses/:id/lessons/:lessonId", router.UpdateLesson)
+	protected.DELETE("/courses/:id/lessons/:lessonId", router.RemoveLesson)
+	protected.POST("/courses/:id/lessons/:lessonId/complete", router.CompleteLesson)
+	protected.GET("/courses/:id/assignments", router.GetCourseAssignments)
+	protected.POST("/courses/:id/assignments", router.AddAssignment)
+	protected.POST("/courses/:id/assignments/:assignmentId/submit", router.SubmitAssignment)
+	protected.GET("/courses/:id/enrollments", router.GetCourseRoster)
+	protected.GET("/courses/:id/enrollments/export", router.ExportCourseRoster)
+	protected.POST("/courses/:id/enrollments/unenroll", router.BulkUnenroll)
+	protected.POST("/courses/:id/enrollments/message", router.MessageLearners)
+	protected.PUT("/courses/:id/enrollment-policy", router.SetEnrollmentPolicy)
+	protected.PUT("/courses/:id/taxonomy", router.SetCourseTaxonomy)
+	protected.POST("/courses/:id/submit", router.SubmitCourseForReview)
+	protected.POST("/courses/:id/publish", router.PublishCourse)
+	protected.POST("/courses/:id/unpublish", router.UnpublishCourse)
+	protected.GET("/courses/:id/workflow", router.GetCourseWorkflow)
+	protected.PUT("/courses/:id/details", router.UpdateCourseDetails)
+	protected.GET("/courses/:id/versions", router.GetCourseVersions)
+	protected.POST("/courses/:id/versions", router.PublishCourseVersion)
+	protected.GET("/courses/:id/versions/diff", router.DiffCourseVersions)
+	protected.GET("/courses/:id/versions/:number", router.GetCourseVersion)
+	protected.POST("/courses/:id/versions/:number/rollback", router.RollbackCourseVersion)
+	protected.GET("/courses/:id/version", router.GetEnrollmentVersion)
+	protected.POST("/courses/:id/version/upgrade", router.UpgradeEnrollmentVersion)
+	protected.POST("/courses/:id/clone", router.CloneCourse)
+	protected.GET("/course-templates", router.GetCourseTemplates)
+	protected.POST("/course-templates/:id/courses", router.CreateCourseFromTemplate)
+	protected.GET("/courses/:id/export", router.ExportCourse)
+	protected.POST("/course-imports", router.ImportCourse)
+	protected.GET("/courses/:id/enrollment-requests", router.GetEnrollmentRequests)
+	protected.POST("/courses/:id/enrollment-requests/:requestId/approve", router.ApproveEnrollmentRequest)
+	protected.POST("/courses/:id/enrollment-requests/:requestId/reject", router.RejectEnrollmentRequest)
+	protected.GET("/courses/:id/invite-codes", router.GetInviteCodes)
+	protected.POST("/courses/:id/invite-codes", router.CreateInviteCode)
+	protected.GET("/courses/:id/discussions", router.GetDiscussionThreads)
+	protected.POST("/courses/:id/discussions", router.StartDiscussionThread)
+	protected.GET("/courses/:id/discussions/:threadId", router.GetDiscussionThread)
+	protected.PUT("/courses/:id/discussions/:threadId/pin", router.PinDiscussionThread)
+	protected.PUT("/courses/:id/discussions/:threadId/answer", router.MarkDiscussionAnswer)
+	protected.PUT("/courses/:id/discussions/:threadId/hide", router.HideDiscussionThread)
+	protected.POST("/courses/:id/discussions/:threadId/posts", router.ReplyToDiscussion)
+	protected.PUT("/courses/:id/discussions/:threadId/posts/:postId", router.EditDiscussionPost)
+	protected.GET("/courses/:id/discussions/:threadId/posts/:postId/history", router.GetDiscussionPostHistory)
+	protected.PUT("/courses/:id/discussions/:threadId/posts/:postId/hide", router.HideDiscussionPost)
+	protected.PUT("/courses/:id/discussions/:threadId/posts/:postId/reactions/:reaction", router.AddDiscussionReaction)
+	protected.DELETE("/courses/:id/discussions/:threadId/posts/:postId/reactions/:reaction", router.RemoveDiscussionReaction)
+	protected.POST("/courses/:id/discussions/:threadId/posts/:postId/report", router.ReportDiscussionPost)
+	protected.GET("/courses/:id/reviews", router.GetCourseReviews)
+	protected.GET("/courses/:id/reviews/mine", router.GetMyCourseReview)
+	protected.PUT("/courses/:id/reviews/mine", router.ReviewCourse)
+	protected.DELETE("/courses/:id/reviews/mine", router.DeleteMyCourseReview)
+	protected.PUT("/courses/:id/reviews/:reviewId/reply", router.ReplyToCourseReview)
+
+	// Account data of the authenticated user
+	protected.GET("/me/export", router.ExportAccountData)
+	protected.GET("/me/export/:id/download", router.DownloadAccountData)
+	protected.DELETE("/me", router.DeleteAccount)
+	protected.POST("/me/restore", router.RestoreAccount)
+	protected.GET("/me/courses", router.GetMyCourses)
+	protected.PUT("/me/courses/:id/archive", router.ArchiveMyCourse)
+	protected.GET("/me/notifications", router.GetNotifications)
+	protected.GET("/me/notifications/unread-count", router.GetUnreadNotificationCount)
+	protected.PUT("/me/notifications/read", router.MarkAllNotificationsRead)
+	protected.PUT("/me/notifications/:id/read", router.MarkNotificationRead)
+	protected.POST("/me/stream/ticket", router.CreateStreamTicket)
+	protected.GET("/me/notification-preferences", router.GetNotificationPreferences)
+	protected.PUT("/me/notification-preferences", router.SetNotificationPreferences)
+
+	// Organizations of the authenticated user
+	protected.GET("/orgs", router.GetMyOrganizations)
+	protected.POST("/orgs/switch", router.SwitchOrganization)
+	protected.GET("/orgs/:id/members", router.GetOrganizationMembers)
+	protected.POST("/orgs/:id/members", router.AddOrganizationMember)
+	protected.PUT("/orgs/:id/members/:username", router.ChangeOrganizationMemberRole)
+	protected.DELETE("/orgs/:id/members/:username", router.RemoveOrganizationMember)
+
+	// Webhooks of the current organization, or of the platform for admins
+	protected.GET("/webhooks", router.GetWebhooks)
+	protected.POST("/webhooks", router.CreateWebhook)
+	protected.GET("/webhooks/:id", router.GetWebhook)
+	protected.PUT("/webhooks/:id", router.UpdateWebhook)
+	protected.DELETE("/webhooks/:id", router.DeleteWebhook)
+	protected.POST("/webhooks/:id/ping", router.PingWebhook)
+	protected.GET("/webhooks/:id/deliveries", router.GetWebhookDeliveries)
+	protected.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", router.RedeliverWebhook)
+}
+
+// initializeStreamRoutes defines routes that push events to connected clients
+func initializeStreamRoutes(stream *gin.RouterGroup) {
+	stream.GET("/me/stream", router.StreamEvents)
+}
+
+// initializeAdminRoutes defines admin-only routes
+func initializeAdminRoutes(admin *gin.RouterGroup) {
+	admin.GET("/users", router.SearchUsers)
+	admin.GET("/users/:id", router.GetUser)
+	admin.GET("/users/:id/enrollments", router.GetUserEnrollments)
+	admin.POST("/users/:id/suspend", router.SuspendUser)
+	admin.POST("/users/:id/reactivate", router.ReactivateUser)
+	admin.POST("/users/:id/password-reset", router.ForcePasswordReset)
+	admin.PUT("/users/:id/role", router.ChangeUserRole)
+	admin.POST("/users/:id/merge", router.MergeUsers)
+	admin.POST("/user-imports", router.StartUserImport)
+	admin.GET("/user-imports/:id", router.GetUserImport)
+	admin.GET("/jobs", router.SearchJobs)
+	admin.GET("/jobs/:id", router.GetJob)
+	admin.POST("/jobs/:id/retry", router.RetryJob)
+	admin.GET("/emails", router.SearchOutboxEmails)
+	admin.GET("/email-suppressions", router.GetEmailSuppressions)
+	admin.POST("/email-suppressions", router.SuppressEmail)
+	admin.DELETE("/email-suppressions/:email", router.RemoveEmailSuppression)
+	admin.GET("/audit", router.SearchAuditEvents)
+	admin.GET("/audit/export", router.ExportAuditEvents)
+	admin.POST("/organizations", router.CreateOrganization)
+	admin.GET("/moderation/reports", router.GetContentReports)
+	admin.POST("/moderation/reports/:id/actions", router.ActOnContentReport)
+	admin.GET("/moderation/word-filters", router.GetWordFilters)
+	admin.POST("/moderation/word-filters", router.AddWordFilter)
+	admin.DELETE("/moderation/word-filters/:id", router.DeleteWordFilter)
+	admin.POST("/categories", router.AddCourseCategory)
+	admin.PUT("/categories/:id", router.UpdateCourseCategory)
+	admin.DELETE("/categories/:id", router.DeleteCourseCategory)
+	admin.POST("/course-templates", router.AddCourseTemplate)
+	admin.DELETE("/course-templates/:id", router.DeleteCourseTemplate)
+}
+
+// initializeEditorialRoutes defines routes for reviewers and admins
+func initializeEditorialRoutes(editorial *gin.RouterGroup) {
+	editorial.GET("/courses", router.GetCourseReviewQueue)
+	editorial.POST("/courses/:id/approve", router.ApproveCourse)
+	editorial.POST("/courses/:id/request-changes", router.RequestCourseChanges)
 }
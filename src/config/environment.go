@@ -22,6 +22,20 @@ type Environment struct {
 	PostgresUser           string
 	PostgresPassword       string
 	PostgresDB             string
+	PostgresMaxConnections string
+	PostgresAcquireTimeout string
+	AccountDeletionGrace   string
+	DataExportLifetime     string
+	MailDriver             string
+	MailFrom               string
+	MailDir                string
+	SMTPHost               string
+	SMTPPort               string
+	SMTPUsername           string
+	SMTPPassword           string
+	WebhookAllowPrivate    string
+	PostRateLimit          string
+	PostRateWindow         string
 }
 
 // LoadEnv loads environment variables into the Environment struct
@@ -46,6 +60,20 @@ func LoadEnv() (*Environment, error) {
 		PostgresUser:           getEnv("POSTGRES_USER", "myuser"),
 		PostgresPassword:       getEnv("POSTGRES_PASSWORD", "mypassword"),
 		PostgresDB:             getEnv("POSTGRES_DB", "mydatabase"),
+		PostgresMaxConnections: getEnv("POSTGRES_MAX_CONNECTIONS", "40"), // Shared by job workers, the stream listener and requests
+		PostgresAcquireTimeout: getEnv("POSTGRES_ACQUIRE_TIMEOUT", "5s"), // How long to wait for a free connection before failing
+		AccountDeletionGrace:   getEnv("ACCOUNT_DELETION_GRACE_PERIOD", "720h"),
+		DataExportLifetime:     getEnv("DATA_EXPORT_LIFETIME", "168h"),
+		MailDriver:             getEnv("MAIL_DRIVER", "log"), // One of smtp, file or log
+		MailFrom:               getEnv("MAIL_FROM", "Orkids Learning <no-reply@orkids.in>"),
+		MailDir:                getEnv("MAIL_DIR", os.TempDir()),
+		SMTPHost:               getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:               getEnv("SMTP_PORT", "1025"),
+		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
+		WebhookAllowPrivate:    getEnv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "false"), // Let webhooks reach private addresses, for local development
+		PostRateLimit:          getEnv("POST_RATE_LIMIT", "10"),                   // Discussion posts a learner may write per window
+		PostRateWindow:         getEnv("POST_RATE_WINDOW", "1m"),
 	}
 
 	// Validate critical environment variables
@@ -93,6 +121,22 @@ func LoadEnv() (*Environment, error) {
 		return nil, errors.EnvVariableNotSet("POSTGRES_DB")
 	}
 
+	if env.AccountDeletionGrace == "" {
+		return nil, errors.EnvVariableNotSet("ACCOUNT_DELETION_GRACE_PERIOD")
+	}
+
+	if env.DataExportLifetime == "" {
+		return nil, errors.EnvVariableNotSet("DATA_EXPORT_LIFETIME")
+	}
+
+	if env.MailDriver == "" {
+		return nil, errors.EnvVariableNotSet("MAIL_DRIVER")
+	}
+
+	if env.MailFrom == "" {
+		return nil, errors.EnvVariableNotSet("MAIL_FROM")
+	}
+
 	return env, nil
 }
 
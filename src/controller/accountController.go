@@ -0,0 +1,333 @@
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// GetOrStartDataExport returns the user's current data export, starting a new one if none is pending or downloadable
+func GetOrStartDataExport(ctx context.Context, contextService *services.ContextService, username string) (*models.DataExport, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetOrStartDataExport")
+	defer span.End()
+
+	_, profileSpan := tracer.Start(ctx, "GetUserProfile")
+	profile, err := contextService.GetPostgres().GetUserProfile(username)
+	profileSpan.End()
+	if err != nil {
+		log.Println("Error getting user profile", err)
+		return nil, err
+	}
+
+	_, latestSpan := tracer.Start(ctx, "GetLatestDataExport")
+	export, err := contextService.GetPostgres().GetLatestDataExport(username)
+	latestSpan.End()
+	if err != nil {
+		log.Println("Error getting latest data export", err)
+		return nil, err
+	}
+	if export != nil {
+		return export, nil
+	}
+
+	_, createSpan := tracer.Start(ctx, "CreateDataExport")
+	export, err = contextService.GetPostgres().CreateDataExport(username)
+	createSpan.End()
+	if err != nil {
+		log.Println("Error creating data export", err)
+		return nil, err
+	}
+
+	// The archive is built by a background job so it is not bound by the handler timeout
+	_, enqueueSpan := tracer.Start(ctx, "EnqueueJob")
+	_, err = contextService.GetJobService().Enqueue(models.JobQueueExports, JobDataExport,
+		dataExportJob{ExportId: export.Id, Username: username})
+	enqueueSpan.End()
+	if err != nil {
+		log.Println("Error enqueueing data export", err)
+		if failErr := contextService.GetPostgres().FailDataExport(export.Id, err.Error()); failErr != nil {
+			log.Println("Error marking data export as failed", failErr)
+		}
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditDataExport, models.AuditTargetUser, profile.Id, nil, export)
+	return export, nil
+}
+
+// dataExportJob is the payload of a JobDataExport job
+type dataExportJob struct {
+	ExportId string `json:"exportId"`
+	Username string `json:"username"`
+}
+
+// buildDataExport collects the user's data into a zip archive with one JSON file per entity.
+// The export is marked failed once the job has no attempts left.
+func buildDataExport(ctx context.Context, contextService *services.ContextService, job models.Job) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "BuildDataExport")
+	defer span.End()
+
+	var payload dataExportJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+
+	archive, err := collectUserData(ctx, contextService, payload.Username)
+	if err == nil {
+		expiresAt := contextService.GetAccountService().ExportExpiry()
+		err = contextService.GetPostgres().CompleteDataExport(payload.ExportId, archive, expiresAt)
+	}
+	if err != nil {
+		log.Println("Error building data export", err)
+		if lastAttempt(job) {
+			if failErr := contextService.GetPostgres().FailDataExport(payload.ExportId, err.Error()); failErr != nil {
+				log.Println("Error marking data export as failed", failErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func collectUserData(ctx context.Context, contextService *services.ContextService, username string) ([]byte, error) {
+	tracer := otel.Tracer("controller")
+
+	_, profileSpan := tracer.Start(ctx, "GetUserProfile")
+	profile, err := contextService.GetPostgres().GetUserProfile(username)
+	profileSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, enrollmentsSpan := tracer.Start(ctx, "GetEnrollmentsForUser")
+	enrollments, err := contextService.GetPostgres().GetEnrollmentsForUser(username)
+	enrollmentsSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, progressSpan := tracer.Start(ctx, "GetLessonProgressForUser")
+	progress, err := contextService.GetPostgres().GetLessonProgressForUser(username)
+	progressSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, submissionsSpan := tracer.Start(ctx, "GetSubmissionsForUser")
+	submissions, err := contextService.GetPostgres().GetSubmissionsForUser(username)
+	submissionsSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, requestsSpan := tracer.Start(ctx, "GetEnrollmentRequestsForUser")
+	requests, err := contextService.GetPostgres().GetEnrollmentRequestsForUser(username)
+	requestsSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, notificationsSpan := tracer.Start(ctx, "GetAllNotifications")
+	notifications, err := contextService.GetPostgres().GetAllNotifications(username)
+	notificationsSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, auditSpan := tracer.Start(ctx, "GetAuditEventsAboutUser")
+	auditEvents, err := contextService.GetPostgres().GetAuditEventsAboutUser(username, profile.Id)
+	auditSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, threadsSpan := tracer.Start(ctx, "GetDiscussionThreadsByAuthor")
+	threads, err := contextService.GetPostgres().GetDiscussionThreadsByAuthor(username)
+	threadsSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, postsSpan := tracer.Start(ctx, "GetDiscussionPostsByAuthor")
+	posts, err := contextService.GetPostgres().GetDiscussionPostsByAuthor(username)
+	postsSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, revisionsSpan := tracer.Start(ctx, "GetDiscussionPostRevisionsByAuthor")
+	revisions, err := contextService.GetPostgres().GetDiscussionPostRevisionsByAuthor(username)
+	revisionsSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, reactionsSpan := tracer.Start(ctx, "GetDiscussionReactionsByUser")
+	reactions, err := contextService.GetPostgres().GetDiscussionReactionsByUser(username)
+	reactionsSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	_, reviewsSpan := tracer.Start(ctx, "GetCourseReviewsForUser")
+	reviews, err := contextService.GetPostgres().GetCourseReviewsForUser(username)
+	reviewsSpan.End()
+	if err != nil {
+		return nil, err
+	}
+
+	entities := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", profile},
+		{"enrollments.json", enrollments},
+		{"lesson_progress.json", progress},
+		{"assignment_submissions.json", submissions},
+		{"enrollment_requests.json", requests},
+		{"notifications.json", notifications},
+		{"audit_events.json", auditEvents},
+		{"discussion_threads.json", threads},
+		{"discussion_posts.json", posts},
+		{"discussion_post_revisions.json", revisions},
+		{"discussion_reactions.json", reactions},
+		{"reviews.json", reviews},
+	}
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, entity := range entities {
+		file, err := writer.Create(entity.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entity.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DownloadDataExport returns the archive of a finished data export
+func DownloadDataExport(ctx context.Context, contextService *services.ContextService, username string, exportId string) ([]byte, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DownloadDataExport")
+	defer span.End()
+
+	_, archiveSpan := tracer.Start(ctx, "GetDataExportArchive")
+	archive, err := contextService.GetPostgres().GetDataExportArchive(username, exportId)
+	archiveSpan.End()
+	if err != nil {
+		log.Println("Error getting data export archive", err)
+		return nil, err
+	}
+	return archive, nil
+}
+
+// RequestAccountDeletion schedules the user's account for purging once the grace period elapses
+func RequestAccountDeletion(ctx context.Context, contextService *services.ContextService, username string) (time.Time, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RequestAccountDeletion")
+	defer span.End()
+
+	_, deletionSpan := tracer.Start(ctx, "RequestAccountDeletion")
+	userId, requestedAt, err := contextService.GetPostgres().RequestAccountDeletion(username)
+	deletionSpan.End()
+	if err != nil {
+		log.Println("Error requesting account deletion", err)
+		return time.Time{}, err
+	}
+
+	purgeAt := contextService.GetAccountService().PurgeTime(requestedAt)
+	recordAudit(ctx, contextService, AuditDeletionRequest, models.AuditTargetUser, userId,
+		nil, map[string]time.Time{"deletionRequestedAt": requestedAt, "purgeAt": purgeAt})
+	return purgeAt, nil
+}
+
+// CancelAccountDeletion keeps an account that was scheduled for deletion
+func CancelAccountDeletion(ctx context.Context, contextService *services.ContextService, username string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "CancelAccountDeletion")
+	defer span.End()
+
+	_, cancelSpan := tracer.Start(ctx, "CancelAccountDeletion")
+	userId, err := contextService.GetPostgres().CancelAccountDeletion(username)
+	cancelSpan.End()
+	if err != nil {
+		log.Println("Error cancelling account deletion", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditDeletionCancel, models.AuditTargetUser, userId,
+		map[string]bool{"deletionRequested": true}, map[string]bool{"deletionRequested": false})
+	return nil
+}
+
+// PurgeDueAccounts anonymises every account whose grace period has elapsed and returns how many were purged
+func PurgeDueAccounts(ctx context.Context, contextService *services.ContextService) (int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "PurgeDueAccounts")
+	defer span.End()
+
+	_, dueSpan := tracer.Start(ctx, "GetAccountsDueForPurge")
+	accounts, err := contextService.GetPostgres().GetAccountsDueForPurge(contextService.GetAccountService().PurgeCutoff())
+	dueSpan.End()
+	if err != nil {
+		log.Println("Error getting accounts due for purge", err)
+		return 0, err
+	}
+
+	purged := 0
+	for _, account := range accounts {
+		// Mongo goes first: once the Postgres row is anonymised the username and email are gone
+		if err := contextService.GetDB().PurgeUser(ctx, account.Username, account.Email); err != nil {
+			log.Println("Error purging user from MongoDB", err)
+			continue
+		}
+
+		_, purgeSpan := tracer.Start(ctx, "PurgeAccount")
+		err := contextService.GetPostgres().PurgeAccount(account.Username)
+		purgeSpan.End()
+		if err != nil {
+			log.Println("Error purging account", err)
+			continue
+		}
+		recordAudit(services.WithActor(ctx, auditSystemActor), contextService, AuditAccountPurge, models.AuditTargetUser, account.Id, nil, nil)
+		purged++
+	}
+	return purged, nil
+}
+
+// RunAccountPurger purges due accounts every interval until ctx is cancelled
+func RunAccountPurger(ctx context.Context, contextService *services.ContextService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := PurgeDueAccounts(ctx, contextService)
+		if err == nil && purged > 0 {
+			log.Printf("Purged %d deleted accounts", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
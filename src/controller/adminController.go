@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+const passwordResetValid = 72 * time.Hour
+
+func SearchUsers(ctx context.Context, contextService *services.ContextService, filter models.UserSearch) ([]models.UserAccount, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SearchUsers")
+	defer span.End()
+
+	_, searchSpan := tracer.Start(ctx, "SearchUsers")
+	users, total, err := contextService.GetPostgres().SearchUsers(filter)
+	searchSpan.End()
+	if err != nil {
+		log.Println("Error searching users", err)
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// GetUserAccount returns a user along with the most recent audit events targeting them
+func GetUserAccount(ctx context.Context, contextService *services.ContextService, userId string) (*models.UserAccount, []models.AuditEvent, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetUserAccount")
+	defer span.End()
+
+	_, userSpan := tracer.Start(ctx, "GetUserAccountById")
+	account, err := contextService.GetPostgres().GetUserAccountById(userId)
+	userSpan.End()
+	if err != nil {
+		log.Println("Error getting user", err)
+		return nil, nil, err
+	}
+
+	history := models.AuditSearch{TargetType: models.AuditTargetUser, TargetId: userId}
+	history.Normalize()
+	_, historySpan := tracer.Start(ctx, "SearchAuditEvents")
+	events, _, err := contextService.GetPostgres().SearchAuditEvents(history)
+	historySpan.End()
+	if err != nil {
+		log.Println("Error getting audit events", err)
+		return nil, nil, err
+	}
+	return account, events, nil
+}
+
+func GetUserEnrollments(ctx context.Context, contextService *services.ContextService, userId string) ([]models.Enrollment, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetUserEnrollments")
+	defer span.End()
+
+	_, userSpan := tracer.Start(ctx, "GetUserAccountById")
+	account, err := contextService.GetPostgres().GetUserAccountById(userId)
+	userSpan.End()
+	if err != nil {
+		log.Println("Error getting user", err)
+		return nil, err
+	}
+
+	_, enrollmentsSpan := tracer.Start(ctx, "GetEnrollmentsForUser")
+	enrollments, err := contextService.GetPostgres().GetEnrollmentsForUser(account.Username)
+	enrollmentsSpan.End()
+	if err != nil {
+		log.Println("Error getting enrollments", err)
+		return nil, err
+	}
+	return enrollments, nil
+}
+
+func SuspendUser(ctx context.Context, contextService *services.ContextService, admin string, userId string, reason string) error {
+	return setUserStatus(ctx, contextService, admin, userId, models.StatusSuspended, reason, AuditUserSuspend)
+}
+
+func ReactivateUser(ctx context.Context, contextService *services.ContextService, admin string, userId string) error {
+	return setUserStatus(ctx, contextService, admin, userId, models.StatusActive, "", AuditUserReactivate)
+}
+
+func setUserStatus(ctx context.Context, contextService *services.ContextService, admin, userId, status, reason, action string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SetUserStatus")
+	defer span.End()
+
+	account, err := getOtherUserAccount(ctx, contextService, admin, userId)
+	if err != nil {
+		return err
+	}
+	if account.Status == models.StatusMerged {
+		return fmt.Errorf("user has been merged into another account")
+	}
+
+	_, statusSpan := tracer.Start(ctx, "SetUserStatus")
+	err = contextService.GetPostgres().SetUserStatus(userId, status, reason)
+	statusSpan.End()
+	if err != nil {
+		log.Println("Error setting user status", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, action, models.AuditTargetUser, userId,
+		map[string]string{"status": account.Status, "reason": account.StatusReason},
+		map[string]string{"status": status, "reason": reason})
+	return nil
+}
+
+// ForcePasswordReset locks the user out and returns a one-time token they must use to set a new password
+func ForcePasswordReset(ctx context.Context, contextService *services.ContextService, admin string, userId string) (string, time.Time, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ForcePasswordReset")
+	defer span.End()
+
+	if _, err := getOtherUserAccount(ctx, contextService, admin, userId); err != nil {
+		return "", time.Time{}, err
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate reset token: %v", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	expiresAt := time.Now().Add(passwordResetValid)
+
+	_, resetSpan := tracer.Start(ctx, "RequirePasswordReset")
+	err := contextService.GetPostgres().RequirePasswordReset(userId, hashResetToken(token), expiresAt)
+	resetSpan.End()
+	if err != nil {
+		log.Println("Error requiring password reset", err)
+		return "", time.Time{}, err
+	}
+
+	recordAudit(ctx, contextService, AuditUserPasswordReset, models.AuditTargetUser, userId,
+		nil, map[string]interface{}{"passwordResetRequired": true, "expiresAt": expiresAt})
+	return token, expiresAt, nil
+}
+
+func ChangeUserRole(ctx context.Context, contextService *services.ContextService, admin string, userId string, role string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ChangeUserRole")
+	defer span.End()
+
+	if !slices.Contains(models.Roles, role) {
+		return fmt.Errorf("unknown role '%s'", role)
+	}
+
+	account, err := getOtherUserAccount(ctx, contextService, admin, userId)
+	if err != nil {
+		return err
+	}
+
+	_, roleSpan := tracer.Start(ctx, "SetUserRole")
+	err = contextService.GetPostgres().SetUserRole(userId, role)
+	roleSpan.End()
+	if err != nil {
+		log.Println("Error setting user role", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditUserRoleChange, models.AuditTargetUser, userId,
+		map[string]string{"role": account.Role}, map[string]string{"role": role})
+	return nil
+}
+
+// MergeUsers folds a duplicate account into the target account
+func MergeUsers(ctx context.Context, contextService *services.ContextService, admin string, targetId string, duplicateId string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "MergeUsers")
+	defer span.End()
+
+	if targetId == duplicateId {
+		return fmt.Errorf("cannot merge a user into itself")
+	}
+
+	_, targetSpan := tracer.Start(ctx, "GetUserAccountById")
+	target, err := contextService.GetPostgres().GetUserAccountById(targetId)
+	targetSpan.End()
+	if err != nil {
+		log.Println("Error getting target user", err)
+		return err
+	}
+
+	duplicate, err := getOtherUserAccount(ctx, contextService, admin, duplicateId)
+	if err != nil {
+		return err
+	}
+	if target.Status == models.StatusMerged || duplicate.Status == models.StatusMerged {
+		return fmt.Errorf("user has already been merged into another account")
+	}
+
+	_, mergeSpan := tracer.Start(ctx, "MergeUsers")
+	err = contextService.GetPostgres().MergeUsers(target.Username, duplicate.Username)
+	mergeSpan.End()
+	if err != nil {
+		log.Println("Error merging users", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditUserMerge, models.AuditTargetUser, duplicateId,
+		map[string]string{"status": duplicate.Status},
+		map[string]string{"status": models.StatusMerged, "mergedInto": targetId})
+	return nil
+}
+
+// getOtherUserAccount loads a user an admin is about to act on, refusing to let admins act on themselves
+func getOtherUserAccount(ctx context.Context, contextService *services.ContextService, admin string, userId string) (*models.UserAccount, error) {
+	tracer := otel.Tracer("controller")
+	_, userSpan := tracer.Start(ctx, "GetUserAccountById")
+	account, err := contextService.GetPostgres().GetUserAccountById(userId)
+	userSpan.End()
+	if err != nil {
+		log.Println("Error getting user", err)
+		return nil, err
+	}
+	if account.Username == admin {
+		return nil, fmt.Errorf("admins cannot perform this action on their own account")
+	}
+	return account, nil
+}
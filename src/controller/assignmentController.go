@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+func AddAssignment(ctx context.Context, contextService *services.ContextService, courseId string, assignment models.AddAssignment) (*models.Assignment, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "AddAssignment")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireEditableCourse(course); err != nil {
+		return nil, err
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddAssignment")
+	added, err := contextService.GetPostgres().AddAssignment(courseId, assignment)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error adding assignment", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditAssignmentCreate, models.AuditTargetCourse, courseId, nil, added)
+	return added, nil
+}
+
+// GetCourseAssignments lists a course's assignments. Learners see when each is due for them and whether they
+// submitted it; course managers see the assignments as configured.
+func GetCourseAssignments(ctx context.Context, contextService *services.ContextService, username, courseId string) ([]models.Assignment, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseAssignments")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err == nil {
+		_, assignmentsSpan := tracer.Start(ctx, "GetAssignmentsForUser")
+		assignments, err := contextService.GetPostgres().GetAssignmentsForUser("", []string{courseId})
+		assignmentsSpan.End()
+		if err != nil {
+			log.Println("Error getting assignments", err)
+			return nil, err
+		}
+		return assignments, nil
+	}
+
+	_, startSpan := tracer.Start(ctx, "GetReleaseStart")
+	releaseStart, err := contextService.GetPostgres().GetReleaseStart(services.TenantFromContext(ctx), username, courseId)
+	startSpan.End()
+	if err != nil {
+		log.Println("Error getting release start", err)
+		return nil, err
+	}
+	if releaseStart == nil {
+		return nil, fmt.Errorf("user '%s' is not enrolled in course '%s'", username, courseId)
+	}
+
+	_, assignmentsSpan := tracer.Start(ctx, "GetAssignmentsForUser")
+	assignments, err := contextService.GetPostgres().GetAssignmentsForUser(username, []string{courseId})
+	assignmentsSpan.End()
+	if err != nil {
+		log.Println("Error getting assignments", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range assignments {
+		dueAssignment(&assignments[i], *releaseStart, now)
+	}
+	return assignments, nil
+}
+
+// dueAssignment dates an assignment for a learner whose deadlines count from releaseStart
+func dueAssignment(assignment *models.Assignment, releaseStart, now time.Time) {
+	dueAt := releaseStart.AddDate(0, 0, assignment.DueAfterDays)
+	assignment.DueAt = &dueAt
+	assignment.Overdue = assignment.SubmittedAt == nil && dueAt.Before(now)
+}
+
+func SubmitAssignment(ctx context.Context, contextService *services.ContextService, username, courseId, assignmentId, content string) (time.Time, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SubmitAssignment")
+	defer span.End()
+
+	_, submitSpan := tracer.Start(ctx, "SubmitAssignment")
+	submittedAt, err := contextService.GetPostgres().SubmitAssignment(services.TenantFromContext(ctx), username, courseId, assignmentId, content)
+	submitSpan.End()
+	if err != nil {
+		log.Println("Error submitting assignment", err)
+		return time.Time{}, err
+	}
+	return submittedAt, nil
+}
@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Audited actions
+const (
+	AuditSignup            = "auth.signup"
+	AuditLogin             = "auth.login"
+	AuditLoginFailed       = "auth.login_failed"
+	AuditPasswordReset     = "auth.password_reset"
+	AuditCourseCreate      = "course.create"
+	AuditEnroll            = "enrollment.create"
+	AuditUnenroll          = "enrollment.delete"
+	AuditDataExport        = "account.export"
+	AuditDeletionRequest   = "account.delete"
+	AuditDeletionCancel    = "account.restore"
+	AuditAccountPurge      = "account.purge"
+	AuditUserSuspend       = "user.suspend"
+	AuditUserReactivate    = "user.reactivate"
+	AuditUserPasswordReset = "user.force_password_reset"
+	AuditUserRoleChange    = "user.change_role"
+	AuditUserMerge         = "user.merge"
+
+	AuditOrganizationCreate       = "organization.create"
+	AuditOrganizationMemberAdd    = "organization.member_add"
+	AuditOrganizationMemberRole   = "organization.member_role"
+	AuditOrganizationMemberRemove = "organization.member_remove"
+
+	AuditWaitlistJoin    = "waitlist.join"
+	AuditWaitlistLeave   = "waitlist.leave"
+	AuditWaitlistPromote = "waitlist.promote"
+
+	AuditEnrollmentPolicy  = "course.enrollment_policy"
+	AuditEnrollmentRequest = "enrollment_request.create"
+	AuditEnrollmentApprove = "enrollment_request.approve"
+	AuditEnrollmentReject  = "enrollment_request.reject"
+	AuditInviteCodeCreate  = "invite_code.create"
+
+	AuditCohortCreate     = "cohort.create"
+	AuditLessonCreate     = "lesson.create"
+	AuditAssignmentCreate = "assignment.create"
+	AuditCourseComplete   = "enrollment.complete"
+	AuditCourseMessage    = "course.message_learners"
+
+	AuditUserImport = "user.import"
+	AuditJobRetry   = "job.retry"
+
+	AuditEmailSuppress   = "email.suppress"
+	AuditEmailUnsuppress = "email.unsuppress"
+
+	AuditWebhookCreate    = "webhook.create"
+	AuditWebhookUpdate    = "webhook.update"
+	AuditWebhookDelete    = "webhook.delete"
+	AuditWebhookRedeliver = "webhook.redeliver"
+
+	AuditDiscussionPin    = "discussion.pin"
+	AuditDiscussionHide   = "discussion.hide"
+	AuditDiscussionAnswer = "discussion.answer"
+
+	AuditContentReport     = "content.report"
+	AuditModerationHide    = "moderation.hide"
+	AuditModerationDelete  = "moderation.delete"
+	AuditModerationWarn    = "moderation.warn"
+	AuditModerationSuspend = "moderation.suspend"
+	AuditModerationDismiss = "moderation.dismiss"
+	AuditWordFilterAdd     = "word_filter.add"
+	AuditWordFilterRemove  = "word_filter.remove"
+
+	AuditCategoryCreate = "category.create"
+	AuditCategoryUpdate = "category.update"
+	AuditCategoryDelete = "category.delete"
+	AuditCourseTaxonomy = "course.taxonomy"
+
+	AuditCourseSubmit         = "course.submit"
+	AuditCourseApprove        = "course.approve"
+	AuditCourseRequestChanges = "course.request_changes"
+	AuditCourseSchedule       = "course.schedule"
+	AuditCoursePublish        = "course.publish"
+	AuditCourseUnpublish      = "course.unpublish"
+
+	AuditCourseUpdate          = "course.update"
+	AuditLessonUpdate          = "lesson.update"
+	AuditLessonRemove          = "lesson.remove"
+	AuditCourseVersionPublish  = "course_version.publish"
+	AuditCourseVersionRollback = "course_version.rollback"
+	AuditEnrollmentUpgrade     = "enrollment.upgrade_version"
+
+	AuditCourseClone          = "course.clone"
+	AuditCourseTemplateAdd    = "course_template.add"
+	AuditCourseTemplateRemove = "course_template.remove"
+
+	AuditCourseExport = "course.export"
+	AuditCourseImport = "course.import"
+)
+
+// auditSystemActor is the actor of events raised by background work rather than a request
+const auditSystemActor = "system"
+
+var auditCSVHeader = []string{"id", "occurred_at", "actor", "action", "target_type", "target_id",
+	"ip", "user_agent", "trace_id", "before", "after"}
+
+// recordAudit appends an event to the audit log. The actor, client address and trace id come from ctx.
+// Auditing never fails the action being audited, so errors are only logged.
+func recordAudit(ctx context.Context, contextService *services.ContextService, action, targetType, targetId string, before, after interface{}) {
+	tracer := otel.Tracer("controller")
+	_, auditSpan := tracer.Start(ctx, "RecordAuditEvent")
+	defer auditSpan.End()
+
+	info := services.RequestInfoFromContext(ctx)
+	event := models.AuditEvent{
+		Actor:      info.Actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+		IP:         info.IP,
+		UserAgent:  info.UserAgent,
+		TraceId:    info.TraceId,
+	}
+
+	var err error
+	if event.Before, err = encodeAuditState(before); err != nil {
+		log.Println("Error encoding audit state", err)
+		return
+	}
+	if event.After, err = encodeAuditState(after); err != nil {
+		log.Println("Error encoding audit state", err)
+		return
+	}
+
+	if err := contextService.GetPostgres().RecordAuditEvent(event); err != nil {
+		log.Println("Error recording audit event", err)
+	}
+}
+
+func encodeAuditState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
+
+func SearchAuditEvents(ctx context.Context, contextService *services.ContextService, filter models.AuditSearch) ([]models.AuditEvent, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SearchAuditEvents")
+	defer span.End()
+
+	_, searchSpan := tracer.Start(ctx, "SearchAuditEvents")
+	events, total, err := contextService.GetPostgres().SearchAuditEvents(filter)
+	searchSpan.End()
+	if err != nil {
+		log.Println("Error searching audit events", err)
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// ExportAuditEvents writes every audit event matching the filter to w as CSV
+func ExportAuditEvents(ctx context.Context, contextService *services.ContextService, filter models.AuditSearch, w io.Writer) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ExportAuditEvents")
+	defer span.End()
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	_, eachSpan := tracer.Start(ctx, "EachAuditEvent")
+	err := contextService.GetPostgres().EachAuditEvent(filter, func(event models.AuditEvent) error {
+		return writer.Write([]string{
+			event.Id,
+			event.OccurredAt.UTC().Format(time.RFC3339),
+			escapeCSVCell(event.Actor),
+			event.Action,
+			event.TargetType,
+			escapeCSVCell(event.TargetId),
+			event.IP,
+			escapeCSVCell(event.UserAgent),
+			event.TraceId,
+			string(event.Before),
+			string(event.After),
+		})
+	})
+	eachSpan.End()
+	if err != nil {
+		log.Println("Error exporting audit events", err)
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
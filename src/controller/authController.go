@@ -2,6 +2,8 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log"
 	models "orkidslearning/src/models/database"
@@ -43,6 +45,8 @@ func Signup(ctx context.Context, contextService *services.ContextService, user m
 		return nil, err
 	}
 
+	contextService.GetEventBus().Publish(ctx, models.UserSignedUp{User: *addedUser})
+
 	return addedUser, nil
 }
 
@@ -60,14 +64,60 @@ func Login(ctx context.Context, contextService *services.ContextService, userCre
 		return nil, fmt.Errorf("user not found")
 	}
 
+	ctx = services.WithActor(ctx, user.Username)
+
 	// Compare the provided password with the stored hashed password
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(userCredentials.Password))
 	if err != nil {
 		log.Println("Invalid password", err)
+		recordAudit(ctx, contextService, AuditLoginFailed, models.AuditTargetUser, user.Id, nil, map[string]string{"reason": "invalid password"})
 		return nil, fmt.Errorf("invalid password")
 	}
 
+	if user.Status != models.StatusActive {
+		log.Println("Inactive user attempted to log in", user.Username)
+		recordAudit(ctx, contextService, AuditLoginFailed, models.AuditTargetUser, user.Id, nil, map[string]string{"reason": "account is " + user.Status})
+		return nil, fmt.Errorf("account is %s", user.Status)
+	}
+
+	if user.PasswordResetRequired {
+		recordAudit(ctx, contextService, AuditLoginFailed, models.AuditTargetUser, user.Id, nil, map[string]string{"reason": "password reset required"})
+		return nil, fmt.Errorf("password reset required")
+	}
+
+	recordAudit(ctx, contextService, AuditLogin, models.AuditTargetUser, user.Id, nil, nil)
+
 	user.Password = ""
 
 	return user, nil
 }
+
+func ResetPassword(ctx context.Context, contextService *services.ContextService, reset models.ResetPassword) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ResetPassword")
+	defer span.End()
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(reset.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Println("Failed to hash password: ", err)
+		return fmt.Errorf("failed to hash password: %v", err)
+	}
+
+	_, resetSpan := tracer.Start(ctx, "ResetPassword")
+	userId, username, err := contextService.GetPostgres().ResetPassword(hashResetToken(reset.Token), string(hashedPassword))
+	resetSpan.End()
+	if err != nil {
+		log.Println("Error resetting password: ", err)
+		return err
+	}
+
+	recordAudit(services.WithActor(ctx, username), contextService, AuditPasswordReset, models.AuditTargetUser, userId,
+		map[string]bool{"passwordResetRequired": true}, map[string]bool{"passwordResetRequired": false})
+	return nil
+}
+
+// hashResetToken derives the value stored for a password reset token so the token itself is never persisted
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+func GetCourseCohorts(ctx context.Context, contextService *services.ContextService, courseId string) ([]models.Cohort, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseCohorts")
+	defer span.End()
+
+	_, courseSpan := tracer.Start(ctx, "CheckIfCourseExists")
+	err := contextService.GetPostgres().CheckIfCourseExists(services.TenantFromContext(ctx), courseId)
+	courseSpan.End()
+	if err != nil {
+		log.Println("Course does not exist", err)
+		return nil, err
+	}
+
+	_, cohortsSpan := tracer.Start(ctx, "GetCohortsForCourse")
+	cohorts, err := contextService.GetPostgres().GetCohortsForCourse(courseId)
+	cohortsSpan.End()
+	if err != nil {
+		log.Println("Error getting cohorts", err)
+		return nil, err
+	}
+	return cohorts, nil
+}
+
+func AddCohort(ctx context.Context, contextService *services.ContextService, courseId string, cohort models.AddCohort) (*models.Cohort, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "AddCohort")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if !course.CohortBased {
+		return nil, fmt.Errorf("course '%s' is self-paced and has no cohorts", courseId)
+	}
+
+	if cohort.Facilitator != "" {
+		_, facilitatorSpan := tracer.Start(ctx, "GetUserAccountByUsername")
+		_, err := contextService.GetPostgres().GetUserAccountByUsername(cohort.Facilitator)
+		facilitatorSpan.End()
+		if err != nil {
+			return nil, fmt.Errorf("facilitator '%s' does not exist", cohort.Facilitator)
+		}
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddCohort")
+	added, err := contextService.GetPostgres().AddCohort(courseId, cohort)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error adding cohort", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditCohortCreate, models.AuditTargetCourse, courseId, nil, added)
+	return added, nil
+}
+
+// chooseCohort returns the cohort a new enrollment joins: none for self-paced courses, otherwise the requested cohort
+// or, when none was requested, the earliest one with a free seat
+func chooseCohort(ctx context.Context, contextService *services.ContextService, course *models.CoursePostgres, cohortId string) (string, error) {
+	tracer := otel.Tracer("controller")
+
+	if !course.CohortBased {
+		if cohortId != "" {
+			return "", fmt.Errorf("course '%s' is self-paced and has no cohorts", course.Id)
+		}
+		return "", nil
+	}
+
+	if cohortId == "" {
+		_, openSpan := tracer.Start(ctx, "GetOpenCohortId")
+		openId, err := contextService.GetPostgres().GetOpenCohortId(course.Id)
+		openSpan.End()
+		if err != nil {
+			log.Println("Error getting open cohort", err)
+			return "", err
+		}
+		if openId == "" {
+			return "", fmt.Errorf("course '%s' has no cohort open for enrollment", course.Id)
+		}
+		return openId, nil
+	}
+
+	_, cohortSpan := tracer.Start(ctx, "GetCohort")
+	cohort, err := contextService.GetPostgres().GetCohort(course.Id, cohortId)
+	cohortSpan.End()
+	if err != nil {
+		return "", err
+	}
+	if !cohort.EndsAt.After(time.Now()) {
+		return "", fmt.Errorf("cohort '%s' has already ended", cohort.Name)
+	}
+	return cohort.Id, nil
+}
@@ -0,0 +1,438 @@
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Bounds on what a course archive may hold
+const (
+	maxCourseArchiveFileSize = 20 << 20
+	maxCourseArchiveLessons  = 1000
+	maxCourseArchiveItems    = 1000
+	maxCourseArchiveTags     = 10
+)
+
+// courseArchiveCourseRef is the ref the imported course's id is reported under in the id map
+const courseArchiveCourseRef = "course"
+
+// mediaReferencePattern finds the targets of HTML src and href attributes, Markdown links and images, and bare URLs
+var mediaReferencePattern = regexp.MustCompile(`(?i)(?:src|href)\s*=\s*["']([^"']+)["']|!?\[[^\]]*\]\(\s*<?([^)\s>]+)|(https?://[^\s"'<>()\[\]]+)`)
+
+// courseArchiveMedia lists the media referenced by the lessons and assignments of an archive, in order of first use.
+// In-page anchors, mail links and inline data are not media files and are left out.
+func courseArchiveMedia(lessons []models.CourseArchiveLesson, assignments []models.CourseArchiveAssignment) []models.CourseArchiveMedia {
+	media := []models.CourseArchiveMedia{}
+	indexes := map[string]int{}
+	collect := func(ref, text string) {
+		for _, match := range mediaReferencePattern.FindAllStringSubmatch(text, -1) {
+			url := match[1] + match[2] + strings.TrimRight(match[3], ".,;:!?")
+			lower := strings.ToLower(url)
+			if url == "" || strings.HasPrefix(url, "#") || strings.HasPrefix(lower, "mailto:") || strings.HasPrefix(lower, "data:") {
+				continue
+			}
+			index, seen := indexes[url]
+			if !seen {
+				index = len(media)
+				indexes[url] = index
+				media = append(media, models.CourseArchiveMedia{URL: url, UsedBy: []string{}})
+			}
+			if !slices.Contains(media[index].UsedBy, ref) {
+				media[index].UsedBy = append(media[index].UsedBy, ref)
+			}
+		}
+	}
+	for _, lesson := range lessons {
+		collect(lesson.Ref, lesson.Content)
+	}
+	for _, assignment := range assignments {
+		collect(assignment.Ref, assignment.Instructions)
+	}
+	return media
+}
+
+// ExportCourse writes the working copy of a course the caller manages to a course archive: a zip holding the
+// manifest, the course details, its lessons and assignments, and the media their content references
+func ExportCourse(ctx context.Context, contextService *services.ContextService, courseId string) ([]byte, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ExportCourse")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, lessonsSpan := tracer.Start(ctx, "GetLessonsForCourse")
+	lessons, err := contextService.GetPostgres().GetLessonsForCourse(courseId)
+	lessonsSpan.End()
+	if err != nil {
+		log.Println("Error getting lessons", err)
+		return nil, err
+	}
+
+	_, assignmentsSpan := tracer.Start(ctx, "GetAssignmentsForUser")
+	assignments, err := contextService.GetPostgres().GetAssignmentsForUser("", []string{courseId})
+	assignmentsSpan.End()
+	if err != nil {
+		log.Println("Error getting assignments", err)
+		return nil, err
+	}
+
+	archive := models.CourseArchive{
+		Course: models.CourseArchiveCourse{
+			Title:            course.Title,
+			Description:      course.Description,
+			CohortBased:      course.CohortBased,
+			Capacity:         course.Capacity,
+			EnrollmentPolicy: course.EnrollmentPolicy,
+			Tags:             course.Tags,
+			Subject:          course.Subject,
+			GradeLevel:       course.GradeLevel,
+		},
+		Lessons:     []models.CourseArchiveLesson{},
+		Assignments: []models.CourseArchiveAssignment{},
+	}
+	if course.CategoryId != "" {
+		_, categorySpan := tracer.Start(ctx, "GetCourseCategory")
+		category, err := contextService.GetPostgres().GetCourseCategory(course.CategoryId)
+		categorySpan.End()
+		if err != nil {
+			log.Println("Error getting course category", err)
+			return nil, err
+		}
+		archive.Course.CategorySlug = category.Slug
+	}
+	for i, lesson := range lessons {
+		archive.Lessons = append(archive.Lessons, models.CourseArchiveLesson{
+			Ref:              fmt.Sprintf("lesson-%d", i+1),
+			Position:         lesson.Position,
+			Title:            lesson.Title,
+			Content:          lesson.Content,
+			ReleaseAfterDays: lesson.ReleaseAfterDays,
+		})
+	}
+	for i, assignment := range assignments {
+		archive.Assignments = append(archive.Assignments, models.CourseArchiveAssignment{
+			Ref:          fmt.Sprintf("assignment-%d", i+1),
+			Title:        assignment.Title,
+			Instructions: assignment.Instructions,
+			DueAfterDays: assignment.DueAfterDays,
+		})
+	}
+	archive.Media = courseArchiveMedia(archive.Lessons, archive.Assignments)
+	archive.Manifest = models.CourseArchiveManifest{
+		Format:               models.CourseArchiveFormat,
+		FormatVersion:        models.CourseArchiveFormatVersion,
+		ExportedAt:           time.Now().UTC(),
+		ExportedBy:           services.RequestInfoFromContext(ctx).Actor,
+		SourceCourseId:       course.Id,
+		SourceOrganizationId: course.OrganizationId,
+		SourceVersion:        course.CurrentVersion,
+		LessonCount:          len(archive.Lessons),
+		AssignmentCount:      len(archive.Assignments),
+		MediaCount:           len(archive.Media),
+	}
+
+	entities := []struct {
+		name string
+		data interface{}
+	}{
+		{models.CourseArchiveManifestFile, archive.Manifest},
+		{models.CourseArchiveCourseFile, archive.Course},
+		{models.CourseArchiveLessonsFile, archive.Lessons},
+		{models.CourseArchiveAssignmentsFile, archive.Assignments},
+		{models.CourseArchiveMediaFile, archive.Media},
+	}
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, entity := range entities {
+		file, err := writer.Create(entity.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entity.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditCourseExport, models.AuditTargetCourse, courseId, nil, archive.Manifest)
+	return buf.Bytes(), nil
+}
+
+// readCourseArchiveFile decodes a JSON file of a course archive. Unknown fields are rejected, since they mean the
+// archive was not written in the format version it claims.
+func readCourseArchiveFile(files map[string]*zip.File, name string, dest interface{}) error {
+	file, ok := files[name]
+	if !ok {
+		return fmt.Errorf("archive is missing %s", name)
+	}
+	if file.UncompressedSize64 > maxCourseArchiveFileSize {
+		return fmt.Errorf("%s is larger than %d bytes", name, maxCourseArchiveFileSize)
+	}
+	opened, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer opened.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(opened, maxCourseArchiveFileSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dest); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// ParseCourseArchive reads a course archive and checks that it is complete and consistent. Media are recomputed
+// from the content rather than trusted.
+func ParseCourseArchive(data []byte) (*models.CourseArchive, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a zip archive: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, file := range reader.File {
+		files[file.Name] = file
+	}
+
+	var archive models.CourseArchive
+	if err := readCourseArchiveFile(files, models.CourseArchiveManifestFile, &archive.Manifest); err != nil {
+		return nil, err
+	}
+	if archive.Manifest.Format != models.CourseArchiveFormat {
+		return nil, fmt.Errorf("not a course archive: unknown format '%s'", archive.Manifest.Format)
+	}
+	if archive.Manifest.FormatVersion != models.CourseArchiveFormatVersion {
+		return nil, fmt.Errorf("unsupported archive format version %d, expected %d", archive.Manifest.FormatVersion, models.CourseArchiveFormatVersion)
+	}
+	if err := readCourseArchiveFile(files, models.CourseArchiveCourseFile, &archive.Course); err != nil {
+		return nil, err
+	}
+	if err := readCourseArchiveFile(files, models.CourseArchiveLessonsFile, &archive.Lessons); err != nil {
+		return nil, err
+	}
+	if err := readCourseArchiveFile(files, models.CourseArchiveAssignmentsFile, &archive.Assignments); err != nil {
+		return nil, err
+	}
+	if archive.Lessons == nil {
+		archive.Lessons = []models.CourseArchiveLesson{}
+	}
+	if archive.Assignments == nil {
+		archive.Assignments = []models.CourseArchiveAssignment{}
+	}
+	if err := validateCourseArchive(&archive); err != nil {
+		return nil, err
+	}
+	archive.Media = courseArchiveMedia(archive.Lessons, archive.Assignments)
+	return &archive, nil
+}
+
+// validateCourseArchive fails unless the archive holds what its manifest says and every ref is unique
+func validateCourseArchive(archive *models.CourseArchive) error {
+	if strings.TrimSpace(archive.Course.Title) == "" {
+		return fmt.Errorf("course title is required")
+	}
+	if archive.Course.Capacity != nil && *archive.Course.Capacity < 1 {
+		return fmt.Errorf("course capacity must be at least 1")
+	}
+	if len(archive.Course.Tags) > maxCourseArchiveTags {
+		return fmt.Errorf("a course can have at most %d tags", maxCourseArchiveTags)
+	}
+	if len(archive.Lessons) > maxCourseArchiveLessons || len(archive.Assignments) > maxCourseArchiveItems {
+		return fmt.Errorf("an archive can hold at most %d lessons and %d assignments", maxCourseArchiveLessons, maxCourseArchiveItems)
+	}
+	if archive.Manifest.LessonCount != len(archive.Lessons) {
+		return fmt.Errorf("manifest lists %d lessons but the archive holds %d", archive.Manifest.LessonCount, len(archive.Lessons))
+	}
+	if archive.Manifest.AssignmentCount != len(archive.Assignments) {
+		return fmt.Errorf("manifest lists %d assignments but the archive holds %d", archive.Manifest.AssignmentCount, len(archive.Assignments))
+	}
+
+	refs := map[string]bool{courseArchiveCourseRef: true}
+	checkRef := func(ref string) error {
+		if ref == "" {
+			return fmt.Errorf("every lesson and assignment needs a ref")
+		}
+		if refs[ref] {
+			return fmt.Errorf("ref '%s' is used more than once", ref)
+		}
+		refs[ref] = true
+		return nil
+	}
+	positions := map[int]bool{}
+	for _, lesson := range archive.Lessons {
+		if err := checkRef(lesson.Ref); err != nil {
+			return err
+		}
+		if strings.TrimSpace(lesson.Title) == "" {
+			return fmt.Errorf("lesson '%s' has no title", lesson.Ref)
+		}
+		if lesson.Position < 1 || positions[lesson.Position] {
+			return fmt.Errorf("lesson '%s' needs a position of its own of at least 1", lesson.Ref)
+		}
+		positions[lesson.Position] = true
+		if lesson.ReleaseAfterDays < 0 {
+			return fmt.Errorf("lesson '%s' cannot be released before the course starts", lesson.Ref)
+		}
+	}
+	for _, assignment := range archive.Assignments {
+		if err := checkRef(assignment.Ref); err != nil {
+			return err
+		}
+		if strings.TrimSpace(assignment.Title) == "" {
+			return fmt.Errorf("assignment '%s' has no title", assignment.Ref)
+		}
+		if assignment.DueAfterDays < 0 {
+			return fmt.Errorf("assignment '%s' cannot be due before the course starts", assignment.Ref)
+		}
+	}
+	return nil
+}
+
+// ImportCourse creates a new draft course of the caller's organization, or a public course outside one, from a
+// course archive. Whatever cannot be carried over as is — a category, subject, grade level or enrollment policy this
+// installation lacks, a title already in use, or media that will not resolve here — is resolved and reported as a
+// conflict. On a dry run nothing is created.
+func ImportCourse(ctx context.Context, contextService *services.ContextService, archive *models.CourseArchive, dryRun bool) (*models.CourseImportResult, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ImportCourse")
+	defer span.End()
+
+	if err := requireCourseAuthor(ctx); err != nil {
+		return nil, err
+	}
+
+	result := models.CourseImportResult{
+		DryRun:    dryRun,
+		Manifest:  archive.Manifest,
+		IdMap:     map[string]string{},
+		Conflicts: []models.CourseImportConflict{},
+	}
+	conflict := func(kind, ref, format string, args ...interface{}) {
+		result.Conflicts = append(result.Conflicts, models.CourseImportConflict{Kind: kind, Ref: ref, Message: fmt.Sprintf(format, args...)})
+	}
+
+	tenant := services.TenantFromContext(ctx)
+	course := models.AddCourse{
+		Title:            strings.TrimSpace(archive.Course.Title),
+		Description:      archive.Course.Description,
+		Visibility:       models.CourseVisibilityPublic,
+		CohortBased:      archive.Course.CohortBased,
+		Capacity:         archive.Course.Capacity,
+		EnrollmentPolicy: archive.Course.EnrollmentPolicy,
+		Tags:             normalizeTags(archive.Course.Tags),
+		Subject:          archive.Course.Subject,
+		GradeLevel:       archive.Course.GradeLevel,
+	}
+	if tenant != "" {
+		course.Visibility = models.CourseVisibilityOrganization
+	}
+
+	if course.EnrollmentPolicy == "" {
+		course.EnrollmentPolicy = models.EnrollmentPolicyOpen
+	} else if err := validateEnrollmentPolicy(course.EnrollmentPolicy, nil, nil); err != nil {
+		conflict(models.CourseImportConflictEnrollmentPolicy, courseArchiveCourseRef, "%s; enrollment is open instead", err)
+		course.EnrollmentPolicy = models.EnrollmentPolicyOpen
+	}
+	if course.Subject != "" && !slices.Contains(models.Subjects, course.Subject) {
+		conflict(models.CourseImportConflictSubject, courseArchiveCourseRef, "unknown subject '%s' was left out", course.Subject)
+		course.Subject = ""
+	}
+	if course.GradeLevel != "" && !slices.Contains(models.GradeLevels, course.GradeLevel) {
+		conflict(models.CourseImportConflictGradeLevel, courseArchiveCourseRef, "unknown grade level '%s' was left out", course.GradeLevel)
+		course.GradeLevel = ""
+	}
+	if slug := archive.Course.CategorySlug; slug != "" {
+		_, categorySpan := tracer.Start(ctx, "GetCourseCategoryBySlug")
+		category, err := contextService.GetPostgres().GetCourseCategoryBySlug(slug)
+		categorySpan.End()
+		if err != nil {
+			conflict(models.CourseImportConflictCategory, courseArchiveCourseRef, "no category with slug '%s'; the course has no category", slug)
+		} else {
+			course.CategoryId = category.Id
+		}
+	}
+
+	// A course of the same name is renamed rather than refused, so that importing an archive twice is harmless
+	for attempt := 1; ; attempt++ {
+		title := course.Title
+		if attempt > 1 {
+			title = fmt.Sprintf("%s (imported %d)", course.Title, attempt)
+		}
+		_, titleSpan := tracer.Start(ctx, "CourseTitleInUse")
+		inUse, err := contextService.GetPostgres().CourseTitleInUse(tenant, title)
+		titleSpan.End()
+		if err != nil {
+			log.Println("Error checking course title", err)
+			return nil, err
+		}
+		if !inUse {
+			if attempt > 1 {
+				conflict(models.CourseImportConflictTitle, courseArchiveCourseRef, "a course named '%s' already exists; imported as '%s'", course.Title, title)
+				course.Title = title
+			}
+			break
+		}
+	}
+
+	for _, media := range archive.Media {
+		lower := strings.ToLower(media.URL)
+		if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+			conflict(models.CourseImportConflictMedia, strings.Join(media.UsedBy, ","),
+				"'%s' is not an absolute URL and may not resolve outside the installation it was exported from", media.URL)
+		}
+	}
+
+	if dryRun {
+		result.Course = models.CoursePostgres{
+			Title:            course.Title,
+			Description:      course.Description,
+			OrganizationId:   tenant,
+			Visibility:       course.Visibility,
+			CohortBased:      course.CohortBased,
+			Capacity:         course.Capacity,
+			EnrollmentPolicy: course.EnrollmentPolicy,
+			CategoryId:       course.CategoryId,
+			Tags:             course.Tags,
+			Subject:          course.Subject,
+			GradeLevel:       course.GradeLevel,
+			Status:           models.CourseStatusDraft,
+		}
+		return &result, nil
+	}
+
+	_, importSpan := tracer.Start(ctx, "ImportCourse")
+	imported, idMap, err := contextService.GetPostgres().ImportCourse(tenant, course, archive.Lessons, archive.Assignments)
+	importSpan.End()
+	if err != nil {
+		log.Println("Error importing course", err)
+		return nil, err
+	}
+	idMap[courseArchiveCourseRef] = imported.Id
+	result.Course = *imported
+	result.IdMap = idMap
+
+	contextService.GetEventBus().Publish(ctx, models.CourseCreated{Course: *imported, ImportedFrom: &archive.Manifest})
+	return &result, nil
+}
@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	models "orkidslearning/src/models/database"
+)
+
+// testCourseArchive returns a consistent archive of a course with two lessons and an assignment
+func testCourseArchive() *models.CourseArchive {
+	capacity := 30
+	return &models.CourseArchive{
+		Manifest: models.CourseArchiveManifest{
+			Format:          models.CourseArchiveFormat,
+			FormatVersion:   models.CourseArchiveFormatVersion,
+			LessonCount:     2,
+			AssignmentCount: 1,
+		},
+		Course: models.CourseArchiveCourse{
+			Title:            "Arithmetic",
+			Capacity:         &capacity,
+			EnrollmentPolicy: models.EnrollmentPolicyOpen,
+			Tags:             []string{"maths"},
+		},
+		Lessons: []models.CourseArchiveLesson{
+			{Ref: "lesson-1", Position: 1, Title: "Intro", Content: `<img src="https://cdn.example.com/intro.png">`},
+			{Ref: "lesson-2", Position: 2, Title: "Basics", Content: "See [the table](https://cdn.example.com/table.pdf).", ReleaseAfterDays: 7},
+		},
+		Assignments: []models.CourseArchiveAssignment{
+			{Ref: "assignment-1", Title: "Homework", Instructions: "Fill in https://cdn.example.com/table.pdf", DueAfterDays: 14},
+		},
+	}
+}
+
+// courseArchiveFiles encodes each part of an archive as the file it is stored in
+func courseArchiveFiles(t *testing.T, archive *models.CourseArchive) map[string][]byte {
+	t.Helper()
+	files := map[string][]byte{}
+	for name, data := range map[string]interface{}{
+		models.CourseArchiveManifestFile:    archive.Manifest,
+		models.CourseArchiveCourseFile:      archive.Course,
+		models.CourseArchiveLessonsFile:     archive.Lessons,
+		models.CourseArchiveAssignmentsFile: archive.Assignments,
+		models.CourseArchiveMediaFile:       archive.Media,
+	} {
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			t.Fatalf("encoding %s: %v", name, err)
+		}
+		files[name] = encoded
+	}
+	return files
+}
+
+func zipCourseArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, data := range files {
+		file, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("creating %s: %v", name, err)
+		}
+		if _, err := file.Write(data); err != nil {
+			t.Fatalf("writing %s: %v", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("closing archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// The media an archive lists are not trusted: they are recomputed from the content it holds
+func TestParseCourseArchive(t *testing.T) {
+	want := testCourseArchive()
+	tampered := *want
+	tampered.Media = []models.CourseArchiveMedia{{URL: "https://evil.example.com/tracker.gif", UsedBy: []string{"lesson-1"}}}
+
+	archive, err := ParseCourseArchive(zipCourseArchive(t, courseArchiveFiles(t, &tampered)))
+	if err != nil {
+		t.Fatalf("parsing: %v", err)
+	}
+	if !reflect.DeepEqual(archive.Course, want.Course) || !reflect.DeepEqual(archive.Lessons, want.Lessons) ||
+		!reflect.DeepEqual(archive.Assignments, want.Assignments) {
+		t.Errorf("parsed %+v, want %+v", archive, want)
+	}
+	media := []models.CourseArchiveMedia{
+		{URL: "https://cdn.example.com/intro.png", UsedBy: []string{"lesson-1"}},
+		{URL: "https://cdn.example.com/table.pdf", UsedBy: []string{"lesson-2", "assignment-1"}},
+	}
+	if !reflect.DeepEqual(archive.Media, media) {
+		t.Errorf("media = %+v, want %+v", archive.Media, media)
+	}
+}
+
+func TestParseCourseArchiveRejectsBadArchives(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive func(archive *models.CourseArchive)
+		files   func(files map[string][]byte)
+		err     string
+	}{
+		{
+			name:  "missing file",
+			files: func(files map[string][]byte) { delete(files, models.CourseArchiveLessonsFile) },
+			err:   "archive is missing lessons.json",
+		},
+		{
+			name:    "other format",
+			archive: func(archive *models.CourseArchive) { archive.Manifest.Format = "other.course" },
+			err:     "unknown format",
+		},
+		{
+			name:    "newer format version",
+			archive: func(archive *models.CourseArchive) { archive.Manifest.FormatVersion++ },
+			err:     "unsupported archive format version",
+		},
+		{
+			name:    "manifest lists more lessons",
+			archive: func(archive *models.CourseArchive) { archive.Manifest.LessonCount = 3 },
+			err:     "manifest lists 3 lessons but the archive holds 2",
+		},
+		{
+			name:    "manifest lists fewer assignments",
+			archive: func(archive *models.CourseArchive) { archive.Manifest.AssignmentCount = 0 },
+			err:     "manifest lists 0 assignments but the archive holds 1",
+		},
+		{
+			name: "unknown course field",
+			files: func(files map[string][]byte) {
+				files[models.CourseArchiveCourseFile] = []byte(`{"title": "Arithmetic", "price": 10}`)
+			},
+			err: `invalid course.json: json: unknown field "price"`,
+		},
+		{
+			name: "unknown lesson field",
+			files: func(files map[string][]byte) {
+				files[models.CourseArchiveLessonsFile] = []byte(`[{"ref": "lesson-1", "position": 1, "title": "Intro", "video": "x"},
+					{"ref": "lesson-2", "position": 2, "title": "Basics"}]`)
+			},
+			err: `invalid lessons.json: json: unknown field "video"`,
+		},
+		{
+			name: "oversize entry",
+			files: func(files map[string][]byte) {
+				files[models.CourseArchiveLessonsFile] = append(bytes.Repeat([]byte(" "), maxCourseArchiveFileSize), files[models.CourseArchiveLessonsFile]...)
+			},
+			err: "lessons.json is larger than",
+		},
+		{
+			name:    "ref shared by a lesson and an assignment",
+			archive: func(archive *models.CourseArchive) { archive.Assignments[0].Ref = "lesson-2" },
+			err:     "ref 'lesson-2' is used more than once",
+		},
+		{
+			name:    "duplicate position",
+			archive: func(archive *models.CourseArchive) { archive.Lessons[1].Position = 1 },
+			err:     "lesson 'lesson-2' needs a position of its own",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			archive := testCourseArchive()
+			if test.archive != nil {
+				test.archive(archive)
+			}
+			files := courseArchiveFiles(t, archive)
+			if test.files != nil {
+				test.files(files)
+			}
+			_, err := ParseCourseArchive(zipCourseArchive(t, files))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("error = %v, want one containing %q", err, test.err)
+			}
+		})
+	}
+
+	if _, err := ParseCourseArchive([]byte("lessons.json")); err == nil || !strings.Contains(err.Error(), "not a zip archive") {
+		t.Errorf("error = %v, want one saying it is not a zip archive", err)
+	}
+}
+
+func TestValidateCourseArchive(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(archive *models.CourseArchive)
+		err    string
+	}{
+		{"valid", func(archive *models.CourseArchive) {}, ""},
+		{"no title", func(archive *models.CourseArchive) { archive.Course.Title = "  " }, "course title is required"},
+		{"no capacity", func(archive *models.CourseArchive) { archive.Course.Capacity = new(int) }, "capacity must be at least 1"},
+		{"too many tags", func(archive *models.CourseArchive) {
+			archive.Course.Tags = strings.Split(strings.Repeat("tag,", maxCourseArchiveTags), ",")
+		}, "at most 10 tags"},
+		{"too many lessons", func(archive *models.CourseArchive) {
+			archive.Lessons = make([]models.CourseArchiveLesson, maxCourseArchiveLessons+1)
+		}, "at most 1000 lessons"},
+		{"missing ref", func(archive *models.CourseArchive) { archive.Lessons[0].Ref = "" }, "needs a ref"},
+		{"ref of the course", func(archive *models.CourseArchive) { archive.Lessons[0].Ref = courseArchiveCourseRef }, "ref 'course' is used more than once"},
+		{"duplicate lesson ref", func(archive *models.CourseArchive) { archive.Lessons[1].Ref = "lesson-1" }, "ref 'lesson-1' is used more than once"},
+		{"duplicate assignment ref", func(archive *models.CourseArchive) {
+			archive.Assignments = append(archive.Assignments, archive.Assignments[0])
+			archive.Manifest.AssignmentCount++
+		}, "ref 'assignment-1' is used more than once"},
+		{"untitled lesson", func(archive *models.CourseArchive) { archive.Lessons[1].Title = "" }, "lesson 'lesson-2' has no title"},
+		{"position zero", func(archive *models.CourseArchive) { archive.Lessons[0].Position = 0 }, "lesson 'lesson-1' needs a position of its own"},
+		{"duplicate position", func(archive *models.CourseArchive) { archive.Lessons[0].Position = 2 }, "lesson 'lesson-2' needs a position of its own"},
+		{"released early", func(archive *models.CourseArchive) { archive.Lessons[0].ReleaseAfterDays = -1 }, "cannot be released before"},
+		{"untitled assignment", func(archive *models.CourseArchive) { archive.Assignments[0].Title = "" }, "assignment 'assignment-1' has no title"},
+		{"due early", func(archive *models.CourseArchive) { archive.Assignments[0].DueAfterDays = -1 }, "cannot be due before"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			archive := testCourseArchive()
+			test.change(archive)
+			err := validateCourseArchive(archive)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("error = %v, want one containing %q", err, test.err)
+			}
+		})
+	}
+}
@@ -2,51 +2,108 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	models "orkidslearning/src/models/database"
 	services "orkidslearning/src/services"
+	apperrors "orkidslearning/src/utils/errors"
+	"time"
 
 	"go.opentelemetry.io/otel"
 )
 
-func EnrollInCourse(ctx context.Context, contextService *services.ContextService, username string, courseId string) error {
+// EnrollInCourse enrolls the user in a course as far as its enrollment policy allows and reports the outcome.
+// Learners who find the course full are put on its waitlist, and approval-only courses record a request instead.
+// Cohort-based courses place the user in the requested cohort, or in the earliest one with a free seat.
+// An error is only returned when the attempt could not be made at all.
+//
+// The enrollment itself is a single transaction, so repeated or concurrent calls enroll the user once and only the
+// call that enrolled them reports Changed.
+func EnrollInCourse(ctx context.Context, contextService *services.ContextService, username string, courseId string, cohortId string, inviteCode string) (*models.EnrollmentResult, error) {
 	tracer := otel.Tracer("controller")
 	ctx, span := tracer.Start(ctx, "EnrollInCourse")
 	defer span.End()
 
-	_, userSpan := tracer.Start(ctx, "CheckIfUserExistsByUsername")
-	err := contextService.GetPostgres().CheckIfUserExistsByUsername(username)
-	userSpan.End()
-	if err != nil {
-		log.Println("User does not exist", err)
-		return err
+	// The course is read and the policy applied while AddUserToCourse holds the course lock, so nothing can change
+	// between deciding to admit the learner and enrolling them
+	var course *models.CoursePostgres
+	needsApproval := false
+	admit := func(locked *models.CoursePostgres, cohortId string) (*models.EnrollmentResult, error) {
+		course = locked
+		if course.Status != models.CourseStatusPublished {
+			return nil, fmt.Errorf("course with ID '%s' is not open for enrollment", courseId)
+		}
+		if result := checkEnrollmentWindow(course, time.Now()); result != nil {
+			return result, nil
+		}
+
+		// A valid invite code admits the learner whatever the policy
+		if inviteCode == "" {
+			switch course.EnrollmentPolicy {
+			case models.EnrollmentPolicyInvite:
+				return &models.EnrollmentResult{
+					Status: models.EnrollmentStatusInviteRequired,
+					Reason: "this course can only be joined with an invite code",
+				}, nil
+			case models.EnrollmentPolicyApproval:
+				needsApproval = true
+				return &models.EnrollmentResult{Status: models.EnrollmentStatusPending, CohortId: cohortId}, nil
+			}
+		}
+		return nil, nil
 	}
 
-	_, courseSpan := tracer.Start(ctx, "CheckIfCourseExists")
-	err = contextService.GetPostgres().CheckIfCourseExists(courseId)
-	courseSpan.End()
+	_, addUserToCourseSpan := tracer.Start(ctx, "AddUserToCourse")
+	result, err := contextService.GetPostgres().AddUserToCourse(services.TenantFromContext(ctx), username, courseId, cohortId, inviteCode, admit)
+	addUserToCourseSpan.End()
+	if errors.Is(err, apperrors.ErrInviteCodeInvalid) {
+		return &models.EnrollmentResult{Status: models.EnrollmentStatusInviteInvalid, Reason: err.Error()}, nil
+	}
 	if err != nil {
-		log.Println("Course does not exist", err)
-		return err
+		log.Println("Failed to add user to course", err)
+		return nil, err
 	}
 
-	_, isEnrolledSpan := tracer.Start(ctx, "CheckIfUserIsEnrolledInCourse")
-	isEnrolled, err := contextService.GetPostgres().CheckIfUserIsEnrolledInCourse(username, courseId)
-	isEnrolledSpan.End()
-	if err != nil {
-		log.Println("User is already enrolled in course", err)
-		return err
+	if needsApproval {
+		return requestEnrollment(ctx, contextService, username, courseId, result.CohortId)
 	}
 
-	if isEnrolled {
-		return nil
+	if result.Status == models.EnrollmentStatusWaitlisted {
+		result.Reason = "the course is full"
+		if result.Changed {
+			recordAudit(ctx, contextService, AuditWaitlistJoin, models.AuditTargetEnrollment, enrollmentTarget(courseId, username),
+				nil, map[string]interface{}{"cohortId": result.CohortId, "position": result.WaitlistPosition, "inviteCode": inviteCode})
+			publishEnrollment(ctx, contextService, username, courseId, models.EnrollmentStatusWaitlisted)
+		}
+		return result, nil
 	}
 
-	_, addUserToCourseSpan := tracer.Start(ctx, "AddUserToCourse")
-	err = contextService.GetPostgres().AddUserToCourse(username, courseId)
-	addUserToCourseSpan.End()
-	if err != nil {
-		log.Println("Failed to add user to course", err)
-		return err
+	if result.Changed {
+		contextService.GetEventBus().Publish(ctx, models.Enrolled{
+			Username:    username,
+			CourseId:    courseId,
+			CourseTitle: course.Title,
+			CohortId:    result.CohortId,
+			InviteCode:  inviteCode,
+		})
+	}
+	return result, nil
+}
+
+// checkEnrollmentWindow returns the outcome for attempts made outside the course's enrollment dates, or nil within them
+func checkEnrollmentWindow(course *models.CoursePostgres, now time.Time) *models.EnrollmentResult {
+	if course.EnrollmentOpensAt != nil && now.Before(*course.EnrollmentOpensAt) {
+		return &models.EnrollmentResult{
+			Status: models.EnrollmentStatusNotOpen,
+			Reason: "enrollment opens at " + course.EnrollmentOpensAt.UTC().Format(time.RFC3339),
+		}
+	}
+	if course.EnrollmentClosesAt != nil && !now.Before(*course.EnrollmentClosesAt) {
+		return &models.EnrollmentResult{
+			Status: models.EnrollmentStatusClosed,
+			Reason: "enrollment closed at " + course.EnrollmentClosesAt.UTC().Format(time.RFC3339),
+		}
 	}
 	return nil
 }
@@ -57,7 +114,7 @@ func IsUserEnrolledInCourse(ctx context.Context, contextService *services.Contex
 	defer span.End()
 
 	_, isEnrolledSpan := tracer.Start(ctx, "CheckIfUserIsEnrolledInCourse")
-	isEnrolled, err := contextService.GetPostgres().CheckIfUserIsEnrolledInCourse(username, courseId)
+	isEnrolled, err := contextService.GetPostgres().CheckIfUserIsEnrolledInCourse(services.TenantFromContext(ctx), username, courseId)
 	isEnrolledSpan.End()
 	if err != nil {
 		log.Println("User is already enrolled in course", err)
@@ -67,45 +124,41 @@ func IsUserEnrolledInCourse(ctx context.Context, contextService *services.Contex
 	return isEnrolled, nil
 }
 
-func UnenrollFromCourse(ctx context.Context, contextService *services.ContextService, username string, courseId string) error {
+// UnenrollFromCourse removes the user from a course, or from its waitlist, in a single transaction and reports whether
+// anything changed
+func UnenrollFromCourse(ctx context.Context, contextService *services.ContextService, username string, courseId string) (bool, error) {
 	tracer := otel.Tracer("controller")
 	ctx, span := tracer.Start(ctx, "UnenrollFromCourse")
 	defer span.End()
 
-	_, userSpan := tracer.Start(ctx, "CheckIfUserExistsByUsername")
-	err := contextService.GetPostgres().CheckIfUserExistsByUsername(username)
-	userSpan.End()
-	if err != nil {
-		log.Println("User does not exist", err)
-		return err
-	}
-
-	_, courseSpan := tracer.Start(ctx, "CheckIfCourseExists")
-	err = contextService.GetPostgres().CheckIfCourseExists(courseId)
-	courseSpan.End()
+	_, removeUserFromCourseSpan := tracer.Start(ctx, "RemoveUserFromCourse")
+	unenrolled, leftWaitlist, promoted, err := contextService.GetPostgres().RemoveUserFromCourse(services.TenantFromContext(ctx), username, courseId)
+	removeUserFromCourseSpan.End()
 	if err != nil {
-		log.Println("Course does not exist", err)
-		return err
+		log.Println("Failed to remove user from course", err)
+		return false, err
 	}
 
-	_, isEnrolledSpan := tracer.Start(ctx, "CheckIfUserIsEnrolledInCourse")
-	isEnrolled, err := contextService.GetPostgres().CheckIfUserIsEnrolledInCourse(username, courseId)
-	isEnrolledSpan.End()
-	if err != nil {
-		log.Println("User is already enrolled in course", err)
-		return err
+	if unenrolled || leftWaitlist {
+		contextService.GetEventBus().Publish(ctx, models.Unenrolled{Username: username, CourseId: courseId, Waitlisted: !unenrolled})
 	}
 
-	if !isEnrolled {
-		return nil
+	if promoted != "" {
+		title := courseId
+		_, courseSpan := tracer.Start(ctx, "GetCourseByIdFromDatabase")
+		course, err := contextService.GetPostgres().GetCourseByIdFromDatabase(services.TenantFromContext(ctx), courseId)
+		courseSpan.End()
+		if err != nil {
+			log.Println("Error getting course", err)
+		} else {
+			title = course.Title
+		}
+		contextService.GetEventBus().Publish(ctx, models.Enrolled{Username: promoted, CourseId: courseId, CourseTitle: title, Promoted: true})
 	}
+	return unenrolled || leftWaitlist, nil
+}
 
-	_, removeUserFromCourseSpan := tracer.Start(ctx, "RemoveUserFromCourse")
-	err = contextService.GetPostgres().RemoveUserFromCourse(username, courseId)
-	removeUserFromCourseSpan.End()
-	if err != nil {
-		log.Println("Failed to remove user from course", err)
-		return err
-	}
-	return nil
+// enrollmentTarget identifies an enrollment in the audit log
+func enrollmentTarget(courseId, username string) string {
+	return courseId + "/" + username
 }
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/services"
+)
+
+// Concurrent enrollments of the same learner, such as a double-clicked button, enroll them once and announce it once
+func TestEnrollInCourseConcurrently(t *testing.T) {
+	contextService := testContextService(t)
+	db := contextService.GetPostgres()
+	var announced atomic.Int64
+	contextService.GetEventBus().Subscribe(models.EventEnrolled, func(ctx context.Context, event models.Event) error {
+		announced.Add(1)
+		return nil
+	})
+
+	username := testName("user")
+	if _, err := db.AddUser(models.AddUser{Username: username, Email: username + "@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("adding user: %v", err)
+	}
+	course, err := db.AddCourseToDatabase("", models.AddCourse{
+		Title:            testName("course"),
+		Visibility:       models.CourseVisibilityPublic,
+		EnrollmentPolicy: models.EnrollmentPolicyOpen,
+	})
+	if err != nil {
+		t.Fatalf("adding course: %v", err)
+	}
+	_, err = db.TransitionCourse(course.Id, []string{models.CourseStatusDraft}, models.CourseWorkflowEvent{
+		Action:   models.WorkflowPublish,
+		ToStatus: models.CourseStatusPublished,
+		Actor:    username,
+	})
+	if err != nil {
+		t.Fatalf("publishing course: %v", err)
+	}
+
+	ctx := services.WithRole(services.WithActor(context.Background(), username), models.RoleLearner)
+	const calls = 8
+	var wg sync.WaitGroup
+	results := make(chan *models.EnrollmentResult, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := EnrollInCourse(ctx, contextService, username, course.Id, "", "")
+			if err != nil {
+				t.Errorf("enrolling: %v", err)
+				return
+			}
+			results <- result
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	changed := 0
+	for result := range results {
+		if result.Status != models.EnrollmentStatusEnrolled {
+			t.Errorf("status = %s, want %s", result.Status, models.EnrollmentStatusEnrolled)
+		}
+		if result.Changed {
+			changed++
+		}
+	}
+	if changed != 1 {
+		t.Errorf("%d calls reported a change, want 1", changed)
+	}
+	if count := announced.Load(); count != 1 {
+		t.Errorf("the enrollment was announced %d times, want once", count)
+	}
+
+	enrollments, err := db.GetEnrollmentsForUser(username)
+	if err != nil {
+		t.Fatalf("getting enrollments: %v", err)
+	}
+	if len(enrollments) != 1 {
+		t.Errorf("%d enrollments, want 1", len(enrollments))
+	}
+}
@@ -2,27 +2,45 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"log"
 	models "orkidslearning/src/models/database"
 	services "orkidslearning/src/services"
+	"slices"
 
 	"go.opentelemetry.io/otel"
 )
 
-func GetAllCourses(ctx context.Context, contextService *services.ContextService) ([]models.CoursePostgres, error) {
+// orgCourseAuthorRoles are the organization roles allowed to create courses for their organization
+var orgCourseAuthorRoles = []string{models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleInstructor}
+
+// courseManagerRoles are the platform roles allowed to manage courses that belong to no organization
+var courseManagerRoles = []string{models.RoleInstructor, models.RoleAdmin}
+
+// GetAllCourses retrieves the catalogue courses matching the filter, along with the facet counts filters can be
+// built from
+func GetAllCourses(ctx context.Context, contextService *services.ContextService, filter models.CourseSearch) ([]models.CoursePostgres, *models.CatalogueFacets, error) {
 	tracer := otel.Tracer("controller")
 	ctx, span := tracer.Start(ctx, "GetAllCourses")
 	defer span.End()
 
 	var courses []models.CoursePostgres
 	_, coursesSpan := tracer.Start(ctx, "GetAllCoursesFromDatabase")
-	courses, err := contextService.GetPostgres().GetAllCoursesFromDatabase()
+	courses, err := contextService.GetPostgres().GetAllCoursesFromDatabase(services.TenantFromContext(ctx), filter)
 	coursesSpan.End()
 	if err != nil {
 		log.Println("Error getting all courses ", err)
-		return nil, err
+		return nil, nil, err
+	}
+
+	_, facetsSpan := tracer.Start(ctx, "GetCatalogueFacets")
+	facets, err := contextService.GetPostgres().GetCatalogueFacets(services.TenantFromContext(ctx), filter)
+	facetsSpan.End()
+	if err != nil {
+		log.Println("Error getting catalogue facets ", err)
+		return nil, nil, err
 	}
-	return courses, nil
+	return courses, facets, nil
 }
 
 func GetCourseById(ctx context.Context, contextService *services.ContextService, id string) (*models.CoursePostgres, error) {
@@ -32,12 +50,25 @@ func GetCourseById(ctx context.Context, contextService *services.ContextService,
 
 	var course *models.CoursePostgres
 	_, courseSpan := tracer.Start(ctx, "GetCourseByIdFromDatabase")
-	course, err := contextService.GetPostgres().GetCourseByIdFromDatabase(id)
+	course, err := contextService.GetPostgres().GetCourseByIdFromDatabase(services.TenantFromContext(ctx), id)
 	courseSpan.End()
 	if err != nil {
 		log.Println("Error getting course by id ", err)
 		return nil, err
 	}
+
+	// Courses outside the catalogue stay visible to their managers, reviewers and enrolled learners
+	if course.Status != models.CourseStatusPublished {
+		if _, err := requireCourseEditor(ctx, contextService, id); err == nil {
+			return course, nil
+		}
+		_, enrolledSpan := tracer.Start(ctx, "CheckIfUserIsEnrolledInCourse")
+		enrolled, err := contextService.GetPostgres().CheckIfUserIsEnrolledInCourse(services.TenantFromContext(ctx), services.RequestInfoFromContext(ctx).Actor, id)
+		enrolledSpan.End()
+		if err != nil || !enrolled {
+			return nil, fmt.Errorf("course with ID '%s' does not exist", id)
+		}
+	}
 	return course, nil
 }
 
@@ -46,12 +77,66 @@ func AddCourse(ctx context.Context, contextService *services.ContextService, cou
 	ctx, span := tracer.Start(ctx, "AddCourse")
 	defer span.End()
 
+	if err := requireCourseAuthor(ctx); err != nil {
+		return nil, err
+	}
+
+	tenant := services.TenantFromContext(ctx)
+	if course.Visibility == "" {
+		course.Visibility = models.CourseVisibilityPublic
+		if tenant != "" {
+			course.Visibility = models.CourseVisibilityOrganization
+		}
+	}
+	if course.Visibility != models.CourseVisibilityPublic && course.Visibility != models.CourseVisibilityOrganization {
+		return nil, fmt.Errorf("unknown course visibility '%s'", course.Visibility)
+	}
+	if course.Visibility == models.CourseVisibilityOrganization && tenant == "" {
+		return nil, fmt.Errorf("organization courses can only be created within an organization")
+	}
+	if course.EnrollmentPolicy == "" {
+		course.EnrollmentPolicy = models.EnrollmentPolicyOpen
+	}
+	if err := validateEnrollmentPolicy(course.EnrollmentPolicy, course.EnrollmentOpensAt, course.EnrollmentClosesAt); err != nil {
+		return nil, err
+	}
+	course.Tags = normalizeTags(course.Tags)
+	if err := validateCourseTaxonomy(ctx, contextService, course.CategoryId, course.Subject, course.GradeLevel); err != nil {
+		return nil, err
+	}
+
 	_, addCourseSpan := tracer.Start(ctx, "AddCourseToDatabase")
-	addedCourse, err := contextService.GetPostgres().AddCourseToDatabase(course)
+	addedCourse, err := contextService.GetPostgres().AddCourseToDatabase(tenant, course)
 	addCourseSpan.End()
 	if err != nil {
 		log.Println("Error adding course ", err)
 		return nil, err
 	}
+
+	contextService.GetEventBus().Publish(ctx, models.CourseCreated{Course: *addedCourse})
 	return addedCourse, nil
 }
+
+// requireCourseManager loads a course and fails unless the caller may manage it. Organization courses are managed by
+// the organization's authors acting within it, other courses by platform instructors and admins.
+func requireCourseManager(ctx context.Context, contextService *services.ContextService, courseId string) (*models.CoursePostgres, error) {
+	tracer := otel.Tracer("controller")
+	_, courseSpan := tracer.Start(ctx, "GetCourseByIdFromDatabase")
+	course, err := contextService.GetPostgres().GetCourseByIdFromDatabase(services.TenantFromContext(ctx), courseId)
+	courseSpan.End()
+	if err != nil {
+		log.Println("Course does not exist", err)
+		return nil, fmt.Errorf("course with ID '%s' does not exist", courseId)
+	}
+
+	if course.OrganizationId != "" {
+		if course.OrganizationId != services.TenantFromContext(ctx) || !slices.Contains(orgCourseAuthorRoles, services.TenantRoleFromContext(ctx)) {
+			return nil, fmt.Errorf("only instructors of the course's organization can manage it")
+		}
+		return course, nil
+	}
+	if !slices.Contains(courseManagerRoles, services.RoleFromContext(ctx)) {
+		return nil, fmt.Errorf("only instructors can manage this course")
+	}
+	return course, nil
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"orkidslearning/src/database"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/services"
+
+	"github.com/jackc/pgx"
+)
+
+// testContextService connects to the database named by the TEST_POSTGRES_* variables, skipping the test when
+// TEST_POSTGRES_HOST is not set, and returns a context service backed by it
+func testContextService(t *testing.T) *services.ContextService {
+	t.Helper()
+	host := os.Getenv("TEST_POSTGRES_HOST")
+	if host == "" {
+		t.Skip("TEST_POSTGRES_HOST is not set")
+	}
+	port, err := strconv.ParseUint(testEnv("TEST_POSTGRES_PORT", "5432"), 10, 16)
+	if err != nil {
+		t.Fatalf("invalid TEST_POSTGRES_PORT: %v", err)
+	}
+	db, err := database.NewPostgresDatabase(context.Background(), pgx.ConnConfig{
+		Host:     host,
+		Port:     uint16(port),
+		User:     testEnv("TEST_POSTGRES_USER", "myuser"),
+		Password: testEnv("TEST_POSTGRES_PASSWORD", "mypassword"),
+		Database: testEnv("TEST_POSTGRES_DB", "mydatabase"),
+	}, 20, 10*time.Second)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() { db.Disconnect() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return services.NewContextService(nil, nil, db, nil, nil, nil, nil, nil, services.NewEventBus(1), nil)
+}
+
+func testEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+var testNames atomic.Int64
+
+// testName returns a name no other test run uses
+func testName(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, time.Now().UnixNano(), testNames.Add(1))
+}
+
+// testOrganizationCourse adds a user owning a new organization with one course, and returns the organization, its
+// owner and the course
+func testOrganizationCourse(t *testing.T, db *database.PostgresDatabase) (string, string, *models.CoursePostgres) {
+	t.Helper()
+	owner := testName("user")
+	if _, err := db.AddUser(models.AddUser{Username: owner, Email: owner + "@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("adding user: %v", err)
+	}
+	slug := testName("org")
+	organization, err := db.AddOrganization(models.AddOrganization{Name: slug, Slug: slug, OwnerUsername: owner})
+	if err != nil {
+		t.Fatalf("adding organization: %v", err)
+	}
+	course, err := db.AddCourseToDatabase(organization.Id, models.AddCourse{
+		Title:            testName("course"),
+		Visibility:       models.CourseVisibilityOrganization,
+		CohortBased:      true,
+		EnrollmentPolicy: models.EnrollmentPolicyOpen,
+	})
+	if err != nil {
+		t.Fatalf("adding course: %v", err)
+	}
+	return organization.Id, owner, course
+}
+
+// An owner of one organization cannot manage, read or change the courses, lessons, cohorts and enrollments of another,
+// whatever their platform role
+func TestRequireCourseManagerIsolatesOrganizations(t *testing.T) {
+	contextService := testContextService(t)
+	db := contextService.GetPostgres()
+	orgA, ownerA, courseA := testOrganizationCourse(t, db)
+	orgB, ownerB, courseB := testOrganizationCourse(t, db)
+
+	lessonB, err := db.AddLesson(courseB.Id, models.AddLesson{Title: "Lesson", Position: 1})
+	if err != nil {
+		t.Fatalf("adding lesson: %v", err)
+	}
+	now := time.Now()
+	if _, err := db.AddCohort(courseB.Id, models.AddCohort{Name: "Cohort", StartsAt: now, EndsAt: now.Add(24 * time.Hour)}); err != nil {
+		t.Fatalf("adding cohort: %v", err)
+	}
+	learnerB := testName("user")
+	if _, err := db.AddUser(models.AddUser{Username: learnerB, Email: learnerB + "@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("adding user: %v", err)
+	}
+	if err := db.AddOrganizationMember(orgB, learnerB, models.OrgRoleMember); err != nil {
+		t.Fatalf("adding member: %v", err)
+	}
+	if _, err := db.AddUserToCourse(orgB, learnerB, courseB.Id, "", "", nil); err != nil {
+		t.Fatalf("enrolling: %v", err)
+	}
+
+	ctxB := services.WithTenant(services.WithRole(services.WithActor(context.Background(), ownerB), models.RoleLearner), orgB, models.OrgRoleOwner)
+	if _, err := requireCourseManager(ctxB, contextService, courseB.Id); err != nil {
+		t.Fatalf("the owner of organization B cannot manage its course: %v", err)
+	}
+
+	for _, role := range []string{models.RoleLearner, models.RoleAdmin} {
+		ctxA := services.WithTenant(services.WithRole(services.WithActor(context.Background(), ownerA), role), orgA, models.OrgRoleOwner)
+		if _, err := requireCourseManager(ctxA, contextService, courseA.Id); err != nil {
+			t.Fatalf("the owner of organization A cannot manage its course: %v", err)
+		}
+		if _, err := requireCourseManager(ctxA, contextService, courseB.Id); err == nil {
+			t.Errorf("%s of organization A may manage a course of organization B", role)
+		}
+
+		if _, err := GetCourseCohorts(ctxA, contextService, courseB.Id); err == nil {
+			t.Errorf("%s of organization A read the cohorts of organization B", role)
+		}
+		if _, _, err := GetCourseRoster(ctxA, contextService, courseB.Id, models.RosterSearch{}); err == nil {
+			t.Errorf("%s of organization A read the roster of organization B", role)
+		}
+		if _, err := AddLesson(ctxA, contextService, courseB.Id, models.AddLesson{Title: "Intruder", Position: 1}); err == nil {
+			t.Errorf("%s of organization A added a lesson to a course of organization B", role)
+		}
+		if _, err := UpdateLesson(ctxA, contextService, courseB.Id, lessonB.Id, models.UpdateLesson{Title: "Intruder", Position: 1}); err == nil {
+			t.Errorf("%s of organization A changed a lesson of organization B", role)
+		}
+		if err := RemoveLesson(ctxA, contextService, courseB.Id, lessonB.Id); err == nil {
+			t.Errorf("%s of organization A removed a lesson of organization B", role)
+		}
+		if _, err := AddCohort(ctxA, contextService, courseB.Id, models.AddCohort{Name: "Intruder", StartsAt: now, EndsAt: now.Add(time.Hour)}); err == nil {
+			t.Errorf("%s of organization A added a cohort to a course of organization B", role)
+		}
+		if _, err := BulkUnenroll(ctxA, contextService, courseB.Id, []string{learnerB}); err == nil {
+			t.Errorf("%s of organization A unenrolled learners of organization B", role)
+		}
+	}
+
+	lessons, err := db.GetLessonsForCourse(courseB.Id)
+	if err != nil {
+		t.Fatalf("getting lessons: %v", err)
+	}
+	if len(lessons) != 1 || lessons[0].Title != "Lesson" {
+		t.Errorf("the lessons of organization B changed: %+v", lessons)
+	}
+	cohorts, err := db.GetCohortsForCourse(courseB.Id)
+	if err != nil {
+		t.Fatalf("getting cohorts: %v", err)
+	}
+	if len(cohorts) != 1 {
+		t.Errorf("organization B has %d cohorts, want 1", len(cohorts))
+	}
+	enrolled, err := db.CheckIfUserIsEnrolledInCourse(orgB, learnerB, courseB.Id)
+	if err != nil {
+		t.Fatalf("checking enrollment: %v", err)
+	}
+	if !enrolled {
+		t.Errorf("the learner of organization B was unenrolled")
+	}
+}
+
+// Learners cannot create courses, whether on the platform or within an organization. The check comes before any
+// database access, so no database is needed.
+func TestAddCourseRequiresCourseAuthor(t *testing.T) {
+	contexts := map[string]context.Context{
+		"platform learner":        services.WithRole(context.Background(), models.RoleLearner),
+		"platform reviewer":       services.WithRole(context.Background(), models.RoleReviewer),
+		"organization member":     services.WithTenant(services.WithRole(context.Background(), models.RoleInstructor), "1", models.OrgRoleMember),
+		"organization non-member": services.WithTenant(services.WithRole(context.Background(), models.RoleAdmin), "1", ""),
+	}
+	for name, ctx := range contexts {
+		if _, err := AddCourse(ctx, nil, models.AddCourse{Title: "Course"}); err == nil {
+			t.Errorf("%s created a course", name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// GetMyCourses lists the courses the user is enrolled in with their progress, next lesson and the assignments
+// they still have to hand in
+func GetMyCourses(ctx context.Context, contextService *services.ContextService, username string, filter models.MyCoursesSearch) ([]models.MyCourse, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetMyCourses")
+	defer span.End()
+
+	if filter.State != "" && !slices.Contains(models.CourseStates, filter.State) {
+		return nil, 0, fmt.Errorf("unknown course state '%s'", filter.State)
+	}
+
+	_, coursesSpan := tracer.Start(ctx, "GetMyCourses")
+	courses, total, err := contextService.GetPostgres().GetMyCourses(services.TenantFromContext(ctx), username, filter)
+	coursesSpan.End()
+	if err != nil {
+		log.Println("Error getting enrolled courses", err)
+		return nil, 0, err
+	}
+	if len(courses) == 0 {
+		return courses, total, nil
+	}
+
+	courseIds := make([]string, len(courses))
+	for i, course := range courses {
+		courseIds[i] = course.Course.Id
+	}
+	_, assignmentsSpan := tracer.Start(ctx, "GetAssignmentsForUser")
+	assignments, err := contextService.GetPostgres().GetAssignmentsForUser(username, courseIds)
+	assignmentsSpan.End()
+	if err != nil {
+		log.Println("Error getting assignments", err)
+		return nil, 0, err
+	}
+
+	now := time.Now()
+	for i := range courses {
+		course := &courses[i]
+		if course.NextLesson != nil {
+			releaseLesson(course.NextLesson, course.ReleaseStart, now)
+		}
+		for _, assignment := range assignments {
+			if assignment.CourseId != course.Course.Id || assignment.SubmittedAt != nil {
+				continue
+			}
+			dueAssignment(&assignment, course.ReleaseStart, now)
+			course.DueAssignments = append(course.DueAssignments, assignment)
+		}
+		slices.SortFunc(course.DueAssignments, func(a, b models.Assignment) int {
+			return a.DueAt.Compare(*b.DueAt)
+		})
+	}
+	return courses, total, nil
+}
+
+// ArchiveMyCourse moves a course the user is enrolled in to or from the archived section of their dashboard
+func ArchiveMyCourse(ctx context.Context, contextService *services.ContextService, username, courseId string, archived bool) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ArchiveMyCourse")
+	defer span.End()
+
+	_, archiveSpan := tracer.Start(ctx, "SetEnrollmentArchived")
+	err := contextService.GetPostgres().SetEnrollmentArchived(username, courseId, archived)
+	archiveSpan.End()
+	if err != nil {
+		log.Println("Error archiving course", err)
+		return err
+	}
+	return nil
+}
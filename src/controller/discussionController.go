@@ -0,0 +1,376 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// requireDiscussionAccess fails unless the user may read and post in a course's discussions: learners enrolled in
+// it and the instructors who manage it. It reports whether the user moderates them, which course managers do.
+func requireDiscussionAccess(ctx context.Context, contextService *services.ContextService, username, courseId string) (bool, error) {
+	if _, err := requireCourseManager(ctx, contextService, courseId); err == nil {
+		return true, nil
+	}
+
+	tracer := otel.Tracer("controller")
+	_, enrolledSpan := tracer.Start(ctx, "CheckIfUserIsEnrolledInCourse")
+	enrolled, err := contextService.GetPostgres().CheckIfUserIsEnrolledInCourse(services.TenantFromContext(ctx), username, courseId)
+	enrolledSpan.End()
+	if err != nil {
+		log.Println("Error checking enrollment", err)
+		return false, err
+	}
+	if !enrolled {
+		return false, fmt.Errorf("only learners enrolled in course '%s' and its instructors can take part in its discussions", courseId)
+	}
+	return false, nil
+}
+
+// withholdHiddenPost blanks a hidden post for users who do not moderate the discussion
+func withholdHiddenPost(post *models.DiscussionPost, moderator bool) {
+	if post.Hidden && !moderator {
+		post.Body = ""
+		post.Reactions = map[string]int{}
+	}
+}
+
+// discussionPostTarget identifies a discussion post in the audit log
+func discussionPostTarget(threadId, postId string) string {
+	return threadId + "/" + postId
+}
+
+func GetDiscussionThreads(ctx context.Context, contextService *services.ContextService, username, courseId string, filter models.DiscussionThreadSearch) ([]models.DiscussionThread, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetDiscussionThreads")
+	defer span.End()
+
+	moderator, err := requireDiscussionAccess(ctx, contextService, username, courseId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	_, threadsSpan := tracer.Start(ctx, "GetDiscussionThreads")
+	threads, total, err := contextService.GetPostgres().GetDiscussionThreads(courseId, moderator, filter)
+	threadsSpan.End()
+	if err != nil {
+		log.Println("Error getting discussion threads", err)
+		return nil, 0, err
+	}
+	return threads, total, nil
+}
+
+// StartDiscussionThread opens a thread about a course, or one of its lessons, with the user's opening post.
+// Like replies, it is subject to the posting rate limit and the word filters.
+func StartDiscussionThread(ctx context.Context, contextService *services.ContextService, username, courseId string, add models.AddDiscussionThread) (*models.DiscussionThread, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "StartDiscussionThread")
+	defer span.End()
+
+	moderator, err := requireDiscussionAccess(ctx, contextService, username, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPostRate(ctx, contextService, username, moderator); err != nil {
+		return nil, err
+	}
+	flagged, err := checkWordFilters(ctx, contextService, add.Title+"\n"+add.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddDiscussionThread")
+	thread, err := contextService.GetPostgres().AddDiscussionThread(courseId, username, add)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error starting discussion thread", err)
+		return nil, err
+	}
+
+	if len(flagged) > 0 {
+		opening, _, err := contextService.GetPostgres().GetDiscussionPosts(thread.Id, username, models.Pagination{Page: 1, PageSize: 1})
+		if err != nil || len(opening) == 0 {
+			log.Println("Error getting opening post to flag", err)
+		} else {
+			flagDiscussionPost(ctx, contextService, courseId, &opening[0], flagged)
+		}
+	}
+	return thread, nil
+}
+
+// GetDiscussionThread retrieves a thread along with a page of its posts, opening post first
+func GetDiscussionThread(ctx context.Context, contextService *services.ContextService, username, courseId, threadId string, page models.Pagination) (*models.DiscussionThread, []models.DiscussionPost, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetDiscussionThread")
+	defer span.End()
+
+	moderator, err := requireDiscussionAccess(ctx, contextService, username, courseId)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	_, threadSpan := tracer.Start(ctx, "GetDiscussionThread")
+	thread, err := contextService.GetPostgres().GetDiscussionThread(courseId, threadId, moderator)
+	threadSpan.End()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	_, postsSpan := tracer.Start(ctx, "GetDiscussionPosts")
+	posts, total, err := contextService.GetPostgres().GetDiscussionPosts(thread.Id, username, page)
+	postsSpan.End()
+	if err != nil {
+		log.Println("Error getting discussion posts", err)
+		return nil, nil, 0, err
+	}
+	for i := range posts {
+		withholdHiddenPost(&posts[i], moderator)
+	}
+	return thread, posts, total, nil
+}
+
+// ReplyToDiscussion adds the user's reply to a thread, or to one of its posts when ParentId is set
+func ReplyToDiscussion(ctx context.Context, contextService *services.ContextService, username, courseId, threadId string, add models.AddDiscussionPost) (*models.DiscussionPost, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ReplyToDiscussion")
+	defer span.End()
+
+	moderator, err := requireDiscussionAccess(ctx, contextService, username, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPostRate(ctx, contextService, username, moderator); err != nil {
+		return nil, err
+	}
+	flagged, err := checkWordFilters(ctx, contextService, add.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddDiscussionPost")
+	post, err := contextService.GetPostgres().AddDiscussionPost(courseId, threadId, username, add)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error replying to discussion", err)
+		return nil, err
+	}
+
+	flagDiscussionPost(ctx, contextService, courseId, post, flagged)
+	return post, nil
+}
+
+// EditDiscussionPost replaces the body of one of the user's own posts, keeping the previous body in its history.
+// Hidden posts cannot be edited.
+func EditDiscussionPost(ctx context.Context, contextService *services.ContextService, username, courseId, threadId, postId string, edit models.EditDiscussionPost) (*models.DiscussionPost, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "EditDiscussionPost")
+	defer span.End()
+
+	if _, err := requireDiscussionAccess(ctx, contextService, username, courseId); err != nil {
+		return nil, err
+	}
+
+	_, postSpan := tracer.Start(ctx, "GetDiscussionPost")
+	post, err := contextService.GetPostgres().GetDiscussionPost(courseId, threadId, postId, username)
+	postSpan.End()
+	if err != nil {
+		return nil, err
+	}
+	if post.Author != username {
+		return nil, fmt.Errorf("only the author of a post can edit it")
+	}
+	if post.Hidden || post.Deleted {
+		return nil, fmt.Errorf("hidden or deleted posts cannot be edited")
+	}
+	flagged, err := checkWordFilters(ctx, contextService, edit.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, editSpan := tracer.Start(ctx, "EditDiscussionPost")
+	edited, err := contextService.GetPostgres().EditDiscussionPost(courseId, threadId, postId, username, edit.Body)
+	editSpan.End()
+	if err != nil {
+		log.Println("Error editing discussion post", err)
+		return nil, err
+	}
+
+	flagDiscussionPost(ctx, contextService, courseId, edited, flagged)
+	return edited, nil
+}
+
+// GetDiscussionPostHistory retrieves the earlier bodies of a post. The history of a hidden post is only shown to
+// moderators.
+func GetDiscussionPostHistory(ctx context.Context, contextService *services.ContextService, username, courseId, threadId, postId string) ([]models.DiscussionPostRevision, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetDiscussionPostHistory")
+	defer span.End()
+
+	moderator, err := requireDiscussionAccess(ctx, contextService, username, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := contextService.GetPostgres().GetDiscussionThread(courseId, threadId, moderator); err != nil {
+		return nil, err
+	}
+	post, err := contextService.GetPostgres().GetDiscussionPost(courseId, threadId, postId, username)
+	if err != nil {
+		return nil, err
+	}
+	if post.Hidden && !moderator {
+		return nil, fmt.Errorf("post not found")
+	}
+
+	_, revisionsSpan := tracer.Start(ctx, "GetDiscussionPostRevisions")
+	revisions, err := contextService.GetPostgres().GetDiscussionPostRevisions(post.Id)
+	revisionsSpan.End()
+	if err != nil {
+		log.Println("Error getting discussion post history", err)
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// ReactToDiscussionPost adds or removes one of the user's reactions to a visible post
+func ReactToDiscussionPost(ctx context.Context, contextService *services.ContextService, username, courseId, threadId, postId, reaction string, reacted bool) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ReactToDiscussionPost")
+	defer span.End()
+
+	if !slices.Contains(models.DiscussionReactions, reaction) {
+		return fmt.Errorf("unknown reaction '%s'", reaction)
+	}
+
+	moderator, err := requireDiscussionAccess(ctx, contextService, username, courseId)
+	if err != nil {
+		return err
+	}
+
+	if _, err := contextService.GetPostgres().GetDiscussionThread(courseId, threadId, moderator); err != nil {
+		return err
+	}
+	post, err := contextService.GetPostgres().GetDiscussionPost(courseId, threadId, postId, username)
+	if err != nil {
+		return err
+	}
+	if post.Hidden || post.Deleted {
+		return fmt.Errorf("hidden or deleted posts cannot be reacted to")
+	}
+
+	_, reactSpan := tracer.Start(ctx, "SetDiscussionReaction")
+	err = contextService.GetPostgres().SetDiscussionReaction(post.Id, username, reaction, reacted)
+	reactSpan.End()
+	if err != nil {
+		log.Println("Error reacting to discussion post", err)
+		return err
+	}
+	return nil
+}
+
+// PinDiscussionThread keeps a thread at the top of the course's discussions, or releases it
+func PinDiscussionThread(ctx context.Context, contextService *services.ContextService, courseId, threadId string, pinned bool) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "PinDiscussionThread")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return err
+	}
+
+	_, pinSpan := tracer.Start(ctx, "SetDiscussionThreadPinned")
+	err := contextService.GetPostgres().SetDiscussionThreadPinned(courseId, threadId, pinned)
+	pinSpan.End()
+	if err != nil {
+		log.Println("Error pinning discussion thread", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditDiscussionPin, models.AuditTargetDiscussion, threadId,
+		nil, map[string]bool{"pinned": pinned})
+	return nil
+}
+
+// MarkDiscussionAnswer marks a reply as the answer to its thread, or clears the answer when postId is empty
+func MarkDiscussionAnswer(ctx context.Context, contextService *services.ContextService, courseId, threadId, postId string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "MarkDiscussionAnswer")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return err
+	}
+
+	_, threadSpan := tracer.Start(ctx, "GetDiscussionThread")
+	thread, err := contextService.GetPostgres().GetDiscussionThread(courseId, threadId, true)
+	threadSpan.End()
+	if err != nil {
+		return err
+	}
+
+	_, answerSpan := tracer.Start(ctx, "SetDiscussionAnswer")
+	err = contextService.GetPostgres().SetDiscussionAnswer(courseId, threadId, postId)
+	answerSpan.End()
+	if err != nil {
+		log.Println("Error marking discussion answer", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditDiscussionAnswer, models.AuditTargetDiscussion, threadId,
+		map[string]string{"answerPostId": thread.AnswerPostId}, map[string]string{"answerPostId": postId})
+	return nil
+}
+
+// HideDiscussionThread hides a thread from everyone but moderators, or shows it again
+func HideDiscussionThread(ctx context.Context, contextService *services.ContextService, courseId, threadId string, hidden bool) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "HideDiscussionThread")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return err
+	}
+
+	actor := services.RequestInfoFromContext(ctx).Actor
+	_, hideSpan := tracer.Start(ctx, "SetDiscussionThreadHidden")
+	err := contextService.GetPostgres().SetDiscussionThreadHidden(courseId, threadId, actor, hidden)
+	hideSpan.End()
+	if err != nil {
+		log.Println("Error hiding discussion thread", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditDiscussionHide, models.AuditTargetDiscussion, threadId,
+		nil, map[string]bool{"hidden": hidden})
+	return nil
+}
+
+// HideDiscussionPost withholds a post from everyone but moderators, or shows it again
+func HideDiscussionPost(ctx context.Context, contextService *services.ContextService, courseId, threadId, postId string, hidden bool) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "HideDiscussionPost")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return err
+	}
+
+	actor := services.RequestInfoFromContext(ctx).Actor
+	_, hideSpan := tracer.Start(ctx, "SetDiscussionPostHidden")
+	err := contextService.GetPostgres().SetDiscussionPostHidden(courseId, threadId, postId, actor, hidden)
+	hideSpan.End()
+	if err != nil {
+		log.Println("Error hiding discussion post", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditDiscussionHide, models.AuditTargetDiscussion, discussionPostTarget(threadId, postId),
+		nil, map[string]bool{"hidden": hidden})
+	return nil
+}
@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// inviteCodeBytes is the entropy of a generated invite code, which encodes to 8 characters
+const inviteCodeBytes = 5
+
+// validateEnrollmentPolicy fails for unknown policies and windows that close before they open
+func validateEnrollmentPolicy(policy string, opensAt, closesAt *time.Time) error {
+	if !slices.Contains(models.EnrollmentPolicies, policy) {
+		return fmt.Errorf("unknown enrollment policy '%s'", policy)
+	}
+	if opensAt != nil && closesAt != nil && !closesAt.After(*opensAt) {
+		return fmt.Errorf("enrollment must close after it opens")
+	}
+	return nil
+}
+
+func SetEnrollmentPolicy(ctx context.Context, contextService *services.ContextService, courseId string, policy models.UpdateEnrollmentPolicy) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SetEnrollmentPolicy")
+	defer span.End()
+
+	if err := validateEnrollmentPolicy(policy.EnrollmentPolicy, policy.EnrollmentOpensAt, policy.EnrollmentClosesAt); err != nil {
+		return err
+	}
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return err
+	}
+
+	_, policySpan := tracer.Start(ctx, "SetEnrollmentPolicy")
+	err = contextService.GetPostgres().SetEnrollmentPolicy(courseId, policy)
+	policySpan.End()
+	if err != nil {
+		log.Println("Error setting enrollment policy", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditEnrollmentPolicy, models.AuditTargetCourse, courseId,
+		models.UpdateEnrollmentPolicy{
+			EnrollmentPolicy:   course.EnrollmentPolicy,
+			EnrollmentOpensAt:  course.EnrollmentOpensAt,
+			EnrollmentClosesAt: course.EnrollmentClosesAt,
+		}, policy)
+	return nil
+}
+
+// requestEnrollment records the user's request to join an approval-only course, or reports the request they already made
+func requestEnrollment(ctx context.Context, contextService *services.ContextService, username, courseId, cohortId string) (*models.EnrollmentResult, error) {
+	tracer := otel.Tracer("controller")
+
+	_, addSpan := tracer.Start(ctx, "AddEnrollmentRequest")
+	request, added, err := contextService.GetPostgres().AddEnrollmentRequest(services.TenantFromContext(ctx), username, courseId, cohortId)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error requesting enrollment", err)
+		return nil, err
+	}
+
+	if added {
+		recordAudit(ctx, contextService, AuditEnrollmentRequest, models.AuditTargetEnrollment, enrollmentTarget(courseId, username), nil, request)
+		publishEnrollment(ctx, contextService, username, courseId, models.EnrollmentStatusPending)
+	}
+
+	if request.Status == models.EnrollmentRequestRejected {
+		return &models.EnrollmentResult{
+			Status:    models.EnrollmentStatusRejected,
+			Reason:    request.Reason,
+			CohortId:  request.CohortId,
+			RequestId: request.Id,
+		}, nil
+	}
+	return &models.EnrollmentResult{
+		Status:    models.EnrollmentStatusPending,
+		Reason:    "an instructor has to approve the enrollment",
+		CohortId:  request.CohortId,
+		RequestId: request.Id,
+	}, nil
+}
+
+func GetEnrollmentRequests(ctx context.Context, contextService *services.ContextService, courseId string, filter models.EnrollmentRequestSearch) ([]models.EnrollmentRequest, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetEnrollmentRequests")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return nil, 0, err
+	}
+
+	_, requestsSpan := tracer.Start(ctx, "GetEnrollmentRequests")
+	requests, total, err := contextService.GetPostgres().GetEnrollmentRequests(courseId, filter)
+	requestsSpan.End()
+	if err != nil {
+		log.Println("Error getting enrollment requests", err)
+		return nil, 0, err
+	}
+	return requests, total, nil
+}
+
+// ApproveEnrollmentRequest enrolls the learner behind a pending or previously rejected request
+func ApproveEnrollmentRequest(ctx context.Context, contextService *services.ContextService, courseId, requestId string) (*models.EnrollmentResult, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ApproveEnrollmentRequest")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := services.RequestInfoFromContext(ctx).Actor
+	_, approveSpan := tracer.Start(ctx, "ApproveEnrollmentRequest")
+	request, position, err := contextService.GetPostgres().ApproveEnrollmentRequest(services.TenantFromContext(ctx), courseId, requestId, actor)
+	approveSpan.End()
+	if err != nil {
+		log.Println("Error approving enrollment request", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditEnrollmentApprove, models.AuditTargetEnrollment, enrollmentTarget(courseId, request.Username),
+		nil, map[string]interface{}{"requestId": request.Id, "cohortId": request.CohortId, "waitlistPosition": position})
+
+	if position > 0 {
+		notifyUser(ctx, contextService, request.Username, NotificationEnrollmentApproved,
+			fmt.Sprintf("Your request to join course '%s' was approved", course.Title))
+		publishEnrollment(ctx, contextService, request.Username, courseId, models.EnrollmentStatusWaitlisted)
+		return &models.EnrollmentResult{
+			Status:           models.EnrollmentStatusWaitlisted,
+			Reason:           "the course is full",
+			CohortId:         request.CohortId,
+			WaitlistPosition: position,
+			RequestId:        request.Id,
+			Changed:          true,
+		}, nil
+	}
+	contextService.GetEventBus().Publish(ctx, models.Enrolled{Username: request.Username, CourseId: courseId, CourseTitle: course.Title, CohortId: request.CohortId})
+	return &models.EnrollmentResult{Status: models.EnrollmentStatusEnrolled, CohortId: request.CohortId, RequestId: request.Id, Changed: true}, nil
+}
+
+func RejectEnrollmentRequest(ctx context.Context, contextService *services.ContextService, courseId, requestId, reason string) (*models.EnrollmentRequest, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RejectEnrollmentRequest")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := services.RequestInfoFromContext(ctx).Actor
+	_, rejectSpan := tracer.Start(ctx, "RejectEnrollmentRequest")
+	request, err := contextService.GetPostgres().RejectEnrollmentRequest(courseId, requestId, actor, reason)
+	rejectSpan.End()
+	if err != nil {
+		log.Println("Error rejecting enrollment request", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditEnrollmentReject, models.AuditTargetEnrollment, enrollmentTarget(courseId, request.Username),
+		nil, map[string]string{"requestId": request.Id, "reason": reason})
+	notifyUser(ctx, contextService, request.Username, NotificationEnrollmentRejected,
+		fmt.Sprintf("Your request to join course '%s' was declined: %s", course.Title, reason))
+	publishEnrollment(ctx, contextService, request.Username, courseId, models.EnrollmentStatusRejected)
+	return request, nil
+}
+
+func CreateInviteCode(ctx context.Context, contextService *services.ContextService, courseId string, invite models.AddInviteCode) (*models.InviteCode, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "CreateInviteCode")
+	defer span.End()
+
+	if invite.ExpiresAt != nil && !invite.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("invite code must expire in the future")
+	}
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, inviteCodeBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	code := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw)
+
+	actor := services.RequestInfoFromContext(ctx).Actor
+	_, addSpan := tracer.Start(ctx, "AddInviteCode")
+	added, err := contextService.GetPostgres().AddInviteCode(courseId, code, actor, invite.MaxUses, invite.ExpiresAt)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error adding invite code", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditInviteCodeCreate, models.AuditTargetCourse, courseId, nil, added)
+	return added, nil
+}
+
+func GetInviteCodes(ctx context.Context, contextService *services.ContextService, courseId string) ([]models.InviteCode, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetInviteCodes")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return nil, err
+	}
+
+	_, invitesSpan := tracer.Start(ctx, "GetInviteCodes")
+	invites, err := contextService.GetPostgres().GetInviteCodes(courseId)
+	invitesSpan.End()
+	if err != nil {
+		log.Println("Error getting invite codes", err)
+		return nil, err
+	}
+	return invites, nil
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+)
+
+// RegisterEventSubscribers hooks the audit log, notifications and the event stream onto the domain events.
+// The audit trail is written synchronously so it is complete when the request returns; anything that only informs
+// the user runs asynchronously.
+func RegisterEventSubscribers(contextService *services.ContextService) {
+	bus := contextService.GetEventBus()
+
+	bus.Subscribe(models.EventUserSignedUp, func(ctx context.Context, event models.Event) error {
+		user := event.(models.UserSignedUp).User
+		recordAudit(services.WithActor(ctx, user.Username), contextService, AuditSignup, models.AuditTargetUser, user.Id,
+			nil, map[string]string{"username": user.Username, "email": user.Email, "role": user.Role})
+		return nil
+	})
+
+	bus.Subscribe(models.EventCourseCreated, func(ctx context.Context, event models.Event) error {
+		created := event.(models.CourseCreated)
+		if created.ClonedFrom != "" {
+			recordAudit(ctx, contextService, AuditCourseClone, models.AuditTargetCourse, created.Course.Id, nil,
+				map[string]interface{}{"course": created.Course, "clonedFrom": created.ClonedFrom})
+			return nil
+		}
+		if created.ImportedFrom != nil {
+			recordAudit(ctx, contextService, AuditCourseImport, models.AuditTargetCourse, created.Course.Id, nil,
+				map[string]interface{}{"course": created.Course, "importedFrom": created.ImportedFrom})
+			return nil
+		}
+		recordAudit(ctx, contextService, AuditCourseCreate, models.AuditTargetCourse, created.Course.Id, nil, created.Course)
+		return nil
+	})
+
+	bus.Subscribe(models.EventEnrolled, func(ctx context.Context, event models.Event) error {
+		enrolled := event.(models.Enrolled)
+		if enrolled.Promoted {
+			recordAudit(ctx, contextService, AuditWaitlistPromote, models.AuditTargetEnrollment, enrollmentTarget(enrolled.CourseId, enrolled.Username),
+				map[string]bool{"enrolled": false}, map[string]bool{"enrolled": true})
+			return nil
+		}
+		recordAudit(ctx, contextService, AuditEnroll, models.AuditTargetEnrollment, enrollmentTarget(enrolled.CourseId, enrolled.Username),
+			map[string]interface{}{"enrolled": false},
+			map[string]interface{}{"enrolled": true, "cohortId": enrolled.CohortId, "inviteCode": enrolled.InviteCode})
+		return nil
+	})
+	bus.SubscribeAsync(models.EventEnrolled, func(ctx context.Context, event models.Event) error {
+		enrolled := event.(models.Enrolled)
+		if enrolled.Promoted {
+			notifyUser(ctx, contextService, enrolled.Username, NotificationWaitlistPromoted,
+				fmt.Sprintf("A seat opened up and you are now enrolled in course '%s'", enrolled.CourseTitle))
+		} else {
+			notifyUser(ctx, contextService, enrolled.Username, NotificationEnrolled, fmt.Sprintf("You are now enrolled in '%s'", enrolled.CourseTitle))
+		}
+		publishEnrollment(ctx, contextService, enrolled.Username, enrolled.CourseId, models.EnrollmentStatusEnrolled)
+		return nil
+	})
+
+	bus.Subscribe(models.EventUnenrolled, func(ctx context.Context, event models.Event) error {
+		unenrolled := event.(models.Unenrolled)
+		target := enrollmentTarget(unenrolled.CourseId, unenrolled.Username)
+		if unenrolled.Waitlisted {
+			recordAudit(ctx, contextService, AuditWaitlistLeave, models.AuditTargetEnrollment, target, nil, nil)
+			return nil
+		}
+		recordAudit(ctx, contextService, AuditUnenroll, models.AuditTargetEnrollment, target,
+			map[string]bool{"enrolled": true}, map[string]bool{"enrolled": false})
+		return nil
+	})
+	bus.SubscribeAsync(models.EventUnenrolled, func(ctx context.Context, event models.Event) error {
+		unenrolled := event.(models.Unenrolled)
+		publishEnrollment(ctx, contextService, unenrolled.Username, unenrolled.CourseId, streamUnenrolled)
+		return nil
+	})
+
+	bus.Subscribe(models.EventLessonCompleted, func(ctx context.Context, event models.Event) error {
+		completed := event.(models.LessonCompleted)
+		if completed.CourseCompleted {
+			recordAudit(ctx, contextService, AuditCourseComplete, models.AuditTargetEnrollment,
+				enrollmentTarget(completed.CourseId, completed.Username),
+				map[string]bool{"completed": false}, map[string]bool{"completed": true})
+		}
+		return nil
+	})
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"log"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Job kinds
+const (
+	JobDataExport     = "account.export"
+	JobUserImport     = "user.import"
+	JobSendEmail      = "email.send"
+	JobDeliverWebhook = "webhook.deliver"
+)
+
+// RegisterJobHandlers tells the job service how to run each kind of job
+func RegisterJobHandlers(contextService *services.ContextService) {
+	jobs := contextService.GetJobService()
+	jobs.Handle(JobDataExport, func(ctx context.Context, job models.Job) error {
+		return buildDataExport(ctx, contextService, job)
+	})
+	jobs.Handle(JobUserImport, func(ctx context.Context, job models.Job) error {
+		return runUserImport(ctx, contextService, job)
+	})
+	jobs.Handle(JobSendEmail, func(ctx context.Context, job models.Job) error {
+		return deliverEmail(ctx, contextService, job)
+	})
+	jobs.Handle(JobDeliverWebhook, func(ctx context.Context, job models.Job) error {
+		return deliverWebhook(ctx, contextService, job)
+	})
+}
+
+// lastAttempt reports whether a failure of the running job makes it dead
+func lastAttempt(job models.Job) bool {
+	return job.Attempts >= job.MaxAttempts
+}
+
+func SearchJobs(ctx context.Context, contextService *services.ContextService, filter models.JobSearch) ([]models.Job, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SearchJobs")
+	defer span.End()
+
+	_, searchSpan := tracer.Start(ctx, "SearchJobs")
+	jobs, total, err := contextService.GetPostgres().SearchJobs(filter)
+	searchSpan.End()
+	if err != nil {
+		log.Println("Error searching jobs", err)
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+func GetJob(ctx context.Context, contextService *services.ContextService, jobId string) (*models.Job, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetJob")
+	defer span.End()
+
+	_, jobSpan := tracer.Start(ctx, "GetJob")
+	job, err := contextService.GetPostgres().GetJob(jobId)
+	jobSpan.End()
+	if err != nil {
+		log.Println("Error getting job", err)
+		return nil, err
+	}
+	return job, nil
+}
+
+// RetryJob runs a dead job once more, or a job waiting out its backoff straight away
+func RetryJob(ctx context.Context, contextService *services.ContextService, jobId string) (*models.Job, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RetryJob")
+	defer span.End()
+
+	_, retrySpan := tracer.Start(ctx, "RetryJob")
+	job, err := contextService.GetPostgres().RetryJob(jobId)
+	retrySpan.End()
+	if err != nil {
+		log.Println("Error retrying job", err)
+		return nil, err
+	}
+	contextService.GetJobService().Wake(job.Queue)
+
+	recordAudit(ctx, contextService, AuditJobRetry, models.AuditTargetJob, job.Id, nil,
+		map[string]interface{}{"queue": job.Queue, "kind": job.Kind, "maxAttempts": job.MaxAttempts})
+	return job, nil
+}
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+func AddLesson(ctx context.Context, contextService *services.ContextService, courseId string, lesson models.AddLesson) (*models.Lesson, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "AddLesson")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireEditableCourse(course); err != nil {
+		return nil, err
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddLesson")
+	added, err := contextService.GetPostgres().AddLesson(courseId, lesson)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error adding lesson", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditLessonCreate, models.AuditTargetCourse, courseId, nil, added)
+	return added, nil
+}
+
+// UpdateLesson edits a lesson of a course's working copy. Learners pinned to a published version keep seeing the
+// lesson as it was until they move to a newer version.
+func UpdateLesson(ctx context.Context, contextService *services.ContextService, courseId, lessonId string, lesson models.UpdateLesson) (*models.Lesson, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "UpdateLesson")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireEditableCourse(course); err != nil {
+		return nil, err
+	}
+
+	_, lessonsSpan := tracer.Start(ctx, "GetLessonsForCourse")
+	lessons, err := contextService.GetPostgres().GetLessonsForCourse(courseId)
+	lessonsSpan.End()
+	if err != nil {
+		log.Println("Error getting lessons", err)
+		return nil, err
+	}
+	index := slices.IndexFunc(lessons, func(lesson models.Lesson) bool { return lesson.Id == lessonId })
+	if index < 0 {
+		return nil, fmt.Errorf("lesson not found")
+	}
+
+	_, updateSpan := tracer.Start(ctx, "UpdateLesson")
+	updated, err := contextService.GetPostgres().UpdateLesson(courseId, lessonId, lesson)
+	updateSpan.End()
+	if err != nil {
+		log.Println("Error updating lesson", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditLessonUpdate, models.AuditTargetCourse, courseId, lessons[index], updated)
+	return updated, nil
+}
+
+// RemoveLesson removes a lesson from a course's working copy. Learners pinned to a version that includes it keep it.
+func RemoveLesson(ctx context.Context, contextService *services.ContextService, courseId, lessonId string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RemoveLesson")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return err
+	}
+	if err := requireEditableCourse(course); err != nil {
+		return err
+	}
+
+	_, removeSpan := tracer.Start(ctx, "RemoveLesson")
+	removed, err := contextService.GetPostgres().RemoveLesson(courseId, lessonId)
+	removeSpan.End()
+	if err != nil {
+		log.Println("Error removing lesson", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditLessonRemove, models.AuditTargetCourse, courseId, removed, nil)
+	return nil
+}
+
+// GetCourseLessons lists a course's lessons as the user sees them. Course managers see every lesson of the working
+// copy; learners see the lessons of the version they are pinned to, when each lesson is released, and the content of
+// a lesson is withheld until then.
+func GetCourseLessons(ctx context.Context, contextService *services.ContextService, username string, courseId string) ([]models.Lesson, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseLessons")
+	defer span.End()
+
+	_, manageErr := requireCourseManager(ctx, contextService, courseId)
+
+	var releaseStart *time.Time
+	if manageErr != nil {
+		_, startSpan := tracer.Start(ctx, "GetReleaseStart")
+		start, err := contextService.GetPostgres().GetReleaseStart(services.TenantFromContext(ctx), username, courseId)
+		startSpan.End()
+		if err != nil {
+			log.Println("Error getting release start", err)
+			return nil, err
+		}
+		if start == nil {
+			return nil, fmt.Errorf("user '%s' is not enrolled in course '%s'", username, courseId)
+		}
+		releaseStart = start
+	}
+
+	var lessons []models.Lesson
+	var err error
+	if releaseStart == nil {
+		_, lessonsSpan := tracer.Start(ctx, "GetLessonsForCourse")
+		lessons, err = contextService.GetPostgres().GetLessonsForCourse(courseId)
+		lessonsSpan.End()
+	} else {
+		_, lessonsSpan := tracer.Start(ctx, "GetLessonsForEnrollment")
+		lessons, err = contextService.GetPostgres().GetLessonsForEnrollment(username, courseId)
+		lessonsSpan.End()
+	}
+	if err != nil {
+		log.Println("Error getting lessons", err)
+		return nil, err
+	}
+
+	if releaseStart != nil {
+		_, completedSpan := tracer.Start(ctx, "GetCompletedLessonIds")
+		completed, err := contextService.GetPostgres().GetCompletedLessonIds(username, courseId)
+		completedSpan.End()
+		if err != nil {
+			log.Println("Error getting lesson progress", err)
+			return nil, err
+		}
+
+		now := time.Now()
+		for i := range lessons {
+			releaseLesson(&lessons[i], *releaseStart, now)
+			lessons[i].Completed = completed[lessons[i].Id]
+		}
+
+		if err := contextService.GetPostgres().TouchEnrollment(username, courseId); err != nil {
+			log.Println("Error recording course access", err)
+		}
+	}
+	return lessons, nil
+}
+
+// releaseLesson dates a lesson for a learner whose lessons are released from releaseStart, withholding its content
+// until then
+func releaseLesson(lesson *models.Lesson, releaseStart, now time.Time) {
+	availableAt := releaseStart.AddDate(0, 0, lesson.ReleaseAfterDays)
+	lesson.AvailableAt = &availableAt
+	if availableAt.After(now) {
+		lesson.Locked = true
+		lesson.Content = ""
+	}
+}
+
+// CompleteLesson marks a released lesson as completed by the user and reports whether that completed the course
+func CompleteLesson(ctx context.Context, contextService *services.ContextService, username, courseId, lessonId string) (bool, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "CompleteLesson")
+	defer span.End()
+
+	_, startSpan := tracer.Start(ctx, "GetReleaseStart")
+	releaseStart, err := contextService.GetPostgres().GetReleaseStart(services.TenantFromContext(ctx), username, courseId)
+	startSpan.End()
+	if err != nil {
+		log.Println("Error getting release start", err)
+		return false, err
+	}
+	if releaseStart == nil {
+		return false, fmt.Errorf("user '%s' is not enrolled in course '%s'", username, courseId)
+	}
+
+	_, lessonsSpan := tracer.Start(ctx, "GetLessonsForEnrollment")
+	lessons, err := contextService.GetPostgres().GetLessonsForEnrollment(username, courseId)
+	lessonsSpan.End()
+	if err != nil {
+		log.Println("Error getting lessons", err)
+		return false, err
+	}
+
+	index := slices.IndexFunc(lessons, func(lesson models.Lesson) bool { return lesson.Id == lessonId })
+	if index < 0 {
+		return false, fmt.Errorf("lesson '%s' does not belong to course '%s'", lessonId, courseId)
+	}
+	releaseLesson(&lessons[index], *releaseStart, time.Now())
+	if lessons[index].Locked {
+		return false, fmt.Errorf("lesson '%s' is not released yet", lessonId)
+	}
+
+	_, completeSpan := tracer.Start(ctx, "CompleteLesson")
+	courseCompleted, err := contextService.GetPostgres().CompleteLesson(username, courseId, lessonId)
+	completeSpan.End()
+	if err != nil {
+		log.Println("Error completing lesson", err)
+		return false, err
+	}
+
+	contextService.GetEventBus().Publish(ctx, models.LessonCompleted{
+		Username:        username,
+		CourseId:        courseId,
+		LessonId:        lessonId,
+		CourseCompleted: courseCompleted,
+	})
+	return courseCompleted, nil
+}
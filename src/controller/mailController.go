@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// sendEmailJob is the payload of a JobSendEmail job
+type sendEmailJob struct {
+	EmailId string `json:"emailId"`
+}
+
+// queueEmail renders the template for the recipient's locale and adds the email to the outbox, from which a
+// background job delivers it. Email to a suppressed address is recorded in the outbox but never sent.
+func queueEmail(ctx context.Context, contextService *services.ContextService, to, template, locale string, data interface{}) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "QueueEmail")
+	defer span.End()
+
+	mail := contextService.GetMailService()
+	message, usedLocale, err := mail.Render(to, template, locale, data)
+	if err != nil {
+		log.Println("Error rendering email", err)
+		return err
+	}
+
+	jobs := contextService.GetJobService()
+	_, queueSpan := tracer.Start(ctx, "QueueEmail")
+	email, job, err := contextService.GetPostgres().QueueEmail(models.OutboxEmail{
+		To:       message.To,
+		Template: template,
+		Locale:   usedLocale,
+		Subject:  message.Subject,
+	}, message.Text, message.HTML, models.JobQueueEmail, JobSendEmail, jobs.MaxAttempts(models.JobQueueEmail))
+	queueSpan.End()
+	if err != nil {
+		log.Println("Error queueing email", err)
+		return err
+	}
+	if job == nil {
+		log.Printf("Email %s to %s not sent: address is suppressed", email.Id, email.To)
+		return nil
+	}
+	jobs.Wake(job.Queue)
+	return nil
+}
+
+// deliverEmail sends an email from the outbox. An address the receiving server rejects outright is suppressed
+// so that later emails to it are not attempted; other failures are retried until the job runs out of attempts.
+func deliverEmail(ctx context.Context, contextService *services.ContextService, job models.Job) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DeliverEmail")
+	defer span.End()
+
+	var payload sendEmailJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+
+	postgres := contextService.GetPostgres()
+	_, emailSpan := tracer.Start(ctx, "GetQueuedEmail")
+	email, text, html, suppressed, err := postgres.GetQueuedEmail(payload.EmailId)
+	emailSpan.End()
+	if err != nil {
+		return err
+	}
+	if email.Status != models.EmailQueued {
+		return nil
+	}
+	if suppressed {
+		return postgres.RecordEmailError(email.Id, models.EmailSuppressed, "address was suppressed before delivery")
+	}
+
+	_, sendSpan := tracer.Start(ctx, "SendEmail")
+	err = contextService.GetMailService().Send(ctx, services.EmailMessage{
+		To:      email.To,
+		Subject: email.Subject,
+		Text:    text,
+		HTML:    html,
+	})
+	sendSpan.End()
+	if err == nil {
+		return postgres.MarkEmailSent(email.Id)
+	}
+
+	log.Println("Error delivering email", err)
+	if errors.Is(err, services.ErrPermanentDelivery) {
+		if suppressErr := postgres.SuppressEmail(email.To, models.SuppressionBounce, err.Error()); suppressErr != nil {
+			log.Println("Error suppressing email address", suppressErr)
+		}
+		if recordErr := postgres.RecordEmailError(email.Id, models.EmailFailed, err.Error()); recordErr != nil {
+			log.Println("Error recording email failure", recordErr)
+		}
+		return nil
+	}
+
+	status := models.EmailQueued
+	if lastAttempt(job) {
+		status = models.EmailFailed
+	}
+	if recordErr := postgres.RecordEmailError(email.Id, status, err.Error()); recordErr != nil {
+		log.Println("Error recording email failure", recordErr)
+	}
+	return err
+}
+
+func SearchOutboxEmails(ctx context.Context, contextService *services.ContextService, filter models.EmailSearch) ([]models.OutboxEmail, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SearchOutboxEmails")
+	defer span.End()
+
+	_, searchSpan := tracer.Start(ctx, "SearchOutboxEmails")
+	emails, total, err := contextService.GetPostgres().SearchOutboxEmails(filter)
+	searchSpan.End()
+	if err != nil {
+		log.Println("Error searching emails", err)
+		return nil, 0, err
+	}
+	return emails, total, nil
+}
+
+func GetEmailSuppressions(ctx context.Context, contextService *services.ContextService, page models.Pagination) ([]models.EmailSuppression, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetEmailSuppressions")
+	defer span.End()
+
+	_, suppressionsSpan := tracer.Start(ctx, "GetEmailSuppressions")
+	suppressions, total, err := contextService.GetPostgres().GetEmailSuppressions(page)
+	suppressionsSpan.End()
+	if err != nil {
+		log.Println("Error getting email suppressions", err)
+		return nil, 0, err
+	}
+	return suppressions, total, nil
+}
+
+// SuppressEmail stops email being sent to an address, for example after a bounce or complaint reported by the provider
+func SuppressEmail(ctx context.Context, contextService *services.ContextService, suppression models.AddEmailSuppression) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SuppressEmail")
+	defer span.End()
+
+	_, suppressSpan := tracer.Start(ctx, "SuppressEmail")
+	err := contextService.GetPostgres().SuppressEmail(suppression.Email, suppression.Reason, suppression.Detail)
+	suppressSpan.End()
+	if err != nil {
+		log.Println("Error suppressing email address", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditEmailSuppress, models.AuditTargetEmail, suppression.Email, nil, suppression)
+	return nil
+}
+
+func RemoveEmailSuppression(ctx context.Context, contextService *services.ContextService, email string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RemoveEmailSuppression")
+	defer span.End()
+
+	_, removeSpan := tracer.Start(ctx, "RemoveEmailSuppression")
+	err := contextService.GetPostgres().RemoveEmailSuppression(email)
+	removeSpan.End()
+	if err != nil {
+		log.Println("Error removing email suppression", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditEmailUnsuppress, models.AuditTargetEmail, email, nil, nil)
+	return nil
+}
@@ -0,0 +1,310 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// reportExcerptLength is how much of reported content is kept with the report
+const reportExcerptLength = 500
+
+// reportExcerpt shortens content to what is kept with a report about it
+func reportExcerpt(body string) string {
+	runes := []rune(body)
+	if len(runes) <= reportExcerptLength {
+		return body
+	}
+	return string(runes[:reportExcerptLength]) + "…"
+}
+
+// checkPostRate fails once the user has written as many discussion posts as the rate limit allows within its
+// window. Course managers are not limited.
+func checkPostRate(ctx context.Context, contextService *services.ContextService, username string, moderator bool) error {
+	if moderator {
+		return nil
+	}
+
+	limit, window := contextService.GetModerationService().PostRateLimit()
+	tracer := otel.Tracer("controller")
+	_, countSpan := tracer.Start(ctx, "CountRecentDiscussionPosts")
+	count, err := contextService.GetPostgres().CountRecentDiscussionPosts(username, time.Now().Add(-window))
+	countSpan.End()
+	if err != nil {
+		log.Println("Error counting recent discussion posts", err)
+		return err
+	}
+	if count >= limit {
+		return fmt.Errorf("you are posting too quickly, try again in a little while")
+	}
+	return nil
+}
+
+// checkWordFilters fails when text matches a blocking word filter, and otherwise returns the flagging patterns it
+// matched
+func checkWordFilters(ctx context.Context, contextService *services.ContextService, text string) ([]string, error) {
+	tracer := otel.Tracer("controller")
+	_, filtersSpan := tracer.Start(ctx, "GetWordFilters")
+	filters, err := contextService.GetPostgres().GetWordFilters()
+	filtersSpan.End()
+	if err != nil {
+		log.Println("Error getting word filters", err)
+		return nil, err
+	}
+
+	blocked, flagged := contextService.GetModerationService().MatchWordFilters(text, filters)
+	if blocked {
+		return nil, fmt.Errorf("your post contains language that is not allowed")
+	}
+	return flagged, nil
+}
+
+// flagDiscussionPost files a report on behalf of the system about a post that matched flagging word filters.
+// Flagging never fails the post, so errors are only logged.
+func flagDiscussionPost(ctx context.Context, contextService *services.ContextService, courseId string, post *models.DiscussionPost, patterns []string) {
+	if len(patterns) == 0 {
+		return
+	}
+
+	tracer := otel.Tracer("controller")
+	_, reportSpan := tracer.Start(ctx, "AddContentReport")
+	_, err := contextService.GetPostgres().AddContentReport(models.ContentReport{
+		ContentType: models.ReportContentDiscussionPost,
+		ContentId:   post.Id,
+		CourseId:    courseId,
+		ThreadId:    post.ThreadId,
+		Author:      post.Author,
+		Excerpt:     reportExcerpt(post.Body),
+		Reporter:    auditSystemActor,
+		Reason:      models.ReportReasonWordFilter,
+		Details:     "Matched word filters: " + strings.Join(patterns, ", "),
+	})
+	reportSpan.End()
+	if err != nil {
+		log.Println("Error flagging discussion post", err)
+	}
+}
+
+// ReportDiscussionPost reports a post in a course the user takes part in to the moderators. Reporting a thread's
+// opening post reports the thread.
+func ReportDiscussionPost(ctx context.Context, contextService *services.ContextService, username, courseId, threadId, postId string, add models.AddContentReport) (*models.ContentReport, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ReportDiscussionPost")
+	defer span.End()
+
+	moderator, err := requireDiscussionAccess(ctx, contextService, username, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := contextService.GetPostgres().GetDiscussionThread(courseId, threadId, moderator); err != nil {
+		return nil, err
+	}
+	_, postSpan := tracer.Start(ctx, "GetDiscussionPost")
+	post, err := contextService.GetPostgres().GetDiscussionPost(courseId, threadId, postId, username)
+	postSpan.End()
+	if err != nil {
+		return nil, err
+	}
+	if post.Author == username {
+		return nil, fmt.Errorf("you cannot report your own post")
+	}
+	if post.Deleted || (post.Hidden && !moderator) {
+		return nil, fmt.Errorf("post not found")
+	}
+
+	_, reportSpan := tracer.Start(ctx, "AddContentReport")
+	report, err := contextService.GetPostgres().AddContentReport(models.ContentReport{
+		ContentType: models.ReportContentDiscussionPost,
+		ContentId:   post.Id,
+		CourseId:    courseId,
+		ThreadId:    threadId,
+		Author:      post.Author,
+		Excerpt:     reportExcerpt(post.Body),
+		Reporter:    username,
+		Reason:      add.Reason,
+		Details:     add.Details,
+	})
+	reportSpan.End()
+	if err != nil {
+		log.Println("Error reporting discussion post", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditContentReport, models.AuditTargetContentReport, report.Id,
+		nil, map[string]string{"contentId": report.ContentId, "reason": report.Reason})
+	return report, nil
+}
+
+func GetContentReports(ctx context.Context, contextService *services.ContextService, filter models.ContentReportSearch) ([]models.ContentReport, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetContentReports")
+	defer span.End()
+
+	_, searchSpan := tracer.Start(ctx, "SearchContentReports")
+	reports, total, err := contextService.GetPostgres().SearchContentReports(filter)
+	searchSpan.End()
+	if err != nil {
+		log.Println("Error getting content reports", err)
+		return nil, 0, err
+	}
+	return reports, total, nil
+}
+
+// moderationAudits maps each moderation action to the audited action recording it
+var moderationAudits = map[string]string{
+	models.ModerationActionHide:    AuditModerationHide,
+	models.ModerationActionDelete:  AuditModerationDelete,
+	models.ModerationActionWarn:    AuditModerationWarn,
+	models.ModerationActionSuspend: AuditModerationSuspend,
+	models.ModerationActionDismiss: AuditModerationDismiss,
+}
+
+// ActOnContentReport applies a moderator's decision to reported content and closes every open report about it.
+// Hiding or deleting a thread's opening post hides the whole thread; warning and suspending act on the author.
+func ActOnContentReport(ctx context.Context, contextService *services.ContextService, admin, reportId string, action models.ModerationAction) (*models.ContentReport, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ActOnContentReport")
+	defer span.End()
+
+	_, reportSpan := tracer.Start(ctx, "GetContentReport")
+	report, err := contextService.GetPostgres().GetContentReport(reportId)
+	reportSpan.End()
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, fmt.Errorf("report has already been %s", report.Status)
+	}
+
+	postgres := contextService.GetPostgres()
+	switch action.Action {
+	case models.ModerationActionHide:
+		_, postSpan := tracer.Start(ctx, "GetDiscussionPost")
+		post, err := postgres.GetDiscussionPost(report.CourseId, report.ThreadId, report.ContentId, admin)
+		postSpan.End()
+		if err != nil {
+			return nil, err
+		}
+		_, hideSpan := tracer.Start(ctx, "SetDiscussionPostHidden")
+		err = postgres.SetDiscussionPostHidden(report.CourseId, report.ThreadId, report.ContentId, admin, true)
+		if err == nil && post.ParentId == "" {
+			err = postgres.SetDiscussionThreadHidden(report.CourseId, report.ThreadId, admin, true)
+		}
+		hideSpan.End()
+		if err != nil {
+			log.Println("Error hiding reported post", err)
+			return nil, err
+		}
+	case models.ModerationActionDelete:
+		_, deleteSpan := tracer.Start(ctx, "DeleteDiscussionPost")
+		err := postgres.DeleteDiscussionPost(report.CourseId, report.ThreadId, report.ContentId, admin)
+		deleteSpan.End()
+		if err != nil {
+			log.Println("Error deleting reported post", err)
+			return nil, err
+		}
+	case models.ModerationActionWarn:
+		message := "A moderator reviewed one of your discussion posts and asks you to follow the community guidelines."
+		if action.Note != "" {
+			message += " " + action.Note
+		}
+		notifyUser(ctx, contextService, report.Author, NotificationModerationWarning, message)
+	case models.ModerationActionSuspend:
+		_, userSpan := tracer.Start(ctx, "GetUserAccountByUsername")
+		account, err := postgres.GetUserAccountByUsername(report.Author)
+		userSpan.End()
+		if err != nil {
+			return nil, err
+		}
+		reason := action.Note
+		if reason == "" {
+			reason = "Suspended for content reported in discussions"
+		}
+		if err := SuspendUser(ctx, contextService, admin, account.Id, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	status := models.ReportStatusResolved
+	if action.Action == models.ModerationActionDismiss {
+		status = models.ReportStatusDismissed
+	}
+	_, resolveSpan := tracer.Start(ctx, "ResolveContentReports")
+	resolved, err := postgres.ResolveContentReports(reportId, status, action.Action, action.Note, admin)
+	resolveSpan.End()
+	if err != nil {
+		log.Println("Error resolving content reports", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, moderationAudits[action.Action], models.AuditTargetContentReport, reportId,
+		map[string]string{"status": report.Status},
+		map[string]string{"status": resolved.Status, "contentId": resolved.ContentId, "author": resolved.Author, "note": action.Note})
+	return resolved, nil
+}
+
+func GetWordFilters(ctx context.Context, contextService *services.ContextService) ([]models.WordFilter, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetWordFilters")
+	defer span.End()
+
+	_, filtersSpan := tracer.Start(ctx, "GetWordFilters")
+	filters, err := contextService.GetPostgres().GetWordFilters()
+	filtersSpan.End()
+	if err != nil {
+		log.Println("Error getting word filters", err)
+		return nil, err
+	}
+	return filters, nil
+}
+
+// AddWordFilter starts blocking or flagging posts containing a word or phrase
+func AddWordFilter(ctx context.Context, contextService *services.ContextService, add models.AddWordFilter) (*models.WordFilter, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "AddWordFilter")
+	defer span.End()
+
+	pattern := strings.ToLower(strings.Join(strings.Fields(add.Pattern), " "))
+	if pattern == "" {
+		return nil, fmt.Errorf("word filter pattern cannot be blank")
+	}
+
+	actor := services.RequestInfoFromContext(ctx).Actor
+	_, addSpan := tracer.Start(ctx, "AddWordFilter")
+	filter, err := contextService.GetPostgres().AddWordFilter(pattern, add.Action, actor)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error adding word filter", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditWordFilterAdd, models.AuditTargetWordFilter, filter.Id,
+		nil, map[string]string{"pattern": filter.Pattern, "action": filter.Action})
+	return filter, nil
+}
+
+func DeleteWordFilter(ctx context.Context, contextService *services.ContextService, filterId string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DeleteWordFilter")
+	defer span.End()
+
+	_, deleteSpan := tracer.Start(ctx, "DeleteWordFilter")
+	filter, err := contextService.GetPostgres().DeleteWordFilter(filterId)
+	deleteSpan.End()
+	if err != nil {
+		log.Println("Error deleting word filter", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditWordFilterRemove, models.AuditTargetWordFilter, filter.Id,
+		map[string]string{"pattern": filter.Pattern, "action": filter.Action}, nil)
+	return nil
+}
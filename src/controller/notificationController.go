@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Notification types
+const (
+	NotificationEnrolled           = "enrollment.created"
+	NotificationWaitlistPromoted   = "waitlist.promoted"
+	NotificationEnrollmentApproved = "enrollment.approved"
+	NotificationEnrollmentRejected = "enrollment.rejected"
+	NotificationUnenrolled         = "enrollment.removed"
+	NotificationCourseMessage      = "course.message"
+	NotificationModerationWarning  = "moderation.warning"
+	NotificationReviewReply        = "review.reply"
+	NotificationCourseApproved     = "course.approved"
+	NotificationCourseChanges      = "course.changes_requested"
+)
+
+// notificationType describes a type of notification: the title of its emails and the channels it is delivered
+// on until the user chooses otherwise
+type notificationType struct {
+	title    string
+	defaults models.NotificationPreference
+}
+
+var notificationTypes = map[string]notificationType{
+	NotificationEnrolled: {"You are enrolled in a course",
+		models.NotificationPreference{InApp: true, Email: false}},
+	NotificationWaitlistPromoted: {"A seat opened up in your course",
+		models.NotificationPreference{InApp: true, Email: true}},
+	NotificationEnrollmentApproved: {"Your enrollment request was approved",
+		models.NotificationPreference{InApp: true, Email: true}},
+	NotificationEnrollmentRejected: {"Your enrollment request was declined",
+		models.NotificationPreference{InApp: true, Email: true}},
+	NotificationUnenrolled: {"You were removed from a course",
+		models.NotificationPreference{InApp: true, Email: true}},
+	NotificationCourseMessage: {"A message from your instructor",
+		models.NotificationPreference{InApp: true, Email: true}},
+	NotificationModerationWarning: {"A warning from the moderators",
+		models.NotificationPreference{InApp: true, Email: true}},
+	NotificationReviewReply: {"An instructor replied to your review",
+		models.NotificationPreference{InApp: true, Email: false}},
+	NotificationCourseApproved: {"Your course was approved",
+		models.NotificationPreference{InApp: true, Email: true}},
+	NotificationCourseChanges: {"A reviewer asked for changes to your course",
+		models.NotificationPreference{InApp: true, Email: true}},
+}
+
+// notifyUser tells a user about something that happened to them outside their own request, on the channels they
+// have chosen for notificationType. Notifying never fails the action that caused it, so errors are only logged.
+func notifyUser(ctx context.Context, contextService *services.ContextService, username, notificationType, message string) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "NotifyUser")
+	defer span.End()
+
+	known, ok := notificationTypes[notificationType]
+	if !ok {
+		log.Printf("Unknown notification type %q for %s", notificationType, username)
+		return
+	}
+
+	_, preferencesSpan := tracer.Start(ctx, "GetNotificationPreferences")
+	preferences, err := contextService.GetPostgres().GetNotificationPreferences(username)
+	preferencesSpan.End()
+	if err != nil {
+		log.Println("Error getting notification preferences", err)
+		return
+	}
+	channels, ok := preferences[notificationType]
+	if !ok {
+		channels = known.defaults
+	}
+
+	if channels.InApp {
+		_, addSpan := tracer.Start(ctx, "AddNotification")
+		notification, err := contextService.GetPostgres().AddNotification(username, notificationType, message)
+		addSpan.End()
+		if err != nil {
+			log.Println("Error adding notification", err)
+		} else if unread, err := contextService.GetPostgres().CountUnreadNotifications(username); err == nil {
+			publishEvent(ctx, contextService, username, StreamNotification, notificationEvent{Notification: *notification, UnreadCount: unread})
+		} else {
+			log.Println("Error counting unread notifications", err)
+		}
+	}
+
+	if channels.Email {
+		_, profileSpan := tracer.Start(ctx, "GetUserProfile")
+		profile, err := contextService.GetPostgres().GetUserProfile(username)
+		profileSpan.End()
+		if err != nil {
+			log.Println("Error getting user to notify", err)
+			return
+		}
+		err = queueEmail(ctx, contextService, profile.Email, "notification", "", struct {
+			Username string
+			Title    string
+			Message  string
+			URL      string
+		}{
+			Username: username,
+			Title:    known.title,
+			Message:  message,
+			URL:      contextService.GetMailService().AppURL() + "/notifications",
+		})
+		if err != nil {
+			log.Println("Error emailing notification", err)
+		}
+	}
+}
+
+// GetNotifications returns a page of the user's feed along with how many notifications are unread
+func GetNotifications(ctx context.Context, contextService *services.ContextService, username string, filter models.NotificationSearch) ([]models.Notification, int, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetNotifications")
+	defer span.End()
+
+	_, notificationsSpan := tracer.Start(ctx, "GetNotifications")
+	notifications, total, unread, err := contextService.GetPostgres().GetNotifications(username, filter)
+	notificationsSpan.End()
+	if err != nil {
+		log.Println("Error getting notifications", err)
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+func CountUnreadNotifications(ctx context.Context, contextService *services.ContextService, username string) (int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "CountUnreadNotifications")
+	defer span.End()
+
+	_, countSpan := tracer.Start(ctx, "CountUnreadNotifications")
+	unread, err := contextService.GetPostgres().CountUnreadNotifications(username)
+	countSpan.End()
+	if err != nil {
+		log.Println("Error counting unread notifications", err)
+		return 0, err
+	}
+	return unread, nil
+}
+
+// MarkNotificationRead marks one of the user's notifications read and returns how many remain unread
+func MarkNotificationRead(ctx context.Context, contextService *services.ContextService, username, notificationId string) (int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "MarkNotificationRead")
+	defer span.End()
+
+	_, markSpan := tracer.Start(ctx, "MarkNotificationRead")
+	err := contextService.GetPostgres().MarkNotificationRead(username, notificationId)
+	markSpan.End()
+	if err != nil {
+		log.Println("Error marking notification read", err)
+		return 0, err
+	}
+	return CountUnreadNotifications(ctx, contextService, username)
+}
+
+// MarkAllNotificationsRead marks the user's whole feed read and returns how many notifications were unread
+func MarkAllNotificationsRead(ctx context.Context, contextService *services.ContextService, username string) (int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "MarkAllNotificationsRead")
+	defer span.End()
+
+	_, markSpan := tracer.Start(ctx, "MarkAllNotificationsRead")
+	marked, err := contextService.GetPostgres().MarkAllNotificationsRead(username)
+	markSpan.End()
+	if err != nil {
+		log.Println("Error marking notifications read", err)
+		return 0, err
+	}
+	return marked, nil
+}
+
+// GetNotificationPreferences returns the user's channels for every notification type, defaults included
+func GetNotificationPreferences(ctx context.Context, contextService *services.ContextService, username string) ([]models.NotificationPreference, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetNotificationPreferences")
+	defer span.End()
+
+	_, preferencesSpan := tracer.Start(ctx, "GetNotificationPreferences")
+	chosen, err := contextService.GetPostgres().GetNotificationPreferences(username)
+	preferencesSpan.End()
+	if err != nil {
+		log.Println("Error getting notification preferences", err)
+		return nil, err
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(notificationTypes))
+	for name, known := range notificationTypes {
+		preference, ok := chosen[name]
+		if !ok {
+			preference = known.defaults
+			preference.Type = name
+		}
+		preferences = append(preferences, preference)
+	}
+	sort.Slice(preferences, func(i, j int) bool {
+		return preferences[i].Type < preferences[j].Type
+	})
+	return preferences, nil
+}
+
+// SetNotificationPreferences saves the user's channels for the given notification types and returns the full set
+func SetNotificationPreferences(ctx context.Context, contextService *services.ContextService, username string, update models.UpdateNotificationPreferences) ([]models.NotificationPreference, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SetNotificationPreferences")
+	defer span.End()
+
+	for _, preference := range update.Preferences {
+		if _, ok := notificationTypes[preference.Type]; !ok {
+			return nil, fmt.Errorf("unknown notification type '%s'", preference.Type)
+		}
+	}
+
+	_, saveSpan := tracer.Start(ctx, "SetNotificationPreferences")
+	err := contextService.GetPostgres().SetNotificationPreferences(username, update.Preferences)
+	saveSpan.End()
+	if err != nil {
+		log.Println("Error saving notification preferences", err)
+		return nil, err
+	}
+	return GetNotificationPreferences(ctx, contextService, username)
+}
@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// organizationSlugPattern restricts slugs to lowercase words joined by hyphens
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// orgManagerRoles are the organization roles allowed to manage members
+var orgManagerRoles = []string{models.OrgRoleOwner, models.OrgRoleAdmin}
+
+func CreateOrganization(ctx context.Context, contextService *services.ContextService, organization models.AddOrganization) (*models.Organization, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "CreateOrganization")
+	defer span.End()
+
+	if !organizationSlugPattern.MatchString(organization.Slug) {
+		return nil, fmt.Errorf("slug must be lowercase letters and digits separated by hyphens")
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddOrganization")
+	added, err := contextService.GetPostgres().AddOrganization(organization)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error creating organization", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditOrganizationCreate, models.AuditTargetOrganization, added.Id,
+		nil, map[string]string{"name": added.Name, "slug": added.Slug, "owner": organization.OwnerUsername})
+	return added, nil
+}
+
+func GetMyOrganizations(ctx context.Context, contextService *services.ContextService, username string) ([]models.OrganizationMembership, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetMyOrganizations")
+	defer span.End()
+
+	_, organizationsSpan := tracer.Start(ctx, "GetOrganizationsForUser")
+	organizations, err := contextService.GetPostgres().GetOrganizationsForUser(username)
+	organizationsSpan.End()
+	if err != nil {
+		log.Println("Error getting organizations", err)
+		return nil, err
+	}
+	return organizations, nil
+}
+
+// GetDefaultOrganization returns the organization a fresh login is scoped to, or "" for users outside any organization
+func GetDefaultOrganization(ctx context.Context, contextService *services.ContextService, username string) (string, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetDefaultOrganization")
+	defer span.End()
+
+	_, defaultSpan := tracer.Start(ctx, "GetDefaultOrganizationId")
+	organizationId, err := contextService.GetPostgres().GetDefaultOrganizationId(username)
+	defaultSpan.End()
+	if err != nil {
+		log.Println("Error getting default organization", err)
+		return "", err
+	}
+	return organizationId, nil
+}
+
+// SwitchOrganization checks that the user may act within the organization, or outside any organization when organizationId is empty
+func SwitchOrganization(ctx context.Context, contextService *services.ContextService, username string, organizationId string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SwitchOrganization")
+	defer span.End()
+
+	if organizationId == "" {
+		return nil
+	}
+
+	_, roleSpan := tracer.Start(ctx, "GetOrganizationRole")
+	_, err := contextService.GetPostgres().GetOrganizationRole(organizationId, username)
+	roleSpan.End()
+	if err != nil {
+		log.Println("Error switching organization", err)
+		return err
+	}
+	return nil
+}
+
+func GetOrganizationMembers(ctx context.Context, contextService *services.ContextService, actor string, organizationId string, page models.Pagination) ([]models.OrganizationMember, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetOrganizationMembers")
+	defer span.End()
+
+	if _, err := requireOrganizationRole(ctx, contextService, organizationId, actor, append(orgManagerRoles, models.OrgRoleInstructor)...); err != nil {
+		return nil, 0, err
+	}
+
+	_, membersSpan := tracer.Start(ctx, "GetOrganizationMembers")
+	members, total, err := contextService.GetPostgres().GetOrganizationMembers(organizationId, page)
+	membersSpan.End()
+	if err != nil {
+		log.Println("Error getting organization members", err)
+		return nil, 0, err
+	}
+	return members, total, nil
+}
+
+func AddOrganizationMember(ctx context.Context, contextService *services.ContextService, actor string, organizationId string, member models.AddOrganizationMember) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "AddOrganizationMember")
+	defer span.End()
+
+	if err := checkOrganizationRoleGrant(ctx, contextService, organizationId, actor, member.Role); err != nil {
+		return err
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddOrganizationMember")
+	err := contextService.GetPostgres().AddOrganizationMember(organizationId, member.Username, member.Role)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error adding organization member", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditOrganizationMemberAdd, models.AuditTargetOrganization, organizationId,
+		nil, map[string]string{"username": member.Username, "role": member.Role})
+	return nil
+}
+
+func ChangeOrganizationMemberRole(ctx context.Context, contextService *services.ContextService, actor string, organizationId string, username string, role string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ChangeOrganizationMemberRole")
+	defer span.End()
+
+	if err := checkOrganizationRoleGrant(ctx, contextService, organizationId, actor, role); err != nil {
+		return err
+	}
+
+	currentRole, err := checkOrganizationMemberChange(ctx, contextService, organizationId, actor, username)
+	if err != nil {
+		return err
+	}
+
+	_, roleSpan := tracer.Start(ctx, "SetOrganizationMemberRole")
+	err = contextService.GetPostgres().SetOrganizationMemberRole(organizationId, username, role)
+	roleSpan.End()
+	if err != nil {
+		log.Println("Error changing organization role", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditOrganizationMemberRole, models.AuditTargetOrganization, organizationId,
+		map[string]string{"username": username, "role": currentRole}, map[string]string{"username": username, "role": role})
+	return nil
+}
+
+func RemoveOrganizationMember(ctx context.Context, contextService *services.ContextService, actor string, organizationId string, username string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RemoveOrganizationMember")
+	defer span.End()
+
+	// Members may always leave; removing someone else takes a manager
+	if actor != username {
+		if _, err := requireOrganizationRole(ctx, contextService, organizationId, actor, orgManagerRoles...); err != nil {
+			return err
+		}
+	}
+
+	currentRole, err := checkOrganizationMemberChange(ctx, contextService, organizationId, actor, username)
+	if err != nil {
+		return err
+	}
+
+	_, removeSpan := tracer.Start(ctx, "RemoveOrganizationMember")
+	err = contextService.GetPostgres().RemoveOrganizationMember(organizationId, username)
+	removeSpan.End()
+	if err != nil {
+		log.Println("Error removing organization member", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditOrganizationMemberRemove, models.AuditTargetOrganization, organizationId,
+		map[string]string{"username": username, "role": currentRole}, nil)
+	return nil
+}
+
+// requireOrganizationRole fails unless the user holds one of the roles in the organization, returning the role held
+func requireOrganizationRole(ctx context.Context, contextService *services.ContextService, organizationId, username string, roles ...string) (string, error) {
+	tracer := otel.Tracer("controller")
+	_, roleSpan := tracer.Start(ctx, "GetOrganizationRole")
+	role, err := contextService.GetPostgres().GetOrganizationRole(organizationId, username)
+	roleSpan.End()
+	if err != nil {
+		log.Println("Error getting organization role", err)
+		return "", err
+	}
+	if !slices.Contains(roles, role) {
+		return "", fmt.Errorf("organization role '%s' is not allowed to do this", role)
+	}
+	return role, nil
+}
+
+// checkOrganizationRoleGrant fails unless the actor may hand out the role. Only owners can create other owners.
+func checkOrganizationRoleGrant(ctx context.Context, contextService *services.ContextService, organizationId, actor, role string) error {
+	if !slices.Contains(models.OrgRoles, role) {
+		return fmt.Errorf("unknown organization role '%s'", role)
+	}
+	actorRole, err := requireOrganizationRole(ctx, contextService, organizationId, actor, orgManagerRoles...)
+	if err != nil {
+		return err
+	}
+	if role == models.OrgRoleOwner && actorRole != models.OrgRoleOwner {
+		return fmt.Errorf("only owners can grant the owner role")
+	}
+	return nil
+}
+
+// checkOrganizationMemberChange returns the member's current role, refusing changes that would leave the organization
+// without an owner or let an admin act on an owner
+func checkOrganizationMemberChange(ctx context.Context, contextService *services.ContextService, organizationId, actor, username string) (string, error) {
+	tracer := otel.Tracer("controller")
+
+	_, roleSpan := tracer.Start(ctx, "GetOrganizationRole")
+	currentRole, err := contextService.GetPostgres().GetOrganizationRole(organizationId, username)
+	roleSpan.End()
+	if err != nil {
+		return "", err
+	}
+	if currentRole != models.OrgRoleOwner {
+		return currentRole, nil
+	}
+
+	if actor != username {
+		if _, err := requireOrganizationRole(ctx, contextService, organizationId, actor, models.OrgRoleOwner); err != nil {
+			return "", fmt.Errorf("only owners can change another owner")
+		}
+	}
+
+	_, ownersSpan := tracer.Start(ctx, "CountOrganizationOwners")
+	owners, err := contextService.GetPostgres().CountOrganizationOwners(organizationId)
+	ownersSpan.End()
+	if err != nil {
+		return "", err
+	}
+	if owners <= 1 {
+		return "", fmt.Errorf("an organization must keep at least one owner")
+	}
+	return currentRole, nil
+}
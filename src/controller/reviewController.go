@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// GetCourseReviews retrieves a page of the reviews of a course visible in the catalogue
+func GetCourseReviews(ctx context.Context, contextService *services.ContextService, courseId string, filter models.CourseReviewSearch) ([]models.CourseReview, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseReviews")
+	defer span.End()
+
+	_, courseSpan := tracer.Start(ctx, "GetCourseByIdFromDatabase")
+	_, err := contextService.GetPostgres().GetCourseByIdFromDatabase(services.TenantFromContext(ctx), courseId)
+	courseSpan.End()
+	if err != nil {
+		log.Println("Course does not exist", err)
+		return nil, 0, fmt.Errorf("course with ID '%s' does not exist", courseId)
+	}
+
+	_, reviewsSpan := tracer.Start(ctx, "GetCourseReviews")
+	reviews, total, err := contextService.GetPostgres().GetCourseReviews(courseId, filter)
+	reviewsSpan.End()
+	if err != nil {
+		log.Println("Error getting course reviews", err)
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// GetMyCourseReview retrieves the user's review of a course, or nil if they have not reviewed it
+func GetMyCourseReview(ctx context.Context, contextService *services.ContextService, username, courseId string) (*models.CourseReview, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetMyCourseReview")
+	defer span.End()
+
+	_, reviewSpan := tracer.Start(ctx, "GetUserCourseReview")
+	review, err := contextService.GetPostgres().GetUserCourseReview(courseId, username)
+	reviewSpan.End()
+	if err != nil {
+		log.Println("Error getting course review", err)
+		return nil, err
+	}
+	return review, nil
+}
+
+// ReviewCourse rates a course the user is enrolled in and has completed at least one lesson of, replacing their
+// earlier review if they have one. Reviews are subject to the blocking word filters.
+func ReviewCourse(ctx context.Context, contextService *services.ContextService, username, courseId string, set models.SetCourseReview) (*models.CourseReview, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ReviewCourse")
+	defer span.End()
+
+	_, enrolledSpan := tracer.Start(ctx, "CheckIfUserIsEnrolledInCourse")
+	enrolled, err := contextService.GetPostgres().CheckIfUserIsEnrolledInCourse(services.TenantFromContext(ctx), username, courseId)
+	enrolledSpan.End()
+	if err != nil {
+		log.Println("Error checking enrollment", err)
+		return nil, err
+	}
+	if !enrolled {
+		return nil, fmt.Errorf("only learners enrolled in course '%s' can review it", courseId)
+	}
+
+	_, progressSpan := tracer.Start(ctx, "GetCompletedLessonIds")
+	completed, err := contextService.GetPostgres().GetCompletedLessonIds(username, courseId)
+	progressSpan.End()
+	if err != nil {
+		log.Println("Error getting lesson progress", err)
+		return nil, err
+	}
+	if len(completed) == 0 {
+		return nil, fmt.Errorf("complete at least one lesson of the course before reviewing it")
+	}
+
+	// Reports only cover discussion posts, so flagging filters are not applied to reviews
+	if _, err := checkWordFilters(ctx, contextService, set.Body); err != nil {
+		return nil, err
+	}
+
+	_, reviewSpan := tracer.Start(ctx, "SetCourseReview")
+	review, err := contextService.GetPostgres().SetCourseReview(courseId, username, set)
+	reviewSpan.End()
+	if err != nil {
+		log.Println("Error reviewing course", err)
+		return nil, err
+	}
+	return review, nil
+}
+
+// DeleteMyCourseReview withdraws the user's review of a course
+func DeleteMyCourseReview(ctx context.Context, contextService *services.ContextService, username, courseId string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DeleteMyCourseReview")
+	defer span.End()
+
+	_, deleteSpan := tracer.Start(ctx, "DeleteCourseReview")
+	_, err := contextService.GetPostgres().DeleteCourseReview(courseId, username)
+	deleteSpan.End()
+	if err != nil {
+		log.Println("Error deleting course review", err)
+		return err
+	}
+	return nil
+}
+
+// ReplyToCourseReview publishes an instructor's reply to a review of their course, or removes it when the body is
+// empty. The reviewer is told about new replies.
+func ReplyToCourseReview(ctx context.Context, contextService *services.ContextService, courseId, reviewId string, reply models.SetReviewReply) (*models.CourseReview, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ReplyToCourseReview")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := services.RequestInfoFromContext(ctx).Actor
+	_, replySpan := tracer.Start(ctx, "SetCourseReviewReply")
+	review, err := contextService.GetPostgres().SetCourseReviewReply(courseId, reviewId, actor, reply.Body)
+	replySpan.End()
+	if err != nil {
+		log.Println("Error replying to course review", err)
+		return nil, err
+	}
+
+	if reply.Body != "" {
+		notifyUser(ctx, contextService, review.Username, NotificationReviewReply,
+			fmt.Sprintf("An instructor replied to your review of %s", course.Title))
+	}
+	return review, nil
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+var rosterCSVHeader = []string{"username", "email", "cohort_id", "enrolled_at", "last_activity_at", "completed_at",
+	"completed_lessons", "total_lessons", "progress_percent"}
+
+// GetCourseRoster lists the learners enrolled in a course the caller manages
+func GetCourseRoster(ctx context.Context, contextService *services.ContextService, courseId string, filter models.RosterSearch) ([]models.RosterEntry, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseRoster")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return nil, 0, err
+	}
+
+	_, rosterSpan := tracer.Start(ctx, "GetCourseRoster")
+	roster, total, err := contextService.GetPostgres().GetCourseRoster(courseId, filter)
+	rosterSpan.End()
+	if err != nil {
+		log.Println("Error getting course roster", err)
+		return nil, 0, err
+	}
+	return roster, total, nil
+}
+
+// ExportCourseRoster checks that the caller manages the course, then writes its roster to w as CSV.
+// Nothing is written if the caller may not see the roster.
+func ExportCourseRoster(ctx context.Context, contextService *services.ContextService, courseId string, filter models.RosterSearch, begin func() io.Writer) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ExportCourseRoster")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(begin())
+	if err := writer.Write(rosterCSVHeader); err != nil {
+		return err
+	}
+
+	_, eachSpan := tracer.Start(ctx, "EachRosterEntry")
+	err := contextService.GetPostgres().EachRosterEntry(courseId, filter, func(entry models.RosterEntry) error {
+		return writer.Write([]string{
+			escapeCSVCell(entry.Username),
+			escapeCSVCell(entry.Email),
+			escapeCSVCell(entry.CohortId),
+			entry.EnrolledAt.UTC().Format(time.RFC3339),
+			formatOptionalTime(entry.LastActivityAt),
+			formatOptionalTime(entry.CompletedAt),
+			strconv.Itoa(entry.Progress.CompletedLessons),
+			strconv.Itoa(entry.Progress.TotalLessons),
+			strconv.Itoa(entry.Progress.Percent),
+		})
+	})
+	eachSpan.End()
+	if err != nil {
+		log.Println("Error exporting course roster", err)
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// escapeCSVCell keeps spreadsheets from running a cell as a formula by prefixing cells that start like one with a
+// quote. Cells holding what users typed must go through it before being exported.
+func escapeCSVCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// BulkUnenroll removes the selected learners from a course the caller manages, promoting from the waitlist as seats
+// free up. Each learner is unenrolled on their own, so one failure does not stop the others.
+func BulkUnenroll(ctx context.Context, contextService *services.ContextService, courseId string, usernames []string) ([]models.BulkResult, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "BulkUnenroll")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.BulkResult, 0, len(usernames))
+	for _, username := range dedupe(usernames) {
+		result := models.BulkResult{Username: username}
+		changed, err := UnenrollFromCourse(ctx, contextService, username, courseId)
+		if err != nil {
+			result.Error = err.Error()
+		} else if !changed {
+			result.Error = "not enrolled in this course"
+		} else {
+			result.Done = true
+			notifyUser(ctx, contextService, username, NotificationUnenrolled,
+				fmt.Sprintf("You were removed from course '%s' by its instructor", course.Title))
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// MessageLearners sends a message from the instructor to the selected learners of a course the caller manages.
+// Users who are not enrolled in the course are skipped.
+func MessageLearners(ctx context.Context, contextService *services.ContextService, courseId string, usernames []string, message string) ([]models.BulkResult, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "MessageLearners")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames = dedupe(usernames)
+	_, enrolledSpan := tracer.Start(ctx, "GetEnrolledUsernames")
+	enrolled, err := contextService.GetPostgres().GetEnrolledUsernames(courseId, usernames)
+	enrolledSpan.End()
+	if err != nil {
+		log.Println("Error checking enrollments", err)
+		return nil, err
+	}
+
+	results := make([]models.BulkResult, 0, len(usernames))
+	var messaged []string
+	for _, username := range usernames {
+		if !enrolled[username] {
+			results = append(results, models.BulkResult{Username: username, Error: "not enrolled in this course"})
+			continue
+		}
+		notifyUser(ctx, contextService, username, NotificationCourseMessage, fmt.Sprintf("%s: %s", course.Title, message))
+		messaged = append(messaged, username)
+		results = append(results, models.BulkResult{Username: username, Done: true})
+	}
+
+	recordAudit(ctx, contextService, AuditCourseMessage, models.AuditTargetCourse, courseId, nil,
+		map[string]interface{}{"recipients": messaged, "message": message})
+	return results, nil
+}
+
+// dedupe drops repeated values, keeping the first occurrence of each
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,27 @@
+package controller
+
+import "testing"
+
+// Names and emails are chosen by learners, so a cell a spreadsheet would read as a formula is exported as text
+func TestEscapeCSVCell(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"", ""},
+		{"alice", "alice"},
+		{"alice@example.com", "alice@example.com"},
+		{"=HYPERLINK(\"https://evil.example.com\")", "'=HYPERLINK(\"https://evil.example.com\")"},
+		{"+1+1", "'+1+1"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+	}
+	for _, test := range tests {
+		if got := escapeCSVCell(test.cell); got != test.want {
+			t.Errorf("escapeCSVCell(%q) = %q, want %q", test.cell, got, test.want)
+		}
+	}
+}
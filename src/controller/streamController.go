@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Stream event types
+const (
+	// StreamReady follows any replayed events; its id is where a reconnecting client resumes from
+	StreamReady        = "ready"
+	StreamNotification = "notification"
+	StreamEnrollment   = "enrollment"
+)
+
+const (
+	// streamHeartbeat keeps idle connections open through proxies and detects clients that have gone away
+	streamHeartbeat = 25 * time.Second
+	// streamReplayPage is how many missed events are read at a time when a client reconnects
+	streamReplayPage = 100
+)
+
+// StreamWriter sends events to one connected client, over Server-Sent Events or a WebSocket
+type StreamWriter interface {
+	WriteEvent(event models.StreamEvent) error
+	WriteHeartbeat() error
+}
+
+// streamUnenrolled is the status of a StreamEnrollment event for a user who left a course or its waitlist.
+// Other events carry the enrollment status the user now has.
+const streamUnenrolled = "unenrolled"
+
+// enrollmentEvent is the data of a StreamEnrollment event
+type enrollmentEvent struct {
+	CourseId string `json:"courseId"`
+	Status   string `json:"status"`
+}
+
+// notificationEvent is the data of a StreamNotification event
+type notificationEvent struct {
+	Notification models.Notification `json:"notification"`
+	UnreadCount  int                 `json:"unreadCount"`
+}
+
+// publishEvent pushes an event to the user's connected clients. Like notifications, publishing never fails the
+// action that caused it, so errors are only logged.
+func publishEvent(ctx context.Context, contextService *services.ContextService, username, eventType string, data interface{}) {
+	tracer := otel.Tracer("controller")
+	_, span := tracer.Start(ctx, "PublishEvent")
+	defer span.End()
+
+	if err := contextService.GetStreamService().Publish(username, eventType, data); err != nil {
+		log.Println("Error publishing stream event", err)
+	}
+}
+
+// publishEnrollment tells the user's clients their enrollment in a course changed
+func publishEnrollment(ctx context.Context, contextService *services.ContextService, username, courseId, status string) {
+	publishEvent(ctx, contextService, username, StreamEnrollment, enrollmentEvent{CourseId: courseId, Status: status})
+}
+
+// StreamEvents sends the user's events to a client until the client goes away or the stream ends. A client
+// reconnecting with the id of the last event it saw is first sent the events it missed, as long as they are still
+// retained. Clients should ignore events whose id they have already seen.
+func StreamEvents(ctx context.Context, contextService *services.ContextService, username, lastEventId string, writer StreamWriter) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "StreamEvents")
+	defer span.End()
+
+	// Subscribe before catching up so nothing published meanwhile is lost
+	streams := contextService.GetStreamService()
+	subscription := streams.Subscribe(username)
+	defer streams.Unsubscribe(subscription)
+
+	replayed := map[string]bool{}
+	resumeId, err := strconv.ParseInt(lastEventId, 10, 64)
+	if lastEventId == "" || err != nil {
+		_, latestSpan := tracer.Start(ctx, "GetLatestStreamEventId")
+		resumeId, err = contextService.GetPostgres().GetLatestStreamEventId(username)
+		latestSpan.End()
+		if err != nil {
+			log.Println("Error getting latest stream event", err)
+			return err
+		}
+	} else {
+		for {
+			_, replaySpan := tracer.Start(ctx, "GetStreamEventsSince")
+			missed, err := contextService.GetPostgres().GetStreamEventsSince(username, resumeId, streamReplayPage)
+			replaySpan.End()
+			if err != nil {
+				log.Println("Error getting missed stream events", err)
+				return err
+			}
+			for _, event := range missed {
+				if err := writer.WriteEvent(event); err != nil {
+					return err
+				}
+				replayed[event.Id] = true
+				resumeId, _ = strconv.ParseInt(event.Id, 10, 64)
+			}
+			if len(missed) < streamReplayPage {
+				break
+			}
+		}
+	}
+
+	ready := models.StreamEvent{
+		Id:        strconv.FormatInt(resumeId, 10),
+		Type:      StreamReady,
+		Data:      json.RawMessage("{}"),
+		CreatedAt: time.Now(),
+	}
+	if err := writer.WriteEvent(ready); err != nil {
+		return err
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-subscription.Done():
+			return nil
+		case event := <-subscription.Events():
+			if replayed[event.Id] {
+				continue
+			}
+			if err := writer.WriteEvent(event); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if err := writer.WriteHeartbeat(); err != nil {
+				return err
+			}
+		}
+	}
+}
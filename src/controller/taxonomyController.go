@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// categorySlugPattern is what category slugs look like: lowercase words joined by hyphens
+var categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// normalizeTags lowercases tags and collapses their spaces, dropping blank and repeated ones
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// validateCourseTaxonomy fails unless the category exists and the subject and grade level are known. Empty values
+// are allowed.
+func validateCourseTaxonomy(ctx context.Context, contextService *services.ContextService, categoryId, subject, gradeLevel string) error {
+	if subject != "" && !slices.Contains(models.Subjects, subject) {
+		return fmt.Errorf("unknown subject '%s'", subject)
+	}
+	if gradeLevel != "" && !slices.Contains(models.GradeLevels, gradeLevel) {
+		return fmt.Errorf("unknown grade level '%s'", gradeLevel)
+	}
+	if categoryId == "" {
+		return nil
+	}
+
+	tracer := otel.Tracer("controller")
+	_, categorySpan := tracer.Start(ctx, "GetCourseCategory")
+	_, err := contextService.GetPostgres().GetCourseCategory(categoryId)
+	categorySpan.End()
+	return err
+}
+
+// GetTaxonomy retrieves the category tree along with the subjects and grade levels courses can be given
+func GetTaxonomy(ctx context.Context, contextService *services.ContextService) (*models.Taxonomy, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetTaxonomy")
+	defer span.End()
+
+	_, categoriesSpan := tracer.Start(ctx, "GetCourseCategories")
+	categories, err := contextService.GetPostgres().GetCourseCategories()
+	categoriesSpan.End()
+	if err != nil {
+		log.Println("Error getting course categories", err)
+		return nil, err
+	}
+	return &models.Taxonomy{Categories: categories, Subjects: models.Subjects, GradeLevels: models.GradeLevels}, nil
+}
+
+// validateCategory normalises the slug of a category being added or changed and fails unless it and the parent are
+// valid
+func validateCategory(ctx context.Context, contextService *services.ContextService, category *models.AddCourseCategory) error {
+	category.Name = strings.TrimSpace(category.Name)
+	category.Slug = strings.ToLower(strings.TrimSpace(category.Slug))
+	if category.Name == "" {
+		return fmt.Errorf("category name cannot be blank")
+	}
+	if !categorySlugPattern.MatchString(category.Slug) {
+		return fmt.Errorf("category slugs can only contain lowercase letters, digits and single hyphens")
+	}
+	if category.ParentId == "" {
+		return nil
+	}
+
+	tracer := otel.Tracer("controller")
+	_, parentSpan := tracer.Start(ctx, "GetCourseCategory")
+	_, err := contextService.GetPostgres().GetCourseCategory(category.ParentId)
+	parentSpan.End()
+	if err != nil {
+		return fmt.Errorf("parent category not found")
+	}
+	return nil
+}
+
+// AddCourseCategory adds a category to the tree
+func AddCourseCategory(ctx context.Context, contextService *services.ContextService, add models.AddCourseCategory) (*models.CourseCategory, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "AddCourseCategory")
+	defer span.End()
+
+	if err := validateCategory(ctx, contextService, &add); err != nil {
+		return nil, err
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddCourseCategory")
+	category, err := contextService.GetPostgres().AddCourseCategory(add)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error adding course category", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditCategoryCreate, models.AuditTargetCategory, category.Id, nil, category)
+	return category, nil
+}
+
+// UpdateCourseCategory renames, moves or reorders a category
+func UpdateCourseCategory(ctx context.Context, contextService *services.ContextService, categoryId string, update models.AddCourseCategory) (*models.CourseCategory, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "UpdateCourseCategory")
+	defer span.End()
+
+	_, categorySpan := tracer.Start(ctx, "GetCourseCategory")
+	before, err := contextService.GetPostgres().GetCourseCategory(categoryId)
+	categorySpan.End()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCategory(ctx, contextService, &update); err != nil {
+		return nil, err
+	}
+
+	_, updateSpan := tracer.Start(ctx, "UpdateCourseCategory")
+	category, err := contextService.GetPostgres().UpdateCourseCategory(categoryId, update)
+	updateSpan.End()
+	if err != nil {
+		log.Println("Error updating course category", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditCategoryUpdate, models.AuditTargetCategory, category.Id, before, category)
+	return category, nil
+}
+
+// DeleteCourseCategory removes a category without subcategories. Its courses become uncategorised.
+func DeleteCourseCategory(ctx context.Context, contextService *services.ContextService, categoryId string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DeleteCourseCategory")
+	defer span.End()
+
+	_, deleteSpan := tracer.Start(ctx, "DeleteCourseCategory")
+	category, err := contextService.GetPostgres().DeleteCourseCategory(categoryId)
+	deleteSpan.End()
+	if err != nil {
+		log.Println("Error deleting course category", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditCategoryDelete, models.AuditTargetCategory, category.Id, category, nil)
+	return nil
+}
+
+// SetCourseTaxonomy classifies a course the caller manages
+func SetCourseTaxonomy(ctx context.Context, contextService *services.ContextService, courseId string, set models.SetCourseTaxonomy) (*models.CoursePostgres, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SetCourseTaxonomy")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	set.Tags = normalizeTags(set.Tags)
+	if err := validateCourseTaxonomy(ctx, contextService, set.CategoryId, set.Subject, set.GradeLevel); err != nil {
+		return nil, err
+	}
+
+	_, setSpan := tracer.Start(ctx, "SetCourseTaxonomy")
+	err = contextService.GetPostgres().SetCourseTaxonomy(courseId, set)
+	setSpan.End()
+	if err != nil {
+		log.Println("Error classifying course", err)
+		return nil, err
+	}
+
+	before := models.SetCourseTaxonomy{CategoryId: course.CategoryId, Tags: course.Tags, Subject: course.Subject, GradeLevel: course.GradeLevel}
+	recordAudit(ctx, contextService, AuditCourseTaxonomy, models.AuditTargetCourse, courseId, before, set)
+
+	course.CategoryId = set.CategoryId
+	course.Tags = set.Tags
+	course.Subject = set.Subject
+	course.GradeLevel = set.GradeLevel
+	return course, nil
+}
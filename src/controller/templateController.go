@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// requireCourseAuthor fails unless the caller may create courses: organization authors acting within their
+// organization, or platform instructors and admins otherwise
+func requireCourseAuthor(ctx context.Context) error {
+	if services.TenantFromContext(ctx) != "" {
+		if !slices.Contains(orgCourseAuthorRoles, services.TenantRoleFromContext(ctx)) {
+			return fmt.Errorf("only organization instructors and admins can create courses")
+		}
+		return nil
+	}
+	if !slices.Contains(courseManagerRoles, services.RoleFromContext(ctx)) {
+		return fmt.Errorf("only instructors can create courses")
+	}
+	return nil
+}
+
+// cloneCourse copies a course into a new draft course of the caller's organization, or a public course outside one
+func cloneCourse(ctx context.Context, contextService *services.ContextService, sourceId, title string) (*models.CoursePostgres, error) {
+	tracer := otel.Tracer("controller")
+	tenant := services.TenantFromContext(ctx)
+	visibility := models.CourseVisibilityPublic
+	if tenant != "" {
+		visibility = models.CourseVisibilityOrganization
+	}
+
+	_, cloneSpan := tracer.Start(ctx, "CloneCourse")
+	clone, err := contextService.GetPostgres().CloneCourse(tenant, sourceId, title, visibility)
+	cloneSpan.End()
+	if err != nil {
+		log.Println("Error cloning course", err)
+		return nil, err
+	}
+
+	contextService.GetEventBus().Publish(ctx, models.CourseCreated{Course: *clone, ClonedFrom: sourceId})
+	return clone, nil
+}
+
+// CloneCourse starts a new draft course from a copy of one the caller manages
+func CloneCourse(ctx context.Context, contextService *services.ContextService, courseId string, clone models.CloneCourse) (*models.CoursePostgres, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "CloneCourse")
+	defer span.End()
+
+	source, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireCourseAuthor(ctx); err != nil {
+		return nil, err
+	}
+	if clone.Title == "" {
+		clone.Title = "Copy of " + source.Title
+	}
+	return cloneCourse(ctx, contextService, courseId, clone.Title)
+}
+
+// GetCourseTemplates lists the template library
+func GetCourseTemplates(ctx context.Context, contextService *services.ContextService, filter models.CourseTemplateSearch) ([]models.CourseTemplate, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseTemplates")
+	defer span.End()
+
+	_, templatesSpan := tracer.Start(ctx, "GetCourseTemplates")
+	templates, err := contextService.GetPostgres().GetCourseTemplates(filter)
+	templatesSpan.End()
+	if err != nil {
+		log.Println("Error getting course templates", err)
+		return nil, err
+	}
+	return templates, nil
+}
+
+// CreateCourseFromTemplate starts a new draft course from a copy of a template. Templates can be used from any
+// organization.
+func CreateCourseFromTemplate(ctx context.Context, contextService *services.ContextService, templateId string, clone models.CloneCourse) (*models.CoursePostgres, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "CreateCourseFromTemplate")
+	defer span.End()
+
+	if err := requireCourseAuthor(ctx); err != nil {
+		return nil, err
+	}
+
+	_, templateSpan := tracer.Start(ctx, "GetCourseTemplate")
+	template, err := contextService.GetPostgres().GetCourseTemplate(templateId)
+	templateSpan.End()
+	if err != nil {
+		return nil, err
+	}
+	if clone.Title == "" {
+		clone.Title = template.Course.Title
+	}
+	return cloneCourse(ctx, contextService, templateId, clone.Title)
+}
+
+// AddCourseTemplate adds a course to the template library, or updates the note of one already in it
+func AddCourseTemplate(ctx context.Context, contextService *services.ContextService, add models.AddCourseTemplate) (*models.CourseTemplate, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "AddCourseTemplate")
+	defer span.End()
+
+	_, addSpan := tracer.Start(ctx, "AddCourseTemplate")
+	template, err := contextService.GetPostgres().AddCourseTemplate(add, services.RequestInfoFromContext(ctx).Actor)
+	addSpan.End()
+	if err != nil {
+		log.Println("Error adding course template", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditCourseTemplateAdd, models.AuditTargetCourse, add.CourseId, nil, add)
+	return template, nil
+}
+
+// DeleteCourseTemplate removes a course from the template library
+func DeleteCourseTemplate(ctx context.Context, contextService *services.ContextService, courseId string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DeleteCourseTemplate")
+	defer span.End()
+
+	_, deleteSpan := tracer.Start(ctx, "DeleteCourseTemplate")
+	err := contextService.GetPostgres().DeleteCourseTemplate(courseId)
+	deleteSpan.End()
+	if err != nil {
+		log.Println("Error removing course template", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditCourseTemplateRemove, models.AuditTargetCourse, courseId, nil, nil)
+	return nil
+}
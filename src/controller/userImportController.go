@@ -0,0 +1,517 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"github.com/gin-gonic/gin/binding"
+	"go.opentelemetry.io/otel"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// invitationValid is how long imported users have to choose their password
+const invitationValid = 7 * 24 * time.Hour
+
+// maxImportRows bounds the size of a single import
+const maxImportRows = 5000
+
+// ParseUserImport reads an import CSV into rows. Rows are only checked for shape here; see ValidateUserImport.
+func ParseUserImport(r io.Reader) ([]models.UserImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("the CSV file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range models.UserImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("the CSV header is missing the '%s' column", name)
+		}
+	}
+	reader.FieldsPerRecord = len(header)
+
+	var rows []models.UserImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("an import can hold at most %d rows", maxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		row := models.UserImportRow{
+			Line:     line,
+			Email:    strings.TrimSpace(record[columns["email"]]),
+			Name:     strings.TrimSpace(record[columns["name"]]),
+			Role:     strings.ToLower(strings.TrimSpace(record[columns["role"]])),
+			CohortId: strings.TrimSpace(record[columns["cohort"]]),
+		}
+		if column, ok := columns[models.UserImportLocaleColumn]; ok {
+			row.Locale = strings.TrimSpace(record[column])
+		}
+		for _, courseId := range strings.Split(record[columns["course_ids"]], ";") {
+			if courseId = strings.TrimSpace(courseId); courseId != "" {
+				row.CourseIds = append(row.CourseIds, courseId)
+			}
+		}
+		if row.Role == "" {
+			row.Role = models.RoleLearner
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the CSV file has no rows")
+	}
+	return rows, nil
+}
+
+// ValidateUserImport checks every row of an import without changing anything. A row may name an existing account
+// as long as its email and name both match it; that account is then only enrolled.
+//
+// The accounts and courses the rows refer to are looked up in one query each, and the cohorts once per course, so
+// validating a large import does not cost a query per row.
+func ValidateUserImport(ctx context.Context, contextService *services.ContextService, rows []models.UserImportRow) (models.UserImportValidation, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ValidateUserImport")
+	defer span.End()
+
+	validation := models.UserImportValidation{TotalRows: len(rows), Errors: []models.UserImportError{}}
+
+	accounts, courses, err := loadImportReferences(ctx, contextService, rows)
+	if err != nil {
+		log.Println("Error looking up import references", err)
+		return validation, err
+	}
+	cohorts := map[string][]models.Cohort{}
+
+	emails := map[string]int{}
+	names := map[string]int{}
+	now := time.Now()
+	for _, row := range rows {
+		var rowErrors []string
+
+		// The same rules apply as when signing up, with a placeholder standing in for the password the user will choose
+		if err := binding.Validator.ValidateStruct(models.AddUser{Username: row.Name, Email: row.Email, Password: "invited"}); err != nil {
+			rowErrors = append(rowErrors, err.Error())
+		}
+		if !slices.Contains(models.Roles, row.Role) {
+			rowErrors = append(rowErrors, fmt.Sprintf("unknown role '%s'", row.Role))
+		}
+		if line, ok := emails[strings.ToLower(row.Email)]; ok {
+			rowErrors = append(rowErrors, fmt.Sprintf("email '%s' is already used on line %d", row.Email, line))
+		}
+		emails[strings.ToLower(row.Email)] = row.Line
+		if line, ok := names[row.Name]; ok {
+			rowErrors = append(rowErrors, fmt.Sprintf("name '%s' is already used on line %d", row.Name, line))
+		}
+		names[row.Name] = row.Line
+
+		if err := checkImportAccount(accounts, row); err != nil {
+			rowErrors = append(rowErrors, err.Error())
+		}
+
+		for _, courseId := range row.CourseIds {
+			course, ok := courses[courseKey(courseId)]
+			if !ok {
+				rowErrors = append(rowErrors, fmt.Sprintf("course with ID '%s' does not exist", courseId))
+				continue
+			}
+			if !course.CohortBased {
+				continue
+			}
+			courseCohorts, ok := cohorts[course.Id]
+			if !ok {
+				_, cohortsSpan := tracer.Start(ctx, "GetCohortsForCourse")
+				courseCohorts, err = contextService.GetPostgres().GetCohortsForCourse(course.Id)
+				cohortsSpan.End()
+				if err != nil {
+					log.Println("Error getting cohorts", err)
+					return validation, err
+				}
+				cohorts[course.Id] = courseCohorts
+			}
+			if err := checkImportCohort(course, courseCohorts, row.CohortId, now); err != nil {
+				rowErrors = append(rowErrors, err.Error())
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			validation.Errors = append(validation.Errors, models.UserImportError{Line: row.Line, Errors: rowErrors})
+		}
+	}
+	validation.Valid = len(validation.Errors) == 0
+	return validation, nil
+}
+
+// importAccounts are the existing accounts an import's rows refer to, by email and by name
+type importAccounts struct {
+	byEmail map[string]string
+	byName  map[string]bool
+}
+
+// courseKey normalises a course ID, which may be written with or without dashes
+func courseKey(courseId string) string {
+	return strings.ReplaceAll(strings.ToLower(courseId), "-", "")
+}
+
+// loadImportReferences looks up the accounts and the courses visible to the tenant that the rows of an import refer to
+func loadImportReferences(ctx context.Context, contextService *services.ContextService, rows []models.UserImportRow) (importAccounts, map[string]*models.CoursePostgres, error) {
+	tracer := otel.Tracer("controller")
+
+	var emails, names, courseIds []string
+	for _, row := range rows {
+		emails = append(emails, row.Email)
+		names = append(names, row.Name)
+		courseIds = append(courseIds, row.CourseIds...)
+	}
+	slices.Sort(courseIds)
+	courseIds = slices.Compact(courseIds)
+
+	accounts := importAccounts{byEmail: map[string]string{}, byName: map[string]bool{}}
+	_, accountsSpan := tracer.Start(ctx, "GetImportAccounts")
+	users, err := contextService.GetPostgres().GetImportAccounts(emails, names)
+	accountsSpan.End()
+	if err != nil {
+		return accounts, nil, err
+	}
+	for _, user := range users {
+		accounts.byEmail[user.Email] = user.Username
+		accounts.byName[user.Username] = true
+	}
+
+	courses := map[string]*models.CoursePostgres{}
+	_, coursesSpan := tracer.Start(ctx, "GetCoursesByIds")
+	found, err := contextService.GetPostgres().GetCoursesByIds(services.TenantFromContext(ctx), courseIds)
+	coursesSpan.End()
+	if err != nil {
+		return accounts, nil, err
+	}
+	for i := range found {
+		courses[courseKey(found[i].Id)] = &found[i]
+	}
+	return accounts, courses, nil
+}
+
+// checkImportAccount fails if the row's email or name belongs to an account the row does not fully describe
+func checkImportAccount(accounts importAccounts, row models.UserImportRow) error {
+	if username, ok := accounts.byEmail[row.Email]; ok {
+		if username != row.Name {
+			return fmt.Errorf("email '%s' already belongs to user '%s'", row.Email, username)
+		}
+		return nil
+	}
+	if accounts.byName[row.Name] {
+		return fmt.Errorf("name '%s' is already taken", row.Name)
+	}
+	return nil
+}
+
+// checkImportCohort fails unless a learner of the row can join a cohort of a cohort-based course: the named cohort,
+// which must not have ended, or else any cohort still open
+func checkImportCohort(course *models.CoursePostgres, cohorts []models.Cohort, cohortId string, now time.Time) error {
+	if cohortId == "" {
+		for _, cohort := range cohorts {
+			if cohort.EndsAt.After(now) {
+				return nil
+			}
+		}
+		return fmt.Errorf("course '%s' has no cohort open for enrollment", course.Id)
+	}
+	index := slices.IndexFunc(cohorts, func(cohort models.Cohort) bool { return cohort.Id == cohortId })
+	if index < 0 {
+		return fmt.Errorf("cohort '%s' does not belong to course '%s'", cohortId, course.Id)
+	}
+	if !cohorts[index].EndsAt.After(now) {
+		return fmt.Errorf("cohort '%s' has already ended", cohorts[index].Name)
+	}
+	return nil
+}
+
+// StartUserImport records a validated import and processes it in the background
+func StartUserImport(ctx context.Context, contextService *services.ContextService, admin string, rows []models.UserImportRow) (*models.UserImport, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "StartUserImport")
+	defer span.End()
+
+	_, createSpan := tracer.Start(ctx, "CreateUserImport")
+	userImport, err := contextService.GetPostgres().CreateUserImport(admin, services.TenantFromContext(ctx), rows)
+	createSpan.End()
+	if err != nil {
+		log.Println("Error creating user import", err)
+		return nil, err
+	}
+
+	// Accounts and enrollments are created by a background job so the import is not bound by the handler timeout
+	_, enqueueSpan := tracer.Start(ctx, "EnqueueJob")
+	_, err = contextService.GetJobService().Enqueue(models.JobQueueImports, JobUserImport, userImportJob{ImportId: userImport.Id})
+	enqueueSpan.End()
+	if err != nil {
+		log.Println("Error enqueueing user import", err)
+		if finishErr := contextService.GetPostgres().FinishUserImport(userImport.Id, models.UserImportFailed); finishErr != nil {
+			log.Println("Error marking user import as failed", finishErr)
+		}
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditUserImport, models.AuditTargetUserImport, userImport.Id, nil,
+		map[string]interface{}{"rows": len(rows), "organizationId": userImport.OrganizationId})
+	return userImport, nil
+}
+
+func GetUserImport(ctx context.Context, contextService *services.ContextService, importId string) (*models.UserImport, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetUserImport")
+	defer span.End()
+
+	_, importSpan := tracer.Start(ctx, "GetUserImport")
+	userImport, err := contextService.GetPostgres().GetUserImport(importId)
+	importSpan.End()
+	if err != nil {
+		log.Println("Error getting user import", err)
+		return nil, err
+	}
+	if userImport.OrganizationId != services.TenantFromContext(ctx) {
+		return nil, fmt.Errorf("user import not found")
+	}
+	return userImport, nil
+}
+
+// userImportJob is the payload of a JobUserImport job
+type userImportJob struct {
+	ImportId string `json:"importId"`
+}
+
+// runUserImport creates the accounts and enrollments of an import row by row, recording progress after each row.
+// A failing row is reported and skipped so the rest of the import still goes through. A retried job resumes after
+// the last row it recorded. The import acts as the admin who started it, within their organization.
+func runUserImport(ctx context.Context, contextService *services.ContextService, job models.Job) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RunUserImport")
+	defer span.End()
+
+	var payload userImportJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+
+	err := importUserRows(ctx, contextService, payload.ImportId)
+	status := models.UserImportCompleted
+	if err != nil {
+		log.Println("Error running user import", err)
+		if !lastAttempt(job) {
+			return err
+		}
+		status = models.UserImportFailed
+	}
+
+	if finishErr := contextService.GetPostgres().FinishUserImport(payload.ImportId, status); finishErr != nil {
+		log.Println("Error finishing user import", finishErr)
+		return finishErr
+	}
+	return err
+}
+
+func importUserRows(ctx context.Context, contextService *services.ContextService, importId string) error {
+	userImport, err := contextService.GetPostgres().GetUserImport(importId)
+	if err != nil {
+		return err
+	}
+	rows, err := contextService.GetPostgres().GetUserImportRows(importId)
+	if err != nil {
+		return err
+	}
+	ctx = services.WithActor(services.WithTenant(ctx, userImport.OrganizationId, ""), userImport.RequestedBy)
+
+	for _, row := range rows[min(userImport.ProcessedRows, len(rows)):] {
+		created, enrollments, err := importUserRow(ctx, contextService, row)
+		userImport.ProcessedRows++
+		userImport.Enrollments += enrollments
+		if created {
+			userImport.CreatedUsers++
+		}
+		if err != nil {
+			userImport.Errors = append(userImport.Errors, models.UserImportError{Line: row.Line, Errors: []string{err.Error()}})
+		}
+		if err := contextService.GetPostgres().UpdateUserImportProgress(*userImport); err != nil {
+			return fmt.Errorf("failed to record progress: %w", err)
+		}
+	}
+	return nil
+}
+
+// importUserRow creates the row's account unless it already exists, then enrolls it in the row's courses.
+// It reports whether an account was created and how many enrollments or waitlist places were added.
+func importUserRow(ctx context.Context, contextService *services.ContextService, row models.UserImportRow) (bool, int, error) {
+	tracer := otel.Tracer("controller")
+	tenant := services.TenantFromContext(ctx)
+
+	// Rows of users who deleted their account while the import was running are emptied
+	if row.Name == "" {
+		return false, 0, fmt.Errorf("the row was withdrawn because its user deleted their account")
+	}
+
+	// The account was checked when the import was uploaded, but the name may have been taken since by someone else,
+	// who must not be added to the organization or enrolled
+	created := false
+	if account, err := contextService.GetPostgres().GetUserAccountByUsername(row.Name); err != nil {
+		if err := createInvitedUser(ctx, contextService, row); err != nil {
+			return false, 0, err
+		}
+		created = true
+	} else if !strings.EqualFold(account.Email, row.Email) {
+		return false, 0, fmt.Errorf("name '%s' is already taken", row.Name)
+	}
+
+	if tenant != "" {
+		_, roleSpan := tracer.Start(ctx, "GetOrganizationRole")
+		role, err := contextService.GetPostgres().GetOrganizationRole(tenant, row.Name)
+		roleSpan.End()
+		if err != nil || role == "" {
+			if err := contextService.GetPostgres().AddOrganizationMember(tenant, row.Name, models.OrgRoleMember); err != nil {
+				return created, 0, fmt.Errorf("failed to add user to the organization: %w", err)
+			}
+			recordAudit(ctx, contextService, AuditOrganizationMemberAdd, models.AuditTargetOrganization, tenant,
+				nil, map[string]string{"username": row.Name, "role": models.OrgRoleMember})
+		}
+	}
+
+	enrollments := 0
+	var errs []error
+	for _, courseId := range row.CourseIds {
+		changed, err := importEnrollment(ctx, contextService, row, courseId)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("course '%s': %w", courseId, err))
+			continue
+		}
+		if changed {
+			enrollments++
+		}
+	}
+	return created, enrollments, errors.Join(errs...)
+}
+
+// createInvitedUser creates an account with an unusable password and invites its owner to choose one
+func createInvitedUser(ctx context.Context, contextService *services.ContextService, row models.UserImportRow) error {
+	tracer := otel.Tracer("controller")
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return fmt.Errorf("failed to generate password: %v", err)
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %v", err)
+	}
+
+	_, userSpan := tracer.Start(ctx, "AddUser")
+	user, err := contextService.GetPostgres().AddUser(models.AddUser{Username: row.Name, Email: row.Email, Password: string(hashedPassword)})
+	userSpan.End()
+	if err != nil {
+		return err
+	}
+	if row.Role != user.Role {
+		if err := contextService.GetPostgres().SetUserRole(user.Id, row.Role); err != nil {
+			return fmt.Errorf("failed to set role: %w", err)
+		}
+		user.Role = row.Role
+	}
+
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return fmt.Errorf("failed to generate invitation token: %v", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+	expiresAt := time.Now().Add(invitationValid)
+	if err := contextService.GetPostgres().RequirePasswordReset(user.Id, hashResetToken(token), expiresAt); err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	recordAudit(ctx, contextService, AuditUserImport, models.AuditTargetUser, user.Id,
+		nil, map[string]string{"username": user.Username, "email": user.Email, "role": user.Role})
+
+	if err := sendInvitation(ctx, contextService, user.Username, user.Email, row.Locale, token, expiresAt); err != nil {
+		return fmt.Errorf("failed to send invitation: %w", err)
+	}
+	return nil
+}
+
+// sendInvitation emails an imported user a link to choose their password
+func sendInvitation(ctx context.Context, contextService *services.ContextService, username, email, locale, token string, expiresAt time.Time) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SendInvitation")
+	defer span.End()
+
+	return queueEmail(ctx, contextService, email, "invitation", locale, struct {
+		Username  string
+		ResetURL  string
+		ExpiresAt time.Time
+	}{
+		Username:  username,
+		ResetURL:  contextService.GetMailService().AppURL() + "/reset-password?token=" + url.QueryEscape(token),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// importEnrollment enrolls an imported user in one course, bypassing the course's enrollment policy but not its capacity
+func importEnrollment(ctx context.Context, contextService *services.ContextService, row models.UserImportRow, courseId string) (bool, error) {
+	tracer := otel.Tracer("controller")
+	tenant := services.TenantFromContext(ctx)
+
+	_, courseSpan := tracer.Start(ctx, "GetCourseByIdFromDatabase")
+	course, err := contextService.GetPostgres().GetCourseByIdFromDatabase(tenant, courseId)
+	courseSpan.End()
+	if err != nil {
+		return false, fmt.Errorf("course does not exist")
+	}
+
+	cohortId := ""
+	if course.CohortBased {
+		if cohortId, err = chooseCohort(ctx, contextService, course, row.CohortId); err != nil {
+			return false, err
+		}
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddUserToCourse")
+	result, err := contextService.GetPostgres().AddUserToCourse(tenant, row.Name, courseId, cohortId, "", nil)
+	addSpan.End()
+	if err != nil {
+		return false, err
+	}
+	if !result.Changed {
+		return false, nil
+	}
+
+	if result.WaitlistPosition > 0 {
+		recordAudit(ctx, contextService, AuditWaitlistJoin, models.AuditTargetEnrollment, enrollmentTarget(courseId, row.Name),
+			nil, map[string]interface{}{"cohortId": cohortId, "position": result.WaitlistPosition})
+		return true, nil
+	}
+	contextService.GetEventBus().Publish(ctx, models.Enrolled{Username: row.Name, CourseId: courseId, CourseTitle: course.Title, CohortId: cohortId})
+	return true, nil
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/services"
+)
+
+// A name registered by someone else between upload and processing fails the row instead of adding that user to the
+// organization and its courses
+func TestImportUserRowRefusesAccountOfSomeoneElse(t *testing.T) {
+	contextService := testContextService(t)
+	db := contextService.GetPostgres()
+	organizationId, owner, course := testOrganizationCourse(t, db)
+
+	name := testName("user")
+	if _, err := db.AddUser(models.AddUser{Username: name, Email: name + "@stranger.example.com", Password: "secret"}); err != nil {
+		t.Fatalf("adding user: %v", err)
+	}
+
+	ctx := services.WithTenant(services.WithRole(services.WithActor(context.Background(), owner), models.RoleLearner), organizationId, models.OrgRoleOwner)
+	row := models.UserImportRow{Line: 2, Email: name + "@example.com", Name: name, Role: models.RoleLearner, CourseIds: []string{course.Id}}
+	if _, _, err := importUserRow(ctx, contextService, row); err == nil {
+		t.Fatalf("the row of another user's name was imported")
+	}
+
+	if role, err := db.GetOrganizationRole(organizationId, name); err == nil && role != "" {
+		t.Errorf("the stranger was added to the organization as %s", role)
+	}
+	enrolled, err := db.CheckIfUserIsEnrolledInCourse(organizationId, name, course.Id)
+	if err != nil {
+		t.Fatalf("checking enrollment: %v", err)
+	}
+	if enrolled {
+		t.Errorf("the stranger was enrolled in the course")
+	}
+}
+
+func TestParseUserImport(t *testing.T) {
+	csv := "Email, Name ,ROLE,course_ids,cohort,locale\n" +
+		"ada@example.com,ada, Instructor ,c1; c2;,,fr\n" +
+		"\"grace@example.com\",\"grace\",,,cohort-1,\n" +
+		"\n" +
+		"alan@example.com,alan,learner,c3,,\n"
+	rows, err := ParseUserImport(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("parsing: %v", err)
+	}
+	want := []models.UserImportRow{
+		{Line: 2, Email: "ada@example.com", Name: "ada", Role: models.RoleInstructor, CourseIds: []string{"c1", "c2"}, Locale: "fr"},
+		{Line: 3, Email: "grace@example.com", Name: "grace", Role: models.RoleLearner, CohortId: "cohort-1"},
+		{Line: 5, Email: "alan@example.com", Name: "alan", Role: models.RoleLearner, CourseIds: []string{"c3"}},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %+v, want %+v", rows, want)
+	}
+}
+
+func TestParseUserImportRejectsBadFiles(t *testing.T) {
+	header := "email,name,role,course_ids,cohort\n"
+	tests := []struct {
+		name string
+		csv  string
+		err  string
+	}{
+		{"empty file", "", "the CSV file is empty"},
+		{"header only", header, "the CSV file has no rows"},
+		{"missing column", "email,name,role,cohort\nada@example.com,ada,,\n", "missing the 'course_ids' column"},
+		{"short row", header + "ada@example.com,ada,learner\n", "invalid CSV"},
+		{"long row", header + "ada@example.com,ada,learner,c1,,extra\n", "invalid CSV"},
+		{"unterminated quote", header + "\"ada@example.com,ada,learner,c1,\n", "invalid CSV"},
+		{"too many rows", header + strings.Repeat("ada@example.com,ada,learner,c1,\n", maxImportRows+1), "at most 5000 rows"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseUserImport(strings.NewReader(test.csv))
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("error = %v, want one containing %q", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,319 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// UpdateCourseDetails edits the title and description of a course's working copy
+func UpdateCourseDetails(ctx context.Context, contextService *services.ContextService, courseId string, update models.UpdateCourseDetails) (*models.CoursePostgres, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "UpdateCourseDetails")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireEditableCourse(course); err != nil {
+		return nil, err
+	}
+
+	_, updateSpan := tracer.Start(ctx, "UpdateCourseDetails")
+	err = contextService.GetPostgres().UpdateCourseDetails(courseId, update)
+	updateSpan.End()
+	if err != nil {
+		log.Println("Error updating course", err)
+		return nil, err
+	}
+
+	before := models.UpdateCourseDetails{Title: course.Title, Description: course.Description}
+	recordAudit(ctx, contextService, AuditCourseUpdate, models.AuditTargetCourse, courseId, before, update)
+
+	course.Title = update.Title
+	course.Description = update.Description
+	return course, nil
+}
+
+// GetCourseVersions lists the versions of a course, newest first
+func GetCourseVersions(ctx context.Context, contextService *services.ContextService, courseId string) ([]models.CourseVersion, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseVersions")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return nil, err
+	}
+
+	_, versionsSpan := tracer.Start(ctx, "GetCourseVersions")
+	versions, err := contextService.GetPostgres().GetCourseVersions(courseId)
+	versionsSpan.End()
+	if err != nil {
+		log.Println("Error getting course versions", err)
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetCourseVersion retrieves a version of a course with its lessons
+func GetCourseVersion(ctx context.Context, contextService *services.ContextService, courseId string, number int) (*models.CourseVersion, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseVersion")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return nil, err
+	}
+
+	_, versionSpan := tracer.Start(ctx, "GetCourseVersion")
+	version, err := contextService.GetPostgres().GetCourseVersion(courseId, number)
+	versionSpan.End()
+	if err != nil {
+		log.Println("Error getting course version", err)
+		return nil, err
+	}
+	return version, nil
+}
+
+// PublishCourseVersion publishes the working copy of a course as a new version. New enrollments get it; learners
+// already enrolled stay on their version until they opt in.
+func PublishCourseVersion(ctx context.Context, contextService *services.ContextService, courseId string, publish models.PublishCourseVersion) (*models.CourseVersion, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "PublishCourseVersion")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return nil, err
+	}
+
+	_, publishSpan := tracer.Start(ctx, "PublishCourseVersion")
+	version, err := contextService.GetPostgres().PublishCourseVersion(services.TenantFromContext(ctx), courseId, publish.Note,
+		services.RequestInfoFromContext(ctx).Actor)
+	publishSpan.End()
+	if err != nil {
+		log.Println("Error publishing course version", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditCourseVersionPublish, models.AuditTargetCourse, courseId, nil,
+		map[string]interface{}{"version": version.Number, "note": version.Note})
+	return version, nil
+}
+
+// RollbackCourseVersion restores the working copy of a course to an earlier version and publishes the result as a
+// new version
+func RollbackCourseVersion(ctx context.Context, contextService *services.ContextService, courseId string, number int, publish models.PublishCourseVersion) (*models.CourseVersion, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RollbackCourseVersion")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireEditableCourse(course); err != nil {
+		return nil, err
+	}
+	if publish.Note == "" {
+		publish.Note = fmt.Sprintf("Rolled back to version %d", number)
+	}
+
+	_, rollbackSpan := tracer.Start(ctx, "RollbackCourseVersion")
+	version, err := contextService.GetPostgres().RollbackCourseVersion(services.TenantFromContext(ctx), courseId, number, publish.Note,
+		services.RequestInfoFromContext(ctx).Actor)
+	rollbackSpan.End()
+	if err != nil {
+		log.Println("Error rolling back course version", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditCourseVersionRollback, models.AuditTargetCourse, courseId,
+		map[string]interface{}{"version": course.CurrentVersion},
+		map[string]interface{}{"version": version.Number, "restored": number, "note": version.Note})
+	return version, nil
+}
+
+// DiffCourseVersions compares two versions of a course, or a version with the working copy
+func DiffCourseVersions(ctx context.Context, contextService *services.ContextService, courseId string, query models.CourseVersionDiffQuery) (*models.CourseVersionDiff, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DiffCourseVersions")
+	defer span.End()
+
+	course, err := requireCourseManager(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, fromSpan := tracer.Start(ctx, "GetCourseVersion")
+	from, err := contextService.GetPostgres().GetCourseVersion(courseId, query.From)
+	fromSpan.End()
+	if err != nil {
+		log.Println("Error getting course version", err)
+		return nil, err
+	}
+
+	var to *models.CourseVersion
+	if query.To == 0 {
+		_, lessonsSpan := tracer.Start(ctx, "GetLessonsForCourse")
+		lessons, err := contextService.GetPostgres().GetLessonsForCourse(courseId)
+		lessonsSpan.End()
+		if err != nil {
+			log.Println("Error getting lessons", err)
+			return nil, err
+		}
+		to = workingCopyVersion(course, lessons)
+	} else {
+		_, toSpan := tracer.Start(ctx, "GetCourseVersion")
+		to, err = contextService.GetPostgres().GetCourseVersion(courseId, query.To)
+		toSpan.End()
+		if err != nil {
+			log.Println("Error getting course version", err)
+			return nil, err
+		}
+	}
+	return diffCourseVersions(from, to), nil
+}
+
+// workingCopyVersion presents the working copy of a course as an unnumbered version
+func workingCopyVersion(course *models.CoursePostgres, lessons []models.Lesson) *models.CourseVersion {
+	version := &models.CourseVersion{
+		CourseId:    course.Id,
+		Title:       course.Title,
+		Description: course.Description,
+		CategoryId:  course.CategoryId,
+		Tags:        course.Tags,
+		Subject:     course.Subject,
+		GradeLevel:  course.GradeLevel,
+		LessonCount: len(lessons),
+	}
+	for _, lesson := range lessons {
+		version.Lessons = append(version.Lessons, models.CourseVersionLesson{
+			Id:               lesson.Id,
+			Position:         lesson.Position,
+			Title:            lesson.Title,
+			Content:          lesson.Content,
+			ReleaseAfterDays: lesson.ReleaseAfterDays,
+		})
+	}
+	return version
+}
+
+// diffCourseVersions lists the changes that turn version from into version to. Lessons are matched by id, so a
+// lesson that was edited or moved shows as changed rather than as removed and added.
+func diffCourseVersions(from, to *models.CourseVersion) *models.CourseVersionDiff {
+	diff := &models.CourseVersionDiff{
+		From:           from.Number,
+		To:             to.Number,
+		Changes:        []models.FieldChange{},
+		LessonsAdded:   []models.CourseVersionLesson{},
+		LessonsRemoved: []models.CourseVersionLesson{},
+		LessonsChanged: []models.LessonChange{},
+	}
+
+	if from.Title != to.Title {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "title", From: from.Title, To: to.Title})
+	}
+	if from.Description != to.Description {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "description", From: from.Description, To: to.Description})
+	}
+	if from.CategoryId != to.CategoryId {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "categoryId", From: from.CategoryId, To: to.CategoryId})
+	}
+	if !slices.Equal(from.Tags, to.Tags) {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "tags", From: from.Tags, To: to.Tags})
+	}
+	if from.Subject != to.Subject {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "subject", From: from.Subject, To: to.Subject})
+	}
+	if from.GradeLevel != to.GradeLevel {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "gradeLevel", From: from.GradeLevel, To: to.GradeLevel})
+	}
+
+	earlier := map[string]models.CourseVersionLesson{}
+	for _, lesson := range from.Lessons {
+		earlier[lesson.Id] = lesson
+	}
+	for _, lesson := range to.Lessons {
+		previous, ok := earlier[lesson.Id]
+		if !ok {
+			diff.LessonsAdded = append(diff.LessonsAdded, lesson)
+			continue
+		}
+		delete(earlier, lesson.Id)
+
+		change := models.LessonChange{LessonId: lesson.Id, Title: lesson.Title}
+		if previous.Position != lesson.Position {
+			change.Changes = append(change.Changes, models.FieldChange{Field: "position", From: previous.Position, To: lesson.Position})
+		}
+		if previous.Title != lesson.Title {
+			change.Changes = append(change.Changes, models.FieldChange{Field: "title", From: previous.Title, To: lesson.Title})
+		}
+		if previous.Content != lesson.Content {
+			change.Changes = append(change.Changes, models.FieldChange{Field: "content", From: previous.Content, To: lesson.Content})
+		}
+		if previous.ReleaseAfterDays != lesson.ReleaseAfterDays {
+			change.Changes = append(change.Changes, models.FieldChange{Field: "releaseAfterDays", From: previous.ReleaseAfterDays, To: lesson.ReleaseAfterDays})
+		}
+		if len(change.Changes) > 0 {
+			diff.LessonsChanged = append(diff.LessonsChanged, change)
+		}
+	}
+	for _, lesson := range from.Lessons {
+		if _, ok := earlier[lesson.Id]; ok {
+			diff.LessonsRemoved = append(diff.LessonsRemoved, lesson)
+		}
+	}
+	return diff
+}
+
+// GetEnrollmentVersion tells a learner which version of a course they are on and whether a newer one is available
+func GetEnrollmentVersion(ctx context.Context, contextService *services.ContextService, username, courseId string) (*models.EnrollmentVersion, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetEnrollmentVersion")
+	defer span.End()
+
+	_, versionSpan := tracer.Start(ctx, "GetEnrollmentVersion")
+	version, err := contextService.GetPostgres().GetEnrollmentVersion(username, courseId)
+	versionSpan.End()
+	if err != nil {
+		log.Println("Error getting enrollment version", err)
+		return nil, err
+	}
+	return version, nil
+}
+
+// UpgradeEnrollmentVersion moves a learner onto the current version of a course. Their progress on lessons that
+// carried over is kept.
+func UpgradeEnrollmentVersion(ctx context.Context, contextService *services.ContextService, username, courseId string) (*models.EnrollmentVersion, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "UpgradeEnrollmentVersion")
+	defer span.End()
+
+	before, err := GetEnrollmentVersion(ctx, contextService, username, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if !before.UpgradeAvailable {
+		return before, nil
+	}
+
+	_, upgradeSpan := tracer.Start(ctx, "UpgradeEnrollmentVersion")
+	err = contextService.GetPostgres().UpgradeEnrollmentVersion(username, courseId)
+	upgradeSpan.End()
+	if err != nil {
+		log.Println("Error upgrading enrollment version", err)
+		return nil, err
+	}
+
+	after := models.EnrollmentVersion{Pinned: before.Current, Current: before.Current}
+	recordAudit(ctx, contextService, AuditEnrollmentUpgrade, models.AuditTargetCourse, courseId,
+		map[string]interface{}{"version": before.Pinned}, map[string]interface{}{"version": after.Pinned})
+	return &after, nil
+}
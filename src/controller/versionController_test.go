@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	models "orkidslearning/src/models/database"
+)
+
+func TestDiffCourseVersions(t *testing.T) {
+	intro := models.CourseVersionLesson{Id: "1", Position: 1, Title: "Intro", Content: "Welcome"}
+	basics := models.CourseVersionLesson{Id: "2", Position: 2, Title: "Basics", Content: "Numbers", ReleaseAfterDays: 1}
+	recap := models.CourseVersionLesson{Id: "3", Position: 3, Title: "Recap", Content: "Summary", ReleaseAfterDays: 7}
+	from := &models.CourseVersion{
+		Number:  1,
+		Title:   "Arithmetic",
+		Tags:    []string{"maths"},
+		Lessons: []models.CourseVersionLesson{intro, basics, recap},
+	}
+
+	// with returns the lessons with replace applied in place of the lesson of the same id
+	with := func(replace ...models.CourseVersionLesson) []models.CourseVersionLesson {
+		lessons := slices.Clone(from.Lessons)
+		for _, lesson := range replace {
+			for i := range lessons {
+				if lessons[i].Id == lesson.Id {
+					lessons[i] = lesson
+				}
+			}
+		}
+		return lessons
+	}
+	movedIntro, movedBasics := intro, basics
+	movedIntro.Position, movedBasics.Position = 2, 1
+	editedBasics := basics
+	editedBasics.Title, editedBasics.Content, editedBasics.ReleaseAfterDays = "Counting", "Numbers and more", 2
+	quiz := models.CourseVersionLesson{Id: "4", Position: 4, Title: "Quiz", Content: "Questions"}
+
+	tests := []struct {
+		name    string
+		to      models.CourseVersion
+		changes []models.FieldChange
+		added   []models.CourseVersionLesson
+		removed []models.CourseVersionLesson
+		changed []models.LessonChange
+	}{
+		{
+			name: "unchanged",
+			to:   models.CourseVersion{Number: 2, Title: "Arithmetic", Tags: []string{"maths"}, Lessons: with()},
+		},
+		{
+			name: "details edited",
+			to:   models.CourseVersion{Number: 2, Title: "Arithmetic 101", Tags: []string{"maths", "numbers"}, Subject: "maths", Lessons: with()},
+			changes: []models.FieldChange{
+				{Field: "title", From: "Arithmetic", To: "Arithmetic 101"},
+				{Field: "tags", From: []string{"maths"}, To: []string{"maths", "numbers"}},
+				{Field: "subject", From: "", To: "maths"},
+			},
+		},
+		{
+			name:  "lesson added",
+			to:    models.CourseVersion{Number: 2, Title: "Arithmetic", Tags: []string{"maths"}, Lessons: append(with(), quiz)},
+			added: []models.CourseVersionLesson{quiz},
+		},
+		{
+			name:    "lesson removed",
+			to:      models.CourseVersion{Number: 2, Title: "Arithmetic", Tags: []string{"maths"}, Lessons: []models.CourseVersionLesson{intro, recap}},
+			removed: []models.CourseVersionLesson{basics},
+		},
+		{
+			name: "lessons moved",
+			to:   models.CourseVersion{Number: 2, Title: "Arithmetic", Tags: []string{"maths"}, Lessons: []models.CourseVersionLesson{movedBasics, movedIntro, recap}},
+			changed: []models.LessonChange{
+				{LessonId: "2", Title: "Basics", Changes: []models.FieldChange{{Field: "position", From: 2, To: 1}}},
+				{LessonId: "1", Title: "Intro", Changes: []models.FieldChange{{Field: "position", From: 1, To: 2}}},
+			},
+		},
+		{
+			name: "lesson edited",
+			to:   models.CourseVersion{Number: 2, Title: "Arithmetic", Tags: []string{"maths"}, Lessons: with(editedBasics)},
+			changed: []models.LessonChange{{LessonId: "2", Title: "Counting", Changes: []models.FieldChange{
+				{Field: "title", From: "Basics", To: "Counting"},
+				{Field: "content", From: "Numbers", To: "Numbers and more"},
+				{Field: "releaseAfterDays", From: 1, To: 2},
+			}}},
+		},
+		{
+			name:    "lessons added, removed and edited against the working copy",
+			to:      models.CourseVersion{Title: "Arithmetic", Tags: []string{"maths"}, Lessons: []models.CourseVersionLesson{intro, editedBasics, quiz}},
+			added:   []models.CourseVersionLesson{quiz},
+			removed: []models.CourseVersionLesson{recap},
+			changed: []models.LessonChange{{LessonId: "2", Title: "Counting", Changes: []models.FieldChange{
+				{Field: "title", From: "Basics", To: "Counting"},
+				{Field: "content", From: "Numbers", To: "Numbers and more"},
+				{Field: "releaseAfterDays", From: 1, To: 2},
+			}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff := diffCourseVersions(from, &test.to)
+			if diff.From != from.Number || diff.To != test.to.Number {
+				t.Errorf("diff from %d to %d, want %d to %d", diff.From, diff.To, from.Number, test.to.Number)
+			}
+			check := func(what string, got, want interface{}) {
+				t.Helper()
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s = %+v, want %+v", what, got, want)
+				}
+			}
+			check("changes", diff.Changes, orEmpty(test.changes))
+			check("lessons added", diff.LessonsAdded, orEmpty(test.added))
+			check("lessons removed", diff.LessonsRemoved, orEmpty(test.removed))
+			check("lessons changed", diff.LessonsChanged, orEmpty(test.changed))
+		})
+	}
+}
+
+// orEmpty turns a nil expectation into the empty slice the diff reports
+func orEmpty[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
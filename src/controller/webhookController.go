@@ -0,0 +1,334 @@
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// webhookRelayBatch is how many outbox events are relayed per transaction
+const webhookRelayBatch = 100
+
+// webhookDeliveryJob is the payload of a JobDeliverWebhook job
+type webhookDeliveryJob struct {
+	DeliveryId string `json:"deliveryId"`
+}
+
+// RunWebhookRelay moves events from the webhook outbox into deliveries every interval until ctx is cancelled.
+// Events are only written to the outbox by committed transactions, so none are lost or sent for changes rolled back.
+func RunWebhookRelay(ctx context.Context, contextService *services.ContextService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	jobs := contextService.GetJobService()
+	for {
+		for {
+			events, deliveries, err := contextService.GetPostgres().RelayWebhookEvents(webhookRelayBatch,
+				models.JobQueueWebhooks, JobDeliverWebhook, jobs.MaxAttempts(models.JobQueueWebhooks))
+			if err != nil {
+				log.Println("Error relaying webhook events", err)
+				break
+			}
+			if deliveries > 0 {
+				jobs.Wake(models.JobQueueWebhooks)
+			}
+			if events < webhookRelayBatch {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliverWebhook sends a delivery to its webhook. Failed attempts are retried with backoff until the job runs out
+// of attempts; deliveries to webhooks deleted or disabled meanwhile are dropped.
+func deliverWebhook(ctx context.Context, contextService *services.ContextService, job models.Job) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DeliverWebhook")
+	defer span.End()
+
+	var payload webhookDeliveryJob
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return err
+	}
+
+	postgres := contextService.GetPostgres()
+	_, targetSpan := tracer.Start(ctx, "GetWebhookDeliveryTarget")
+	delivery, body, webhook, err := postgres.GetWebhookDeliveryTarget(payload.DeliveryId)
+	targetSpan.End()
+	if err != nil {
+		return err
+	}
+	if delivery.Status != models.WebhookDeliveryPending {
+		return nil
+	}
+	if !webhook.Active {
+		_, err := postgres.RecordWebhookAttempt(delivery.Id, models.WebhookDeliveryFailed, 0, "", "webhook is disabled")
+		return err
+	}
+
+	_, err = sendWebhookDelivery(ctx, contextService, delivery, body, webhook, !lastAttempt(job))
+	return err
+}
+
+// sendWebhookDelivery makes one attempt at a delivery and records the outcome. A failed delivery stays pending
+// when it will be retried.
+func sendWebhookDelivery(ctx context.Context, contextService *services.ContextService, delivery *models.WebhookDelivery, body string, webhook *models.Webhook, retry bool) (*models.WebhookDelivery, error) {
+	tracer := otel.Tracer("controller")
+
+	_, sendSpan := tracer.Start(ctx, "SendWebhook")
+	responseStatus, responseBody, sendErr := contextService.GetWebhookService().Send(ctx, webhook.URL, webhook.Secret,
+		delivery.Id, delivery.EventType, []byte(body))
+	sendSpan.End()
+
+	status, lastError := models.WebhookDeliverySucceeded, ""
+	if sendErr != nil {
+		log.Println("Error sending webhook", sendErr)
+		status, lastError = models.WebhookDeliveryFailed, sendErr.Error()
+		if retry {
+			status = models.WebhookDeliveryPending
+		}
+	}
+
+	_, recordSpan := tracer.Start(ctx, "RecordWebhookAttempt")
+	recorded, err := contextService.GetPostgres().RecordWebhookAttempt(delivery.Id, status, responseStatus, responseBody, lastError)
+	recordSpan.End()
+	if err != nil {
+		log.Println("Error recording webhook attempt", err)
+		return nil, err
+	}
+	return recorded, sendErr
+}
+
+// requireWebhookManager fails unless the user may manage the webhooks of the request's tenant: owners and admins of
+// the organization, or platform admins for the platform's own webhooks. It returns the tenant.
+func requireWebhookManager(ctx context.Context) (string, error) {
+	tenant := services.TenantFromContext(ctx)
+	if tenant != "" {
+		if !slices.Contains(orgManagerRoles, services.TenantRoleFromContext(ctx)) {
+			return "", fmt.Errorf("only organization owners and admins can manage webhooks")
+		}
+		return tenant, nil
+	}
+	if services.RoleFromContext(ctx) != models.RoleAdmin {
+		return "", fmt.Errorf("only admins can manage webhooks")
+	}
+	return "", nil
+}
+
+func GetWebhooks(ctx context.Context, contextService *services.ContextService) ([]models.Webhook, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetWebhooks")
+	defer span.End()
+
+	tenant, err := requireWebhookManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, webhooksSpan := tracer.Start(ctx, "GetWebhooks")
+	webhooks, err := contextService.GetPostgres().GetWebhooks(tenant)
+	webhooksSpan.End()
+	if err != nil {
+		log.Println("Error getting webhooks", err)
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func GetWebhook(ctx context.Context, contextService *services.ContextService, webhookId string) (*models.Webhook, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetWebhook")
+	defer span.End()
+
+	tenant, err := requireWebhookManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, webhookSpan := tracer.Start(ctx, "GetWebhook")
+	webhook, err := contextService.GetPostgres().GetWebhook(tenant, webhookId)
+	webhookSpan.End()
+	if err != nil {
+		log.Println("Error getting webhook", err)
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// CreateWebhook adds a webhook for the request's tenant. The returned webhook holds the signing secret, which is
+// not shown again.
+func CreateWebhook(ctx context.Context, contextService *services.ContextService, add models.AddWebhook) (*models.Webhook, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "CreateWebhook")
+	defer span.End()
+
+	tenant, err := requireWebhookManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %v", err)
+	}
+	secret := "whsec_" + hex.EncodeToString(secretBytes)
+
+	actor := services.RequestInfoFromContext(ctx).Actor
+	_, createSpan := tracer.Start(ctx, "CreateWebhook")
+	webhook, err := contextService.GetPostgres().CreateWebhook(tenant, actor, secret, add)
+	createSpan.End()
+	if err != nil {
+		log.Println("Error creating webhook", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditWebhookCreate, models.AuditTargetWebhook, webhook.Id, nil, add)
+	return webhook, nil
+}
+
+func UpdateWebhook(ctx context.Context, contextService *services.ContextService, webhookId string, update models.UpdateWebhook) (*models.Webhook, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "UpdateWebhook")
+	defer span.End()
+
+	before, err := GetWebhook(ctx, contextService, webhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, updateSpan := tracer.Start(ctx, "UpdateWebhook")
+	webhook, err := contextService.GetPostgres().UpdateWebhook(services.TenantFromContext(ctx), webhookId, update)
+	updateSpan.End()
+	if err != nil {
+		log.Println("Error updating webhook", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, AuditWebhookUpdate, models.AuditTargetWebhook, webhook.Id, before, webhook)
+	return webhook, nil
+}
+
+func DeleteWebhook(ctx context.Context, contextService *services.ContextService, webhookId string) error {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "DeleteWebhook")
+	defer span.End()
+
+	before, err := GetWebhook(ctx, contextService, webhookId)
+	if err != nil {
+		return err
+	}
+
+	_, deleteSpan := tracer.Start(ctx, "DeleteWebhook")
+	err = contextService.GetPostgres().DeleteWebhook(services.TenantFromContext(ctx), webhookId)
+	deleteSpan.End()
+	if err != nil {
+		log.Println("Error deleting webhook", err)
+		return err
+	}
+
+	recordAudit(ctx, contextService, AuditWebhookDelete, models.AuditTargetWebhook, webhookId, before, nil)
+	return nil
+}
+
+func GetWebhookDeliveries(ctx context.Context, contextService *services.ContextService, webhookId string, filter models.WebhookDeliverySearch) ([]models.WebhookDelivery, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetWebhookDeliveries")
+	defer span.End()
+
+	if _, err := GetWebhook(ctx, contextService, webhookId); err != nil {
+		return nil, 0, err
+	}
+
+	_, deliveriesSpan := tracer.Start(ctx, "GetWebhookDeliveries")
+	deliveries, total, err := contextService.GetPostgres().GetWebhookDeliveries(webhookId, filter)
+	deliveriesSpan.End()
+	if err != nil {
+		log.Println("Error getting webhook deliveries", err)
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// RedeliverWebhook sends a past delivery again, as a new delivery with the same payload and so the same event id
+func RedeliverWebhook(ctx context.Context, contextService *services.ContextService, webhookId, deliveryId string) (*models.WebhookDelivery, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RedeliverWebhook")
+	defer span.End()
+
+	if _, err := GetWebhook(ctx, contextService, webhookId); err != nil {
+		return nil, err
+	}
+
+	jobs := contextService.GetJobService()
+	_, redeliverSpan := tracer.Start(ctx, "RedeliverWebhook")
+	delivery, err := contextService.GetPostgres().RedeliverWebhook(webhookId, deliveryId, models.JobQueueWebhooks,
+		JobDeliverWebhook, jobs.MaxAttempts(models.JobQueueWebhooks))
+	redeliverSpan.End()
+	if err != nil {
+		log.Println("Error redelivering webhook", err)
+		return nil, err
+	}
+	jobs.Wake(models.JobQueueWebhooks)
+
+	recordAudit(ctx, contextService, AuditWebhookRedeliver, models.AuditTargetWebhook, webhookId,
+		nil, map[string]string{"deliveryId": deliveryId, "redeliveryId": delivery.Id})
+	return delivery, nil
+}
+
+// PingWebhook sends a test event to a webhook straight away, without retries, and returns the outcome
+func PingWebhook(ctx context.Context, contextService *services.ContextService, webhookId string) (*models.WebhookDelivery, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "PingWebhook")
+	defer span.End()
+
+	webhook, err := GetWebhook(ctx, contextService, webhookId)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.Secret, err = contextService.GetPostgres().GetWebhookSecret(services.TenantFromContext(ctx), webhookId); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":             "ping-" + strconv.FormatInt(time.Now().UnixNano(), 10),
+		"type":           models.WebhookEventPing,
+		"createdAt":      time.Now().UTC(),
+		"organizationId": services.TenantFromContext(ctx),
+		"data":           map[string]string{"webhookId": webhook.Id},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, addSpan := tracer.Start(ctx, "AddWebhookDelivery")
+	delivery, err := contextService.GetPostgres().AddWebhookDelivery(webhook.Id, models.WebhookEventPing, string(body))
+	addSpan.End()
+	if err != nil {
+		log.Println("Error recording webhook ping", err)
+		return nil, err
+	}
+
+	// The outcome of the ping is the answer, so a failed send is not an error here
+	delivery, err = sendWebhookDelivery(ctx, contextService, delivery, string(body), webhook, false)
+	if delivery == nil {
+		return nil, err
+	}
+	return delivery, nil
+}
@@ -0,0 +1,272 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	services "orkidslearning/src/services"
+
+	"go.opentelemetry.io/otel"
+)
+
+// editorialRoles are the platform roles allowed to review courses, whatever their organization
+var editorialRoles = []string{models.RoleReviewer, models.RoleAdmin}
+
+// workflowAudits maps each workflow step to the audited action recording it
+var workflowAudits = map[string]string{
+	models.WorkflowSubmit:         AuditCourseSubmit,
+	models.WorkflowApprove:        AuditCourseApprove,
+	models.WorkflowRequestChanges: AuditCourseRequestChanges,
+	models.WorkflowSchedule:       AuditCourseSchedule,
+	models.WorkflowPublish:        AuditCoursePublish,
+	models.WorkflowUnpublish:      AuditCourseUnpublish,
+}
+
+// transitionCourse moves a course through the workflow and audits the step
+func transitionCourse(ctx context.Context, contextService *services.ContextService, courseId string, allowed []string, event models.CourseWorkflowEvent) (*models.CourseWorkflowEvent, error) {
+	tracer := otel.Tracer("controller")
+	event.Actor = services.RequestInfoFromContext(ctx).Actor
+	_, transitionSpan := tracer.Start(ctx, "TransitionCourse")
+	recorded, err := contextService.GetPostgres().TransitionCourse(courseId, allowed, event)
+	transitionSpan.End()
+	if err != nil {
+		log.Println("Error moving course through the workflow", err)
+		return nil, err
+	}
+
+	recordAudit(ctx, contextService, workflowAudits[recorded.Action], models.AuditTargetCourse, courseId,
+		map[string]string{"status": recorded.FromStatus},
+		map[string]interface{}{"status": recorded.ToStatus, "comment": recorded.Comment, "publishAt": recorded.PublishAt})
+	return recorded, nil
+}
+
+// courseSubmitter returns who last submitted a course for review, according to its workflow history
+func courseSubmitter(ctx context.Context, contextService *services.ContextService, courseId string) (string, error) {
+	tracer := otel.Tracer("controller")
+	_, workflowSpan := tracer.Start(ctx, "GetCourseWorkflow")
+	events, err := contextService.GetPostgres().GetCourseWorkflow(courseId)
+	workflowSpan.End()
+	if err != nil {
+		log.Println("Error getting course workflow", err)
+		return "", err
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Action == models.WorkflowSubmit {
+			return events[i].Actor, nil
+		}
+	}
+	return "", nil
+}
+
+// requireCourseEditor loads a course the caller may act on in the workflow: any course for reviewers and admins,
+// and otherwise the courses they manage
+func requireCourseEditor(ctx context.Context, contextService *services.ContextService, courseId string) (*models.CoursePostgres, error) {
+	if slices.Contains(editorialRoles, services.RoleFromContext(ctx)) {
+		tracer := otel.Tracer("controller")
+		_, courseSpan := tracer.Start(ctx, "GetCourseForReview")
+		course, err := contextService.GetPostgres().GetCourseForReview(courseId)
+		courseSpan.End()
+		return course, err
+	}
+	return requireCourseManager(ctx, contextService, courseId)
+}
+
+// reviewedStatuses are the workflow statuses in which a course's content is frozen, so that what is published is
+// what the reviewers saw
+var reviewedStatuses = []string{models.CourseStatusInReview, models.CourseStatusApproved}
+
+// requireEditableCourse fails when the course is in review or approved. Its authors can edit it again once a
+// reviewer asks for changes, or after unpublishing an approved course.
+func requireEditableCourse(course *models.CoursePostgres) error {
+	if slices.Contains(reviewedStatuses, course.Status) {
+		return fmt.Errorf("cannot edit a course that is %s", course.Status)
+	}
+	return nil
+}
+
+// SubmitCourseForReview sends a draft, a course sent back for changes or an unpublished course to the reviewers
+func SubmitCourseForReview(ctx context.Context, contextService *services.ContextService, courseId string, submit models.SubmitCourse) (*models.CourseWorkflowEvent, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "SubmitCourseForReview")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return nil, err
+	}
+	return transitionCourse(ctx, contextService, courseId,
+		[]string{models.CourseStatusDraft, models.CourseStatusChangesRequested, models.CourseStatusUnpublished},
+		models.CourseWorkflowEvent{Action: models.WorkflowSubmit, ToStatus: models.CourseStatusInReview, Comment: submit.Comment})
+}
+
+// GetCourseReviewQueue retrieves a page of the courses awaiting review, longest waiting first
+func GetCourseReviewQueue(ctx context.Context, contextService *services.ContextService, page models.Pagination) ([]models.CoursePostgres, int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseReviewQueue")
+	defer span.End()
+
+	_, queueSpan := tracer.Start(ctx, "GetCoursesInReview")
+	courses, total, err := contextService.GetPostgres().GetCoursesInReview(page)
+	queueSpan.End()
+	if err != nil {
+		log.Println("Error getting courses in review", err)
+		return nil, 0, err
+	}
+	return courses, total, nil
+}
+
+// ApproveCourse approves a course in review for publication. Reviewers cannot approve courses they submitted.
+func ApproveCourse(ctx context.Context, contextService *services.ContextService, courseId string, approve models.ApproveCourse) (*models.CourseWorkflowEvent, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "ApproveCourse")
+	defer span.End()
+
+	course, err := requireCourseEditor(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	submitter, err := courseSubmitter(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if submitter == services.RequestInfoFromContext(ctx).Actor {
+		return nil, fmt.Errorf("courses must be approved by someone other than who submitted them")
+	}
+
+	event, err := transitionCourse(ctx, contextService, courseId, []string{models.CourseStatusInReview},
+		models.CourseWorkflowEvent{Action: models.WorkflowApprove, ToStatus: models.CourseStatusApproved, Comment: approve.Comment})
+	if err != nil {
+		return nil, err
+	}
+
+	if submitter != "" {
+		notifyUser(ctx, contextService, submitter, NotificationCourseApproved,
+			fmt.Sprintf("%s was approved and can now be published", course.Title))
+	}
+	return event, nil
+}
+
+// RequestCourseChanges sends a course in review back to its authors with the reviewer's comments
+func RequestCourseChanges(ctx context.Context, contextService *services.ContextService, courseId string, request models.RequestCourseChanges) (*models.CourseWorkflowEvent, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "RequestCourseChanges")
+	defer span.End()
+
+	course, err := requireCourseEditor(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+	submitter, err := courseSubmitter(ctx, contextService, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := transitionCourse(ctx, contextService, courseId, []string{models.CourseStatusInReview},
+		models.CourseWorkflowEvent{Action: models.WorkflowRequestChanges, ToStatus: models.CourseStatusChangesRequested, Comment: request.Comment})
+	if err != nil {
+		return nil, err
+	}
+
+	if submitter != "" {
+		notifyUser(ctx, contextService, submitter, NotificationCourseChanges,
+			fmt.Sprintf("A reviewer asked for changes to %s: %s", course.Title, request.Comment))
+	}
+	return event, nil
+}
+
+// PublishCourse publishes an approved course now, or schedules it for a future publish time. An unpublished course
+// has to be submitted for review again first.
+func PublishCourse(ctx context.Context, contextService *services.ContextService, courseId string, publish models.PublishCourse) (*models.CourseWorkflowEvent, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "PublishCourse")
+	defer span.End()
+
+	if _, err := requireCourseManager(ctx, contextService, courseId); err != nil {
+		return nil, err
+	}
+
+	allowed := []string{models.CourseStatusApproved}
+	if publish.PublishAt != nil && publish.PublishAt.After(time.Now()) {
+		return transitionCourse(ctx, contextService, courseId, allowed,
+			models.CourseWorkflowEvent{Action: models.WorkflowSchedule, ToStatus: models.CourseStatusApproved, PublishAt: publish.PublishAt})
+	}
+	return transitionCourse(ctx, contextService, courseId, allowed,
+		models.CourseWorkflowEvent{Action: models.WorkflowPublish, ToStatus: models.CourseStatusPublished})
+}
+
+// UnpublishCourse takes a course out of the catalogue, or cancels its scheduled publication. Learners already
+// enrolled keep their access. Reviewers and admins can unpublish any course.
+func UnpublishCourse(ctx context.Context, contextService *services.ContextService, courseId string, unpublish models.UnpublishCourse) (*models.CourseWorkflowEvent, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "UnpublishCourse")
+	defer span.End()
+
+	if _, err := requireCourseEditor(ctx, contextService, courseId); err != nil {
+		return nil, err
+	}
+	return transitionCourse(ctx, contextService, courseId, []string{models.CourseStatusPublished, models.CourseStatusApproved},
+		models.CourseWorkflowEvent{Action: models.WorkflowUnpublish, ToStatus: models.CourseStatusUnpublished, Comment: unpublish.Comment})
+}
+
+// GetCourseWorkflow retrieves the workflow history of a course, for its managers and reviewers
+func GetCourseWorkflow(ctx context.Context, contextService *services.ContextService, courseId string) (*models.CoursePostgres, []models.CourseWorkflowEvent, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "GetCourseWorkflow")
+	defer span.End()
+
+	course, err := requireCourseEditor(ctx, contextService, courseId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, workflowSpan := tracer.Start(ctx, "GetCourseWorkflow")
+	events, err := contextService.GetPostgres().GetCourseWorkflow(courseId)
+	workflowSpan.End()
+	if err != nil {
+		log.Println("Error getting course workflow", err)
+		return nil, nil, err
+	}
+	return course, events, nil
+}
+
+// PublishDueCourses publishes the approved courses whose publish time has come and returns how many there were
+func PublishDueCourses(ctx context.Context, contextService *services.ContextService) (int, error) {
+	tracer := otel.Tracer("controller")
+	ctx, span := tracer.Start(ctx, "PublishDueCourses")
+	defer span.End()
+
+	events, err := contextService.GetPostgres().PublishDueCourses(auditSystemActor)
+	if err != nil {
+		log.Println("Error publishing scheduled courses", err)
+		return 0, err
+	}
+
+	ctx = services.WithActor(ctx, auditSystemActor)
+	for _, event := range events {
+		recordAudit(ctx, contextService, AuditCoursePublish, models.AuditTargetCourse, event.CourseId,
+			map[string]string{"status": event.FromStatus}, map[string]string{"status": event.ToStatus})
+	}
+	return len(events), nil
+}
+
+// RunCoursePublisher publishes scheduled courses every interval until ctx is cancelled
+func RunCoursePublisher(ctx context.Context, contextService *services.ContextService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := PublishDueCourses(ctx, contextService)
+		if err == nil && published > 0 {
+			log.Printf("Published %d scheduled courses", published)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
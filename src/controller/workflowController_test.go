@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"testing"
+
+	models "orkidslearning/src/models/database"
+)
+
+// Content is frozen from submission until a reviewer sends the course back or it is published or unpublished
+func TestRequireEditableCourse(t *testing.T) {
+	tests := []struct {
+		status   string
+		editable bool
+	}{
+		{models.CourseStatusDraft, true},
+		{models.CourseStatusInReview, false},
+		{models.CourseStatusChangesRequested, true},
+		{models.CourseStatusApproved, false},
+		{models.CourseStatusPublished, true},
+		{models.CourseStatusUnpublished, true},
+	}
+	for _, test := range tests {
+		err := requireEditableCourse(&models.CoursePostgres{Status: test.status})
+		if (err == nil) != test.editable {
+			t.Errorf("%s: editable = %v, want %v", test.status, err == nil, test.editable)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// GetUserProfile retrieves the profile of an active user by username
+func (db *PostgresDatabase) GetUserProfile(username string) (*models.UserProfile, error) {
+	query := `SELECT id, username, email, created_at, deletion_requested_at
+		FROM users WHERE username = $1 AND deleted_at IS NULL`
+	var profile models.UserProfile
+	var id int
+	err := db.conn.QueryRow(query, username).Scan(&id, &profile.Username, &profile.Email, &profile.CreatedAt, &profile.DeletionRequestedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("error fetching user profile: %w", err)
+	}
+	profile.Id = strconv.Itoa(id)
+	return &profile, nil
+}
+
+// GetEnrollmentsForUser retrieves every course a user is enrolled in
+func (db *PostgresDatabase) GetEnrollmentsForUser(username string) ([]models.Enrollment, error) {
+	query := `SELECT c.id, c.title, e.enrolled_at
+		FROM course_enrollments e JOIN courses c ON c.id = e.id
+		WHERE e.username = $1 ORDER BY e.enrolled_at`
+	rows, err := db.conn.Query(query, username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	enrollments := []models.Enrollment{}
+	for rows.Next() {
+		var enrollment models.Enrollment
+		var id pgtype.UUID
+		if err := rows.Scan(&id, &enrollment.Title, &enrollment.EnrolledAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		enrollment.CourseId = fmt.Sprintf("%x", id.Bytes)
+		enrollments = append(enrollments, enrollment)
+	}
+	return enrollments, rows.Err()
+}
+
+const dataExportColumns = "id, username, status, COALESCE(error, ''), requested_at, completed_at, expires_at"
+
+func scanDataExport(row *pgx.Row) (*models.DataExport, error) {
+	var export models.DataExport
+	var id int64
+	err := row.Scan(&id, &export.Username, &export.Status, &export.Error, &export.RequestedAt, &export.CompletedAt, &export.ExpiresAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("data export not found")
+		}
+		return nil, fmt.Errorf("error fetching data export: %w", err)
+	}
+	export.Id = strconv.FormatInt(id, 10)
+	return &export, nil
+}
+
+// CreateDataExport records a new pending data export for a user
+func (db *PostgresDatabase) CreateDataExport(username string) (*models.DataExport, error) {
+	query := "INSERT INTO data_exports (username) VALUES ($1) RETURNING " + dataExportColumns
+	return scanDataExport(db.conn.QueryRow(query, username))
+}
+
+// GetLatestDataExport retrieves the newest export that is still pending or downloadable,
+// returning nil if there is none
+func (db *PostgresDatabase) GetLatestDataExport(username string) (*models.DataExport, error) {
+	query := `SELECT id FROM data_exports
+		WHERE username = $1 AND (status = $2 OR (status = $3 AND expires_at > now()))
+		ORDER BY requested_at DESC LIMIT 1`
+	var id int64
+	err := db.conn.QueryRow(query, username, models.DataExportPending, models.DataExportReady).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching data export: %w", err)
+	}
+	return db.GetDataExport(username, strconv.FormatInt(id, 10))
+}
+
+// GetDataExport retrieves a data export belonging to a user
+func (db *PostgresDatabase) GetDataExport(username, exportId string) (*models.DataExport, error) {
+	query := "SELECT " + dataExportColumns + " FROM data_exports WHERE id = $1 AND username = $2"
+	return scanDataExport(db.conn.QueryRow(query, exportId, username))
+}
+
+// GetDataExportArchive retrieves the archive of a finished, unexpired data export
+func (db *PostgresDatabase) GetDataExportArchive(username, exportId string) ([]byte, error) {
+	query := `SELECT archive FROM data_exports
+		WHERE id = $1 AND username = $2 AND status = $3 AND expires_at > now()`
+	var archive []byte
+	err := db.conn.QueryRow(query, exportId, username, models.DataExportReady).Scan(&archive)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("data export is not available for download")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching data export archive: %w", err)
+	}
+	return archive, nil
+}
+
+// CompleteDataExport stores the archive of a data export and marks it ready
+func (db *PostgresDatabase) CompleteDataExport(exportId string, archive []byte, expiresAt time.Time) error {
+	query := "UPDATE data_exports SET status = $2, archive = $3, completed_at = now(), expires_at = $4 WHERE id = $1"
+	_, err := db.conn.Exec(query, exportId, models.DataExportReady, archive, expiresAt)
+	return err
+}
+
+// FailDataExport marks a data export as failed
+func (db *PostgresDatabase) FailDataExport(exportId string, reason string) error {
+	query := "UPDATE data_exports SET status = $2, error = $3, completed_at = now() WHERE id = $1"
+	_, err := db.conn.Exec(query, exportId, models.DataExportFailed, reason)
+	return err
+}
+
+// RequestAccountDeletion marks an account for deletion, keeping the original request time if already marked.
+// It returns the user's id and when deletion was requested.
+func (db *PostgresDatabase) RequestAccountDeletion(username string) (string, time.Time, error) {
+	query := `UPDATE users SET deletion_requested_at = COALESCE(deletion_requested_at, now())
+		WHERE username = $1 AND deleted_at IS NULL RETURNING id, deletion_requested_at`
+	var id int
+	var requestedAt time.Time
+	err := db.conn.QueryRow(query, username).Scan(&id, &requestedAt)
+	if err == pgx.ErrNoRows {
+		return "", time.Time{}, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error requesting account deletion: %w", err)
+	}
+	return strconv.Itoa(id), requestedAt, nil
+}
+
+// CancelAccountDeletion clears a pending deletion request and returns the user's id
+func (db *PostgresDatabase) CancelAccountDeletion(username string) (string, error) {
+	query := "UPDATE users SET deletion_requested_at = NULL WHERE username = $1 AND deleted_at IS NULL RETURNING id"
+	var id int
+	err := db.conn.QueryRow(query, username).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return "", fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(id), nil
+}
+
+// GetAccountsDueForPurge retrieves the accounts whose deletion was requested before the cutoff
+func (db *PostgresDatabase) GetAccountsDueForPurge(cutoff time.Time) ([]models.UserProfile, error) {
+	query := `SELECT id, username, email, created_at, deletion_requested_at FROM users
+		WHERE deleted_at IS NULL AND deletion_requested_at <= $1`
+	rows, err := db.conn.Query(query, cutoff)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []models.UserProfile
+	for rows.Next() {
+		var profile models.UserProfile
+		var id int
+		if err := rows.Scan(&id, &profile.Username, &profile.Email, &profile.CreatedAt, &profile.DeletionRequestedAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		profile.Id = strconv.Itoa(id)
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+// PurgeAccount anonymises a user row and removes the personal data attached to it.
+// The row itself is kept so that ids referenced elsewhere stay valid.
+func (db *PostgresDatabase) PurgeAccount(username string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The name the user's content is attributed to from now on. Locking the row keeps a concurrent purge from
+	// anonymising it twice.
+	var anonymised string
+	err = tx.QueryRow(`SELECT 'deleted-' || id FROM users WHERE username = $1 AND deleted_at IS NULL FOR UPDATE`,
+		username).Scan(&anonymised)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM organization_members
+		WHERE user_id = (SELECT id FROM users WHERE username = $1 AND deleted_at IS NULL)`, username)
+	if err != nil {
+		return fmt.Errorf("failed to remove organization memberships: %w", err)
+	}
+
+	// Discussion posts stay so threads keep making sense, but are no longer attributed to the user and lose their
+	// earlier versions
+	_, err = tx.Exec(`DELETE FROM discussion_post_revisions WHERE post_id IN (
+		SELECT id FROM discussion_posts WHERE author = $1)`, username)
+	if err != nil {
+		return fmt.Errorf("failed to remove discussion post history: %w", err)
+	}
+	for _, table := range []string{"discussion_threads", "discussion_posts"} {
+		_, err = tx.Exec("UPDATE "+table+" SET author = $2 WHERE author = $1", username, anonymised)
+		if err != nil {
+			return fmt.Errorf("failed to anonymise %s: %w", table, err)
+		}
+	}
+	for _, column := range []string{"author", "reporter"} {
+		_, err = tx.Exec("UPDATE content_reports SET "+column+" = $2 WHERE "+column+" = $1", username, anonymised)
+		if err != nil {
+			return fmt.Errorf("failed to anonymise content reports: %w", err)
+		}
+	}
+
+	// Reviews keep counting towards course ratings, but are no longer attributed to the user
+	for _, column := range []string{"username", "replied_by"} {
+		_, err = tx.Exec("UPDATE course_reviews SET "+column+" = $2 WHERE "+column+" = $1", username, anonymised)
+		if err != nil {
+			return fmt.Errorf("failed to anonymise course reviews: %w", err)
+		}
+	}
+
+	_, err = tx.Exec("UPDATE course_workflow_events SET actor = $2 WHERE actor = $1", username, anonymised)
+	if err != nil {
+		return fmt.Errorf("failed to anonymise course workflow history: %w", err)
+	}
+	_, err = tx.Exec("UPDATE course_versions SET created_by = $2 WHERE created_by = $1", username, anonymised)
+	if err != nil {
+		return fmt.Errorf("failed to anonymise course versions: %w", err)
+	}
+	_, err = tx.Exec("UPDATE course_templates SET added_by = $2 WHERE added_by = $1", username, anonymised)
+	if err != nil {
+		return fmt.Errorf("failed to anonymise course templates: %w", err)
+	}
+	_, err = tx.Exec("UPDATE enrollment_requests SET decided_by = $2 WHERE decided_by = $1", username, anonymised)
+	if err != nil {
+		return fmt.Errorf("failed to anonymise enrollment decisions: %w", err)
+	}
+
+	// Emails to the user stay in the outbox for delivery statistics, but lose their address and content. Those not
+	// sent yet are failed so they never go out, and the address is no longer kept as suppressed.
+	_, err = tx.Exec(`UPDATE email_outbox o SET
+			to_address = 'deleted-' || u.id || '@deleted.invalid',
+			subject = '',
+			text_body = '',
+			html_body = '',
+			status = CASE WHEN o.status = $2 THEN $3 ELSE o.status END,
+			last_error = CASE WHEN o.status = $2 THEN 'recipient deleted their account' ELSE '' END
+		FROM users u
+		WHERE u.username = $1 AND u.deleted_at IS NULL AND lower(o.to_address) = lower(u.email)`,
+		username, models.EmailQueued, models.EmailFailed)
+	if err != nil {
+		return fmt.Errorf("failed to redact outbox emails: %w", err)
+	}
+	_, err = tx.Exec(`DELETE FROM email_suppressions
+		WHERE email = (SELECT lower(email) FROM users WHERE username = $1 AND deleted_at IS NULL)`, username)
+	if err != nil {
+		return fmt.Errorf("failed to remove email suppression: %w", err)
+	}
+
+	// Imports still running keep their rows in place so progress stays valid, but the user's rows lose everything
+	// but their line number and are reported as failed when reached
+	_, err = tx.Exec(`UPDATE user_imports i SET rows = (
+			SELECT jsonb_agg(CASE WHEN r->>'name' = u.username OR lower(r->>'email') = lower(u.email)
+				THEN jsonb_build_object('line', r->'line') ELSE r END ORDER BY n)
+			FROM jsonb_array_elements(i.rows) WITH ORDINALITY AS e(r, n))
+		FROM users u
+		WHERE u.username = $1 AND u.deleted_at IS NULL AND EXISTS (
+			SELECT 1 FROM jsonb_array_elements(i.rows) r WHERE r->>'name' = u.username OR lower(r->>'email') = lower(u.email))`, username)
+	if err != nil {
+		return fmt.Errorf("failed to remove the user from imports: %w", err)
+	}
+
+	// Webhooks hear that the user left their courses; like the earlier events about the user, these are redacted below
+	if err = removeEnrollments(tx, username); err != nil {
+		return fmt.Errorf("failed to remove enrollments: %w", err)
+	}
+
+	// Webhook events about the user, and the deliveries made from them, keep their shape for receivers but carry the
+	// anonymised username and email instead of the user's
+	_, err = tx.Exec(`UPDATE webhook_events e SET data = jsonb_set(jsonb_set(e.data,
+			'{username}', to_jsonb('deleted-' || u.id), false),
+			'{email}', to_jsonb('deleted-' || u.id || '@deleted.invalid'), false)
+		FROM users u
+		WHERE u.username = $1 AND u.deleted_at IS NULL
+			AND (e.data->>'username' = u.username OR lower(e.data->>'email') = lower(u.email))`, username)
+	if err != nil {
+		return fmt.Errorf("failed to redact webhook events: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE webhook_deliveries d SET payload = jsonb_set(jsonb_set(d.payload::jsonb,
+			'{data,username}', to_jsonb('deleted-' || u.id), false),
+			'{data,email}', to_jsonb('deleted-' || u.id || '@deleted.invalid'), false)::text
+		FROM users u
+		WHERE u.username = $1 AND u.deleted_at IS NULL AND d.event_id IS NOT NULL
+			AND (d.payload::jsonb->'data'->>'username' = u.username
+				OR lower(d.payload::jsonb->'data'->>'email') = lower(u.email))`, username)
+	if err != nil {
+		return fmt.Errorf("failed to redact webhook deliveries: %w", err)
+	}
+
+	// Audit events stay, but lose the user's name, client address and user agent, along with the email and username
+	// recorded in the states of events about their account
+	if _, err = tx.Exec("SELECT set_config('orkidslearning.audit_erasure', 'on', true)"); err != nil {
+		return fmt.Errorf("failed to allow audit log erasure: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE audit_events a SET
+			actor = CASE WHEN a.actor = $1 THEN 'deleted-' || u.id ELSE a.actor END,
+			ip = CASE WHEN a.actor = $1 THEN '' ELSE a.ip END,
+			user_agent = CASE WHEN a.actor = $1 THEN '' ELSE a.user_agent END,
+			target_id = CASE WHEN a.target_type = $3 AND split_part(a.target_id, '/', 2) = $1
+				THEN split_part(a.target_id, '/', 1) || '/deleted-' || u.id ELSE a.target_id END,
+			before = CASE WHEN jsonb_typeof(a.before) = 'object' THEN a.before - 'email' - 'username' ELSE a.before END,
+			after = CASE WHEN jsonb_typeof(a.after) = 'object' THEN a.after - 'email' - 'username' ELSE a.after END
+		FROM users u
+		WHERE u.username = $1 AND u.deleted_at IS NULL AND (a.actor = $1
+			OR (a.target_type = $2 AND a.target_id = u.id::text)
+			OR (a.target_type = $3 AND split_part(a.target_id, '/', 2) = $1))`,
+		username, models.AuditTargetUser, models.AuditTargetEnrollment)
+	if err != nil {
+		return fmt.Errorf("failed to anonymise audit events: %w", err)
+	}
+
+	_, err = tx.Exec(`UPDATE users SET
+			username = 'deleted-' || id,
+			email = 'deleted-' || id || '@deleted.invalid',
+			password = '',
+			deletion_requested_at = NULL,
+			deleted_at = now()
+		WHERE username = $1 AND deleted_at IS NULL`, username)
+	if err != nil {
+		return fmt.Errorf("failed to anonymise user: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM course_waitlist WHERE username = $1", username); err != nil {
+		return fmt.Errorf("failed to remove waitlist places: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM enrollment_requests WHERE username = $1", username); err != nil {
+		return fmt.Errorf("failed to remove enrollment requests: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM lesson_progress WHERE username = $1", username); err != nil {
+		return fmt.Errorf("failed to remove lesson progress: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM assignment_submissions WHERE username = $1", username); err != nil {
+		return fmt.Errorf("failed to remove assignment submissions: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM data_exports WHERE username = $1", username); err != nil {
+		return fmt.Errorf("failed to remove data exports: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM notifications WHERE username = $1", username); err != nil {
+		return fmt.Errorf("failed to remove notifications: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM notification_preferences WHERE username = $1", username); err != nil {
+		return fmt.Errorf("failed to remove notification preferences: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM stream_events WHERE username = $1", username); err != nil {
+		return fmt.Errorf("failed to remove stream events: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM discussion_reactions WHERE username = $1", username); err != nil {
+		return fmt.Errorf("failed to remove discussion reactions: %w", err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,348 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+// rowScanner is satisfied by both *pgx.Row and *pgx.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+const userAccountColumns = `id, username, email, role, status, status_reason, password_reset_required,
+	created_at, deletion_requested_at, COALESCE(merged_into::text, '')`
+
+func scanUserAccount(row rowScanner) (*models.UserAccount, error) {
+	var account models.UserAccount
+	var id int
+	err := row.Scan(&id, &account.Username, &account.Email, &account.Role, &account.Status, &account.StatusReason,
+		&account.PasswordResetRequired, &account.CreatedAt, &account.DeletionRequestedAt, &account.MergedInto)
+	if err != nil {
+		return nil, err
+	}
+	account.Id = strconv.Itoa(id)
+	return &account, nil
+}
+
+// SearchUsers retrieves a page of active and suspended users matching the filter along with the total match count
+func (db *PostgresDatabase) SearchUsers(filter models.UserSearch) ([]models.UserAccount, int, error) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Email != "" {
+		addCondition("email ILIKE '%%' || $%d || '%%'", filter.Email)
+	}
+	if filter.Username != "" {
+		addCondition("username ILIKE '%%' || $%d || '%%'", filter.Username)
+	}
+	if filter.Role != "" {
+		addCondition("role = $%d", filter.Role)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.SignedUpAfter != nil {
+		addCondition("created_at >= $%d", *filter.SignedUpAfter)
+	}
+	if filter.SignedUpBefore != nil {
+		addCondition("created_at < $%d", *filter.SignedUpBefore)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM users WHERE "+where, args...).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM users WHERE %s ORDER BY created_at DESC, id DESC LIMIT %d OFFSET %d",
+		userAccountColumns, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	accounts := []models.UserAccount{}
+	for rows.Next() {
+		account, err := scanUserAccount(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		accounts = append(accounts, *account)
+	}
+	return accounts, total, rows.Err()
+}
+
+// GetUserAccountById retrieves the administrative view of a user by id
+func (db *PostgresDatabase) GetUserAccountById(userId string) (*models.UserAccount, error) {
+	query := "SELECT " + userAccountColumns + " FROM users WHERE id = $1 AND deleted_at IS NULL"
+	account, err := scanUserAccount(db.conn.QueryRow(query, userId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %w", err)
+	}
+	return account, nil
+}
+
+// GetUserAccountByUsername retrieves the administrative view of a user by username
+func (db *PostgresDatabase) GetUserAccountByUsername(username string) (*models.UserAccount, error) {
+	query := "SELECT " + userAccountColumns + " FROM users WHERE username = $1 AND deleted_at IS NULL"
+	account, err := scanUserAccount(db.conn.QueryRow(query, username))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user: %w", err)
+	}
+	return account, nil
+}
+
+// SetUserStatus changes the status of a user and records why
+func (db *PostgresDatabase) SetUserStatus(userId, status, reason string) error {
+	query := "UPDATE users SET status = $2, status_reason = $3 WHERE id = $1 AND deleted_at IS NULL"
+	tag, err := db.conn.Exec(query, userId, status, reason)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// SetUserRole changes the role of a user
+func (db *PostgresDatabase) SetUserRole(userId, role string) error {
+	query := "UPDATE users SET role = $2 WHERE id = $1 AND deleted_at IS NULL"
+	tag, err := db.conn.Exec(query, userId, role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// RequirePasswordReset locks a user out until they choose a new password with the given reset token
+func (db *PostgresDatabase) RequirePasswordReset(userId, tokenHash string, expiresAt time.Time) error {
+	query := `UPDATE users SET password_reset_required = true, password_reset_token_hash = $2, password_reset_expires_at = $3
+		WHERE id = $1 AND deleted_at IS NULL`
+	tag, err := db.conn.Exec(query, userId, tokenHash, expiresAt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// ResetPassword replaces the password of the user holding an unexpired reset token and returns that user's id and username
+func (db *PostgresDatabase) ResetPassword(tokenHash, passwordHash string) (string, string, error) {
+	query := `UPDATE users SET password = $2, password_reset_required = false,
+			password_reset_token_hash = NULL, password_reset_expires_at = NULL
+		WHERE password_reset_token_hash = $1 AND password_reset_expires_at > now() AND deleted_at IS NULL
+		RETURNING id, username`
+	var id int
+	var username string
+	err := db.conn.QueryRow(query, tokenHash, passwordHash).Scan(&id, &username)
+	if err == pgx.ErrNoRows {
+		return "", "", fmt.Errorf("invalid or expired reset token")
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return strconv.Itoa(id), username, nil
+}
+
+// MergeUsers moves the enrollments, requests, organization memberships and other activity of a duplicate account
+// onto the target account and retires the duplicate
+func (db *PostgresDatabase) MergeUsers(targetUsername, duplicateUsername string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Webhooks hear of the target's new enrollments and of the duplicate leaving all of its own
+	_, err = tx.Exec(`WITH moved AS (
+			INSERT INTO course_enrollments (username, id, enrolled_at, organization_id, cohort_id, version)
+			SELECT $1, d.id, d.enrolled_at, d.organization_id, d.cohort_id, d.version FROM course_enrollments d
+			WHERE d.username = $2 AND NOT EXISTS (
+				SELECT 1 FROM course_enrollments t WHERE t.username = $1 AND t.id = d.id
+			) RETURNING id, organization_id
+		)
+		INSERT INTO webhook_events (organization_id, type, data)
+		SELECT m.organization_id, $3, `+enrollmentWebhookData+`
+		FROM moved m JOIN courses c ON c.id = m.id JOIN users u ON u.username = $1`,
+		targetUsername, duplicateUsername, models.WebhookEventEnrollmentCreated)
+	if err != nil {
+		return fmt.Errorf("failed to move enrollments: %w", err)
+	}
+
+	if err = removeEnrollments(tx, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to remove duplicate enrollments: %w", err)
+	}
+
+	// Waitlist places move too, unless the target is already enrolled or waiting
+	_, err = tx.Exec(`UPDATE course_waitlist d SET username = $1 WHERE d.username = $2 AND NOT EXISTS (
+			SELECT 1 FROM course_enrollments t WHERE t.username = $1 AND t.id = d.course_id
+		) AND NOT EXISTS (
+			SELECT 1 FROM course_waitlist t WHERE t.username = $1 AND t.course_id = d.course_id
+		)`, targetUsername, duplicateUsername)
+	if err != nil {
+		return fmt.Errorf("failed to move waitlist places: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM course_waitlist WHERE username = $1", duplicateUsername); err != nil {
+		return fmt.Errorf("failed to remove duplicate waitlist places: %w", err)
+	}
+
+	// Open enrollment requests move unless the target already has one for the course or is enrolled in it
+	_, err = tx.Exec(`UPDATE enrollment_requests d SET username = $1 WHERE d.username = $2 AND NOT (
+			d.status IN ($3, $4) AND (EXISTS (
+				SELECT 1 FROM enrollment_requests t WHERE t.username = $1 AND t.course_id = d.course_id AND t.status IN ($3, $4)
+			) OR EXISTS (
+				SELECT 1 FROM course_enrollments t WHERE t.username = $1 AND t.id = d.course_id
+			)))`, targetUsername, duplicateUsername, models.EnrollmentRequestPending, models.EnrollmentRequestRejected)
+	if err != nil {
+		return fmt.Errorf("failed to move enrollment requests: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM enrollment_requests WHERE username = $1", duplicateUsername); err != nil {
+		return fmt.Errorf("failed to remove duplicate enrollment requests: %w", err)
+	}
+	if _, err = tx.Exec("UPDATE enrollment_requests SET decided_by = $1 WHERE decided_by = $2", targetUsername, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to move enrollment decisions: %w", err)
+	}
+
+	// Organization memberships move too, keeping the target's own role in organizations both accounts belong to
+	_, err = tx.Exec(`INSERT INTO organization_members (organization_id, user_id, role, joined_at)
+		SELECT d.organization_id, t.id, d.role, d.joined_at
+		FROM organization_members d JOIN users du ON du.id = d.user_id, users t
+		WHERE du.username = $2 AND t.username = $1
+		ON CONFLICT (organization_id, user_id) DO NOTHING`, targetUsername, duplicateUsername)
+	if err != nil {
+		return fmt.Errorf("failed to move organization memberships: %w", err)
+	}
+	_, err = tx.Exec(`DELETE FROM organization_members WHERE user_id = (SELECT id FROM users WHERE username = $1)`, duplicateUsername)
+	if err != nil {
+		return fmt.Errorf("failed to remove duplicate organization memberships: %w", err)
+	}
+
+	// Progress and submissions follow, keeping the target's own where both accounts have one
+	_, err = tx.Exec(`INSERT INTO lesson_progress (lesson_id, username, completed_at)
+		SELECT lesson_id, $1, completed_at FROM lesson_progress WHERE username = $2
+		ON CONFLICT DO NOTHING`, targetUsername, duplicateUsername)
+	if err != nil {
+		return fmt.Errorf("failed to move lesson progress: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO assignment_submissions (assignment_id, username, content, submitted_at)
+		SELECT assignment_id, $1, content, submitted_at FROM assignment_submissions WHERE username = $2
+		ON CONFLICT DO NOTHING`, targetUsername, duplicateUsername)
+	if err != nil {
+		return fmt.Errorf("failed to move assignment submissions: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM lesson_progress WHERE username = $1", duplicateUsername); err != nil {
+		return fmt.Errorf("failed to remove duplicate lesson progress: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM assignment_submissions WHERE username = $1", duplicateUsername); err != nil {
+		return fmt.Errorf("failed to remove duplicate assignment submissions: %w", err)
+	}
+
+	// The feed moves over, but the target's own notification preferences win
+	if _, err = tx.Exec("UPDATE notifications SET username = $1 WHERE username = $2", targetUsername, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to move notifications: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM notification_preferences WHERE username = $1", duplicateUsername); err != nil {
+		return fmt.Errorf("failed to remove duplicate notification preferences: %w", err)
+	}
+
+	// Discussion posts and reactions are attributed to the target
+	if _, err = tx.Exec("UPDATE discussion_threads SET author = $1 WHERE author = $2", targetUsername, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to move discussion threads: %w", err)
+	}
+	if _, err = tx.Exec("UPDATE discussion_posts SET author = $1 WHERE author = $2", targetUsername, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to move discussion posts: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO discussion_reactions (post_id, username, reaction, created_at)
+		SELECT post_id, $1, reaction, created_at FROM discussion_reactions WHERE username = $2
+		ON CONFLICT (post_id, username, reaction) DO NOTHING`, targetUsername, duplicateUsername)
+	if err != nil {
+		return fmt.Errorf("failed to move discussion reactions: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM discussion_reactions WHERE username = $1", duplicateUsername); err != nil {
+		return fmt.Errorf("failed to remove duplicate discussion reactions: %w", err)
+	}
+
+	// Content reports follow their author and reporter; an open report both users filed about the same content
+	// is kept once
+	if _, err = tx.Exec("UPDATE content_reports SET author = $1 WHERE author = $2", targetUsername, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to move content reports: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE content_reports r SET reporter = $1 WHERE r.reporter = $2 AND NOT (r.status = 'open' AND EXISTS (
+			SELECT 1 FROM content_reports o WHERE o.reporter = $1 AND o.status = 'open'
+				AND o.content_type = r.content_type AND o.content_id = r.content_id))`, targetUsername, duplicateUsername)
+	if err != nil {
+		return fmt.Errorf("failed to move content reports: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM content_reports WHERE reporter = $1", duplicateUsername); err != nil {
+		return fmt.Errorf("failed to remove duplicate content reports: %w", err)
+	}
+
+	// Reviews move to the target unless it reviewed the same course, in which case its own review is kept
+	_, err = tx.Exec(`SELECT 1 FROM courses WHERE id IN (
+			SELECT course_id FROM course_reviews WHERE username IN ($1, $2)
+		) ORDER BY id FOR UPDATE`, targetUsername, duplicateUsername)
+	if err != nil {
+		return fmt.Errorf("failed to lock reviewed courses: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE course_reviews r SET username = $1 WHERE r.username = $2 AND NOT EXISTS (
+			SELECT 1 FROM course_reviews o WHERE o.username = $1 AND o.course_id = r.course_id)`, targetUsername, duplicateUsername)
+	if err != nil {
+		return fmt.Errorf("failed to move course reviews: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM course_reviews WHERE username = $1", duplicateUsername); err != nil {
+		return fmt.Errorf("failed to remove duplicate course reviews: %w", err)
+	}
+	_, err = tx.Exec(refreshCourseRatings+"c.id IN (SELECT course_id FROM course_reviews WHERE username = $1)", targetUsername)
+	if err != nil {
+		return fmt.Errorf("failed to update course ratings: %w", err)
+	}
+	if _, err = tx.Exec("UPDATE course_reviews SET replied_by = $1 WHERE replied_by = $2", targetUsername, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to move review replies: %w", err)
+	}
+	if _, err = tx.Exec("UPDATE course_workflow_events SET actor = $1 WHERE actor = $2", targetUsername, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to move course workflow history: %w", err)
+	}
+	if _, err = tx.Exec("UPDATE course_versions SET created_by = $1 WHERE created_by = $2", targetUsername, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to move course versions: %w", err)
+	}
+	if _, err = tx.Exec("UPDATE course_templates SET added_by = $1 WHERE added_by = $2", targetUsername, duplicateUsername); err != nil {
+		return fmt.Errorf("failed to move course templates: %w", err)
+	}
+
+	_, err = tx.Exec(`UPDATE users SET status = $3, status_reason = 'merged into ' || $1::text,
+			merged_into = (SELECT id FROM users WHERE username = $1)
+		WHERE username = $2`, targetUsername, duplicateUsername, models.StatusMerged)
+	if err != nil {
+		return fmt.Errorf("failed to retire duplicate user: %w", err)
+	}
+
+	return tx.Commit()
+}
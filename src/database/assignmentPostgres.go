@@ -0,0 +1,115 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// AddAssignment adds an assignment to a course
+func (db *PostgresDatabase) AddAssignment(courseId string, assignment models.AddAssignment) (*models.Assignment, error) {
+	query := `INSERT INTO course_assignments (course_id, title, instructions, due_after_days)
+		VALUES ($1, $2, $3, $4) RETURNING id`
+	var id int64
+	err := db.conn.QueryRow(query, courseId, assignment.Title, assignment.Instructions, int32(assignment.DueAfterDays)).Scan(&id)
+	if err != nil {
+		log.Println("Insert error:", err)
+		return nil, fmt.Errorf("failed to add assignment: %w", err)
+	}
+	return &models.Assignment{
+		Id:           strconv.FormatInt(id, 10),
+		CourseId:     courseId,
+		Title:        assignment.Title,
+		Instructions: assignment.Instructions,
+		DueAfterDays: assignment.DueAfterDays,
+	}, nil
+}
+
+// GetAssignmentsForUser retrieves the assignments of the given courses along with when the user submitted each,
+// ordered by how soon they fall due. Without a username no submissions are looked up.
+func (db *PostgresDatabase) GetAssignmentsForUser(username string, courseIds []string) ([]models.Assignment, error) {
+	query := `SELECT a.id, a.course_id, a.title, a.instructions, a.due_after_days, s.submitted_at
+		FROM course_assignments a
+		LEFT JOIN assignment_submissions s ON s.assignment_id = a.id AND s.username = $1
+		WHERE a.course_id = ANY($2::uuid[])
+		ORDER BY a.due_after_days, a.id`
+	rows, err := db.conn.Query(query, username, courseIds)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []models.Assignment{}
+	for rows.Next() {
+		var assignment models.Assignment
+		var id int64
+		var courseId pgtype.UUID
+		var dueAfterDays int32
+		err := rows.Scan(&id, &courseId, &assignment.Title, &assignment.Instructions, &dueAfterDays, &assignment.SubmittedAt)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		assignment.Id = strconv.FormatInt(id, 10)
+		assignment.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+		assignment.DueAfterDays = int(dueAfterDays)
+		assignments = append(assignments, assignment)
+	}
+	return assignments, rows.Err()
+}
+
+// SubmitAssignment stores a user's submission for an assignment of a course they are enrolled in, replacing an
+// earlier one, and returns when it was submitted. The course must be visible to the tenant and still open to the
+// user, so members who left its organization can no longer submit.
+func (db *PostgresDatabase) SubmitAssignment(tenant, username, courseId, assignmentId, content string) (time.Time, error) {
+	query := `INSERT INTO assignment_submissions (assignment_id, username, content)
+		SELECT a.id, $2, $5 FROM course_assignments a
+		JOIN course_enrollments e ON e.id = a.course_id AND e.username = $2
+		JOIN courses c ON c.id = a.course_id
+		WHERE a.id = $4 AND a.course_id = $3 AND ` + courseVisibleToTenant(1) + ` AND ` + courseAccessibleToUser(2) + `
+		ON CONFLICT (assignment_id, username) DO UPDATE SET content = excluded.content, submitted_at = now()
+		RETURNING submitted_at`
+	var submittedAt time.Time
+	err := db.conn.QueryRow(query, tenant, username, courseId, assignmentId, content).Scan(&submittedAt)
+	if err == pgx.ErrNoRows {
+		return time.Time{}, fmt.Errorf("assignment '%s' of course '%s' not found among the user's courses", assignmentId, courseId)
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to submit assignment: %w", err)
+	}
+	return submittedAt, nil
+}
+
+// GetSubmissionsForUser retrieves every assignment submission of the user, oldest first
+func (db *PostgresDatabase) GetSubmissionsForUser(username string) ([]models.AssignmentSubmission, error) {
+	rows, err := db.conn.Query(`SELECT a.course_id, a.id, a.title, s.content, s.submitted_at
+		FROM assignment_submissions s JOIN course_assignments a ON a.id = s.assignment_id
+		WHERE s.username = $1 ORDER BY s.submitted_at, a.id`, username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	submissions := []models.AssignmentSubmission{}
+	for rows.Next() {
+		var submission models.AssignmentSubmission
+		var courseId pgtype.UUID
+		var assignmentId int64
+		if err := rows.Scan(&courseId, &assignmentId, &submission.AssignmentTitle, &submission.Content, &submission.SubmittedAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		submission.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+		submission.AssignmentId = strconv.FormatInt(assignmentId, 10)
+		submissions = append(submissions, submission)
+	}
+	return submissions, rows.Err()
+}
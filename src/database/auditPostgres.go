@@ -0,0 +1,164 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	models "orkidslearning/src/models/database"
+)
+
+const auditEventColumns = "id, occurred_at, actor, action, target_type, target_id, ip, user_agent, trace_id, before::text, after::text"
+
+func scanAuditEvent(row rowScanner) (*models.AuditEvent, error) {
+	var event models.AuditEvent
+	var id int64
+	var before, after *string
+	err := row.Scan(&id, &event.OccurredAt, &event.Actor, &event.Action, &event.TargetType, &event.TargetId,
+		&event.IP, &event.UserAgent, &event.TraceId, &before, &after)
+	if err != nil {
+		return nil, err
+	}
+	event.Id = strconv.FormatInt(id, 10)
+	if before != nil {
+		event.Before = json.RawMessage(*before)
+	}
+	if after != nil {
+		event.After = json.RawMessage(*after)
+	}
+	return &event, nil
+}
+
+// RecordAuditEvent appends an event to the audit log
+func (db *PostgresDatabase) RecordAuditEvent(event models.AuditEvent) error {
+	query := `INSERT INTO audit_events (actor, action, target_type, target_id, ip, user_agent, trace_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb, $9::jsonb)`
+	_, err := db.conn.Exec(query, event.Actor, event.Action, event.TargetType, event.TargetId,
+		event.IP, event.UserAgent, event.TraceId, nullableJSON(event.Before), nullableJSON(event.After))
+	if err != nil {
+		log.Println("Insert error:", err)
+		return err
+	}
+	return nil
+}
+
+// nullableJSON maps an empty document to NULL
+func nullableJSON(document json.RawMessage) *string {
+	if len(document) == 0 {
+		return nil
+	}
+	value := string(document)
+	return &value
+}
+
+func auditSearchConditions(filter models.AuditSearch) (string, []interface{}) {
+	conditions := []string{"true"}
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Actor != "" {
+		addCondition("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+	if filter.TargetType != "" {
+		addCondition("target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetId != "" {
+		addCondition("target_id = $%d", filter.TargetId)
+	}
+	if filter.From != nil {
+		addCondition("occurred_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("occurred_at < $%d", *filter.To)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// SearchAuditEvents retrieves a page of audit events matching the filter, newest first, along with the total match count
+func (db *PostgresDatabase) SearchAuditEvents(filter models.AuditSearch) ([]models.AuditEvent, int, error) {
+	where, args := auditSearchConditions(filter)
+
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM audit_events WHERE "+where, args...).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM audit_events WHERE %s ORDER BY occurred_at DESC, id DESC LIMIT %d OFFSET %d",
+		auditEventColumns, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		events = append(events, *event)
+	}
+	return events, total, rows.Err()
+}
+
+// EachAuditEvent calls fn for every audit event matching the filter, oldest first, ignoring pagination
+func (db *PostgresDatabase) EachAuditEvent(filter models.AuditSearch, fn func(models.AuditEvent) error) error {
+	where, args := auditSearchConditions(filter)
+
+	query := fmt.Sprintf("SELECT %s FROM audit_events WHERE %s ORDER BY occurred_at, id", auditEventColumns, where)
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return err
+		}
+		if err := fn(*event); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetAuditEventsAboutUser retrieves every audit event the user acted in or that concerns their account or their
+// enrollments, oldest first
+func (db *PostgresDatabase) GetAuditEventsAboutUser(username, userId string) ([]models.AuditEvent, error) {
+	query := "SELECT " + auditEventColumns + ` FROM audit_events
+		WHERE actor = $1 OR (target_type = $3 AND target_id = $2) OR (target_type = $4 AND split_part(target_id, '/', 2) = $1)
+		ORDER BY occurred_at, id`
+	rows, err := db.conn.Query(query, username, userId, models.AuditTargetUser, models.AuditTargetEnrollment)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
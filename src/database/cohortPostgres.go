@@ -0,0 +1,151 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// cohortHasSeat is true for cohorts aliased h that still have a free seat
+const cohortHasSeat = `(h.capacity IS NULL OR h.capacity > (SELECT count(*) FROM course_enrollments s WHERE s.cohort_id = h.id))`
+
+const cohortColumns = `h.id, h.course_id, h.name, h.starts_at, h.ends_at, COALESCE(u.username, ''), h.capacity,
+	(SELECT count(*) FROM course_enrollments s WHERE s.cohort_id = h.id)`
+
+func scanCohort(row rowScanner) (*models.Cohort, error) {
+	var cohort models.Cohort
+	var id int64
+	var courseId pgtype.UUID
+	var capacity *int32
+	var enrolled int64
+	if err := row.Scan(&id, &courseId, &cohort.Name, &cohort.StartsAt, &cohort.EndsAt, &cohort.Facilitator, &capacity, &enrolled); err != nil {
+		return nil, err
+	}
+	cohort.Id = strconv.FormatInt(id, 10)
+	cohort.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+	cohort.Capacity = intFromNullable(capacity)
+	cohort.Enrolled = int(enrolled)
+	return &cohort, nil
+}
+
+// AddCohort schedules a new cohort of a course
+func (db *PostgresDatabase) AddCohort(courseId string, cohort models.AddCohort) (*models.Cohort, error) {
+	query := `INSERT INTO cohorts (course_id, name, starts_at, ends_at, facilitator_id, capacity)
+		VALUES ($1, $2, $3, $4, (SELECT id FROM users WHERE username = NULLIF($5, '') AND deleted_at IS NULL), $6)
+		RETURNING id`
+	var id int64
+	err := db.conn.QueryRow(query, courseId, cohort.Name, cohort.StartsAt, cohort.EndsAt, cohort.Facilitator,
+		nullableFromInt(cohort.Capacity)).Scan(&id)
+	if err != nil {
+		log.Println("Insert error:", err)
+		return nil, fmt.Errorf("failed to add cohort: %w", err)
+	}
+	return &models.Cohort{
+		Id:          strconv.FormatInt(id, 10),
+		CourseId:    courseId,
+		Name:        cohort.Name,
+		StartsAt:    cohort.StartsAt,
+		EndsAt:      cohort.EndsAt,
+		Facilitator: cohort.Facilitator,
+		Capacity:    cohort.Capacity,
+	}, nil
+}
+
+// GetCohortsForCourse retrieves every cohort of a course, earliest start first
+func (db *PostgresDatabase) GetCohortsForCourse(courseId string) ([]models.Cohort, error) {
+	query := "SELECT " + cohortColumns + ` FROM cohorts h LEFT JOIN users u ON u.id = h.facilitator_id
+		WHERE h.course_id = $1 ORDER BY h.starts_at, h.id`
+	rows, err := db.conn.Query(query, courseId)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	cohorts := []models.Cohort{}
+	for rows.Next() {
+		cohort, err := scanCohort(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		cohorts = append(cohorts, *cohort)
+	}
+	return cohorts, rows.Err()
+}
+
+// GetCohort retrieves a cohort of a course by its ID
+func (db *PostgresDatabase) GetCohort(courseId, cohortId string) (*models.Cohort, error) {
+	query := "SELECT " + cohortColumns + ` FROM cohorts h LEFT JOIN users u ON u.id = h.facilitator_id
+		WHERE h.course_id = $1 AND h.id = $2`
+	cohort, err := scanCohort(db.conn.QueryRow(query, courseId, cohortId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("cohort '%s' does not belong to course '%s'", cohortId, courseId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cohort: %w", err)
+	}
+	return cohort, nil
+}
+
+// GetOpenCohortId retrieves the cohort of a course new learners join: the earliest starting one with a free seat,
+// or the earliest that has not ended when all are full. It returns "" if every cohort has ended.
+func (db *PostgresDatabase) GetOpenCohortId(courseId string) (string, error) {
+	return openCohortId(db.conn.QueryRow(openCohortQuery, courseId))
+}
+
+const openCohortQuery = "SELECT h.id FROM cohorts h WHERE h.course_id = $1 AND h.ends_at > now() ORDER BY " + cohortHasSeat + " DESC, h.starts_at, h.id LIMIT 1"
+
+func openCohortId(row rowScanner) (string, error) {
+	var id int64
+	err := row.Scan(&id)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error fetching open cohort: %w", err)
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// pickCohort chooses the cohort of a locked course a learner joins, the way the course's open cohort is chosen when
+// cohortId is empty. A self-paced course has no cohorts, and a chosen cohort must belong to the course and not have
+// ended.
+func pickCohort(tx *pgx.Tx, course *models.CoursePostgres, cohortId string) (string, error) {
+	if !course.CohortBased {
+		if cohortId != "" {
+			return "", fmt.Errorf("course '%s' is self-paced and has no cohorts", course.Id)
+		}
+		return "", nil
+	}
+
+	if cohortId == "" {
+		openId, err := openCohortId(tx.QueryRow(openCohortQuery, course.Id))
+		if err != nil {
+			return "", err
+		}
+		if openId == "" {
+			return "", fmt.Errorf("course '%s' has no cohort open for enrollment", course.Id)
+		}
+		return openId, nil
+	}
+
+	var name string
+	var open bool
+	err := tx.QueryRow("SELECT name, ends_at > now() FROM cohorts WHERE course_id = $1 AND id = $2", course.Id, cohortId).Scan(&name, &open)
+	if err == pgx.ErrNoRows {
+		return "", fmt.Errorf("cohort '%s' does not belong to course '%s'", cohortId, course.Id)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error fetching cohort: %w", err)
+	}
+	if !open {
+		return "", fmt.Errorf("cohort '%s' has already ended", name)
+	}
+	return cohortId, nil
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx/pgtype"
+)
+
+// CourseTitleInUse reports whether a course visible to the tenant already has the title, ignoring case
+func (db *PostgresDatabase) CourseTitleInUse(tenant, title string) (bool, error) {
+	query := "SELECT EXISTS (SELECT 1 FROM courses c WHERE lower(c.title) = lower($2) AND " + courseVisibleToTenant(1) + ")"
+	var inUse bool
+	if err := db.conn.QueryRow(query, tenant, title).Scan(&inUse); err != nil {
+		return false, fmt.Errorf("error checking course title: %w", err)
+	}
+	return inUse, nil
+}
+
+// ImportCourse creates a draft course of the tenant along with the lessons and assignments of an archive, and returns
+// the ids given to the refs of the lessons and assignments
+func (db *PostgresDatabase) ImportCourse(tenant string, course models.AddCourse, lessons []models.CourseArchiveLesson, assignments []models.CourseArchiveAssignment) (*models.CoursePostgres, map[string]string, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	var id pgtype.UUID
+	err = tx.QueryRow(`INSERT INTO courses (title, description, organization_id, visibility, cohort_based, capacity,
+			enrollment_policy, category_id, tags, subject, grade_level)
+		VALUES ($1, $2, NULLIF($3, '')::bigint, $4, $5, $6, $7, NULLIF($8, '')::bigint, $9::text[], $10, $11) RETURNING id`,
+		course.Title, course.Description, tenant, course.Visibility, course.CohortBased, nullableFromInt(course.Capacity),
+		course.EnrollmentPolicy, course.CategoryId, course.Tags, course.Subject, course.GradeLevel).Scan(&id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to import course: %w", err)
+	}
+	courseId := fmt.Sprintf("%x", id.Bytes)
+
+	idMap := map[string]string{}
+	for _, lesson := range lessons {
+		var lessonId int64
+		err := tx.QueryRow(`INSERT INTO course_lessons (course_id, position, title, content, release_after_days)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id`, courseId, int32(lesson.Position), lesson.Title, lesson.Content,
+			int32(lesson.ReleaseAfterDays)).Scan(&lessonId)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to import lesson '%s': %w", lesson.Ref, err)
+		}
+		idMap[lesson.Ref] = strconv.FormatInt(lessonId, 10)
+	}
+	for _, assignment := range assignments {
+		var assignmentId int64
+		err := tx.QueryRow(`INSERT INTO course_assignments (course_id, title, instructions, due_after_days)
+			VALUES ($1, $2, $3, $4) RETURNING id`, courseId, assignment.Title, assignment.Instructions,
+			int32(assignment.DueAfterDays)).Scan(&assignmentId)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to import assignment '%s': %w", assignment.Ref, err)
+		}
+		idMap[assignment.Ref] = strconv.FormatInt(assignmentId, 10)
+	}
+
+	imported, err := scanCourse(tx.QueryRow("SELECT "+courseColumns+" FROM courses c WHERE c.id = $1", courseId))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read imported course: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return imported, idMap, nil
+}
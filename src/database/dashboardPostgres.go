@@ -0,0 +1,207 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+// enrollmentState derives the dashboard state of the enrollment aliased e
+const enrollmentState = `CASE WHEN e.archived_at IS NOT NULL THEN 'archived'
+	WHEN e.completed_at IS NOT NULL THEN 'completed' ELSE 'in_progress' END`
+
+// myCoursesOrder maps the dashboard sort keys onto ORDER BY expressions
+var myCoursesOrder = map[string]string{
+	"lastAccessed": "e.last_accessed_at %s NULLS LAST",
+	"enrolled":     "e.enrolled_at %s",
+	"title":        "c.title %s",
+	"progress":     "progress.completed::float / NULLIF(progress.total, 0) %s NULLS LAST",
+}
+
+// GetMyCourses retrieves a page of the courses a user is enrolled in and that the tenant can see, with their progress
+// and next lesson, along with the total match count
+func (db *PostgresDatabase) GetMyCourses(tenant, username string, filter models.MyCoursesSearch) ([]models.MyCourse, int, error) {
+	where := "e.username = $2 AND " + courseVisibleToTenant(1)
+	args := []interface{}{tenant, username}
+	if filter.State != "" {
+		args = append(args, filter.State)
+		where += fmt.Sprintf(" AND %s = $%d", enrollmentState, len(args))
+	}
+
+	var total int
+	err := db.conn.QueryRow("SELECT count(*) FROM course_enrollments e JOIN courses c ON c.id = e.id WHERE "+where, args...).Scan(&total)
+	if err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	sort, ok := myCoursesOrder[filter.Sort]
+	if !ok {
+		sort = myCoursesOrder["lastAccessed"]
+	}
+	order := "DESC"
+	if filter.Order == "asc" {
+		order = "ASC"
+	}
+
+	query := fmt.Sprintf(`SELECT %s, COALESCE(e.cohort_id::text, ''), %s, e.enrolled_at, e.last_accessed_at, e.completed_at,
+			e.archived_at, COALESCE(h.starts_at, e.enrolled_at), progress.total, progress.completed,
+			next.id, next.position, next.title, next.release_after_days
+		FROM course_enrollments e
+		JOIN courses c ON c.id = e.id
+		LEFT JOIN cohorts h ON h.id = e.cohort_id
+		LEFT JOIN LATERAL (
+			SELECT count(*) AS total, count(p.lesson_id) AS completed FROM `+enrollmentLessons+` l
+			LEFT JOIN lesson_progress p ON p.lesson_id = l.id AND p.username = e.username
+		) progress ON true
+		LEFT JOIN LATERAL (
+			SELECT l.id, l.position, l.title, l.release_after_days FROM `+enrollmentLessons+` l
+			WHERE NOT EXISTS (
+				SELECT 1 FROM lesson_progress p WHERE p.lesson_id = l.id AND p.username = e.username
+			)
+			ORDER BY l.position LIMIT 1
+		) next ON true
+		WHERE %s ORDER BY %s, e.enrolled_at DESC LIMIT %d OFFSET %d`,
+		courseColumns, enrollmentState, where, fmt.Sprintf(sort, order), filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	courses := []models.MyCourse{}
+	for rows.Next() {
+		var myCourse models.MyCourse
+		var totalLessons, completedLessons int64
+		var nextId *int64
+		var nextPosition, nextReleaseAfterDays *int32
+		var nextTitle *string
+		course, err := scanCourse(rowScannerFunc(func(dest ...interface{}) error {
+			return rows.Scan(append(dest, &myCourse.CohortId, &myCourse.State, &myCourse.EnrolledAt, &myCourse.LastAccessedAt,
+				&myCourse.CompletedAt, &myCourse.ArchivedAt, &myCourse.ReleaseStart, &totalLessons, &completedLessons,
+				&nextId, &nextPosition, &nextTitle, &nextReleaseAfterDays)...)
+		}))
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		myCourse.Course = *course
+		myCourse.Progress = models.CourseProgress{CompletedLessons: int(completedLessons), TotalLessons: int(totalLessons)}
+		if totalLessons > 0 {
+			myCourse.Progress.Percent = int(completedLessons * 100 / totalLessons)
+		}
+		if nextId != nil {
+			myCourse.NextLesson = &models.Lesson{
+				Id:               strconv.FormatInt(*nextId, 10),
+				CourseId:         course.Id,
+				Position:         int(*nextPosition),
+				Title:            *nextTitle,
+				ReleaseAfterDays: int(*nextReleaseAfterDays),
+			}
+		}
+		myCourse.DueAssignments = []models.Assignment{}
+		courses = append(courses, myCourse)
+	}
+	return courses, total, rows.Err()
+}
+
+// rowScannerFunc lets a scan function that reads extra columns stand in for a row
+type rowScannerFunc func(dest ...interface{}) error
+
+func (f rowScannerFunc) Scan(dest ...interface{}) error {
+	return f(dest...)
+}
+
+// TouchEnrollment records that a user has just opened a course they are enrolled in
+func (db *PostgresDatabase) TouchEnrollment(username, courseId string) error {
+	_, err := db.conn.Exec("UPDATE course_enrollments SET last_accessed_at = now() WHERE username = $1 AND id = $2", username, courseId)
+	return err
+}
+
+// SetEnrollmentArchived archives or restores a course on the user's dashboard
+func (db *PostgresDatabase) SetEnrollmentArchived(username, courseId string, archived bool) error {
+	query := `UPDATE course_enrollments SET archived_at = CASE WHEN $3 THEN COALESCE(archived_at, now()) END
+		WHERE username = $1 AND id = $2`
+	tag, err := db.conn.Exec(query, username, courseId, archived)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user '%s' is not enrolled in course '%s'", username, courseId)
+	}
+	return nil
+}
+
+// CompleteLesson records that a user completed a lesson, marking the course completed once every lesson is.
+// It reports whether this completed the course.
+func (db *PostgresDatabase) CompleteLesson(username, courseId, lessonId string) (bool, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	tag, err := tx.Exec("UPDATE course_enrollments SET last_accessed_at = now() WHERE username = $1 AND id = $2", username, courseId)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, fmt.Errorf("user '%s' is not enrolled in course '%s'", username, courseId)
+	}
+
+	_, err = tx.Exec(`INSERT INTO lesson_progress (lesson_id, username)
+		SELECT l.id, $1 FROM course_enrollments e, LATERAL `+enrollmentLessons+` l
+		WHERE e.username = $1 AND e.id = $2 AND l.id = $3
+		ON CONFLICT (lesson_id, username) DO NOTHING`, username, courseId, lessonId)
+	if err != nil {
+		return false, fmt.Errorf("failed to record lesson progress: %w", err)
+	}
+
+	var organizationId string
+	err = tx.QueryRow(`UPDATE course_enrollments e SET completed_at = now()
+		WHERE e.username = $1 AND e.id = $2 AND e.completed_at IS NULL AND NOT EXISTS (
+			SELECT 1 FROM `+enrollmentLessons+` l WHERE NOT EXISTS (
+				SELECT 1 FROM lesson_progress p WHERE p.lesson_id = l.id AND p.username = e.username
+			)
+		) RETURNING COALESCE(e.organization_id::text, '')`, username, courseId).Scan(&organizationId)
+	if err != nil && err != pgx.ErrNoRows {
+		return false, fmt.Errorf("failed to complete course: %w", err)
+	}
+	completed := err == nil
+	if completed {
+		if err := addEnrollmentWebhookEvent(tx, organizationId, models.WebhookEventCourseCompleted, username, courseId); err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return completed, nil
+}
+
+// GetCompletedLessonIds retrieves the lessons of a course the user has completed
+func (db *PostgresDatabase) GetCompletedLessonIds(username, courseId string) (map[string]bool, error) {
+	rows, err := db.conn.Query(`SELECT p.lesson_id FROM lesson_progress p JOIN course_lessons l ON l.id = p.lesson_id
+		WHERE p.username = $1 AND l.course_id = $2`, username, courseId)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	completed := map[string]bool{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		completed[strconv.FormatInt(id, 10)] = true
+	}
+	return completed, rows.Err()
+}
@@ -0,0 +1,579 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+const discussionThreadColumns = `t.id, t.course_id, COALESCE(t.lesson_id::text, ''), t.title, t.author, t.pinned,
+	COALESCE(t.answer_post_id::text, ''), t.hidden_at IS NOT NULL,
+	(SELECT count(*) FROM discussion_posts p WHERE p.thread_id = t.id AND p.parent_id IS NOT NULL),
+	t.created_at, t.last_activity_at`
+
+// discussionPostColumns selects the post aliased p along with its reaction counts and the reactions of the user in
+// parameter $n
+func discussionPostColumns(n int) string {
+	return fmt.Sprintf(`p.id, p.thread_id, COALESCE(p.parent_id::text, ''), p.author, p.body, p.hidden_at IS NOT NULL,
+		p.deleted_at IS NOT NULL, COALESCE((SELECT jsonb_object_agg(counts.reaction, counts.total)::text FROM (
+			SELECT r.reaction, count(*) AS total FROM discussion_reactions r WHERE r.post_id = p.id GROUP BY r.reaction
+		) counts), '{}'),
+		COALESCE((SELECT array_agg(r.reaction ORDER BY r.reaction) FROM discussion_reactions r
+			WHERE r.post_id = p.id AND r.username = $%d), '{}'),
+		p.created_at, p.edited_at`, n)
+}
+
+func scanDiscussionThread(row rowScanner) (*models.DiscussionThread, error) {
+	var thread models.DiscussionThread
+	var id, replies int64
+	var courseId pgtype.UUID
+	err := row.Scan(&id, &courseId, &thread.LessonId, &thread.Title, &thread.Author, &thread.Pinned, &thread.AnswerPostId,
+		&thread.Hidden, &replies, &thread.CreatedAt, &thread.LastActivityAt)
+	if err != nil {
+		return nil, err
+	}
+	thread.Id = strconv.FormatInt(id, 10)
+	thread.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+	thread.ReplyCount = int(replies)
+	return &thread, nil
+}
+
+func scanDiscussionPost(row rowScanner) (*models.DiscussionPost, error) {
+	var post models.DiscussionPost
+	var id, threadId int64
+	var reactions string
+	err := row.Scan(&id, &threadId, &post.ParentId, &post.Author, &post.Body, &post.Hidden, &post.Deleted, &reactions, &post.MyReactions,
+		&post.CreatedAt, &post.EditedAt)
+	if err != nil {
+		return nil, err
+	}
+	post.Id = strconv.FormatInt(id, 10)
+	post.ThreadId = strconv.FormatInt(threadId, 10)
+	if err := json.Unmarshal([]byte(reactions), &post.Reactions); err != nil {
+		return nil, fmt.Errorf("error decoding reactions: %w", err)
+	}
+	return &post, nil
+}
+
+// AddDiscussionThread starts a thread about a course, or one of its lessons, with its opening post
+func (db *PostgresDatabase) AddDiscussionThread(courseId, author string, add models.AddDiscussionThread) (*models.DiscussionThread, error) {
+	if add.LessonId != "" {
+		if _, err := strconv.ParseInt(add.LessonId, 10, 64); err != nil {
+			return nil, fmt.Errorf("lesson '%s' does not belong to course '%s'", add.LessonId, courseId)
+		}
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var threadId int64
+	err = tx.QueryRow(`INSERT INTO discussion_threads (course_id, lesson_id, title, author)
+		SELECT $1::uuid, NULLIF($2, '')::bigint, $3, $4
+		WHERE NULLIF($2, '') IS NULL OR EXISTS (
+			SELECT 1 FROM course_lessons l WHERE l.id = NULLIF($2, '')::bigint AND l.course_id = $1
+		) RETURNING id`, courseId, add.LessonId, add.Title, author).Scan(&threadId)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("lesson '%s' does not belong to course '%s'", add.LessonId, courseId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to start thread: %w", err)
+	}
+
+	_, err = tx.Exec("INSERT INTO discussion_posts (thread_id, author, body) VALUES ($1, $2, $3)", threadId, author, add.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add opening post: %w", err)
+	}
+
+	thread, err := scanDiscussionThread(tx.QueryRow("SELECT "+discussionThreadColumns+" FROM discussion_threads t WHERE t.id = $1", threadId))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// GetDiscussionThreads retrieves a page of a course's threads, pinned ones first and then the most recently active,
+// along with the total match count. Hidden threads are left out unless includeHidden is set.
+func (db *PostgresDatabase) GetDiscussionThreads(courseId string, includeHidden bool, filter models.DiscussionThreadSearch) ([]models.DiscussionThread, int, error) {
+	conditions := []string{"t.course_id = $1"}
+	args := []interface{}{courseId}
+	if !includeHidden {
+		conditions = append(conditions, "t.hidden_at IS NULL")
+	}
+	if filter.LessonId != "" {
+		if _, err := strconv.ParseInt(filter.LessonId, 10, 64); err != nil {
+			return []models.DiscussionThread{}, 0, nil
+		}
+		args = append(args, filter.LessonId)
+		conditions = append(conditions, fmt.Sprintf("t.lesson_id = $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM discussion_threads t WHERE "+where, args...).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM discussion_threads t WHERE %s
+		ORDER BY t.pinned DESC, t.last_activity_at DESC, t.id DESC LIMIT %d OFFSET %d`,
+		discussionThreadColumns, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	threads := []models.DiscussionThread{}
+	for rows.Next() {
+		thread, err := scanDiscussionThread(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		threads = append(threads, *thread)
+	}
+	return threads, total, rows.Err()
+}
+
+// GetDiscussionThread retrieves one of a course's threads, failing for hidden threads unless includeHidden is set
+func (db *PostgresDatabase) GetDiscussionThread(courseId, threadId string, includeHidden bool) (*models.DiscussionThread, error) {
+	if _, err := strconv.ParseInt(threadId, 10, 64); err != nil {
+		return nil, fmt.Errorf("thread not found")
+	}
+	query := "SELECT " + discussionThreadColumns + " FROM discussion_threads t WHERE t.id = $1 AND t.course_id = $2"
+	if !includeHidden {
+		query += " AND t.hidden_at IS NULL"
+	}
+	thread, err := scanDiscussionThread(db.conn.QueryRow(query, threadId, courseId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("thread not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching thread: %w", err)
+	}
+	return thread, nil
+}
+
+// GetDiscussionPosts retrieves a page of a thread's posts, oldest first so the opening post comes first, along with
+// the total post count. Reactions of username are reported as theirs.
+func (db *PostgresDatabase) GetDiscussionPosts(threadId, username string, page models.Pagination) ([]models.DiscussionPost, int, error) {
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM discussion_posts WHERE thread_id = $1", threadId).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM discussion_posts p WHERE p.thread_id = $1 ORDER BY p.id LIMIT %d OFFSET %d",
+		discussionPostColumns(2), page.Limit(), page.Offset())
+	rows, err := db.conn.Query(query, threadId, username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	posts := []models.DiscussionPost{}
+	for rows.Next() {
+		post, err := scanDiscussionPost(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		posts = append(posts, *post)
+	}
+	return posts, total, rows.Err()
+}
+
+// GetDiscussionPost retrieves a post of one of a course's threads. Reactions of username are reported as theirs.
+func (db *PostgresDatabase) GetDiscussionPost(courseId, threadId, postId, username string) (*models.DiscussionPost, error) {
+	if _, err := strconv.ParseInt(postId, 10, 64); err != nil {
+		return nil, fmt.Errorf("post not found")
+	}
+	query := "SELECT " + discussionPostColumns(4) + ` FROM discussion_posts p
+		JOIN discussion_threads t ON t.id = p.thread_id
+		WHERE p.id = $1 AND p.thread_id = $2 AND t.course_id = $3`
+	post, err := scanDiscussionPost(db.conn.QueryRow(query, postId, threadId, courseId, username))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("post not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching post: %w", err)
+	}
+	return post, nil
+}
+
+// AddDiscussionPost replies to a visible thread of a course, or to a visible post in it when ParentId is set
+func (db *PostgresDatabase) AddDiscussionPost(courseId, threadId, author string, add models.AddDiscussionPost) (*models.DiscussionPost, error) {
+	if _, err := strconv.ParseInt(threadId, 10, 64); err != nil {
+		return nil, fmt.Errorf("thread not found")
+	}
+	if add.ParentId != "" {
+		if _, err := strconv.ParseInt(add.ParentId, 10, 64); err != nil {
+			return nil, fmt.Errorf("post '%s' is not in this thread", add.ParentId)
+		}
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tag, err := tx.Exec(`UPDATE discussion_threads SET last_activity_at = now()
+		WHERE id = $1 AND course_id = $2 AND hidden_at IS NULL`, threadId, courseId)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("thread not found")
+	}
+
+	query := `INSERT INTO discussion_posts AS p (thread_id, parent_id, author, body)
+		SELECT $1::bigint, NULLIF($2, '')::bigint, $3, $4
+		WHERE NULLIF($2, '') IS NULL OR EXISTS (
+			SELECT 1 FROM discussion_posts parent
+			WHERE parent.id = NULLIF($2, '')::bigint AND parent.thread_id = $1
+				AND parent.hidden_at IS NULL AND parent.deleted_at IS NULL
+		) RETURNING ` + discussionPostColumns(3)
+	post, err := scanDiscussionPost(tx.QueryRow(query, threadId, add.ParentId, author, add.Body))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("post '%s' is not in this thread", add.ParentId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add post: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// EditDiscussionPost replaces the body of a visible post by editor, keeping the previous body in its history
+func (db *PostgresDatabase) EditDiscussionPost(courseId, threadId, postId, editor, body string) (*models.DiscussionPost, error) {
+	if _, err := strconv.ParseInt(postId, 10, 64); err != nil {
+		return nil, fmt.Errorf("post not found")
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	tag, err := tx.Exec(`INSERT INTO discussion_post_revisions (post_id, body, edited_by)
+		SELECT p.id, p.body, $4 FROM discussion_posts p JOIN discussion_threads t ON t.id = p.thread_id
+		WHERE p.id = $1 AND p.thread_id = $2 AND t.course_id = $3 AND p.author = $4
+			AND p.hidden_at IS NULL AND p.deleted_at IS NULL AND t.hidden_at IS NULL
+		FOR UPDATE OF p`, postId, threadId, courseId, editor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record post history: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("post not found")
+	}
+
+	query := `UPDATE discussion_posts p SET body = $2, edited_at = now() WHERE p.id = $1 RETURNING ` + discussionPostColumns(3)
+	post, err := scanDiscussionPost(tx.QueryRow(query, postId, body, editor))
+	if err != nil {
+		return nil, fmt.Errorf("failed to edit post: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// GetDiscussionPostRevisions retrieves the earlier bodies of a post, oldest first
+func (db *PostgresDatabase) GetDiscussionPostRevisions(postId string) ([]models.DiscussionPostRevision, error) {
+	rows, err := db.conn.Query("SELECT body, edited_by, edited_at FROM discussion_post_revisions WHERE post_id = $1 ORDER BY id", postId)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.DiscussionPostRevision{}
+	for rows.Next() {
+		var revision models.DiscussionPostRevision
+		if err := rows.Scan(&revision.Body, &revision.EditedBy, &revision.EditedAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
+// SetDiscussionReaction adds or removes a user's reaction to a post. Adding a reaction twice keeps one.
+func (db *PostgresDatabase) SetDiscussionReaction(postId, username, reaction string, reacted bool) error {
+	if !reacted {
+		_, err := db.conn.Exec("DELETE FROM discussion_reactions WHERE post_id = $1 AND username = $2 AND reaction = $3",
+			postId, username, reaction)
+		return err
+	}
+	_, err := db.conn.Exec(`INSERT INTO discussion_reactions (post_id, username, reaction) VALUES ($1, $2, $3)
+		ON CONFLICT (post_id, username, reaction) DO NOTHING`, postId, username, reaction)
+	return err
+}
+
+// SetDiscussionThreadPinned pins a thread of a course to the top of its listing, or unpins it
+func (db *PostgresDatabase) SetDiscussionThreadPinned(courseId, threadId string, pinned bool) error {
+	if _, err := strconv.ParseInt(threadId, 10, 64); err != nil {
+		return fmt.Errorf("thread not found")
+	}
+	tag, err := db.conn.Exec("UPDATE discussion_threads SET pinned = $3 WHERE id = $1 AND course_id = $2", threadId, courseId, pinned)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("thread not found")
+	}
+	return nil
+}
+
+// SetDiscussionThreadHidden hides a thread of a course from everyone but moderators, or shows it again
+func (db *PostgresDatabase) SetDiscussionThreadHidden(courseId, threadId, moderator string, hidden bool) error {
+	if _, err := strconv.ParseInt(threadId, 10, 64); err != nil {
+		return fmt.Errorf("thread not found")
+	}
+	query := `UPDATE discussion_threads SET hidden_at = CASE WHEN $3 THEN COALESCE(hidden_at, now()) END,
+			hidden_by = CASE WHEN $3 THEN COALESCE(hidden_by, $4) END
+		WHERE id = $1 AND course_id = $2`
+	tag, err := db.conn.Exec(query, threadId, courseId, hidden, moderator)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("thread not found")
+	}
+	return nil
+}
+
+// SetDiscussionPostHidden withholds the body of a post in one of a course's threads from everyone but moderators, or
+// shows it again. A hidden answer stops being the thread's answer.
+func (db *PostgresDatabase) SetDiscussionPostHidden(courseId, threadId, postId, moderator string, hidden bool) error {
+	if _, err := strconv.ParseInt(postId, 10, 64); err != nil {
+		return fmt.Errorf("post not found")
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE discussion_posts p SET hidden_at = CASE WHEN $4 THEN COALESCE(p.hidden_at, now()) END,
+			hidden_by = CASE WHEN $4 THEN COALESCE(p.hidden_by, $5) END
+		FROM discussion_threads t
+		WHERE t.id = p.thread_id AND p.id = $1 AND p.thread_id = $2 AND t.course_id = $3`
+	tag, err := tx.Exec(query, postId, threadId, courseId, hidden, moderator)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("post not found")
+	}
+
+	if hidden {
+		_, err = tx.Exec("UPDATE discussion_threads SET answer_post_id = NULL WHERE id = $1 AND answer_post_id = $2", threadId, postId)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetDiscussionAnswer marks a visible reply in one of a course's threads as the thread's answer, or clears the
+// answer when postId is empty
+func (db *PostgresDatabase) SetDiscussionAnswer(courseId, threadId, postId string) error {
+	if _, err := strconv.ParseInt(threadId, 10, 64); err != nil {
+		return fmt.Errorf("thread not found")
+	}
+	if postId != "" {
+		if _, err := strconv.ParseInt(postId, 10, 64); err != nil {
+			return fmt.Errorf("post '%s' is not a reply in this thread", postId)
+		}
+	}
+
+	var exists bool
+	err := db.conn.QueryRow("SELECT EXISTS (SELECT 1 FROM discussion_threads WHERE id = $1 AND course_id = $2)",
+		threadId, courseId).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("thread not found")
+	}
+
+	tag, err := db.conn.Exec(`UPDATE discussion_threads t SET answer_post_id = NULLIF($2, '')::bigint
+		WHERE t.id = $1 AND (NULLIF($2, '') IS NULL OR EXISTS (
+			SELECT 1 FROM discussion_posts p
+			WHERE p.id = NULLIF($2, '')::bigint AND p.thread_id = t.id AND p.parent_id IS NOT NULL
+				AND p.hidden_at IS NULL AND p.deleted_at IS NULL
+		))`, threadId, postId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("post '%s' is not a reply in this thread", postId)
+	}
+	return nil
+}
+
+// DeleteDiscussionPost removes the content of a post in one of a course's threads for good, keeping its place in
+// the thread. Deleting the opening post hides the whole thread.
+func (db *PostgresDatabase) DeleteDiscussionPost(courseId, threadId, postId, moderator string) error {
+	if _, err := strconv.ParseInt(postId, 10, 64); err != nil {
+		return fmt.Errorf("post not found")
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var opening bool
+	err = tx.QueryRow(`UPDATE discussion_posts p SET body = '', deleted_at = COALESCE(p.deleted_at, now()),
+			deleted_by = COALESCE(p.deleted_by, $4)
+		FROM discussion_threads t
+		WHERE t.id = p.thread_id AND p.id = $1 AND p.thread_id = $2 AND t.course_id = $3
+		RETURNING p.parent_id IS NULL`, postId, threadId, courseId, moderator).Scan(&opening)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("post not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete post: %w", err)
+	}
+
+	if _, err = tx.Exec("DELETE FROM discussion_post_revisions WHERE post_id = $1", postId); err != nil {
+		return fmt.Errorf("failed to remove post history: %w", err)
+	}
+	if _, err = tx.Exec("DELETE FROM discussion_reactions WHERE post_id = $1", postId); err != nil {
+		return fmt.Errorf("failed to remove post reactions: %w", err)
+	}
+
+	query := `UPDATE discussion_threads SET
+			answer_post_id = CASE WHEN answer_post_id = $2 THEN NULL ELSE answer_post_id END,
+			hidden_at = CASE WHEN $3 THEN COALESCE(hidden_at, now()) ELSE hidden_at END,
+			hidden_by = CASE WHEN $3 THEN COALESCE(hidden_by, $4) ELSE hidden_by END
+		WHERE id = $1`
+	if _, err = tx.Exec(query, threadId, postId, opening, moderator); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CountRecentDiscussionPosts counts the posts, opening posts included, a user has written since a given time
+func (db *PostgresDatabase) CountRecentDiscussionPosts(username string, since time.Time) (int, error) {
+	var count int
+	err := db.conn.QueryRow("SELECT count(*) FROM discussion_posts WHERE author = $1 AND created_at > $2", username, since).Scan(&count)
+	return count, err
+}
+
+// GetDiscussionThreadsByAuthor retrieves every thread a user started, hidden ones included, oldest first
+func (db *PostgresDatabase) GetDiscussionThreadsByAuthor(username string) ([]models.DiscussionThread, error) {
+	rows, err := db.conn.Query("SELECT "+discussionThreadColumns+" FROM discussion_threads t WHERE t.author = $1 ORDER BY t.id", username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	threads := []models.DiscussionThread{}
+	for rows.Next() {
+		thread, err := scanDiscussionThread(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		threads = append(threads, *thread)
+	}
+	return threads, rows.Err()
+}
+
+// GetDiscussionPostsByAuthor retrieves every post a user wrote, hidden and deleted ones included, oldest first
+func (db *PostgresDatabase) GetDiscussionPostsByAuthor(username string) ([]models.DiscussionPost, error) {
+	rows, err := db.conn.Query("SELECT "+discussionPostColumns(1)+" FROM discussion_posts p WHERE p.author = $1 ORDER BY p.id", username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []models.DiscussionPost{}
+	for rows.Next() {
+		post, err := scanDiscussionPost(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		posts = append(posts, *post)
+	}
+	return posts, rows.Err()
+}
+
+// GetDiscussionPostRevisionsByAuthor retrieves the earlier bodies of every post a user wrote, oldest first
+func (db *PostgresDatabase) GetDiscussionPostRevisionsByAuthor(username string) ([]models.DiscussionPostRevision, error) {
+	rows, err := db.conn.Query(`SELECT r.post_id, r.body, r.edited_by, r.edited_at FROM discussion_post_revisions r
+		JOIN discussion_posts p ON p.id = r.post_id WHERE p.author = $1 ORDER BY r.id`, username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.DiscussionPostRevision{}
+	for rows.Next() {
+		var revision models.DiscussionPostRevision
+		var postId int64
+		if err := rows.Scan(&postId, &revision.Body, &revision.EditedBy, &revision.EditedAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		revision.PostId = strconv.FormatInt(postId, 10)
+		revisions = append(revisions, revision)
+	}
+	return revisions, rows.Err()
+}
+
+// GetDiscussionReactionsByUser retrieves every reaction a user added to a post, oldest first
+func (db *PostgresDatabase) GetDiscussionReactionsByUser(username string) ([]models.DiscussionReaction, error) {
+	rows, err := db.conn.Query(`SELECT post_id, reaction, created_at FROM discussion_reactions
+		WHERE username = $1 ORDER BY created_at, post_id, reaction`, username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reactions := []models.DiscussionReaction{}
+	for rows.Next() {
+		var reaction models.DiscussionReaction
+		var postId int64
+		if err := rows.Scan(&postId, &reaction.Reaction, &reaction.CreatedAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		reaction.PostId = strconv.FormatInt(postId, 10)
+		reactions = append(reactions, reaction)
+	}
+	return reactions, rows.Err()
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+const outboxEmailColumns = "id, to_address, template, locale, subject, status, last_error, created_at, sent_at"
+
+func scanOutboxEmail(row rowScanner) (*models.OutboxEmail, error) {
+	var email models.OutboxEmail
+	var id int64
+	err := row.Scan(&id, &email.To, &email.Template, &email.Locale, &email.Subject, &email.Status, &email.LastError,
+		&email.CreatedAt, &email.SentAt)
+	if err != nil {
+		return nil, err
+	}
+	email.Id = strconv.FormatInt(id, 10)
+	return &email, nil
+}
+
+// QueueEmail adds an email to the outbox. Unless the address is suppressed, a job of the given kind is enqueued in
+// the same transaction to deliver it, with the email's id as emailId in its payload, and returned along with it.
+func (db *PostgresDatabase) QueueEmail(email models.OutboxEmail, text, html, queue, kind string, maxAttempts int) (*models.OutboxEmail, *models.Job, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO email_outbox (to_address, template, locale, subject, text_body, html_body, status)
+		SELECT $1, $2, $3, $4, $5, $6, CASE WHEN EXISTS (
+			SELECT 1 FROM email_suppressions WHERE email = lower($1)
+		) THEN 'suppressed' ELSE 'queued' END
+		RETURNING ` + outboxEmailColumns
+	queued, err := scanOutboxEmail(tx.QueryRow(query, email.To, email.Template, email.Locale, email.Subject, text, html))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error queueing email: %w", err)
+	}
+
+	var job *models.Job
+	if queued.Status == models.EmailQueued {
+		payload, err := json.Marshal(map[string]string{"emailId": queued.Id})
+		if err != nil {
+			return nil, nil, err
+		}
+		if job, err = enqueueJob(tx, queue, kind, payload, time.Now(), maxAttempts); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return queued, job, nil
+}
+
+// GetQueuedEmail retrieves an email waiting in the outbox with its bodies, and whether its address has been
+// suppressed since it was queued
+func (db *PostgresDatabase) GetQueuedEmail(emailId string) (*models.OutboxEmail, string, string, bool, error) {
+	query := `SELECT ` + outboxEmailColumns + `, text_body, html_body,
+			EXISTS (SELECT 1 FROM email_suppressions s WHERE s.email = lower(o.to_address))
+		FROM email_outbox o WHERE id = $1`
+	var email models.OutboxEmail
+	var id int64
+	var text, html string
+	var suppressed bool
+	err := db.conn.QueryRow(query, emailId).Scan(&id, &email.To, &email.Template, &email.Locale, &email.Subject, &email.Status,
+		&email.LastError, &email.CreatedAt, &email.SentAt, &text, &html, &suppressed)
+	if err == pgx.ErrNoRows {
+		return nil, "", "", false, fmt.Errorf("email not found")
+	}
+	if err != nil {
+		return nil, "", "", false, fmt.Errorf("error fetching email: %w", err)
+	}
+	email.Id = strconv.FormatInt(id, 10)
+	return &email, text, html, suppressed, nil
+}
+
+// MarkEmailSent records the delivery of an email and discards its bodies
+func (db *PostgresDatabase) MarkEmailSent(emailId string) error {
+	query := `UPDATE email_outbox SET status = 'sent', sent_at = now(), last_error = '', text_body = '', html_body = ''
+		WHERE id = $1`
+	_, err := db.conn.Exec(query, emailId)
+	return err
+}
+
+// RecordEmailError records why delivering an email failed, moving it to status, which is queued while it will be
+// retried. Bodies of emails that will not be retried are discarded.
+func (db *PostgresDatabase) RecordEmailError(emailId, status, reason string) error {
+	query := `UPDATE email_outbox SET status = $2, last_error = $3,
+			text_body = CASE WHEN $2 = 'queued' THEN text_body ELSE '' END,
+			html_body = CASE WHEN $2 = 'queued' THEN html_body ELSE '' END
+		WHERE id = $1`
+	_, err := db.conn.Exec(query, emailId, status, reason)
+	return err
+}
+
+// SearchOutboxEmails retrieves a page of outbox emails matching the filter, newest first, along with the total match count
+func (db *PostgresDatabase) SearchOutboxEmails(filter models.EmailSearch) ([]models.OutboxEmail, int, error) {
+	conditions := []string{"true"}
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.To != "" {
+		addCondition("lower(to_address) = lower($%d)", filter.To)
+	}
+	if filter.Template != "" {
+		addCondition("template = $%d", filter.Template)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM email_outbox WHERE "+where, args...).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM email_outbox WHERE %s ORDER BY created_at DESC, id DESC LIMIT %d OFFSET %d",
+		outboxEmailColumns, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	emails := []models.OutboxEmail{}
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		emails = append(emails, *email)
+	}
+	return emails, total, rows.Err()
+}
+
+// SuppressEmail stops email being sent to an address, keeping the first reason recorded for it
+func (db *PostgresDatabase) SuppressEmail(email, reason, detail string) error {
+	query := `INSERT INTO email_suppressions (email, reason, detail) VALUES (lower($1), $2, $3)
+		ON CONFLICT (email) DO NOTHING`
+	_, err := db.conn.Exec(query, email, reason, detail)
+	return err
+}
+
+// RemoveEmailSuppression allows email to an address again
+func (db *PostgresDatabase) RemoveEmailSuppression(email string) error {
+	tag, err := db.conn.Exec("DELETE FROM email_suppressions WHERE email = lower($1)", email)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("email address is not suppressed")
+	}
+	return nil
+}
+
+// GetEmailSuppressions retrieves a page of suppressed addresses, newest first, along with the total count
+func (db *PostgresDatabase) GetEmailSuppressions(page models.Pagination) ([]models.EmailSuppression, int, error) {
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM email_suppressions").Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT email, reason, detail, created_at FROM email_suppressions ORDER BY created_at DESC, email LIMIT %d OFFSET %d",
+		page.Limit(), page.Offset())
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	suppressions := []models.EmailSuppression{}
+	for rows.Next() {
+		var suppression models.EmailSuppression
+		if err := rows.Scan(&suppression.Email, &suppression.Reason, &suppression.Detail, &suppression.CreatedAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		suppressions = append(suppressions, suppression)
+	}
+	return suppressions, total, rows.Err()
+}
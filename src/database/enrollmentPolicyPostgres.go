@@ -0,0 +1,272 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+	apperrors "orkidslearning/src/utils/errors"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// SetEnrollmentPolicy changes how learners may enroll in a course and when enrollment is open
+func (db *PostgresDatabase) SetEnrollmentPolicy(courseId string, policy models.UpdateEnrollmentPolicy) error {
+	query := `UPDATE courses SET enrollment_policy = $2, enrollment_opens_at = $3, enrollment_closes_at = $4 WHERE id = $1`
+	tag, err := db.conn.Exec(query, courseId, policy.EnrollmentPolicy, policy.EnrollmentOpensAt, policy.EnrollmentClosesAt)
+	if err != nil {
+		return fmt.Errorf("failed to update enrollment policy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("course with ID '%s' does not exist", courseId)
+	}
+	return nil
+}
+
+const enrollmentRequestColumns = `r.id, r.course_id, COALESCE(r.cohort_id::text, ''), r.username, r.status, r.reason,
+	r.requested_at, r.decided_at, r.decided_by`
+
+func scanEnrollmentRequest(row rowScanner) (*models.EnrollmentRequest, error) {
+	var request models.EnrollmentRequest
+	var id int64
+	var courseId pgtype.UUID
+	err := row.Scan(&id, &courseId, &request.CohortId, &request.Username, &request.Status, &request.Reason,
+		&request.RequestedAt, &request.DecidedAt, &request.DecidedBy)
+	if err != nil {
+		return nil, err
+	}
+	request.Id = strconv.FormatInt(id, 10)
+	request.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+	return &request, nil
+}
+
+// GetOpenEnrollmentRequest retrieves the user's pending or rejected request to join a course, or nil if there is none
+func (db *PostgresDatabase) GetOpenEnrollmentRequest(username, courseId string) (*models.EnrollmentRequest, error) {
+	query := "SELECT " + enrollmentRequestColumns + ` FROM enrollment_requests r
+		WHERE r.username = $1 AND r.course_id = $2 AND r.status IN ($3, $4)`
+	request, err := scanEnrollmentRequest(db.conn.QueryRow(query, username, courseId,
+		models.EnrollmentRequestPending, models.EnrollmentRequestRejected))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching enrollment request: %w", err)
+	}
+	return request, nil
+}
+
+// AddEnrollmentRequest asks for a user to be enrolled in a course once an instructor approves. If the user already
+// has a pending or rejected request for the course, as when they ask twice at once, that request is returned instead.
+// It reports whether the request was added.
+func (db *PostgresDatabase) AddEnrollmentRequest(tenant, username, courseId, cohortId string) (*models.EnrollmentRequest, bool, error) {
+	query := `INSERT INTO enrollment_requests AS r (course_id, cohort_id, username, organization_id)
+		VALUES ($1, NULLIF($2, '')::bigint, $3, NULLIF($4, '')::bigint)
+		ON CONFLICT (course_id, username) WHERE status IN ('pending', 'rejected') DO NOTHING
+		RETURNING ` + enrollmentRequestColumns
+	request, err := scanEnrollmentRequest(db.conn.QueryRow(query, courseId, cohortId, username, tenant))
+	if err == nil {
+		return request, true, nil
+	}
+	if err != pgx.ErrNoRows {
+		log.Println("Insert error:", err)
+		return nil, false, fmt.Errorf("failed to request enrollment: %w", err)
+	}
+
+	request, err = db.GetOpenEnrollmentRequest(username, courseId)
+	if err != nil {
+		return nil, false, err
+	}
+	if request == nil {
+		return nil, false, fmt.Errorf("failed to request enrollment: the earlier request was decided meanwhile")
+	}
+	return request, false, nil
+}
+
+// GetEnrollmentRequests retrieves a page of a course's enrollment requests, oldest first, along with the match count
+func (db *PostgresDatabase) GetEnrollmentRequests(courseId string, filter models.EnrollmentRequestSearch) ([]models.EnrollmentRequest, int, error) {
+	conditions := []string{"r.course_id = $1"}
+	args := []interface{}{courseId}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("r.status = $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM enrollment_requests r WHERE "+where, args...).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM enrollment_requests r WHERE %s ORDER BY r.requested_at, r.id LIMIT %d OFFSET %d",
+		enrollmentRequestColumns, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	requests := []models.EnrollmentRequest{}
+	for rows.Next() {
+		request, err := scanEnrollmentRequest(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		requests = append(requests, *request)
+	}
+	return requests, total, rows.Err()
+}
+
+// GetEnrollmentRequestsForUser retrieves every enrollment request a user has made, oldest first
+func (db *PostgresDatabase) GetEnrollmentRequestsForUser(username string) ([]models.EnrollmentRequest, error) {
+	query := "SELECT " + enrollmentRequestColumns + " FROM enrollment_requests r WHERE r.username = $1 ORDER BY r.requested_at, r.id"
+	rows, err := db.conn.Query(query, username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []models.EnrollmentRequest{}
+	for rows.Next() {
+		request, err := scanEnrollmentRequest(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	return requests, rows.Err()
+}
+
+// ApproveEnrollmentRequest approves a pending or rejected request and enrolls the learner, or puts them on the
+// waitlist when the course is full. It returns the approved request and the learner's waitlist position.
+func (db *PostgresDatabase) ApproveEnrollmentRequest(tenant, courseId, requestId, decidedBy string) (*models.EnrollmentRequest, int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	courseCapacity, err := lockCourse(tx, tenant, courseId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `UPDATE enrollment_requests r SET status = $3, decided_at = now(), decided_by = $4, reason = ''
+		WHERE r.id = $1 AND r.course_id = $2 AND r.status IN ($5, $6)
+		RETURNING ` + enrollmentRequestColumns + `, COALESCE(r.organization_id::text, '')`
+	var organizationId string
+	var request models.EnrollmentRequest
+	var id int64
+	var course pgtype.UUID
+	err = tx.QueryRow(query, requestId, courseId, models.EnrollmentRequestApproved, decidedBy,
+		models.EnrollmentRequestPending, models.EnrollmentRequestRejected).Scan(&id, &course, &request.CohortId,
+		&request.Username, &request.Status, &request.Reason, &request.RequestedAt, &request.DecidedAt, &request.DecidedBy,
+		&organizationId)
+	if err == pgx.ErrNoRows {
+		return nil, 0, fmt.Errorf("no open enrollment request '%s' for course '%s'", requestId, courseId)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to approve enrollment request: %w", err)
+	}
+	request.Id = strconv.FormatInt(id, 10)
+	request.CourseId = fmt.Sprintf("%x", course.Bytes)
+
+	position, err := enrollOrWaitlist(tx, organizationId, request.Username, courseId, request.CohortId, courseCapacity)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, err
+	}
+	return &request, position, nil
+}
+
+// RejectEnrollmentRequest turns down a pending request, recording why
+func (db *PostgresDatabase) RejectEnrollmentRequest(courseId, requestId, decidedBy, reason string) (*models.EnrollmentRequest, error) {
+	query := `UPDATE enrollment_requests r SET status = $3, decided_at = now(), decided_by = $4, reason = $5
+		WHERE r.id = $1 AND r.course_id = $2 AND r.status = $6
+		RETURNING ` + enrollmentRequestColumns
+	request, err := scanEnrollmentRequest(db.conn.QueryRow(query, requestId, courseId, models.EnrollmentRequestRejected,
+		decidedBy, reason, models.EnrollmentRequestPending))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("no pending enrollment request '%s' for course '%s'", requestId, courseId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject enrollment request: %w", err)
+	}
+	return request, nil
+}
+
+const inviteCodeColumns = "code, course_id, max_uses, uses, expires_at, created_by, created_at"
+
+func scanInviteCode(row rowScanner) (*models.InviteCode, error) {
+	var invite models.InviteCode
+	var courseId pgtype.UUID
+	var maxUses *int32
+	var uses int32
+	err := row.Scan(&invite.Code, &courseId, &maxUses, &uses, &invite.ExpiresAt, &invite.CreatedBy, &invite.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	invite.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+	invite.MaxUses = intFromNullable(maxUses)
+	invite.Uses = int(uses)
+	return &invite, nil
+}
+
+// AddInviteCode stores a newly generated invite code for a course
+func (db *PostgresDatabase) AddInviteCode(courseId, code, createdBy string, maxUses *int, expiresAt *time.Time) (*models.InviteCode, error) {
+	query := `INSERT INTO course_invite_codes (code, course_id, max_uses, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5) RETURNING ` + inviteCodeColumns
+	invite, err := scanInviteCode(db.conn.QueryRow(query, code, courseId, nullableFromInt(maxUses), expiresAt, createdBy))
+	if err != nil {
+		log.Println("Insert error:", err)
+		return nil, fmt.Errorf("failed to add invite code: %w", err)
+	}
+	return invite, nil
+}
+
+// GetInviteCodes retrieves every invite code of a course, newest first
+func (db *PostgresDatabase) GetInviteCodes(courseId string) ([]models.InviteCode, error) {
+	rows, err := db.conn.Query("SELECT "+inviteCodeColumns+" FROM course_invite_codes WHERE course_id = $1 ORDER BY created_at DESC", courseId)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []models.InviteCode{}
+	for rows.Next() {
+		invite, err := scanInviteCode(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		invites = append(invites, *invite)
+	}
+	return invites, rows.Err()
+}
+
+// redeemInviteCode uses up one redemption of a course's invite code, failing if it has expired or been used up
+func redeemInviteCode(tx *pgx.Tx, courseId, code string) error {
+	tag, err := tx.Exec(`UPDATE course_invite_codes SET uses = uses + 1
+		WHERE code = $1 AND course_id = $2
+			AND (expires_at IS NULL OR expires_at > now())
+			AND (max_uses IS NULL OR uses < max_uses)`, code, courseId)
+	if err != nil {
+		return fmt.Errorf("failed to redeem invite code: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return apperrors.ErrInviteCodeInvalid
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+const jobColumns = `id, queue, kind, payload::text, status, attempts, max_attempts, run_at, last_error, locked_by,
+	created_at, started_at, completed_at`
+
+func scanJob(row rowScanner) (*models.Job, error) {
+	var job models.Job
+	var id int64
+	var payload string
+	var attempts, maxAttempts int32
+	err := row.Scan(&id, &job.Queue, &job.Kind, &payload, &job.Status, &attempts, &maxAttempts, &job.RunAt, &job.LastError,
+		&job.LockedBy, &job.CreatedAt, &job.StartedAt, &job.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Id = strconv.FormatInt(id, 10)
+	job.Payload = json.RawMessage(payload)
+	job.Attempts = int(attempts)
+	job.MaxAttempts = int(maxAttempts)
+	return &job, nil
+}
+
+// EnqueueJob adds a job that becomes ready to run at runAt
+func (db *PostgresDatabase) EnqueueJob(queue, kind string, payload json.RawMessage, runAt time.Time, maxAttempts int) (*models.Job, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	job, err := enqueueJob(tx, queue, kind, payload, runAt, maxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	return job, tx.Commit()
+}
+
+// enqueueJob adds a job within tx, so that it only exists if the work it follows up on is committed too
+func enqueueJob(tx *pgx.Tx, queue, kind string, payload json.RawMessage, runAt time.Time, maxAttempts int) (*models.Job, error) {
+	query := `INSERT INTO jobs (queue, kind, payload, run_at, max_attempts) VALUES ($1, $2, $3::jsonb, $4, $5)
+		RETURNING ` + jobColumns
+	job, err := scanJob(tx.QueryRow(query, queue, kind, string(payload), runAt, maxAttempts))
+	if err != nil {
+		return nil, fmt.Errorf("error enqueueing job: %w", err)
+	}
+	return job, nil
+}
+
+// ClaimJob locks the next ready job of a queue for a worker and counts the attempt, returning nil if none is ready.
+// SKIP LOCKED lets concurrent workers, in this process or another replica, each claim a different job.
+func (db *PostgresDatabase) ClaimJob(queue, worker string) (*models.Job, error) {
+	query := `UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_by = $2, locked_at = now(), started_at = now()
+		WHERE id = (
+			SELECT id FROM jobs WHERE queue = $1 AND status = 'pending' AND run_at <= now()
+			ORDER BY run_at, id LIMIT 1 FOR UPDATE SKIP LOCKED
+		) RETURNING ` + jobColumns
+	job, err := scanJob(db.conn.QueryRow(query, queue, worker))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error claiming job: %w", err)
+	}
+	return job, nil
+}
+
+// CompleteJob marks a job as done, provided it is still running under the given claim of worker. A job released as
+// stale and claimed again belongs to its new claim, so the original worker finishing late must not record its outcome.
+func (db *PostgresDatabase) CompleteJob(jobId, worker string, attempt int) error {
+	query := `UPDATE jobs SET status = 'completed', completed_at = now(), locked_by = '', locked_at = NULL, last_error = ''
+		WHERE id = $1 AND status = 'running' AND locked_by = $2 AND attempts = $3`
+	tag, err := db.conn.Exec(query, jobId, worker, attempt)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("job '%s' is no longer held by worker '%s'", jobId, worker)
+	}
+	return nil
+}
+
+// FailJob records why a job failed, provided it is still running under the given claim of worker. The job runs again
+// at retryAt unless it has used all its attempts, in which case it is dead. It returns the job's new status.
+func (db *PostgresDatabase) FailJob(jobId, worker string, attempt int, reason string, retryAt time.Time) (string, error) {
+	query := `UPDATE jobs SET
+			status = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+			completed_at = CASE WHEN attempts >= max_attempts THEN now() END,
+			run_at = $5, last_error = $4, locked_by = '', locked_at = NULL
+		WHERE id = $1 AND status = 'running' AND locked_by = $2 AND attempts = $3 RETURNING status`
+	var status string
+	err := db.conn.QueryRow(query, jobId, worker, attempt, reason, retryAt).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return "", fmt.Errorf("job '%s' is no longer held by worker '%s'", jobId, worker)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error failing job: %w", err)
+	}
+	return status, nil
+}
+
+// ReleaseStaleJobs fails the jobs of a queue that have been running since before cutoff, whose worker must have
+// stopped without finishing them, so that they are retried. It returns how many jobs were released.
+func (db *PostgresDatabase) ReleaseStaleJobs(queue string, cutoff time.Time) (int, error) {
+	query := `UPDATE jobs SET
+			status = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+			completed_at = CASE WHEN attempts >= max_attempts THEN now() END,
+			run_at = now(), last_error = 'worker ' || locked_by || ' stopped before finishing', locked_by = '', locked_at = NULL
+		WHERE queue = $1 AND status = 'running' AND locked_at < $2`
+	tag, err := db.conn.Exec(query, queue, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// SearchJobs retrieves a page of jobs matching the filter, newest first, along with the total match count
+func (db *PostgresDatabase) SearchJobs(filter models.JobSearch) ([]models.Job, int, error) {
+	conditions := []string{"true"}
+	var args []interface{}
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Queue != "" {
+		addCondition("queue = $%d", filter.Queue)
+	}
+	if filter.Kind != "" {
+		addCondition("kind = $%d", filter.Kind)
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM jobs WHERE "+where, args...).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM jobs WHERE %s ORDER BY created_at DESC, id DESC LIMIT %d OFFSET %d",
+		jobColumns, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	jobs := []models.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, total, rows.Err()
+}
+
+// GetJob retrieves a job by id
+func (db *PostgresDatabase) GetJob(jobId string) (*models.Job, error) {
+	job, err := scanJob(db.conn.QueryRow("SELECT "+jobColumns+" FROM jobs WHERE id = $1", jobId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("job not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching job: %w", err)
+	}
+	return job, nil
+}
+
+// RetryJob makes a dead or waiting job ready to run now, granting a dead job one more attempt
+func (db *PostgresDatabase) RetryJob(jobId string) (*models.Job, error) {
+	query := `UPDATE jobs SET status = 'pending', run_at = now(), completed_at = NULL,
+			max_attempts = GREATEST(max_attempts, attempts + 1)
+		WHERE id = $1 AND status IN ('pending', 'dead') RETURNING ` + jobColumns
+	job, err := scanJob(db.conn.QueryRow(query, jobId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("job not found or not waiting to be retried")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrying job: %w", err)
+	}
+	return job, nil
+}
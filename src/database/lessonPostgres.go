@@ -0,0 +1,177 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// AddLesson adds a lesson to a course, appending it after the last lesson when no position is given
+func (db *PostgresDatabase) AddLesson(courseId string, lesson models.AddLesson) (*models.Lesson, error) {
+	query := `INSERT INTO course_lessons (course_id, position, title, content, release_after_days)
+		SELECT $1, COALESCE(NULLIF($2, 0), (SELECT COALESCE(max(position), 0) + 1 FROM course_lessons WHERE course_id = $1 AND removed_at IS NULL)), $3, $4, $5
+		RETURNING id, position`
+	var id int64
+	var position int32
+	err := db.conn.QueryRow(query, courseId, int32(lesson.Position), lesson.Title, lesson.Content, int32(lesson.ReleaseAfterDays)).Scan(&id, &position)
+	if err != nil {
+		log.Println("Insert error:", err)
+		return nil, fmt.Errorf("failed to add lesson: %w", err)
+	}
+	return &models.Lesson{
+		Id:               strconv.FormatInt(id, 10),
+		CourseId:         courseId,
+		Position:         int(position),
+		Title:            lesson.Title,
+		Content:          lesson.Content,
+		ReleaseAfterDays: lesson.ReleaseAfterDays,
+	}, nil
+}
+
+// enrollmentLessons selects the lessons of the version the enrollment aliased e is pinned to, or those of the working
+// copy when it is not pinned to one
+const enrollmentLessons = `(
+		SELECT l.id, l.position, l.title, l.content, l.release_after_days FROM course_lessons l
+		WHERE l.course_id = e.id AND e.version IS NULL AND l.removed_at IS NULL
+		UNION ALL
+		SELECT (s->>'id')::bigint, (s->>'position')::int, s->>'title', s->>'content', (s->>'releaseAfterDays')::int
+		FROM course_versions v, jsonb_array_elements(v.lessons) s
+		WHERE v.course_id = e.id AND v.number = e.version
+	)`
+
+const lessonColumns = "l.id, l.position, l.title, l.content, l.release_after_days"
+
+func scanLesson(row rowScanner, courseId string) (*models.Lesson, error) {
+	var lesson models.Lesson
+	var id int64
+	var position, releaseAfterDays int32
+	if err := row.Scan(&id, &position, &lesson.Title, &lesson.Content, &releaseAfterDays); err != nil {
+		return nil, err
+	}
+	lesson.Id = strconv.FormatInt(id, 10)
+	lesson.CourseId = courseId
+	lesson.Position = int(position)
+	lesson.ReleaseAfterDays = int(releaseAfterDays)
+	return &lesson, nil
+}
+
+// queryLessons runs a query returning lessons of a course
+func (db *PostgresDatabase) queryLessons(courseId, query string, args ...interface{}) ([]models.Lesson, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	lessons := []models.Lesson{}
+	for rows.Next() {
+		lesson, err := scanLesson(rows, courseId)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		lessons = append(lessons, *lesson)
+	}
+	return lessons, rows.Err()
+}
+
+// GetLessonsForCourse retrieves the lessons of a course's working copy in order
+func (db *PostgresDatabase) GetLessonsForCourse(courseId string) ([]models.Lesson, error) {
+	return db.queryLessons(courseId, "SELECT "+lessonColumns+" FROM course_lessons l WHERE l.course_id = $1 AND l.removed_at IS NULL ORDER BY l.position", courseId)
+}
+
+// GetLessonsForEnrollment retrieves in order the lessons of the course version the user's enrollment is pinned to
+func (db *PostgresDatabase) GetLessonsForEnrollment(username, courseId string) ([]models.Lesson, error) {
+	return db.queryLessons(courseId, "SELECT "+lessonColumns+" FROM course_enrollments e, LATERAL "+enrollmentLessons+` l
+		WHERE e.username = $1 AND e.id = $2 ORDER BY l.position`, username, courseId)
+}
+
+// UpdateLesson edits a lesson of a course's working copy
+func (db *PostgresDatabase) UpdateLesson(courseId, lessonId string, update models.UpdateLesson) (*models.Lesson, error) {
+	if _, err := strconv.ParseInt(lessonId, 10, 64); err != nil {
+		return nil, fmt.Errorf("lesson not found")
+	}
+	query := `UPDATE course_lessons l SET position = $3, title = $4, content = $5, release_after_days = $6
+		WHERE l.id = $2 AND l.course_id = $1 AND l.removed_at IS NULL RETURNING ` + lessonColumns
+	lesson, err := scanLesson(db.conn.QueryRow(query, courseId, lessonId, int32(update.Position), update.Title, update.Content,
+		int32(update.ReleaseAfterDays)), courseId)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("lesson not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update lesson: %w", err)
+	}
+	return lesson, nil
+}
+
+// RemoveLesson removes a lesson from a course's working copy and returns it. The lesson is kept for the versions
+// that include it.
+func (db *PostgresDatabase) RemoveLesson(courseId, lessonId string) (*models.Lesson, error) {
+	if _, err := strconv.ParseInt(lessonId, 10, 64); err != nil {
+		return nil, fmt.Errorf("lesson not found")
+	}
+	query := `UPDATE course_lessons l SET removed_at = now()
+		WHERE l.id = $2 AND l.course_id = $1 AND l.removed_at IS NULL RETURNING ` + lessonColumns
+	lesson, err := scanLesson(db.conn.QueryRow(query, courseId, lessonId), courseId)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("lesson not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove lesson: %w", err)
+	}
+	return lesson, nil
+}
+
+// GetReleaseStart retrieves the date a learner's lessons are released from: the start of their cohort,
+// or their enrollment date in self-paced courses. It returns nil if the user is not enrolled, or if the course is
+// not visible to the tenant or no longer open to the user, such as after they left its organization.
+func (db *PostgresDatabase) GetReleaseStart(tenant, username, courseId string) (*time.Time, error) {
+	query := `SELECT COALESCE(h.starts_at, e.enrolled_at) FROM course_enrollments e
+		JOIN courses c ON c.id = e.id
+		LEFT JOIN cohorts h ON h.id = e.cohort_id
+		WHERE e.username = $2 AND e.id = $3 AND ` + courseVisibleToTenant(1) + ` AND ` + courseAccessibleToUser(2) + `
+		ORDER BY e.enrolled_at LIMIT 1`
+	var start time.Time
+	err := db.conn.QueryRow(query, tenant, username, courseId).Scan(&start)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching release start: %w", err)
+	}
+	return &start, nil
+}
+
+// GetLessonProgressForUser retrieves every lesson the user completed, oldest first
+func (db *PostgresDatabase) GetLessonProgressForUser(username string) ([]models.LessonProgress, error) {
+	rows, err := db.conn.Query(`SELECT l.course_id, l.id, l.title, p.completed_at
+		FROM lesson_progress p JOIN course_lessons l ON l.id = p.lesson_id
+		WHERE p.username = $1 ORDER BY p.completed_at, l.id`, username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := []models.LessonProgress{}
+	for rows.Next() {
+		var entry models.LessonProgress
+		var courseId pgtype.UUID
+		var lessonId int64
+		if err := rows.Scan(&courseId, &lessonId, &entry.LessonTitle, &entry.CompletedAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		entry.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+		entry.LessonId = strconv.FormatInt(lessonId, 10)
+		progress = append(progress, entry)
+	}
+	return progress, rows.Err()
+}
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+const contentReportColumns = `r.id, r.content_type, r.content_id, r.course_id, COALESCE(r.thread_id::text, ''), r.author,
+	r.excerpt, r.reporter, r.reason, r.details, r.status, r.resolution, r.note, r.resolved_by, r.resolved_at, r.created_at,
+	(SELECT count(*) FROM content_reports o
+		WHERE o.content_type = r.content_type AND o.content_id = r.content_id AND o.status = 'open')`
+
+const wordFilterColumns = "id, pattern, action, created_by, created_at"
+
+func scanContentReport(row rowScanner) (*models.ContentReport, error) {
+	var report models.ContentReport
+	var id, openReports int64
+	var courseId pgtype.UUID
+	err := row.Scan(&id, &report.ContentType, &report.ContentId, &courseId, &report.ThreadId, &report.Author,
+		&report.Excerpt, &report.Reporter, &report.Reason, &report.Details, &report.Status, &report.Resolution, &report.Note,
+		&report.ResolvedBy, &report.ResolvedAt, &report.CreatedAt, &openReports)
+	if err != nil {
+		return nil, err
+	}
+	report.Id = strconv.FormatInt(id, 10)
+	if courseId.Status == pgtype.Present {
+		report.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+	}
+	report.OpenReports = int(openReports)
+	return &report, nil
+}
+
+func scanWordFilter(row rowScanner) (*models.WordFilter, error) {
+	var filter models.WordFilter
+	var id int64
+	if err := row.Scan(&id, &filter.Pattern, &filter.Action, &filter.CreatedBy, &filter.CreatedAt); err != nil {
+		return nil, err
+	}
+	filter.Id = strconv.FormatInt(id, 10)
+	return &filter, nil
+}
+
+// AddContentReport files a report about a piece of content. Reporting the same content again while the earlier
+// report is open updates that report instead.
+func (db *PostgresDatabase) AddContentReport(report models.ContentReport) (*models.ContentReport, error) {
+	query := `INSERT INTO content_reports AS r (content_type, content_id, course_id, thread_id, author, excerpt, reporter, reason, details)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, NULLIF($4, '')::bigint, $5, $6, $7, $8, $9)
+		ON CONFLICT (content_type, content_id, reporter) WHERE status = 'open'
+		DO UPDATE SET reason = EXCLUDED.reason, details = EXCLUDED.details
+		RETURNING ` + contentReportColumns
+	added, err := scanContentReport(db.conn.QueryRow(query, report.ContentType, report.ContentId, report.CourseId, report.ThreadId,
+		report.Author, report.Excerpt, report.Reporter, report.Reason, report.Details))
+	if err != nil {
+		log.Println("Insert error:", err)
+		return nil, fmt.Errorf("failed to report content: %w", err)
+	}
+	return added, nil
+}
+
+// GetContentReport retrieves a content report
+func (db *PostgresDatabase) GetContentReport(reportId string) (*models.ContentReport, error) {
+	if _, err := strconv.ParseInt(reportId, 10, 64); err != nil {
+		return nil, fmt.Errorf("report not found")
+	}
+	report, err := scanContentReport(db.conn.QueryRow("SELECT "+contentReportColumns+" FROM content_reports r WHERE r.id = $1", reportId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("report not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching report: %w", err)
+	}
+	return report, nil
+}
+
+// SearchContentReports retrieves a page of the moderation queue, open reports first and oldest first within each
+// status, along with the total match count
+func (db *PostgresDatabase) SearchContentReports(filter models.ContentReportSearch) ([]models.ContentReport, int, error) {
+	conditions := []string{"true"}
+	args := []interface{}{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("r.status = $%d", len(args)))
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM content_reports r WHERE "+where, args...).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM content_reports r WHERE %s
+		ORDER BY r.status = 'open' DESC, r.created_at, r.id LIMIT %d OFFSET %d`,
+		contentReportColumns, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reports := []models.ContentReport{}
+	for rows.Next() {
+		report, err := scanContentReport(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, total, rows.Err()
+}
+
+// ResolveContentReports closes a report, along with every other open report about the same content, recording
+// what the moderator did. It returns the closed report.
+func (db *PostgresDatabase) ResolveContentReports(reportId, status, resolution, note, moderator string) (*models.ContentReport, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE content_reports r SET status = $2, resolution = $3, note = $4, resolved_by = $5, resolved_at = now()
+		FROM content_reports target
+		WHERE target.id = $1 AND r.content_type = target.content_type AND r.content_id = target.content_id
+			AND (r.id = target.id OR r.status = 'open')`, reportId, status, resolution, note, moderator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve reports: %w", err)
+	}
+
+	report, err := scanContentReport(tx.QueryRow("SELECT "+contentReportColumns+" FROM content_reports r WHERE r.id = $1", reportId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("report not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// GetWordFilters retrieves every word filter, in pattern order
+func (db *PostgresDatabase) GetWordFilters() ([]models.WordFilter, error) {
+	rows, err := db.conn.Query("SELECT " + wordFilterColumns + " FROM word_filters ORDER BY pattern")
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	filters := []models.WordFilter{}
+	for rows.Next() {
+		filter, err := scanWordFilter(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		filters = append(filters, *filter)
+	}
+	return filters, rows.Err()
+}
+
+// AddWordFilter adds a word filter, or changes the action of the filter with the same pattern
+func (db *PostgresDatabase) AddWordFilter(pattern, action, createdBy string) (*models.WordFilter, error) {
+	query := `INSERT INTO word_filters (pattern, action, created_by) VALUES ($1, $2, $3)
+		ON CONFLICT (pattern) DO UPDATE SET action = EXCLUDED.action
+		RETURNING ` + wordFilterColumns
+	filter, err := scanWordFilter(db.conn.QueryRow(query, pattern, action, createdBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add word filter: %w", err)
+	}
+	return filter, nil
+}
+
+// DeleteWordFilter removes a word filter and returns it
+func (db *PostgresDatabase) DeleteWordFilter(filterId string) (*models.WordFilter, error) {
+	if _, err := strconv.ParseInt(filterId, 10, 64); err != nil {
+		return nil, fmt.Errorf("word filter not found")
+	}
+	filter, err := scanWordFilter(db.conn.QueryRow("DELETE FROM word_filters WHERE id = $1 RETURNING "+wordFilterColumns, filterId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("word filter not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete word filter: %w", err)
+	}
+	return filter, nil
+}
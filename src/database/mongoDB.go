@@ -169,8 +169,8 @@ func (db *Database) AddUser(ctx context.Context, user models.AddUser) (*models.U
 	}, nil
 }
 
-// AddUserToCourse enrolls a user in a course
-func (db *Database) AddUserToCourse(ctx context.Context, username, courseId string) error {
+// AddUserToCourse enrolls a user in a course and reports whether they were not enrolled before
+func (db *Database) AddUserToCourse(ctx context.Context, username, courseId string) (bool, error) {
 	tracer := otel.Tracer("database")
 	ctx, span := tracer.Start(ctx, "AddUserToCourse")
 	defer span.End()
@@ -180,16 +180,20 @@ func (db *Database) AddUserToCourse(ctx context.Context, username, courseId stri
 	objectId, err := primitive.ObjectIDFromHex(courseId)
 	if err != nil {
 		log.Printf("Invalid ObjectId: %v", err)
-		return err
+		return false, err
 	}
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objectId}, bson.M{"$push": bson.M{"enrolledUsers": username}})
+	// $addToSet leaves the document untouched when the user is already enrolled, so concurrent calls cannot duplicate them
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectId}, bson.M{"$addToSet": bson.M{"enrolledUsers": username}})
 	if err != nil {
 		log.Println("UpdateOne error:", err)
-		return err
+		return false, err
+	}
+	if result.MatchedCount == 0 {
+		return false, fmt.Errorf("course does not exist")
 	}
 
-	return nil
+	return result.ModifiedCount > 0, nil
 }
 
 func (db *Database) CheckIfUserExistsByUsername(ctx context.Context, username string) error {
@@ -247,7 +251,8 @@ func (db *Database) CheckIfUserIsEnrolledInCourse(ctx context.Context, username,
 	return slices.Contains(course.EnrolledUsers, username), nil
 }
 
-func (db *Database) RemoveUserFromCourse(ctx context.Context, username, courseId string) error {
+// RemoveUserFromCourse removes a user from a course and reports whether they were enrolled
+func (db *Database) RemoveUserFromCourse(ctx context.Context, username, courseId string) (bool, error) {
 	tracer := otel.Tracer("database")
 	ctx, span := tracer.Start(ctx, "RemoveUserFromCourse")
 	defer span.End()
@@ -257,11 +262,33 @@ func (db *Database) RemoveUserFromCourse(ctx context.Context, username, courseId
 	objectId, err := primitive.ObjectIDFromHex(courseId)
 	if err != nil {
 		log.Printf("Invalid ObjectId: %v", err)
+		return false, err
+	}
+
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objectId}, bson.M{"$pull": bson.M{"enrolledUsers": username}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// PurgeUser removes a user document and every course membership held by the user
+func (db *Database) PurgeUser(ctx context.Context, username, email string) error {
+	tracer := otel.Tracer("database")
+	ctx, span := tracer.Start(ctx, "PurgeUser")
+	defer span.End()
+
+	userCollection := db.client.Database(db.dbName).Collection(db.userColl)
+	_, err := userCollection.DeleteMany(ctx, bson.M{"$or": bson.A{bson.M{"username": username}, bson.M{"email": email}}})
+	if err != nil {
+		log.Println("DeleteMany error:", err)
 		return err
 	}
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objectId}, bson.M{"$pull": bson.M{"enrolledUsers": username}})
+	courseCollection := db.client.Database(db.dbName).Collection(db.courseColl)
+	_, err = courseCollection.UpdateMany(ctx, bson.M{"enrolledUsers": username}, bson.M{"$pull": bson.M{"enrolledUsers": username}})
 	if err != nil {
+		log.Println("UpdateMany error:", err)
 		return err
 	}
 	return nil
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	models "orkidslearning/src/models/database"
+)
+
+const notificationColumns = "id, type, message, read_at, created_at"
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	var notification models.Notification
+	var id int64
+	if err := row.Scan(&id, &notification.Type, &notification.Message, &notification.ReadAt, &notification.CreatedAt); err != nil {
+		return nil, err
+	}
+	notification.Id = strconv.FormatInt(id, 10)
+	return &notification, nil
+}
+
+// AddNotification appends a notification to a user's feed
+func (db *PostgresDatabase) AddNotification(username, notificationType, message string) (*models.Notification, error) {
+	query := `INSERT INTO notifications (username, type, message) VALUES ($1, $2, $3) RETURNING ` + notificationColumns
+	notification, err := scanNotification(db.conn.QueryRow(query, username, notificationType, message))
+	if err != nil {
+		return nil, fmt.Errorf("error adding notification: %w", err)
+	}
+	return notification, nil
+}
+
+// GetNotifications retrieves a page of a user's feed, newest first, along with the number of notifications matching
+// the filter and the number still unread
+func (db *PostgresDatabase) GetNotifications(username string, filter models.NotificationSearch) ([]models.Notification, int, int, error) {
+	where := "username = $1"
+	if filter.Unread {
+		where += " AND read_at IS NULL"
+	}
+
+	var total, unread int
+	query := "SELECT count(*), count(*) FILTER (WHERE read_at IS NULL) FROM notifications WHERE username = $1"
+	if err := db.conn.QueryRow(query, username).Scan(&total, &unread); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, 0, err
+	}
+	if filter.Unread {
+		total = unread
+	}
+
+	query = fmt.Sprintf("SELECT %s FROM notifications WHERE %s ORDER BY created_at DESC, id DESC LIMIT %d OFFSET %d",
+		notificationColumns, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, 0, err
+		}
+		notifications = append(notifications, *notification)
+	}
+	return notifications, total, unread, rows.Err()
+}
+
+// GetAllNotifications retrieves a user's whole feed, oldest first
+func (db *PostgresDatabase) GetAllNotifications(username string) ([]models.Notification, error) {
+	rows, err := db.conn.Query("SELECT "+notificationColumns+" FROM notifications WHERE username = $1 ORDER BY created_at, id", username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		notifications = append(notifications, *notification)
+	}
+	return notifications, rows.Err()
+}
+
+// CountUnreadNotifications returns how many notifications in a user's feed are unread
+func (db *PostgresDatabase) CountUnreadNotifications(username string) (int, error) {
+	var unread int
+	err := db.conn.QueryRow("SELECT count(*) FROM notifications WHERE username = $1 AND read_at IS NULL", username).Scan(&unread)
+	return unread, err
+}
+
+// MarkNotificationRead marks one of a user's notifications read. Marking a read notification again keeps its first read time.
+func (db *PostgresDatabase) MarkNotificationRead(username, notificationId string) error {
+	if _, err := strconv.ParseInt(notificationId, 10, 64); err != nil {
+		return fmt.Errorf("notification not found")
+	}
+	tag, err := db.conn.Exec(`UPDATE notifications SET read_at = COALESCE(read_at, now())
+		WHERE id = $1 AND username = $2`, notificationId, username)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("notification not found")
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead marks every unread notification in a user's feed read and returns how many there were
+func (db *PostgresDatabase) MarkAllNotificationsRead(username string) (int, error) {
+	tag, err := db.conn.Exec("UPDATE notifications SET read_at = now() WHERE username = $1 AND read_at IS NULL", username)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// GetNotificationPreferences retrieves the channels a user has chosen, by notification type.
+// Types the user has not chosen for are missing.
+func (db *PostgresDatabase) GetNotificationPreferences(username string) (map[string]models.NotificationPreference, error) {
+	rows, err := db.conn.Query("SELECT type, in_app, email FROM notification_preferences WHERE username = $1", username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	preferences := map[string]models.NotificationPreference{}
+	for rows.Next() {
+		var preference models.NotificationPreference
+		if err := rows.Scan(&preference.Type, &preference.InApp, &preference.Email); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		preferences[preference.Type] = preference
+	}
+	return preferences, rows.Err()
+}
+
+// SetNotificationPreferences saves a user's choice of channels for each of the given notification types
+func (db *PostgresDatabase) SetNotificationPreferences(username string, preferences []models.NotificationPreference) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, preference := range preferences {
+		_, err := tx.Exec(`INSERT INTO notification_preferences (username, type, in_app, email) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (username, type) DO UPDATE SET in_app = EXCLUDED.in_app, email = EXCLUDED.email`,
+			username, preference.Type, preference.InApp, preference.Email)
+		if err != nil {
+			return fmt.Errorf("failed to save notification preference: %w", err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,211 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+// AddOrganization creates an organization and makes the given user its owner
+func (db *PostgresDatabase) AddOrganization(organization models.AddOrganization) (*models.Organization, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var added models.Organization
+	var id int64
+	err = tx.QueryRow("INSERT INTO organizations (name, slug) VALUES ($1, $2) RETURNING id, name, slug, created_at",
+		organization.Name, organization.Slug).Scan(&id, &added.Name, &added.Slug, &added.CreatedAt)
+	if err != nil {
+		log.Println("Insert error:", err)
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	added.Id = strconv.FormatInt(id, 10)
+
+	tag, err := tx.Exec(`INSERT INTO organization_members (organization_id, user_id, role)
+		SELECT $1, id, $3 FROM users WHERE username = $2 AND deleted_at IS NULL`,
+		id, organization.OwnerUsername, models.OrgRoleOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add organization owner: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &added, nil
+}
+
+// GetOrganizationsForUser retrieves every organization a user belongs to, oldest membership first
+func (db *PostgresDatabase) GetOrganizationsForUser(username string) ([]models.OrganizationMembership, error) {
+	query := `SELECT o.id, o.name, o.slug, o.created_at, m.role, m.joined_at
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id
+		JOIN users u ON u.id = m.user_id
+		WHERE u.username = $1 ORDER BY m.joined_at`
+	rows, err := db.conn.Query(query, username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	memberships := []models.OrganizationMembership{}
+	for rows.Next() {
+		var membership models.OrganizationMembership
+		var id int64
+		if err := rows.Scan(&id, &membership.Name, &membership.Slug, &membership.CreatedAt, &membership.Role, &membership.JoinedAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		membership.Id = strconv.FormatInt(id, 10)
+		memberships = append(memberships, membership)
+	}
+	return memberships, rows.Err()
+}
+
+// GetOrganizationRole retrieves the role a user holds in an organization, failing if they are not a member
+func (db *PostgresDatabase) GetOrganizationRole(organizationId, username string) (string, error) {
+	query := `SELECT m.role FROM organization_members m JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1 AND u.username = $2`
+	var role string
+	err := db.conn.QueryRow(query, organizationId, username).Scan(&role)
+	if err == pgx.ErrNoRows {
+		return "", fmt.Errorf("user is not a member of this organization")
+	}
+	if err != nil {
+		return "", fmt.Errorf("error checking organization membership: %w", err)
+	}
+	return role, nil
+}
+
+// GetDefaultOrganizationId retrieves the organization a user joined first, or "" if they belong to none
+func (db *PostgresDatabase) GetDefaultOrganizationId(username string) (string, error) {
+	query := `SELECT m.organization_id FROM organization_members m JOIN users u ON u.id = m.user_id
+		WHERE u.username = $1 ORDER BY m.joined_at LIMIT 1`
+	var id int64
+	err := db.conn.QueryRow(query, username).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error fetching default organization: %w", err)
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// GetOrganizationMembers retrieves a page of an organization's members along with the member count
+func (db *PostgresDatabase) GetOrganizationMembers(organizationId string, page models.Pagination) ([]models.OrganizationMember, int, error) {
+	var total int
+	err := db.conn.QueryRow("SELECT count(*) FROM organization_members WHERE organization_id = $1", organizationId).Scan(&total)
+	if err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT u.id, u.username, u.email, m.role, m.joined_at
+		FROM organization_members m JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = $1 ORDER BY m.joined_at, u.id LIMIT %d OFFSET %d`, page.Limit(), page.Offset())
+	rows, err := db.conn.Query(query, organizationId)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	members := []models.OrganizationMember{}
+	for rows.Next() {
+		var member models.OrganizationMember
+		var id int
+		if err := rows.Scan(&id, &member.Username, &member.Email, &member.Role, &member.JoinedAt); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		member.UserId = strconv.Itoa(id)
+		members = append(members, member)
+	}
+	return members, total, rows.Err()
+}
+
+// CountOrganizationOwners counts the owners of an organization
+func (db *PostgresDatabase) CountOrganizationOwners(organizationId string) (int, error) {
+	query := "SELECT count(*) FROM organization_members WHERE organization_id = $1 AND role = $2"
+	var owners int
+	err := db.conn.QueryRow(query, organizationId, models.OrgRoleOwner).Scan(&owners)
+	return owners, err
+}
+
+// AddOrganizationMember adds an existing user to an organization
+func (db *PostgresDatabase) AddOrganizationMember(organizationId, username, role string) error {
+	query := `INSERT INTO organization_members (organization_id, user_id, role)
+		SELECT $1, id, $3 FROM users WHERE username = $2 AND deleted_at IS NULL
+		ON CONFLICT (organization_id, user_id) DO NOTHING`
+	tag, err := db.conn.Exec(query, organizationId, username, role)
+	if err != nil {
+		log.Println("Insert error:", err)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user not found or already a member")
+	}
+	return nil
+}
+
+// SetOrganizationMemberRole changes the role a member holds in an organization
+func (db *PostgresDatabase) SetOrganizationMemberRole(organizationId, username, role string) error {
+	query := `UPDATE organization_members m SET role = $3 FROM users u
+		WHERE u.id = m.user_id AND m.organization_id = $1 AND u.username = $2`
+	tag, err := db.conn.Exec(query, organizationId, username, role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user is not a member of this organization")
+	}
+	return nil
+}
+
+// RemoveOrganizationMember removes a user from an organization
+func (db *PostgresDatabase) RemoveOrganizationMember(organizationId, username string) error {
+	query := `DELETE FROM organization_members m USING users u
+		WHERE u.id = m.user_id AND m.organization_id = $1 AND u.username = $2`
+	tag, err := db.conn.Exec(query, organizationId, username)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user is not a member of this organization")
+	}
+	return nil
+}
+
+// courseAccessibleToUser is the condition, on the course aliased c, that the user passed as parameter $n may join it:
+// the course is public or the user belongs to its organization
+func courseAccessibleToUser(n int) string {
+	return fmt.Sprintf(`(c.visibility = 'public' OR EXISTS (
+		SELECT 1 FROM organization_members m JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = c.organization_id AND u.username = $%d
+	))`, n)
+}
+
+// CheckIfUserCanAccessCourse fails unless the course is public or the user belongs to the organization owning it
+func (db *PostgresDatabase) CheckIfUserCanAccessCourse(username, courseId string) error {
+	query := "SELECT 1 FROM courses c WHERE c.id = $2 AND " + courseAccessibleToUser(1)
+	var exists int
+	err := db.conn.QueryRow(query, username, courseId).Scan(&exists)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("user '%s' cannot access course '%s'", username, courseId)
+	}
+	if err != nil {
+		return fmt.Errorf("error checking course access: %w", err)
+	}
+	return nil
+}
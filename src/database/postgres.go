@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math"
 	"strconv"
+	"time"
 
 	models "orkidslearning/src/models/database"
 
@@ -14,12 +16,18 @@ import (
 )
 
 type PostgresDatabase struct {
-	conn *pgx.Conn
+	conn *pgx.ConnPool
 }
 
 // NewDatabase creates a new Database instance
-func NewPostgresDatabase(ctx context.Context, connConfig pgx.ConnConfig) (*PostgresDatabase, error) {
-	conn, err := pgx.Connect(connConfig)
+// The pool is shared by request handlers and background work, and callers wait at most acquireTimeout for a free
+// connection rather than queueing forever when it is exhausted.
+func NewPostgresDatabase(ctx context.Context, connConfig pgx.ConnConfig, maxConnections int, acquireTimeout time.Duration) (*PostgresDatabase, error) {
+	conn, err := pgx.NewConnPool(pgx.ConnPoolConfig{
+		ConnConfig:     connConfig,
+		MaxConnections: maxConnections,
+		AcquireTimeout: acquireTimeout,
+	})
 	if err != nil {
 		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
 		return nil, err
@@ -30,13 +38,78 @@ func NewPostgresDatabase(ctx context.Context, connConfig pgx.ConnConfig) (*Postg
 
 // Disconnect closes the database connection
 func (db *PostgresDatabase) Disconnect() error {
-	return db.conn.Close()
+	db.conn.Close()
+	return nil
+}
+
+// courseVisibleToTenant restricts the courses aliased c to public ones and those of the tenant passed as parameter $n.
+// An empty tenant only sees public courses.
+func courseVisibleToTenant(n int) string {
+	return fmt.Sprintf("(c.visibility = 'public' OR c.organization_id = NULLIF($%d, '')::bigint)", n)
+}
+
+// intFromNullable converts a nullable INTEGER column, where NULL means no limit
+func intFromNullable(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+	converted := int(*value)
+	return &converted
+}
+
+// nullableFromInt converts an optional limit into a value for a nullable INTEGER column
+func nullableFromInt(value *int) *int32 {
+	if value == nil {
+		return nil
+	}
+	converted := int32(*value)
+	return &converted
+}
+
+const courseColumns = `c.id, c.title, c.description, COALESCE(c.organization_id::text, ''), c.visibility, c.cohort_based,
+	c.capacity, c.enrollment_policy, c.enrollment_opens_at, c.enrollment_closes_at, c.rating_count, c.rating_total,
+	COALESCE(c.category_id::text, ''), c.tags, c.subject, c.grade_level, c.status, c.publish_at, c.published_at,
+	c.current_version`
+
+func scanCourse(row rowScanner) (*models.CoursePostgres, error) {
+	var course models.CoursePostgres
+	var id pgtype.UUID
+	var capacity *int32
+	var ratingCount, ratingTotal int32
+	var currentVersion *int32
+	if err := row.Scan(&id, &course.Title, &course.Description, &course.OrganizationId, &course.Visibility, &course.CohortBased, &capacity,
+		&course.EnrollmentPolicy, &course.EnrollmentOpensAt, &course.EnrollmentClosesAt, &ratingCount, &ratingTotal,
+		&course.CategoryId, &course.Tags, &course.Subject, &course.GradeLevel, &course.Status, &course.PublishAt,
+		&course.PublishedAt, &currentVersion); err != nil {
+		return nil, err
+	}
+	course.Id = fmt.Sprintf("%x", id.Bytes)
+	course.Capacity = intFromNullable(capacity)
+	course.CurrentVersion = intFromNullable(currentVersion)
+	course.RatingCount = int(ratingCount)
+	if ratingCount > 0 {
+		average := math.Round(float64(ratingTotal)/float64(ratingCount)*10) / 10
+		course.AverageRating = &average
+	}
+	return &course, nil
+}
+
+// catalogueOrder maps the catalogue sort keys onto ORDER BY expressions
+var catalogueOrder = map[string]string{
+	"title":  "c.title, c.id",
+	"rating": "c.rating_total::float / NULLIF(c.rating_count, 0) DESC NULLS LAST, c.rating_count DESC, c.title, c.id",
 }
 
-// GetAllCoursesFromDatabase retrieves all courses
-func (db *PostgresDatabase) GetAllCoursesFromDatabase() ([]models.CoursePostgres, error) {
-	query := "SELECT id, title, description FROM courses"
-	rows, err := db.conn.Query(query)
+// GetAllCoursesFromDatabase retrieves all courses visible to the tenant that match the filter, in the order it asks for
+func (db *PostgresDatabase) GetAllCoursesFromDatabase(tenant string, filter models.CourseSearch) ([]models.CoursePostgres, error) {
+	order, ok := catalogueOrder[filter.Sort]
+	if !ok {
+		order = catalogueOrder["title"]
+	}
+	args := []interface{}{tenant}
+	where := catalogueConditions(filter, "", &args)
+	query := "SELECT " + courseColumns + " FROM courses c WHERE " + where + " ORDER BY " + order
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
 		log.Println("Query error:", err)
 		return nil, err
@@ -45,56 +118,108 @@ func (db *PostgresDatabase) GetAllCoursesFromDatabase() ([]models.CoursePostgres
 
 	var courses []models.CoursePostgres
 	for rows.Next() {
-		var course models.CoursePostgres
-		var id pgtype.UUID
-		if err := rows.Scan(&id, &course.Title, &course.Description); err != nil {
+		course, err := scanCourse(rows)
+		if err != nil {
 			log.Println("Row scan error:", err)
 			return nil, err
 		}
-		course.Id = fmt.Sprintf("%x", id.Bytes)
-		courses = append(courses, course)
+		courses = append(courses, *course)
 	}
 	return courses, nil
 }
 
-// GetCourseByIdFromDatabase retrieves a course by its ID
-func (db *PostgresDatabase) GetCourseByIdFromDatabase(courseId string) (*models.CoursePostgres, error) {
-	query := "SELECT id, title, description FROM courses WHERE id = $1"
-	var course models.CoursePostgres
-	var id pgtype.UUID
-	err := db.conn.QueryRow(query, courseId).Scan(&id, &course.Title, &course.Description)
+// GetCourseByIdFromDatabase retrieves a course visible to the tenant by its ID
+func (db *PostgresDatabase) GetCourseByIdFromDatabase(tenant, courseId string) (*models.CoursePostgres, error) {
+	query := "SELECT " + courseColumns + " FROM courses c WHERE c.id = $2 AND " + courseVisibleToTenant(1)
+	course, err := scanCourse(db.conn.QueryRow(query, tenant, courseId))
 	if err != nil {
 		log.Println("QueryRow error:", err)
 		return nil, err
 	}
-	course.Id = fmt.Sprintf("%x", id.Bytes)
-	return &course, nil
+	return course, nil
+}
+
+// GetCoursesByIds retrieves the courses visible to the tenant among the given IDs in one query. IDs that are not
+// UUIDs or name no visible course are left out.
+func (db *PostgresDatabase) GetCoursesByIds(tenant string, courseIds []string) ([]models.CoursePostgres, error) {
+	query := "SELECT " + courseColumns + ` FROM courses c WHERE c.id IN (
+			SELECT id::uuid FROM unnest($2::text[]) id
+			WHERE id ~* '^[0-9a-f]{8}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{4}-?[0-9a-f]{12}$'
+		) AND ` + courseVisibleToTenant(1)
+	rows, err := db.conn.Query(query, tenant, courseIds)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	courses := []models.CoursePostgres{}
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		courses = append(courses, *course)
+	}
+	return courses, rows.Err()
 }
 
-// AddCourseToDatabase adds a new course
-func (db *PostgresDatabase) AddCourseToDatabase(course models.AddCourse) (*models.CoursePostgres, error) {
-	query := "INSERT INTO courses (title, description) VALUES ($1, $2) RETURNING id"
+// AddCourseToDatabase adds a new course owned by the tenant
+func (db *PostgresDatabase) AddCourseToDatabase(tenant string, course models.AddCourse) (*models.CoursePostgres, error) {
+	query := `INSERT INTO courses (title, description, organization_id, visibility, cohort_based, capacity,
+			enrollment_policy, enrollment_opens_at, enrollment_closes_at, category_id, tags, subject, grade_level)
+		VALUES ($1, $2, NULLIF($3, '')::bigint, $4, $5, $6, $7, $8, $9, NULLIF($10, '')::bigint, $11::text[], $12, $13) RETURNING id`
 	var id pgtype.UUID
-	err := db.conn.QueryRow(query, course.Title, course.Description).Scan(&id)
+	err := db.conn.QueryRow(query, course.Title, course.Description, tenant, course.Visibility, course.CohortBased,
+		nullableFromInt(course.Capacity), course.EnrollmentPolicy, course.EnrollmentOpensAt, course.EnrollmentClosesAt,
+		course.CategoryId, course.Tags, course.Subject, course.GradeLevel).Scan(&id)
 	if err != nil {
 		log.Println("Insert error:", err)
 		return nil, err
 	}
 	return &models.CoursePostgres{
-		Id:          fmt.Sprintf("%x", id.Bytes),
-		Title:       course.Title,
-		Description: course.Description,
+		Id:             fmt.Sprintf("%x", id.Bytes),
+		Title:          course.Title,
+		Description:    course.Description,
+		OrganizationId: tenant,
+		Visibility:     course.Visibility,
+		CohortBased:    course.CohortBased,
+		Capacity:       course.Capacity,
+
+		EnrollmentPolicy:   course.EnrollmentPolicy,
+		EnrollmentOpensAt:  course.EnrollmentOpensAt,
+		EnrollmentClosesAt: course.EnrollmentClosesAt,
+
+		CategoryId: course.CategoryId,
+		Tags:       course.Tags,
+		Subject:    course.Subject,
+		GradeLevel: course.GradeLevel,
+
+		Status: models.CourseStatusDraft,
 	}, nil
 }
 
+// UpdateCourseDetails edits the title and description of a course's working copy
+func (db *PostgresDatabase) UpdateCourseDetails(courseId string, update models.UpdateCourseDetails) error {
+	tag, err := db.conn.Exec("UPDATE courses SET title = $2, description = $3 WHERE id = $1", courseId, update.Title, update.Description)
+	if err != nil {
+		return fmt.Errorf("failed to update course: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("course not found")
+	}
+	return nil
+}
+
 // GetUserByEmail retrieves a user by email
 func (db *PostgresDatabase) GetUserByEmail(email string) (*models.UserPostgres, error) {
-	query := "SELECT id, username, email, password FROM users WHERE email = $1"
+	query := "SELECT id, username, email, password, role, status, password_reset_required FROM users WHERE email = $1"
 	var user models.UserPostgres
 
 	// Use a temporary variable if needed for type conversion
 	var id int
-	err := db.conn.QueryRow(query, email).Scan(&id, &user.Username, &user.Email, &user.Password)
+	err := db.conn.QueryRow(query, email).Scan(&id, &user.Username, &user.Email, &user.Password, &user.Role, &user.Status, &user.PasswordResetRequired)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
@@ -122,23 +247,24 @@ func (db *PostgresDatabase) CheckIfUserExists(username, email string) error {
 	return errors.New("user already exists")
 }
 
+// CheckIfUserExistsByUsername fails unless a user that has not been deleted holds the username
 func (db *PostgresDatabase) CheckIfUserExistsByUsername(username string) error {
-	query := "SELECT 1 FROM users WHERE username = $1"
+	query := "SELECT 1 FROM users WHERE username = $1 AND deleted_at IS NULL"
 	var exists int
 	err := db.conn.QueryRow(query, username).Scan(&exists)
 	if err == pgx.ErrNoRows {
-		return nil
+		return fmt.Errorf("user '%s' does not exist", username)
 	}
 	if err != nil {
 		return err
 	}
-	return errors.New("user already exists")
+	return nil
 }
 
-func (db *PostgresDatabase) CheckIfCourseExists(courseId string) error {
-	query := "SELECT 1 FROM courses WHERE id = $1"
+func (db *PostgresDatabase) CheckIfCourseExists(tenant, courseId string) error {
+	query := "SELECT 1 FROM courses c WHERE c.id = $2 AND " + courseVisibleToTenant(1)
 	var exists int
-	err := db.conn.QueryRow(query, courseId).Scan(&exists)
+	err := db.conn.QueryRow(query, tenant, courseId).Scan(&exists)
 	if err == pgx.ErrNoRows {
 		// Return an error if the course does not exist
 		return fmt.Errorf("course with ID '%s' does not exist", courseId)
@@ -150,10 +276,11 @@ func (db *PostgresDatabase) CheckIfCourseExists(courseId string) error {
 	return nil
 }
 
-func (db *PostgresDatabase) CheckIfUserIsEnrolledInCourse(username, courseId string) (bool, error) {
-	query := "SELECT 1 FROM course_enrollments WHERE username = $1 AND id = $2"
+func (db *PostgresDatabase) CheckIfUserIsEnrolledInCourse(tenant, username, courseId string) (bool, error) {
+	query := `SELECT 1 FROM course_enrollments e JOIN courses c ON c.id = e.id
+		WHERE e.username = $2 AND e.id = $3 AND ` + courseVisibleToTenant(1)
 	var exists int
-	err := db.conn.QueryRow(query, username, courseId).Scan(&exists)
+	err := db.conn.QueryRow(query, tenant, username, courseId).Scan(&exists)
 	if err == pgx.ErrNoRows {
 		// User is not enrolled in the course
 		return false, nil
@@ -168,9 +295,17 @@ func (db *PostgresDatabase) CheckIfUserIsEnrolledInCourse(username, courseId str
 
 // AddUser adds a new user
 func (db *PostgresDatabase) AddUser(user models.AddUser) (*models.UserPostgres, error) {
-	query := "INSERT INTO users (username, email, password) VALUES ($1, $2, $3) RETURNING id"
+	// The new account is announced to platform webhooks in the same statement
+	query := `WITH added AS (
+			INSERT INTO users (username, email, password) VALUES ($1, $2, $3) RETURNING id, username, email, role, status
+		), event AS (
+			INSERT INTO webhook_events (type, data)
+			SELECT $4, jsonb_build_object('userId', id::text, 'username', username, 'email', email) FROM added
+		)
+		SELECT id, role, status FROM added`
 	var id int
-	err := db.conn.QueryRow(query, user.Username, user.Email, user.Password).Scan(&id)
+	var role, status string
+	err := db.conn.QueryRow(query, user.Username, user.Email, user.Password, models.WebhookEventUserSignedUp).Scan(&id, &role, &status)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert user: %v", err)
 	}
@@ -179,26 +314,136 @@ func (db *PostgresDatabase) AddUser(user models.AddUser) (*models.UserPostgres,
 		Username: user.Username,
 		Email:    user.Email,
 		Password: "", // Do not return the password
+		Role:     role,
+		Status:   status,
 	}, nil
 }
 
-// AddUserToCourse enrolls a user in a course
-func (db *PostgresDatabase) AddUserToCourse(username, courseId string) error {
-	query := "INSERT INTO course_enrollments (username, id) VALUES ($1, $2)"
-	_, err := db.conn.Exec(query, username, courseId)
+// AddUserToCourse enrolls a user in a course visible to the tenant, recording the tenant the enrollment was made under.
+// The user must be allowed to access the course. When cohortId is set the user joins that cohort, otherwise the open
+// cohort of a cohort-based course. If the course or cohort has no free seat the user joins the end of its waitlist
+// instead. A non-empty invite code is redeemed along the way, failing with ErrInviteCodeInvalid if it cannot be.
+//
+// Everything happens in one transaction holding the course lock, so concurrent calls for the same user enroll them
+// once. Once the user is known to be neither enrolled nor waiting, admit, when given, is called with the locked course
+// and the chosen cohort and may hold the attempt back by returning the outcome to report instead. The result tells
+// whether anything changed and the user's waitlist position, which is 0 once they are enrolled.
+func (db *PostgresDatabase) AddUserToCourse(tenant, username, courseId, cohortId, inviteCode string,
+	admit func(course *models.CoursePostgres, cohortId string) (*models.EnrollmentResult, error)) (*models.EnrollmentResult, error) {
+	tx, err := db.conn.Begin()
 	if err != nil {
-		log.Println("Insert error:", err)
-		return err
+		return nil, err
 	}
-	return nil
+	defer tx.Rollback()
+
+	course, err := lockCourseRow(tx, tenant, courseId)
+	if err != nil {
+		return nil, err
+	}
+
+	var exists, accessible, enrolled bool
+	var position int64
+	err = tx.QueryRow(`SELECT
+			EXISTS (SELECT 1 FROM users WHERE username = $1 AND deleted_at IS NULL),
+			EXISTS (SELECT 1 FROM courses c WHERE c.id = $2 AND `+courseAccessibleToUser(1)+`),
+			EXISTS (SELECT 1 FROM course_enrollments WHERE username = $1 AND id = $2),
+			(SELECT count(*) FROM course_waitlist w JOIN course_waitlist mine ON mine.course_id = w.course_id AND mine.username = $1
+				WHERE w.course_id = $2 AND w.cohort_id IS NOT DISTINCT FROM mine.cohort_id AND w.id <= mine.id)`,
+		username, courseId).Scan(&exists, &accessible, &enrolled, &position)
+	if err != nil {
+		return nil, fmt.Errorf("error checking enrollment: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("user '%s' does not exist", username)
+	}
+	if !accessible {
+		return nil, fmt.Errorf("user '%s' cannot access course '%s'", username, courseId)
+	}
+	if enrolled {
+		return &models.EnrollmentResult{Status: models.EnrollmentStatusEnrolled}, nil
+	}
+	if position > 0 {
+		return &models.EnrollmentResult{Status: models.EnrollmentStatusWaitlisted, WaitlistPosition: int(position)}, nil
+	}
+
+	cohortId, err = pickCohort(tx, course, cohortId)
+	if err != nil {
+		return nil, err
+	}
+
+	if admit != nil {
+		result, err := admit(course, cohortId)
+		if err != nil || result != nil {
+			return result, err
+		}
+	}
+
+	if inviteCode != "" {
+		if err := redeemInviteCode(tx, courseId, inviteCode); err != nil {
+			return nil, err
+		}
+	}
+
+	waitlistPosition, err := enrollOrWaitlist(tx, tenant, username, courseId, cohortId, nullableFromInt(course.Capacity))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	result := &models.EnrollmentResult{Status: models.EnrollmentStatusEnrolled, CohortId: cohortId, Changed: true}
+	if waitlistPosition > 0 {
+		result.Status = models.EnrollmentStatusWaitlisted
+		result.WaitlistPosition = waitlistPosition
+	}
+	return result, nil
 }
 
-// RemoveUserFromCourse removes a user from a course
-func (db *PostgresDatabase) RemoveUserFromCourse(username, courseId string) error {
-	query := "DELETE FROM course_enrollments WHERE username = $1 AND id = $2"
-	_, err := db.conn.Exec(query, username, courseId)
+// RemoveUserFromCourse removes a user from a course visible to the tenant, or from its waitlist if they never got a seat,
+// and reports whether they were enrolled and whether they were waiting. A freed seat goes to the first learner on the
+// waitlist who fits in it, whose username is returned, or "" if nobody was promoted.
+func (db *PostgresDatabase) RemoveUserFromCourse(tenant, username, courseId string) (bool, bool, string, error) {
+	tx, err := db.conn.Begin()
 	if err != nil {
-		return err
+		return false, false, "", err
 	}
-	return nil
+	defer tx.Rollback()
+
+	courseCapacity, err := lockCourse(tx, tenant, courseId)
+	if err != nil {
+		return false, false, "", err
+	}
+
+	var organizationId string
+	err = tx.QueryRow(`DELETE FROM course_enrollments WHERE username = $1 AND id = $2
+		RETURNING COALESCE(organization_id::text, '')`, username, courseId).Scan(&organizationId)
+	if err != nil && err != pgx.ErrNoRows {
+		return false, false, "", err
+	}
+	unenrolled := err == nil
+	if unenrolled {
+		if err := addEnrollmentWebhookEvent(tx, organizationId, models.WebhookEventEnrollmentRemoved, username, courseId); err != nil {
+			return false, false, "", err
+		}
+	}
+
+	tag, err := tx.Exec("DELETE FROM course_waitlist WHERE username = $1 AND course_id = $2", username, courseId)
+	if err != nil {
+		return false, false, "", err
+	}
+	leftWaitlist := tag.RowsAffected() > 0
+
+	promoted := ""
+	if unenrolled {
+		promoted, err = promoteFromWaitlist(tx, courseId, courseCapacity)
+		if err != nil {
+			return false, false, "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, false, "", err
+	}
+	return unenrolled, leftWaitlist, promoted, nil
 }
@@ -0,0 +1,547 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+// testPostgres connects to the database named by the TEST_POSTGRES_* variables and migrates it, skipping the test
+// when TEST_POSTGRES_HOST is not set. Tests create their own uniquely named rows, so they can share the database.
+func testPostgres(t *testing.T) *PostgresDatabase {
+	t.Helper()
+	host := os.Getenv("TEST_POSTGRES_HOST")
+	if host == "" {
+		t.Skip("TEST_POSTGRES_HOST is not set")
+	}
+	port, err := strconv.ParseUint(testEnv("TEST_POSTGRES_PORT", "5432"), 10, 16)
+	if err != nil {
+		t.Fatalf("invalid TEST_POSTGRES_PORT: %v", err)
+	}
+	db, err := NewPostgresDatabase(context.Background(), pgx.ConnConfig{
+		Host:     host,
+		Port:     uint16(port),
+		User:     testEnv("TEST_POSTGRES_USER", "myuser"),
+		Password: testEnv("TEST_POSTGRES_PASSWORD", "mypassword"),
+		Database: testEnv("TEST_POSTGRES_DB", "mydatabase"),
+	}, 20, 10*time.Second)
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	t.Cleanup(func() { db.Disconnect() })
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return db
+}
+
+func testEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+var testNames atomic.Int64
+
+// testName returns a name no other test run uses
+func testName(prefix string) string {
+	return fmt.Sprintf("%s-%d-%d", prefix, time.Now().UnixNano(), testNames.Add(1))
+}
+
+func testUser(t *testing.T, db *PostgresDatabase) string {
+	t.Helper()
+	username := testName("user")
+	if _, err := db.AddUser(models.AddUser{Username: username, Email: username + "@example.com", Password: "secret"}); err != nil {
+		t.Fatalf("adding user: %v", err)
+	}
+	return username
+}
+
+// testCourse adds a published course to the organization, or a public course when organizationId is empty
+func testCourse(t *testing.T, db *PostgresDatabase, organizationId string, capacity *int) *models.CoursePostgres {
+	t.Helper()
+	visibility := models.CourseVisibilityPublic
+	if organizationId != "" {
+		visibility = models.CourseVisibilityOrganization
+	}
+	course, err := db.AddCourseToDatabase(organizationId, models.AddCourse{
+		Title:            testName("course"),
+		Visibility:       visibility,
+		Capacity:         capacity,
+		EnrollmentPolicy: models.EnrollmentPolicyOpen,
+	})
+	if err != nil {
+		t.Fatalf("adding course: %v", err)
+	}
+	if _, err := db.conn.Exec("UPDATE courses SET status = $2 WHERE id = $1", course.Id, models.CourseStatusPublished); err != nil {
+		t.Fatalf("publishing course: %v", err)
+	}
+	return course
+}
+
+func countEnrollments(t *testing.T, db *PostgresDatabase, username, courseId string) int {
+	t.Helper()
+	var count int64
+	err := db.conn.QueryRow("SELECT count(*) FROM course_enrollments WHERE username = $1 AND id = $2", username, courseId).Scan(&count)
+	if err != nil {
+		t.Fatalf("counting enrollments: %v", err)
+	}
+	return int(count)
+}
+
+func TestAddUserToCourseConcurrently(t *testing.T) {
+	db := testPostgres(t)
+	username := testUser(t, db)
+	course := testCourse(t, db, "", nil)
+
+	const calls = 8
+	var wg sync.WaitGroup
+	results := make(chan *models.EnrollmentResult, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := db.AddUserToCourse("", username, course.Id, "", "", nil)
+			if err != nil {
+				t.Errorf("enrolling: %v", err)
+				return
+			}
+			results <- result
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	changed := 0
+	for result := range results {
+		if result.Status != models.EnrollmentStatusEnrolled {
+			t.Errorf("status = %s, want %s", result.Status, models.EnrollmentStatusEnrolled)
+		}
+		if result.Changed {
+			changed++
+		}
+	}
+	if changed != 1 {
+		t.Errorf("%d calls reported a change, want 1", changed)
+	}
+	if count := countEnrollments(t, db, username, course.Id); count != 1 {
+		t.Errorf("%d enrollment rows, want 1", count)
+	}
+}
+
+func TestRemoveUserFromCourseConcurrently(t *testing.T) {
+	db := testPostgres(t)
+	username := testUser(t, db)
+	course := testCourse(t, db, "", nil)
+	if _, err := db.AddUserToCourse("", username, course.Id, "", "", nil); err != nil {
+		t.Fatalf("enrolling: %v", err)
+	}
+
+	const calls = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	unenrolledCalls := 0
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unenrolled, _, _, err := db.RemoveUserFromCourse("", username, course.Id)
+			if err != nil {
+				t.Errorf("unenrolling: %v", err)
+				return
+			}
+			if unenrolled {
+				mu.Lock()
+				unenrolledCalls++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if unenrolledCalls != 1 {
+		t.Errorf("%d calls reported unenrolling, want 1", unenrolledCalls)
+	}
+	if count := countEnrollments(t, db, username, course.Id); count != 0 {
+		t.Errorf("%d enrollment rows, want 0", count)
+	}
+}
+
+// Enrolling and unenrolling at the same time leaves at most one enrollment, and every reported change really happened
+func TestEnrollAndUnenrollConcurrently(t *testing.T) {
+	db := testPostgres(t)
+	username := testUser(t, db)
+	course := testCourse(t, db, "", nil)
+
+	const calls = 16
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	enrolls, unenrolls := 0, 0
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(enroll bool) {
+			defer wg.Done()
+			if enroll {
+				result, err := db.AddUserToCourse("", username, course.Id, "", "", nil)
+				if err != nil {
+					t.Errorf("enrolling: %v", err)
+					return
+				}
+				if result.Changed {
+					mu.Lock()
+					enrolls++
+					mu.Unlock()
+				}
+				return
+			}
+			unenrolled, _, _, err := db.RemoveUserFromCourse("", username, course.Id)
+			if err != nil {
+				t.Errorf("unenrolling: %v", err)
+				return
+			}
+			if unenrolled {
+				mu.Lock()
+				unenrolls++
+				mu.Unlock()
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+
+	count := countEnrollments(t, db, username, course.Id)
+	if count > 1 {
+		t.Fatalf("%d enrollment rows, want at most 1", count)
+	}
+	if enrolls-unenrolls != count {
+		t.Errorf("%d enrollments and %d unenrollments reported, but %d rows remain", enrolls, unenrolls, count)
+	}
+}
+
+// A full course puts concurrent learners on its waitlist instead of overfilling it
+func TestAddUserToFullCourseConcurrently(t *testing.T) {
+	db := testPostgres(t)
+	capacity := 1
+	course := testCourse(t, db, "", &capacity)
+
+	const learners = 6
+	var wg sync.WaitGroup
+	for i := 0; i < learners; i++ {
+		username := testUser(t, db)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := db.AddUserToCourse("", username, course.Id, "", "", nil); err != nil {
+				t.Errorf("enrolling: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var enrolled, waiting int64
+	if err := db.conn.QueryRow("SELECT count(*) FROM course_enrollments WHERE id = $1", course.Id).Scan(&enrolled); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.conn.QueryRow("SELECT count(*) FROM course_waitlist WHERE course_id = $1", course.Id).Scan(&waiting); err != nil {
+		t.Fatal(err)
+	}
+	if enrolled != 1 || waiting != learners-1 {
+		t.Errorf("%d enrolled and %d waiting, want 1 and %d", enrolled, waiting, learners-1)
+	}
+}
+
+func testOrganization(t *testing.T, db *PostgresDatabase, owner string) string {
+	t.Helper()
+	slug := testName("org")
+	organization, err := db.AddOrganization(models.AddOrganization{Name: slug, Slug: slug, OwnerUsername: owner})
+	if err != nil {
+		t.Fatalf("adding organization: %v", err)
+	}
+	return organization.Id
+}
+
+// A caller acting within one organization can neither see nor change the courses and enrollments of another, and a
+// member who leaves an organization loses access to its courses
+func TestCoursesAreIsolatedBetweenOrganizations(t *testing.T) {
+	db := testPostgres(t)
+	ownerA, ownerB := testUser(t, db), testUser(t, db)
+	orgA, orgB := testOrganization(t, db, ownerA), testOrganization(t, db, ownerB)
+	courseA, courseB := testCourse(t, db, orgA, nil), testCourse(t, db, orgB, nil)
+
+	learnerB := testUser(t, db)
+	if err := db.AddOrganizationMember(orgB, learnerB, models.OrgRoleMember); err != nil {
+		t.Fatalf("adding member: %v", err)
+	}
+	if _, err := db.AddUserToCourse(orgB, learnerB, courseB.Id, "", "", nil); err != nil {
+		t.Fatalf("enrolling within the organization: %v", err)
+	}
+
+	courses, err := db.GetAllCoursesFromDatabase(orgA, models.CourseSearch{})
+	if err != nil {
+		t.Fatalf("listing courses: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, course := range courses {
+		seen[course.Id] = true
+	}
+	if !seen[courseA.Id] {
+		t.Errorf("organization A does not see its own course")
+	}
+	if seen[courseB.Id] {
+		t.Errorf("organization A sees a course of organization B")
+	}
+
+	if _, err := db.GetCourseByIdFromDatabase(orgA, courseB.Id); err == nil {
+		t.Errorf("organization A read a course of organization B")
+	}
+	if _, err := db.GetCourseByIdFromDatabase(orgB, courseB.Id); err != nil {
+		t.Errorf("organization B cannot read its own course: %v", err)
+	}
+	if _, err := db.GetCourseByIdFromDatabase("", courseB.Id); err == nil {
+		t.Errorf("a caller outside any organization read an organization course")
+	}
+
+	if _, err := db.AddUserToCourse(orgA, ownerA, courseB.Id, "", "", nil); err == nil {
+		t.Errorf("organization A enrolled a user in a course of organization B")
+	}
+	if count := countEnrollments(t, db, ownerA, courseB.Id); count != 0 {
+		t.Errorf("%d enrollments in a course of another organization, want 0", count)
+	}
+	// Joining under the course's own tenant still needs membership of its organization
+	if _, err := db.AddUserToCourse(orgB, ownerA, courseB.Id, "", "", nil); err == nil {
+		t.Errorf("a user outside organization B enrolled in its course")
+	}
+
+	if _, _, _, err := db.RemoveUserFromCourse(orgA, learnerB, courseB.Id); err == nil {
+		t.Errorf("organization A unenrolled a learner from a course of organization B")
+	}
+	if count := countEnrollments(t, db, learnerB, courseB.Id); count != 1 {
+		t.Errorf("%d enrollments left after a cross-organization unenroll, want 1", count)
+	}
+
+	enrolled, err := db.CheckIfUserIsEnrolledInCourse(orgA, learnerB, courseB.Id)
+	if err != nil {
+		t.Fatalf("checking enrollment: %v", err)
+	}
+	if enrolled {
+		t.Errorf("organization A sees an enrollment in a course of organization B")
+	}
+
+	assignment, err := db.AddAssignment(courseB.Id, models.AddAssignment{Title: "Assignment"})
+	if err != nil {
+		t.Fatalf("adding assignment: %v", err)
+	}
+	if _, err := db.SubmitAssignment(orgA, learnerB, courseB.Id, assignment.Id, "answer"); err == nil {
+		t.Errorf("organization A took a submission for an assignment of organization B")
+	}
+	if _, err := db.SubmitAssignment(orgB, learnerB, courseB.Id, assignment.Id, "answer"); err != nil {
+		t.Errorf("the learner of organization B cannot submit its assignment: %v", err)
+	}
+
+	if start, err := db.GetReleaseStart(orgB, learnerB, courseB.Id); err != nil || start == nil {
+		t.Errorf("the learner of organization B cannot reach its lessons: %v", err)
+	}
+	if start, err := db.GetReleaseStart(orgA, learnerB, courseB.Id); err != nil || start != nil {
+		t.Errorf("organization A reached the lessons of organization B: %v", err)
+	}
+
+	// A member removed from the organization keeps the enrollment row, but can no longer reach its lessons or submit
+	// its assignments under any tenant
+	if err := db.RemoveOrganizationMember(orgB, learnerB); err != nil {
+		t.Fatalf("removing member: %v", err)
+	}
+	for _, tenant := range []string{orgB, ""} {
+		if start, err := db.GetReleaseStart(tenant, learnerB, courseB.Id); err != nil || start != nil {
+			t.Errorf("a removed member reached the lessons of organization B under tenant %q: %v", tenant, err)
+		}
+		if _, err := db.SubmitAssignment(tenant, learnerB, courseB.Id, assignment.Id, "answer"); err == nil {
+			t.Errorf("a removed member submitted an assignment of organization B under tenant %q", tenant)
+		}
+	}
+}
+
+// Purging an account tells webhooks the user left their courses, and leaves no trace of the user's username or email
+// in webhook events or deliveries
+func TestPurgeAccountRedactsWebhookPayloads(t *testing.T) {
+	db := testPostgres(t)
+	owner := testUser(t, db)
+	organizationId := testOrganization(t, db, owner)
+	webhook, err := db.CreateWebhook(organizationId, owner, "secret", models.AddWebhook{
+		URL:    "https://hooks.example.com",
+		Events: []string{models.WebhookEventEnrollmentCreated},
+	})
+	if err != nil {
+		t.Fatalf("adding webhook: %v", err)
+	}
+
+	username := testUser(t, db)
+	if err := db.AddOrganizationMember(organizationId, username, models.OrgRoleMember); err != nil {
+		t.Fatalf("adding member: %v", err)
+	}
+	course := testCourse(t, db, organizationId, nil)
+	if _, err := db.AddUserToCourse(organizationId, username, course.Id, "", "", nil); err != nil {
+		t.Fatalf("enrolling: %v", err)
+	}
+	// Deliver the user's events to the webhook as the relay would, without taking other tests' events
+	_, err = db.conn.Exec(`INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT $1, id, type, jsonb_build_object('id', id::text, 'type', type, 'data', data)::text
+		FROM webhook_events WHERE data->>'username' = $2`, webhook.Id, username)
+	if err != nil {
+		t.Fatalf("adding deliveries: %v", err)
+	}
+
+	if err := db.PurgeAccount(username); err != nil {
+		t.Fatalf("purging: %v", err)
+	}
+
+	// The email starts with the username, so looking for the username finds both
+	var events, deliveries int64
+	err = db.conn.QueryRow(`SELECT count(*) FROM webhook_events WHERE data::text LIKE '%' || $1 || '%'`, username).Scan(&events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.conn.QueryRow(`SELECT count(*) FROM webhook_deliveries WHERE payload LIKE '%' || $1 || '%'`, username).Scan(&deliveries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events != 0 || deliveries != 0 {
+		t.Errorf("%d webhook events and %d deliveries still name %s", events, deliveries, username)
+	}
+	var redacted int64
+	err = db.conn.QueryRow(`SELECT count(*) FROM webhook_deliveries WHERE webhook_id = $1
+		AND payload::jsonb->'data'->>'email' LIKE '%@deleted.invalid'`, webhook.Id).Scan(&redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redacted == 0 {
+		t.Errorf("no redacted delivery was kept")
+	}
+
+	var removed int64
+	err = db.conn.QueryRow(`SELECT count(*) FROM webhook_events WHERE organization_id = $1::bigint AND type = $2
+		AND data->>'courseId' = $3`, organizationId, models.WebhookEventEnrollmentRemoved, course.Id).Scan(&removed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("%d enrollment.removed events for the purged user's course, want 1", removed)
+	}
+}
+
+// Concurrent reviews of a course all count towards its rating totals
+func TestSetCourseReviewConcurrently(t *testing.T) {
+	db := testPostgres(t)
+	course := testCourse(t, db, "", nil)
+
+	const reviewers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < reviewers; i++ {
+		username := testUser(t, db)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := db.SetCourseReview(course.Id, username, models.SetCourseReview{Rating: 4}); err != nil {
+				t.Errorf("reviewing: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var count, total int32
+	err := db.conn.QueryRow("SELECT rating_count, rating_total FROM courses WHERE id = $1", course.Id).Scan(&count, &total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != reviewers || total != 4*reviewers {
+		t.Errorf("rating count %d and total %d, want %d and %d", count, total, reviewers, 4*reviewers)
+	}
+}
+
+// Asking to join a course several times at once records one request and reports it to every caller
+func TestAddEnrollmentRequestConcurrently(t *testing.T) {
+	db := testPostgres(t)
+	username := testUser(t, db)
+	course := testCourse(t, db, "", nil)
+
+	const calls = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	added := 0
+	ids := map[string]bool{}
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request, created, err := db.AddEnrollmentRequest("", username, course.Id, "")
+			if err != nil {
+				t.Errorf("requesting enrollment: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			ids[request.Id] = true
+			if created {
+				added++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if added != 1 || len(ids) != 1 {
+		t.Errorf("%d requests added and %d distinct requests returned, want 1 and 1", added, len(ids))
+	}
+}
+
+func TestStaleWorkerCannotRecordJobOutcome(t *testing.T) {
+	db := testPostgres(t)
+	queue := testName("queue")
+	if _, err := db.EnqueueJob(queue, "test", []byte(`{}`), time.Now(), 3); err != nil {
+		t.Fatalf("enqueueing: %v", err)
+	}
+
+	stale, err := db.ClaimJob(queue, "worker-a")
+	if err != nil || stale == nil {
+		t.Fatalf("claiming: %v %v", stale, err)
+	}
+	if released, err := db.ReleaseStaleJobs(queue, time.Now().Add(time.Minute)); err != nil || released != 1 {
+		t.Fatalf("releasing: %d %v", released, err)
+	}
+	current, err := db.ClaimJob(queue, "worker-b")
+	if err != nil || current == nil || current.Id != stale.Id {
+		t.Fatalf("claiming again: %v %v", current, err)
+	}
+
+	// worker-a finishing late must leave worker-b's claim alone, as must a later claim of worker-a itself
+	if err := db.CompleteJob(stale.Id, "worker-a", stale.Attempts); err == nil {
+		t.Errorf("the stale worker completed the job")
+	}
+	if _, err := db.FailJob(stale.Id, "worker-a", stale.Attempts, "too late", time.Now()); err == nil {
+		t.Errorf("the stale worker failed the job")
+	}
+	if err := db.CompleteJob(stale.Id, "worker-b", stale.Attempts); err == nil {
+		t.Errorf("an earlier claim completed the job")
+	}
+	job, err := db.GetJob(stale.Id)
+	if err != nil {
+		t.Fatalf("getting job: %v", err)
+	}
+	if job.Status != models.JobRunning || job.LockedBy != "worker-b" {
+		t.Fatalf("job is %s by %q, want running by worker-b", job.Status, job.LockedBy)
+	}
+
+	if err := db.CompleteJob(current.Id, "worker-b", current.Attempts); err != nil {
+		t.Fatalf("completing: %v", err)
+	}
+	if err := db.CompleteJob(current.Id, "worker-b", current.Attempts); err == nil {
+		t.Errorf("a completed job was completed again")
+	}
+}
@@ -0,0 +1,216 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+const courseReviewColumns = "r.id, r.course_id, r.username, r.rating, r.body, r.created_at, r.updated_at, r.reply, r.replied_by, r.replied_at"
+
+// refreshCourseRatings recomputes the rating totals of the courses matched by the condition, which may refer to
+// the course aliased c. Callers lock the courses before changing their reviews, as otherwise the recount can miss a
+// review committed by a concurrent transaction.
+const refreshCourseRatings = `UPDATE courses c SET
+		rating_count = (SELECT count(*) FROM course_reviews r WHERE r.course_id = c.id),
+		rating_total = (SELECT COALESCE(sum(r.rating), 0) FROM course_reviews r WHERE r.course_id = c.id)
+	WHERE `
+
+// courseReviewsOrder maps the review sort keys onto ORDER BY expressions
+var courseReviewsOrder = map[string]string{
+	"recent":  "COALESCE(r.updated_at, r.created_at) DESC",
+	"highest": "r.rating DESC, COALESCE(r.updated_at, r.created_at) DESC",
+	"lowest":  "r.rating, COALESCE(r.updated_at, r.created_at) DESC",
+}
+
+func scanCourseReview(row rowScanner) (*models.CourseReview, error) {
+	var review models.CourseReview
+	var id int64
+	var courseId pgtype.UUID
+	var rating int32
+	err := row.Scan(&id, &courseId, &review.Username, &rating, &review.Body, &review.CreatedAt, &review.UpdatedAt,
+		&review.Reply, &review.RepliedBy, &review.RepliedAt)
+	if err != nil {
+		return nil, err
+	}
+	review.Id = strconv.FormatInt(id, 10)
+	review.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+	review.Rating = int(rating)
+	return &review, nil
+}
+
+// GetCourseReviews retrieves a page of a course's reviews, along with the total match count
+func (db *PostgresDatabase) GetCourseReviews(courseId string, filter models.CourseReviewSearch) ([]models.CourseReview, int, error) {
+	where := "r.course_id = $1"
+	args := []interface{}{courseId}
+	if filter.Rating != 0 {
+		args = append(args, filter.Rating)
+		where += fmt.Sprintf(" AND r.rating = $%d", len(args))
+	}
+
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM course_reviews r WHERE "+where, args...).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	order, ok := courseReviewsOrder[filter.Sort]
+	if !ok {
+		order = courseReviewsOrder["recent"]
+	}
+	query := fmt.Sprintf("SELECT %s FROM course_reviews r WHERE %s ORDER BY %s, r.id DESC LIMIT %d OFFSET %d",
+		courseReviewColumns, where, order, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reviews := []models.CourseReview{}
+	for rows.Next() {
+		review, err := scanCourseReview(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		reviews = append(reviews, *review)
+	}
+	return reviews, total, rows.Err()
+}
+
+// GetCourseReview retrieves a review of a course
+func (db *PostgresDatabase) GetCourseReview(courseId, reviewId string) (*models.CourseReview, error) {
+	if _, err := strconv.ParseInt(reviewId, 10, 64); err != nil {
+		return nil, fmt.Errorf("review not found")
+	}
+	query := "SELECT " + courseReviewColumns + " FROM course_reviews r WHERE r.id = $1 AND r.course_id = $2"
+	review, err := scanCourseReview(db.conn.QueryRow(query, reviewId, courseId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("review not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching review: %w", err)
+	}
+	return review, nil
+}
+
+// GetUserCourseReview retrieves a user's review of a course, or nil if they have not reviewed it
+func (db *PostgresDatabase) GetUserCourseReview(courseId, username string) (*models.CourseReview, error) {
+	query := "SELECT " + courseReviewColumns + " FROM course_reviews r WHERE r.course_id = $1 AND r.username = $2"
+	review, err := scanCourseReview(db.conn.QueryRow(query, courseId, username))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching review: %w", err)
+	}
+	return review, nil
+}
+
+// GetCourseReviewsForUser retrieves every review a user wrote, oldest first
+func (db *PostgresDatabase) GetCourseReviewsForUser(username string) ([]models.CourseReview, error) {
+	rows, err := db.conn.Query("SELECT "+courseReviewColumns+" FROM course_reviews r WHERE r.username = $1 ORDER BY r.created_at, r.id", username)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []models.CourseReview{}
+	for rows.Next() {
+		review, err := scanCourseReview(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		reviews = append(reviews, *review)
+	}
+	return reviews, rows.Err()
+}
+
+// SetCourseReview adds a user's review of a course, or replaces their earlier one, and updates the course's
+// rating totals
+func (db *PostgresDatabase) SetCourseReview(courseId, username string, set models.SetCourseReview) (*models.CourseReview, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("SELECT 1 FROM courses WHERE id = $1 FOR UPDATE", courseId); err != nil {
+		return nil, fmt.Errorf("failed to lock course: %w", err)
+	}
+
+	query := `INSERT INTO course_reviews AS r (course_id, username, rating, body) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (course_id, username) DO UPDATE SET rating = EXCLUDED.rating, body = EXCLUDED.body, updated_at = now()
+		RETURNING ` + courseReviewColumns
+	review, err := scanCourseReview(tx.QueryRow(query, courseId, username, set.Rating, set.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save review: %w", err)
+	}
+
+	if _, err = tx.Exec(refreshCourseRatings+"c.id = $1", courseId); err != nil {
+		return nil, fmt.Errorf("failed to update course rating: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// DeleteCourseReview removes a user's review of a course, updates the course's rating totals and returns the
+// removed review
+func (db *PostgresDatabase) DeleteCourseReview(courseId, username string) (*models.CourseReview, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("SELECT 1 FROM courses WHERE id = $1 FOR UPDATE", courseId); err != nil {
+		return nil, fmt.Errorf("failed to lock course: %w", err)
+	}
+
+	query := "DELETE FROM course_reviews r WHERE r.course_id = $1 AND r.username = $2 RETURNING " + courseReviewColumns
+	review, err := scanCourseReview(tx.QueryRow(query, courseId, username))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("review not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete review: %w", err)
+	}
+
+	if _, err = tx.Exec(refreshCourseRatings+"c.id = $1", courseId); err != nil {
+		return nil, fmt.Errorf("failed to update course rating: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// SetCourseReviewReply sets an instructor's public reply to a review, or removes it when body is empty
+func (db *PostgresDatabase) SetCourseReviewReply(courseId, reviewId, instructor, body string) (*models.CourseReview, error) {
+	if _, err := strconv.ParseInt(reviewId, 10, 64); err != nil {
+		return nil, fmt.Errorf("review not found")
+	}
+	query := `UPDATE course_reviews r SET reply = $3,
+			replied_by = CASE WHEN $3 = '' THEN NULL ELSE $4 END,
+			replied_at = CASE WHEN $3 = '' THEN NULL ELSE now() END
+		WHERE r.id = $1 AND r.course_id = $2
+		RETURNING ` + courseReviewColumns
+	review, err := scanCourseReview(db.conn.QueryRow(query, reviewId, courseId, body, instructor))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("review not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reply to review: %w", err)
+	}
+	return review, nil
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	models "orkidslearning/src/models/database"
+)
+
+// rosterColumns reads a roster entry from the enrollment aliased e of the user aliased u
+const rosterColumns = `e.username, u.email, COALESCE(e.cohort_id::text, ''), e.enrolled_at,
+	GREATEST(e.last_accessed_at, progress.last_completed_at, submissions.last_submitted_at), e.completed_at,
+	progress.total, progress.completed`
+
+// rosterFrom joins each enrollment of course $1 to its learner, lesson progress and latest submission
+const rosterFrom = `FROM course_enrollments e
+	JOIN users u ON u.username = e.username
+	LEFT JOIN LATERAL (
+		SELECT count(*) AS total, count(p.lesson_id) AS completed, max(p.completed_at) AS last_completed_at
+		FROM ` + enrollmentLessons + ` l
+		LEFT JOIN lesson_progress p ON p.lesson_id = l.id AND p.username = e.username
+	) progress ON true
+	LEFT JOIN LATERAL (
+		SELECT max(s.submitted_at) AS last_submitted_at FROM assignment_submissions s
+		JOIN course_assignments a ON a.id = s.assignment_id
+		WHERE a.course_id = e.id AND s.username = e.username
+	) submissions ON true`
+
+func rosterConditions(courseId string, filter models.RosterSearch) (string, []interface{}) {
+	conditions := "e.id = $1 AND u.deleted_at IS NULL"
+	args := []interface{}{courseId}
+	if filter.CohortId != "" {
+		args = append(args, filter.CohortId)
+		conditions += fmt.Sprintf(" AND e.cohort_id = $%d", len(args))
+	}
+	if filter.Search != "" {
+		args = append(args, filter.Search)
+		conditions += fmt.Sprintf(" AND (e.username ILIKE '%%' || $%d || '%%' OR u.email ILIKE '%%' || $%d || '%%')", len(args), len(args))
+	}
+	return conditions, args
+}
+
+func scanRosterEntry(row rowScanner) (*models.RosterEntry, error) {
+	var entry models.RosterEntry
+	var totalLessons, completedLessons int64
+	err := row.Scan(&entry.Username, &entry.Email, &entry.CohortId, &entry.EnrolledAt, &entry.LastActivityAt, &entry.CompletedAt,
+		&totalLessons, &completedLessons)
+	if err != nil {
+		return nil, err
+	}
+	entry.Progress = models.CourseProgress{CompletedLessons: int(completedLessons), TotalLessons: int(totalLessons)}
+	if totalLessons > 0 {
+		entry.Progress.Percent = int(completedLessons * 100 / totalLessons)
+	}
+	return &entry, nil
+}
+
+// GetCourseRoster retrieves a page of the learners enrolled in a course, in enrollment order, along with the total match count
+func (db *PostgresDatabase) GetCourseRoster(courseId string, filter models.RosterSearch) ([]models.RosterEntry, int, error) {
+	where, args := rosterConditions(courseId, filter)
+
+	var total int
+	err := db.conn.QueryRow("SELECT count(*) FROM course_enrollments e JOIN users u ON u.username = e.username WHERE "+where, args...).Scan(&total)
+	if err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s %s WHERE %s ORDER BY e.enrolled_at, e.username LIMIT %d OFFSET %d",
+		rosterColumns, rosterFrom, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	roster := []models.RosterEntry{}
+	for rows.Next() {
+		entry, err := scanRosterEntry(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		roster = append(roster, *entry)
+	}
+	return roster, total, rows.Err()
+}
+
+// EachRosterEntry calls fn for every learner enrolled in a course matching the filter, ignoring pagination
+func (db *PostgresDatabase) EachRosterEntry(courseId string, filter models.RosterSearch, fn func(models.RosterEntry) error) error {
+	where, args := rosterConditions(courseId, filter)
+
+	query := fmt.Sprintf("SELECT %s %s WHERE %s ORDER BY e.enrolled_at, e.username", rosterColumns, rosterFrom, where)
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanRosterEntry(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return err
+		}
+		if err := fn(*entry); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetEnrolledUsernames returns which of the given users are enrolled in a course
+func (db *PostgresDatabase) GetEnrolledUsernames(courseId string, usernames []string) (map[string]bool, error) {
+	rows, err := db.conn.Query("SELECT username FROM course_enrollments WHERE id = $1 AND username = ANY($2::text[])", courseId, usernames)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	enrolled := map[string]bool{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		enrolled[username] = true
+	}
+	return enrolled, rows.Err()
+}
@@ -0,0 +1,522 @@
+package database
+
+import (
+	"fmt"
+	"log"
+)
+
+// schema holds the statements that bring PostgreSQL up to the shape the service expects.
+// Migrate runs every statement on start-up, so each one must be safe to repeat.
+var schema = []string{
+	`CREATE EXTENSION IF NOT EXISTS pgcrypto`,
+	`CREATE TABLE IF NOT EXISTS users (
+		id SERIAL PRIMARY KEY,
+		username TEXT NOT NULL UNIQUE,
+		email TEXT NOT NULL UNIQUE,
+		password TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS courses (
+		id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+		title TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE TABLE IF NOT EXISTS course_enrollments (
+		username TEXT NOT NULL,
+		id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE
+	)`,
+
+	// Account lifecycle
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now()`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMPTZ`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+	`ALTER TABLE course_enrollments ADD COLUMN IF NOT EXISTS enrolled_at TIMESTAMPTZ NOT NULL DEFAULT now()`,
+	`CREATE TABLE IF NOT EXISTS data_exports (
+		id BIGSERIAL PRIMARY KEY,
+		username TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		archive BYTEA,
+		error TEXT,
+		requested_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		completed_at TIMESTAMPTZ,
+		expires_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS data_exports_username_idx ON data_exports (username)`,
+
+	// User administration
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'learner'`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active'`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS status_reason TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS merged_into INTEGER REFERENCES users (id)`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_required BOOLEAN NOT NULL DEFAULT false`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_token_hash TEXT`,
+	`ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_expires_at TIMESTAMPTZ`,
+
+	// Audit log
+	`CREATE TABLE IF NOT EXISTS audit_events (
+		id BIGSERIAL PRIMARY KEY,
+		occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		actor TEXT NOT NULL DEFAULT '',
+		action TEXT NOT NULL,
+		target_type TEXT NOT NULL,
+		target_id TEXT NOT NULL,
+		ip TEXT NOT NULL DEFAULT '',
+		user_agent TEXT NOT NULL DEFAULT '',
+		trace_id TEXT NOT NULL DEFAULT '',
+		before JSONB,
+		after JSONB
+	)`,
+	`CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor, occurred_at)`,
+	`CREATE INDEX IF NOT EXISTS audit_events_target_idx ON audit_events (target_type, target_id, occurred_at)`,
+	`CREATE INDEX IF NOT EXISTS audit_events_occurred_at_idx ON audit_events (occurred_at)`,
+	// The only sanctioned change is an account purge erasing personal data, which it announces by setting
+	// orkidslearning.audit_erasure for its own transaction
+	`CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+	BEGIN
+		IF TG_OP = 'UPDATE' AND current_setting('orkidslearning.audit_erasure', true) = 'on' THEN
+			RETURN NULL;
+		END IF;
+		RAISE EXCEPTION 'audit_events is append-only';
+	END;
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events`,
+	`CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_events
+		FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only()`,
+
+	// Organizations
+	`CREATE TABLE IF NOT EXISTS organizations (
+		id BIGSERIAL PRIMARY KEY,
+		name TEXT NOT NULL,
+		slug TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS organization_members (
+		organization_id BIGINT NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+		role TEXT NOT NULL,
+		joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (organization_id, user_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS organization_members_user_idx ON organization_members (user_id)`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS organization_id BIGINT REFERENCES organizations (id)`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'public'`,
+	`CREATE INDEX IF NOT EXISTS courses_organization_idx ON courses (organization_id)`,
+	`ALTER TABLE course_enrollments ADD COLUMN IF NOT EXISTS organization_id BIGINT REFERENCES organizations (id)`,
+
+	// Cohorts and lessons
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS cohort_based BOOLEAN NOT NULL DEFAULT false`,
+	`CREATE TABLE IF NOT EXISTS cohorts (
+		id BIGSERIAL PRIMARY KEY,
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		starts_at TIMESTAMPTZ NOT NULL,
+		ends_at TIMESTAMPTZ NOT NULL,
+		facilitator_id INTEGER REFERENCES users (id),
+		capacity INTEGER,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		CHECK (ends_at > starts_at)
+	)`,
+	`CREATE INDEX IF NOT EXISTS cohorts_course_idx ON cohorts (course_id, starts_at)`,
+	`ALTER TABLE course_enrollments ADD COLUMN IF NOT EXISTS cohort_id BIGINT REFERENCES cohorts (id)`,
+	`CREATE INDEX IF NOT EXISTS course_enrollments_cohort_idx ON course_enrollments (cohort_id)`,
+	`CREATE TABLE IF NOT EXISTS course_lessons (
+		id BIGSERIAL PRIMARY KEY,
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		content TEXT NOT NULL DEFAULT '',
+		release_after_days INTEGER NOT NULL DEFAULT 0,
+		UNIQUE (course_id, position)
+	)`,
+
+	// Capacity and waitlists
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS capacity INTEGER`,
+	`CREATE TABLE IF NOT EXISTS course_waitlist (
+		id BIGSERIAL PRIMARY KEY,
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		cohort_id BIGINT REFERENCES cohorts (id) ON DELETE CASCADE,
+		username TEXT NOT NULL,
+		organization_id BIGINT REFERENCES organizations (id),
+		joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (course_id, username)
+	)`,
+
+	// Enrollment policies
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS enrollment_policy TEXT NOT NULL DEFAULT 'open'`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS enrollment_opens_at TIMESTAMPTZ`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS enrollment_closes_at TIMESTAMPTZ`,
+	`CREATE TABLE IF NOT EXISTS enrollment_requests (
+		id BIGSERIAL PRIMARY KEY,
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		cohort_id BIGINT REFERENCES cohorts (id) ON DELETE CASCADE,
+		username TEXT NOT NULL,
+		organization_id BIGINT REFERENCES organizations (id),
+		status TEXT NOT NULL DEFAULT 'pending',
+		reason TEXT NOT NULL DEFAULT '',
+		requested_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		decided_at TIMESTAMPTZ,
+		decided_by TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS enrollment_requests_open_idx ON enrollment_requests (course_id, username)
+		WHERE status IN ('pending', 'rejected')`,
+	`CREATE INDEX IF NOT EXISTS enrollment_requests_course_idx ON enrollment_requests (course_id, status, requested_at)`,
+	`CREATE TABLE IF NOT EXISTS course_invite_codes (
+		code TEXT PRIMARY KEY,
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		max_uses INTEGER,
+		uses INTEGER NOT NULL DEFAULT 0,
+		expires_at TIMESTAMPTZ,
+		created_by TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS course_invite_codes_course_idx ON course_invite_codes (course_id)`,
+
+	// Enrollments are unique per user and course. Duplicates left by earlier concurrent enrollments are dropped first,
+	// which only needs doing, and scanning the table, until the index exists.
+	`DELETE FROM course_enrollments a USING course_enrollments b
+		WHERE to_regclass('course_enrollments_user_course_idx') IS NULL
+			AND a.username = b.username AND a.id = b.id AND a.ctid > b.ctid`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS course_enrollments_user_course_idx ON course_enrollments (username, id)`,
+
+	// Learner progress and assignments
+	`ALTER TABLE course_enrollments ADD COLUMN IF NOT EXISTS last_accessed_at TIMESTAMPTZ`,
+	`ALTER TABLE course_enrollments ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ`,
+	`ALTER TABLE course_enrollments ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ`,
+	`CREATE TABLE IF NOT EXISTS lesson_progress (
+		lesson_id BIGINT NOT NULL REFERENCES course_lessons (id) ON DELETE CASCADE,
+		username TEXT NOT NULL,
+		completed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (lesson_id, username)
+	)`,
+	`CREATE INDEX IF NOT EXISTS lesson_progress_username_idx ON lesson_progress (username)`,
+	`CREATE TABLE IF NOT EXISTS course_assignments (
+		id BIGSERIAL PRIMARY KEY,
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		title TEXT NOT NULL,
+		instructions TEXT NOT NULL DEFAULT '',
+		due_after_days INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS course_assignments_course_idx ON course_assignments (course_id)`,
+	`CREATE TABLE IF NOT EXISTS assignment_submissions (
+		assignment_id BIGINT NOT NULL REFERENCES course_assignments (id) ON DELETE CASCADE,
+		username TEXT NOT NULL,
+		content TEXT NOT NULL,
+		submitted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (assignment_id, username)
+	)`,
+
+	// Bulk user imports
+	`CREATE TABLE IF NOT EXISTS user_imports (
+		id BIGSERIAL PRIMARY KEY,
+		requested_by TEXT NOT NULL,
+		organization_id BIGINT REFERENCES organizations (id) ON DELETE CASCADE,
+		status TEXT NOT NULL DEFAULT 'running',
+		rows JSONB NOT NULL,
+		total_rows INTEGER NOT NULL,
+		processed_rows INTEGER NOT NULL DEFAULT 0,
+		created_users INTEGER NOT NULL DEFAULT 0,
+		enrollments INTEGER NOT NULL DEFAULT 0,
+		errors JSONB NOT NULL DEFAULT '[]',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		completed_at TIMESTAMPTZ
+	)`,
+	// Finished imports no longer keep the rows they were created with
+	`UPDATE user_imports SET rows = '[]' WHERE completed_at IS NOT NULL AND rows <> '[]'`,
+
+	// Background jobs
+	`CREATE TABLE IF NOT EXISTS jobs (
+		id BIGSERIAL PRIMARY KEY,
+		queue TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		payload JSONB NOT NULL DEFAULT '{}',
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL,
+		run_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		last_error TEXT NOT NULL DEFAULT '',
+		locked_by TEXT NOT NULL DEFAULT '',
+		locked_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		started_at TIMESTAMPTZ,
+		completed_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS jobs_ready_idx ON jobs (queue, run_at) WHERE status = 'pending'`,
+	`CREATE INDEX IF NOT EXISTS jobs_running_idx ON jobs (queue, locked_at) WHERE status = 'running'`,
+
+	// Email outbox and suppression list
+	`CREATE TABLE IF NOT EXISTS email_outbox (
+		id BIGSERIAL PRIMARY KEY,
+		to_address TEXT NOT NULL,
+		template TEXT NOT NULL,
+		locale TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		text_body TEXT NOT NULL DEFAULT '',
+		html_body TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'queued',
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		sent_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS email_outbox_to_idx ON email_outbox (lower(to_address))`,
+	`CREATE TABLE IF NOT EXISTS email_suppressions (
+		email TEXT PRIMARY KEY,
+		reason TEXT NOT NULL,
+		detail TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+
+	// Notifications
+	`CREATE TABLE IF NOT EXISTS notifications (
+		id BIGSERIAL PRIMARY KEY,
+		username TEXT NOT NULL,
+		type TEXT NOT NULL,
+		message TEXT NOT NULL,
+		read_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS notifications_username_idx ON notifications (username, created_at DESC)`,
+	`CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications (username) WHERE read_at IS NULL`,
+	`CREATE TABLE IF NOT EXISTS notification_preferences (
+		username TEXT NOT NULL,
+		type TEXT NOT NULL,
+		in_app BOOLEAN NOT NULL,
+		email BOOLEAN NOT NULL,
+		PRIMARY KEY (username, type)
+	)`,
+
+	// Events streamed to connected clients, kept for a while so reconnecting clients can catch up
+	`CREATE TABLE IF NOT EXISTS stream_events (
+		id BIGSERIAL PRIMARY KEY,
+		username TEXT NOT NULL,
+		type TEXT NOT NULL,
+		data JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS stream_events_username_idx ON stream_events (username, id)`,
+	`CREATE INDEX IF NOT EXISTS stream_events_created_at_idx ON stream_events (created_at)`,
+
+	// Webhooks, fed by an outbox of events written in the transactions that cause them
+	`CREATE TABLE IF NOT EXISTS webhooks (
+		id BIGSERIAL PRIMARY KEY,
+		organization_id BIGINT REFERENCES organizations (id) ON DELETE CASCADE,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT[] NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		active BOOLEAN NOT NULL DEFAULT true,
+		created_by TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		deleted_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS webhooks_organization_idx ON webhooks (organization_id) WHERE deleted_at IS NULL`,
+	`CREATE TABLE IF NOT EXISTS webhook_events (
+		id BIGSERIAL PRIMARY KEY,
+		organization_id BIGINT,
+		type TEXT NOT NULL,
+		data JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		relayed_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS webhook_events_unrelayed_idx ON webhook_events (id) WHERE relayed_at IS NULL`,
+	`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id BIGSERIAL PRIMARY KEY,
+		webhook_id BIGINT NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+		event_id BIGINT,
+		event_type TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'pending',
+		attempts INT NOT NULL DEFAULT 0,
+		response_status INT NOT NULL DEFAULT 0,
+		response_body TEXT NOT NULL DEFAULT '',
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		last_attempt_at TIMESTAMPTZ,
+		delivered_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, id DESC)`,
+
+	// Discussions
+	`CREATE TABLE IF NOT EXISTS discussion_threads (
+		id BIGSERIAL PRIMARY KEY,
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		lesson_id BIGINT REFERENCES course_lessons (id) ON DELETE CASCADE,
+		title TEXT NOT NULL,
+		author TEXT NOT NULL,
+		pinned BOOLEAN NOT NULL DEFAULT false,
+		answer_post_id BIGINT,
+		hidden_at TIMESTAMPTZ,
+		hidden_by TEXT,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		last_activity_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS discussion_threads_course_idx ON discussion_threads (course_id, pinned DESC, last_activity_at DESC)`,
+	`CREATE TABLE IF NOT EXISTS discussion_posts (
+		id BIGSERIAL PRIMARY KEY,
+		thread_id BIGINT NOT NULL REFERENCES discussion_threads (id) ON DELETE CASCADE,
+		parent_id BIGINT REFERENCES discussion_posts (id) ON DELETE CASCADE,
+		author TEXT NOT NULL,
+		body TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		edited_at TIMESTAMPTZ,
+		hidden_at TIMESTAMPTZ,
+		hidden_by TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS discussion_posts_thread_idx ON discussion_posts (thread_id, id)`,
+	`CREATE INDEX IF NOT EXISTS discussion_posts_author_idx ON discussion_posts (author)`,
+	`CREATE TABLE IF NOT EXISTS discussion_post_revisions (
+		id BIGSERIAL PRIMARY KEY,
+		post_id BIGINT NOT NULL REFERENCES discussion_posts (id) ON DELETE CASCADE,
+		body TEXT NOT NULL,
+		edited_by TEXT NOT NULL,
+		edited_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS discussion_post_revisions_post_idx ON discussion_post_revisions (post_id, id)`,
+	`CREATE TABLE IF NOT EXISTS discussion_reactions (
+		post_id BIGINT NOT NULL REFERENCES discussion_posts (id) ON DELETE CASCADE,
+		username TEXT NOT NULL,
+		reaction TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (post_id, username, reaction)
+	)`,
+	`CREATE INDEX IF NOT EXISTS discussion_reactions_username_idx ON discussion_reactions (username)`,
+
+	// Moderation of user-generated content
+	`ALTER TABLE discussion_posts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ`,
+	`ALTER TABLE discussion_posts ADD COLUMN IF NOT EXISTS deleted_by TEXT`,
+	`CREATE TABLE IF NOT EXISTS content_reports (
+		id BIGSERIAL PRIMARY KEY,
+		content_type TEXT NOT NULL,
+		content_id TEXT NOT NULL,
+		course_id UUID REFERENCES courses (id) ON DELETE CASCADE,
+		thread_id BIGINT,
+		author TEXT NOT NULL,
+		excerpt TEXT NOT NULL,
+		reporter TEXT NOT NULL,
+		reason TEXT NOT NULL,
+		details TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'open',
+		resolution TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		resolved_by TEXT,
+		resolved_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS content_reports_open_idx ON content_reports (content_type, content_id, reporter) WHERE status = 'open'`,
+	`CREATE INDEX IF NOT EXISTS content_reports_status_idx ON content_reports (status, created_at)`,
+	`CREATE TABLE IF NOT EXISTS word_filters (
+		id BIGSERIAL PRIMARY KEY,
+		pattern TEXT NOT NULL UNIQUE,
+		action TEXT NOT NULL,
+		created_by TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+
+	// Course ratings and reviews. Courses keep running totals of their ratings so the catalogue can sort by them.
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS rating_total INTEGER NOT NULL DEFAULT 0`,
+	`CREATE TABLE IF NOT EXISTS course_reviews (
+		id BIGSERIAL PRIMARY KEY,
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		username TEXT NOT NULL,
+		rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+		body TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ,
+		reply TEXT NOT NULL DEFAULT '',
+		replied_by TEXT,
+		replied_at TIMESTAMPTZ,
+		UNIQUE (course_id, username)
+	)`,
+	`CREATE INDEX IF NOT EXISTS course_reviews_username_idx ON course_reviews (username)`,
+
+	// Course taxonomy: a category tree managed by admins, free-form tags, subject and grade level
+	`CREATE TABLE IF NOT EXISTS course_categories (
+		id BIGSERIAL PRIMARY KEY,
+		parent_id BIGINT REFERENCES course_categories (id),
+		name TEXT NOT NULL,
+		slug TEXT NOT NULL UNIQUE,
+		position INTEGER NOT NULL DEFAULT 0,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS course_categories_parent_idx ON course_categories (parent_id)`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS category_id BIGINT REFERENCES course_categories (id) ON DELETE SET NULL`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}'`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS subject TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS grade_level TEXT NOT NULL DEFAULT ''`,
+	`CREATE INDEX IF NOT EXISTS courses_category_idx ON courses (category_id)`,
+	`CREATE INDEX IF NOT EXISTS courses_tags_idx ON courses USING GIN (tags)`,
+
+	// Publishing workflow. Courses created before it existed stay published; new ones start as drafts.
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published'`,
+	`ALTER TABLE courses ALTER COLUMN status SET DEFAULT 'draft'`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ`,
+	`CREATE INDEX IF NOT EXISTS courses_status_idx ON courses (status)`,
+	`CREATE TABLE IF NOT EXISTS course_workflow_events (
+		id BIGSERIAL PRIMARY KEY,
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		action TEXT NOT NULL,
+		from_status TEXT NOT NULL,
+		to_status TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		comment TEXT NOT NULL DEFAULT '',
+		publish_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS course_workflow_events_course_idx ON course_workflow_events (course_id, created_at)`,
+
+	// Content versions. Lessons are the working copy instructors edit; removed lessons are kept so that versions and
+	// learner progress can still refer to them. New enrollments are pinned to the course's current version.
+	`ALTER TABLE course_lessons ADD COLUMN IF NOT EXISTS removed_at TIMESTAMPTZ`,
+	`ALTER TABLE course_lessons DROP CONSTRAINT IF EXISTS course_lessons_course_id_position_key`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS course_lessons_position_idx ON course_lessons (course_id, position)
+		WHERE removed_at IS NULL`,
+	`CREATE TABLE IF NOT EXISTS course_versions (
+		course_id UUID NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		number INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL,
+		category_id BIGINT,
+		tags TEXT[] NOT NULL DEFAULT '{}',
+		subject TEXT NOT NULL DEFAULT '',
+		grade_level TEXT NOT NULL DEFAULT '',
+		lessons JSONB NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		created_by TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (course_id, number)
+	)`,
+	`ALTER TABLE courses ADD COLUMN IF NOT EXISTS current_version INTEGER`,
+	`ALTER TABLE course_enrollments ADD COLUMN IF NOT EXISTS version INTEGER`,
+	`CREATE OR REPLACE FUNCTION course_enrollments_pin_version() RETURNS trigger AS $$
+	BEGIN
+		IF NEW.version IS NULL THEN
+			NEW.version := (SELECT current_version FROM courses WHERE id = NEW.id);
+		END IF;
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS course_enrollments_pin_version ON course_enrollments`,
+	`CREATE TRIGGER course_enrollments_pin_version BEFORE INSERT ON course_enrollments
+		FOR EACH ROW EXECUTE FUNCTION course_enrollments_pin_version()`,
+
+	// Course template library
+	`CREATE TABLE IF NOT EXISTS course_templates (
+		course_id UUID PRIMARY KEY REFERENCES courses (id) ON DELETE CASCADE,
+		note TEXT NOT NULL DEFAULT '',
+		added_by TEXT NOT NULL,
+		added_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+}
+
+// Migrate applies the schema statements in order
+func (db *PostgresDatabase) Migrate() error {
+	for _, statement := range schema {
+		if _, err := db.conn.Exec(statement); err != nil {
+			log.Println("Migration error:", err)
+			return fmt.Errorf("failed to apply schema: %w", err)
+		}
+	}
+	fmt.Println("PostgreSQL schema is up to date!")
+	return nil
+}
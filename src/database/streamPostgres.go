@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+// streamEventChannel is the LISTEN/NOTIFY channel announcing new stream events to every replica
+const streamEventChannel = "stream_events"
+
+const streamEventColumns = "id, type, data::text, created_at"
+
+func scanStreamEvent(row rowScanner) (*models.StreamEvent, error) {
+	var event models.StreamEvent
+	var id int64
+	var data string
+	if err := row.Scan(&id, &event.Type, &data, &event.CreatedAt); err != nil {
+		return nil, err
+	}
+	event.Id = strconv.FormatInt(id, 10)
+	event.Data = json.RawMessage(data)
+	return &event, nil
+}
+
+// StreamEventNotice is the payload of a notification on streamEventChannel
+type StreamEventNotice struct {
+	Id       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// AddStreamEvent records an event for a user and announces it to every replica once committed
+func (db *PostgresDatabase) AddStreamEvent(username, eventType string, data json.RawMessage) error {
+	query := `WITH event AS (
+			INSERT INTO stream_events (username, type, data) VALUES ($1, $2, $3) RETURNING id, username
+		)
+		SELECT pg_notify($4, json_build_object('id', id, 'username', username)::text) FROM event`
+	if _, err := db.conn.Exec(query, username, eventType, string(data), streamEventChannel); err != nil {
+		return fmt.Errorf("error adding stream event: %w", err)
+	}
+	return nil
+}
+
+// GetStreamEvent retrieves a single stream event
+func (db *PostgresDatabase) GetStreamEvent(eventId int64) (*models.StreamEvent, error) {
+	event, err := scanStreamEvent(db.conn.QueryRow("SELECT "+streamEventColumns+" FROM stream_events WHERE id = $1", eventId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("stream event not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching stream event: %w", err)
+	}
+	return event, nil
+}
+
+// GetStreamEventsSince retrieves up to limit of a user's events after afterId, oldest first
+func (db *PostgresDatabase) GetStreamEventsSince(username string, afterId int64, limit int) ([]models.StreamEvent, error) {
+	query := "SELECT " + streamEventColumns + " FROM stream_events WHERE username = $1 AND id > $2 ORDER BY id LIMIT $3"
+	rows, err := db.conn.Query(query, username, afterId, limit)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.StreamEvent{}
+	for rows.Next() {
+		event, err := scanStreamEvent(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
+
+// GetLatestStreamEventId returns the id of the user's newest event, or 0 if they have none
+func (db *PostgresDatabase) GetLatestStreamEventId(username string) (int64, error) {
+	var id int64
+	err := db.conn.QueryRow("SELECT COALESCE(max(id), 0) FROM stream_events WHERE username = $1", username).Scan(&id)
+	return id, err
+}
+
+// PruneStreamEvents deletes events created before the given time, after which reconnecting clients can no longer catch up on them
+func (db *PostgresDatabase) PruneStreamEvents(before time.Time) (int64, error) {
+	tag, err := db.conn.Exec("DELETE FROM stream_events WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListenStreamEvents holds a pool connection listening for new stream events, handing each notice to handle, until
+// ctx ends or the connection fails
+func (db *PostgresDatabase) ListenStreamEvents(ctx context.Context, handle func(StreamEventNotice)) error {
+	conn, err := db.conn.Acquire()
+	if err != nil {
+		return err
+	}
+	defer db.conn.Release(conn)
+
+	if err := conn.Listen(streamEventChannel); err != nil {
+		return err
+	}
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var notice StreamEventNotice
+		if err := json.Unmarshal([]byte(notification.Payload), &notice); err != nil {
+			log.Println("Invalid stream event notice:", err)
+			continue
+		}
+		handle(notice)
+	}
+}
@@ -0,0 +1,297 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+const courseCategoryColumns = "id, COALESCE(parent_id::text, ''), name, slug, position, created_at"
+
+// categorySubtree selects the category passed as parameter $n and all of its descendants
+func categorySubtree(n int) string {
+	return fmt.Sprintf(`WITH RECURSIVE subtree AS (
+			SELECT id FROM course_categories WHERE id = $%d::bigint
+			UNION ALL SELECT k.id FROM course_categories k JOIN subtree s ON k.parent_id = s.id
+		) SELECT id FROM subtree`, n)
+}
+
+// catalogueConditions builds the WHERE conditions of a catalogue query on the published courses aliased c, with the
+// tenant as parameter $1, appending the filter values to args. The filter on the facet named skip is left out.
+func catalogueConditions(filter models.CourseSearch, skip string, args *[]interface{}) string {
+	conditions := []string{courseVisibleToTenant(1), "c.status = 'published'"}
+	if filter.Category != "" && skip != "category" {
+		if _, err := strconv.ParseInt(filter.Category, 10, 64); err != nil {
+			conditions = append(conditions, "false")
+		} else {
+			*args = append(*args, filter.Category)
+			conditions = append(conditions, "c.category_id IN ("+categorySubtree(len(*args))+")")
+		}
+	}
+	if filter.Tag != "" && skip != "tag" {
+		*args = append(*args, strings.ToLower(filter.Tag))
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(c.tags)", len(*args)))
+	}
+	if filter.Subject != "" && skip != "subject" {
+		*args = append(*args, filter.Subject)
+		conditions = append(conditions, fmt.Sprintf("c.subject = $%d", len(*args)))
+	}
+	if filter.GradeLevel != "" && skip != "gradeLevel" {
+		*args = append(*args, filter.GradeLevel)
+		conditions = append(conditions, fmt.Sprintf("c.grade_level = $%d", len(*args)))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// facetCounts runs a query returning facet values and their counts
+func (db *PostgresDatabase) facetCounts(query string, args []interface{}) ([]models.FacetCount, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []models.FacetCount{}
+	for rows.Next() {
+		var count models.FacetCount
+		var n int64
+		if err := rows.Scan(&count.Value, &n); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		count.Count = int(n)
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+// GetCatalogueFacets counts the catalogue courses visible to the tenant for each category, tag, subject and grade
+// level, applying every filter but the one on the facet being counted
+func (db *PostgresDatabase) GetCatalogueFacets(tenant string, filter models.CourseSearch) (*models.CatalogueFacets, error) {
+	var facets models.CatalogueFacets
+	var err error
+
+	args := []interface{}{tenant}
+	where := catalogueConditions(filter, "category", &args)
+	facets.Categories, err = db.facetCounts(`WITH RECURSIVE tree (ancestor, id) AS (
+			SELECT id, id FROM course_categories
+			UNION ALL SELECT t.ancestor, k.id FROM tree t JOIN course_categories k ON k.parent_id = t.id
+		)
+		SELECT t.ancestor::text, count(DISTINCT c.id) FROM tree t JOIN courses c ON c.category_id = t.id
+		WHERE `+where+` GROUP BY t.ancestor ORDER BY t.ancestor`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	args = []interface{}{tenant}
+	where = catalogueConditions(filter, "tag", &args)
+	facets.Tags, err = db.facetCounts(`SELECT tag, count(*) FROM courses c, unnest(c.tags) tag
+		WHERE `+where+` GROUP BY tag ORDER BY count(*) DESC, tag LIMIT 50`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	args = []interface{}{tenant}
+	where = catalogueConditions(filter, "subject", &args)
+	facets.Subjects, err = db.facetCounts(`SELECT c.subject, count(*) FROM courses c
+		WHERE `+where+` AND c.subject <> '' GROUP BY c.subject ORDER BY c.subject`, args)
+	if err != nil {
+		return nil, err
+	}
+
+	args = []interface{}{tenant}
+	where = catalogueConditions(filter, "gradeLevel", &args)
+	facets.GradeLevels, err = db.facetCounts(`SELECT c.grade_level, count(*) FROM courses c
+		WHERE `+where+` AND c.grade_level <> '' GROUP BY c.grade_level ORDER BY c.grade_level`, args)
+	if err != nil {
+		return nil, err
+	}
+	return &facets, nil
+}
+
+func scanCourseCategory(row rowScanner) (*models.CourseCategory, error) {
+	var category models.CourseCategory
+	var id int64
+	var position int32
+	if err := row.Scan(&id, &category.ParentId, &category.Name, &category.Slug, &position, &category.CreatedAt); err != nil {
+		return nil, err
+	}
+	category.Id = strconv.FormatInt(id, 10)
+	category.Position = int(position)
+	return &category, nil
+}
+
+// GetCourseCategories retrieves the whole category tree as a list, parents before their children and siblings in
+// position order, each with its path from the root
+func (db *PostgresDatabase) GetCourseCategories() ([]models.CourseCategory, error) {
+	rows, err := db.conn.Query("SELECT " + courseCategoryColumns + " FROM course_categories ORDER BY position, name")
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	children := map[string][]models.CourseCategory{}
+	for rows.Next() {
+		category, err := scanCourseCategory(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		children[category.ParentId] = append(children[category.ParentId], *category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	categories := []models.CourseCategory{}
+	var walk func(parentId, parentPath string)
+	walk = func(parentId, parentPath string) {
+		for _, category := range children[parentId] {
+			category.Path = category.Name
+			if parentPath != "" {
+				category.Path = parentPath + " > " + category.Name
+			}
+			categories = append(categories, category)
+			walk(category.Id, category.Path)
+		}
+	}
+	walk("", "")
+	return categories, nil
+}
+
+// GetCourseCategory retrieves a category
+func (db *PostgresDatabase) GetCourseCategory(categoryId string) (*models.CourseCategory, error) {
+	if _, err := strconv.ParseInt(categoryId, 10, 64); err != nil {
+		return nil, fmt.Errorf("category not found")
+	}
+	category, err := scanCourseCategory(db.conn.QueryRow("SELECT "+courseCategoryColumns+" FROM course_categories WHERE id = $1", categoryId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching category: %w", err)
+	}
+	return category, nil
+}
+
+// GetCourseCategoryBySlug retrieves a category by its slug
+func (db *PostgresDatabase) GetCourseCategoryBySlug(slug string) (*models.CourseCategory, error) {
+	category, err := scanCourseCategory(db.conn.QueryRow("SELECT "+courseCategoryColumns+" FROM course_categories WHERE slug = $1", slug))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching category: %w", err)
+	}
+	return category, nil
+}
+
+// AddCourseCategory creates a category. Its parent, if any, must exist.
+func (db *PostgresDatabase) AddCourseCategory(add models.AddCourseCategory) (*models.CourseCategory, error) {
+	query := `INSERT INTO course_categories (parent_id, name, slug, position) VALUES (NULLIF($1, '')::bigint, $2, $3, $4)
+		ON CONFLICT (slug) DO NOTHING
+		RETURNING ` + courseCategoryColumns
+	category, err := scanCourseCategory(db.conn.QueryRow(query, add.ParentId, add.Name, add.Slug, add.Position))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("category slug '%s' is already in use", add.Slug)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add category: %w", err)
+	}
+	return category, nil
+}
+
+// UpdateCourseCategory renames, moves or reorders a category. A category cannot be moved under itself or one of its
+// descendants.
+func (db *PostgresDatabase) UpdateCourseCategory(categoryId string, update models.AddCourseCategory) (*models.CourseCategory, error) {
+	if _, err := strconv.ParseInt(categoryId, 10, 64); err != nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if update.ParentId != "" {
+		var cycle bool
+		err = tx.QueryRow("SELECT $2::bigint IN ("+categorySubtree(1)+")", categoryId, update.ParentId).Scan(&cycle)
+		if err != nil {
+			return nil, err
+		}
+		if cycle {
+			return nil, fmt.Errorf("a category cannot be moved under itself or one of its subcategories")
+		}
+	}
+
+	var taken bool
+	err = tx.QueryRow("SELECT EXISTS (SELECT 1 FROM course_categories WHERE slug = $1 AND id <> $2)", update.Slug, categoryId).Scan(&taken)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, fmt.Errorf("category slug '%s' is already in use", update.Slug)
+	}
+
+	query := `UPDATE course_categories SET parent_id = NULLIF($2, '')::bigint, name = $3, slug = $4, position = $5
+		WHERE id = $1 RETURNING ` + courseCategoryColumns
+	category, err := scanCourseCategory(tx.QueryRow(query, categoryId, update.ParentId, update.Name, update.Slug, update.Position))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCourseCategory removes a category that has no subcategories and returns it. Its courses become
+// uncategorised.
+func (db *PostgresDatabase) DeleteCourseCategory(categoryId string) (*models.CourseCategory, error) {
+	if _, err := strconv.ParseInt(categoryId, 10, 64); err != nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	var hasChildren bool
+	err := db.conn.QueryRow("SELECT EXISTS (SELECT 1 FROM course_categories WHERE parent_id = $1)", categoryId).Scan(&hasChildren)
+	if err != nil {
+		return nil, err
+	}
+	if hasChildren {
+		return nil, fmt.Errorf("move or delete the subcategories of this category first")
+	}
+
+	category, err := scanCourseCategory(db.conn.QueryRow("DELETE FROM course_categories WHERE id = $1 RETURNING "+courseCategoryColumns, categoryId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete category: %w", err)
+	}
+	return category, nil
+}
+
+// SetCourseTaxonomy replaces the category, tags, subject and grade level of a course
+func (db *PostgresDatabase) SetCourseTaxonomy(courseId string, set models.SetCourseTaxonomy) error {
+	query := `UPDATE courses SET category_id = NULLIF($2, '')::bigint, tags = $3::text[], subject = $4, grade_level = $5
+		WHERE id = $1`
+	tag, err := db.conn.Exec(query, courseId, set.CategoryId, set.Tags, set.Subject, set.GradeLevel)
+	if err != nil {
+		return fmt.Errorf("failed to classify course: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("course not found")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// CloneCourse copies a course's details, lessons and assignments into a new draft course of the tenant. Enrollments,
+// cohorts, submissions, discussions, reviews and versions stay with the original.
+func (db *PostgresDatabase) CloneCourse(tenant, sourceId, title, visibility string) (*models.CoursePostgres, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id pgtype.UUID
+	err = tx.QueryRow(`INSERT INTO courses (title, description, organization_id, visibility, cohort_based, capacity,
+			enrollment_policy, category_id, tags, subject, grade_level)
+		SELECT $2::text, s.description, NULLIF($3::text, '')::bigint, $4::text, s.cohort_based, s.capacity,
+			s.enrollment_policy, s.category_id, s.tags, s.subject, s.grade_level
+		FROM courses s WHERE s.id = $1
+		RETURNING id`, sourceId, title, tenant, visibility).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("course with ID '%s' does not exist", sourceId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone course: %w", err)
+	}
+	cloneId := fmt.Sprintf("%x", id.Bytes)
+
+	_, err = tx.Exec(`INSERT INTO course_lessons (course_id, position, title, content, release_after_days)
+		SELECT $2::uuid, position, title, content, release_after_days FROM course_lessons
+		WHERE course_id = $1 AND removed_at IS NULL`, sourceId, cloneId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone lessons: %w", err)
+	}
+	_, err = tx.Exec(`INSERT INTO course_assignments (course_id, title, instructions, due_after_days)
+		SELECT $2::uuid, title, instructions, due_after_days FROM course_assignments
+		WHERE course_id = $1 ORDER BY id`, sourceId, cloneId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone assignments: %w", err)
+	}
+
+	clone, err := scanCourse(tx.QueryRow("SELECT "+courseColumns+" FROM courses c WHERE c.id = $1", cloneId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cloned course: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// courseTemplateFrom joins each template aliased t to its course aliased c
+const courseTemplateFrom = `FROM course_templates t JOIN courses c ON c.id = t.course_id`
+
+func scanCourseTemplate(row rowScanner) (*models.CourseTemplate, error) {
+	var template models.CourseTemplate
+	var lessons, assignments int64
+	course, err := scanCourse(rowScannerFunc(func(dest ...interface{}) error {
+		return row.Scan(append(dest, &template.Note, &template.AddedBy, &template.AddedAt, &lessons, &assignments)...)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	template.Course = *course
+	template.LessonCount = int(lessons)
+	template.AssignmentCount = int(assignments)
+	return &template, nil
+}
+
+const courseTemplateColumns = courseColumns + `, t.note, t.added_by, t.added_at,
+	(SELECT count(*) FROM course_lessons l WHERE l.course_id = c.id AND l.removed_at IS NULL),
+	(SELECT count(*) FROM course_assignments a WHERE a.course_id = c.id)`
+
+// GetCourseTemplates lists the template library, filtered by subject and grade level
+func (db *PostgresDatabase) GetCourseTemplates(filter models.CourseTemplateSearch) ([]models.CourseTemplate, error) {
+	query := "SELECT " + courseTemplateColumns + " " + courseTemplateFrom + `
+		WHERE ($1 = '' OR c.subject = $1) AND ($2 = '' OR c.grade_level = $2)
+		ORDER BY c.subject, c.title, c.id`
+	rows, err := db.conn.Query(query, filter.Subject, filter.GradeLevel)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []models.CourseTemplate{}
+	for rows.Next() {
+		template, err := scanCourseTemplate(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	return templates, rows.Err()
+}
+
+// GetCourseTemplate retrieves a template by the id of its course
+func (db *PostgresDatabase) GetCourseTemplate(courseId string) (*models.CourseTemplate, error) {
+	query := "SELECT " + courseTemplateColumns + " " + courseTemplateFrom + " WHERE t.course_id = $1"
+	template, err := scanCourseTemplate(db.conn.QueryRow(query, courseId))
+	if err != nil {
+		log.Println("QueryRow error:", err)
+		return nil, fmt.Errorf("template not found")
+	}
+	return template, nil
+}
+
+// AddCourseTemplate adds a course to the template library, or updates its note when it is already there
+func (db *PostgresDatabase) AddCourseTemplate(add models.AddCourseTemplate, addedBy string) (*models.CourseTemplate, error) {
+	tag, err := db.conn.Exec(`INSERT INTO course_templates (course_id, note, added_by)
+		SELECT c.id, $2::text, $3::text FROM courses c WHERE c.id = $1
+		ON CONFLICT (course_id) DO UPDATE SET note = EXCLUDED.note`, add.CourseId, add.Note, addedBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add template: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("course with ID '%s' does not exist", add.CourseId)
+	}
+	return db.GetCourseTemplate(add.CourseId)
+}
+
+// DeleteCourseTemplate removes a course from the template library. The course itself is left alone.
+func (db *PostgresDatabase) DeleteCourseTemplate(courseId string) error {
+	tag, err := db.conn.Exec("DELETE FROM course_templates WHERE course_id = $1", courseId)
+	if err != nil {
+		return fmt.Errorf("failed to remove template: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("template not found")
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+const userImportColumns = `id, requested_by, COALESCE(organization_id::text, ''), status, total_rows, processed_rows,
+	created_users, enrollments, errors::text, created_at, completed_at`
+
+func scanUserImport(row rowScanner) (*models.UserImport, error) {
+	var userImport models.UserImport
+	var id int64
+	var totalRows, processedRows, createdUsers, enrollments int32
+	var errors string
+	err := row.Scan(&id, &userImport.RequestedBy, &userImport.OrganizationId, &userImport.Status, &totalRows, &processedRows,
+		&createdUsers, &enrollments, &errors, &userImport.CreatedAt, &userImport.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+	userImport.Id = strconv.FormatInt(id, 10)
+	userImport.TotalRows = int(totalRows)
+	userImport.ProcessedRows = int(processedRows)
+	userImport.CreatedUsers = int(createdUsers)
+	userImport.Enrollments = int(enrollments)
+	if err := json.Unmarshal([]byte(errors), &userImport.Errors); err != nil {
+		return nil, fmt.Errorf("error decoding import errors: %w", err)
+	}
+	return &userImport, nil
+}
+
+// GetImportAccounts retrieves in one query the accounts using one of the emails, and the live accounts using one of
+// the usernames, so the rows of an import can be checked against them
+func (db *PostgresDatabase) GetImportAccounts(emails, usernames []string) ([]models.UserPostgres, error) {
+	rows, err := db.conn.Query(`SELECT id, username, email FROM users
+		WHERE email = ANY($1::text[]) OR (username = ANY($2::text[]) AND deleted_at IS NULL)`, emails, usernames)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []models.UserPostgres{}
+	for rows.Next() {
+		var account models.UserPostgres
+		var id int
+		if err := rows.Scan(&id, &account.Username, &account.Email); err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		account.Id = strconv.Itoa(id)
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+// CreateUserImport records a validated import, keeping its rows so it can be processed in the background
+func (db *PostgresDatabase) CreateUserImport(requestedBy, tenant string, rows []models.UserImportRow) (*models.UserImport, error) {
+	encoded, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+	query := `INSERT INTO user_imports (requested_by, organization_id, rows, total_rows)
+		VALUES ($1, NULLIF($2, '')::bigint, $3::jsonb, $4) RETURNING ` + userImportColumns
+	userImport, err := scanUserImport(db.conn.QueryRow(query, requestedBy, tenant, string(encoded), len(rows)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating user import: %w", err)
+	}
+	return userImport, nil
+}
+
+// GetUserImport retrieves the progress of an import
+func (db *PostgresDatabase) GetUserImport(importId string) (*models.UserImport, error) {
+	query := "SELECT " + userImportColumns + " FROM user_imports WHERE id = $1"
+	userImport, err := scanUserImport(db.conn.QueryRow(query, importId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("user import not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching user import: %w", err)
+	}
+	return userImport, nil
+}
+
+// GetUserImportRows retrieves the rows an import was created with
+func (db *PostgresDatabase) GetUserImportRows(importId string) ([]models.UserImportRow, error) {
+	var encoded string
+	if err := db.conn.QueryRow("SELECT rows::text FROM user_imports WHERE id = $1", importId).Scan(&encoded); err != nil {
+		return nil, fmt.Errorf("error fetching user import rows: %w", err)
+	}
+	var rows []models.UserImportRow
+	if err := json.Unmarshal([]byte(encoded), &rows); err != nil {
+		return nil, fmt.Errorf("error decoding user import rows: %w", err)
+	}
+	return rows, nil
+}
+
+// UpdateUserImportProgress records how far an import has got
+func (db *PostgresDatabase) UpdateUserImportProgress(userImport models.UserImport) error {
+	encoded, err := json.Marshal(userImport.Errors)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE user_imports SET processed_rows = $2, created_users = $3, enrollments = $4, errors = $5::jsonb
+		WHERE id = $1`
+	_, err = db.conn.Exec(query, userImport.Id, userImport.ProcessedRows, userImport.CreatedUsers, userImport.Enrollments, string(encoded))
+	return err
+}
+
+// FinishUserImport marks an import completed or failed. Its rows are dropped, since they hold the email and name of
+// everyone imported and are no longer needed.
+func (db *PostgresDatabase) FinishUserImport(importId, status string) error {
+	_, err := db.conn.Exec("UPDATE user_imports SET status = $2, completed_at = now(), rows = '[]' WHERE id = $1", importId, status)
+	return err
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+// courseVersionColumns reads a version aliased v, followed by its lessons or NULL when they are left out
+const courseVersionColumns = `v.course_id, v.number, v.title, v.description, COALESCE(v.category_id::text, ''), v.tags,
+	v.subject, v.grade_level, v.note, v.created_by, v.created_at, jsonb_array_length(v.lessons),
+	(SELECT count(*) FROM course_enrollments e WHERE e.id = v.course_id AND e.version = v.number)`
+
+func scanCourseVersion(row rowScanner) (*models.CourseVersion, error) {
+	var version models.CourseVersion
+	var courseId pgtype.UUID
+	var number, lessonCount int32
+	var pinned int64
+	var lessons *string
+	err := row.Scan(&courseId, &number, &version.Title, &version.Description, &version.CategoryId, &version.Tags,
+		&version.Subject, &version.GradeLevel, &version.Note, &version.CreatedBy, &version.CreatedAt, &lessonCount, &pinned,
+		&lessons)
+	if err != nil {
+		return nil, err
+	}
+	version.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+	version.Number = int(number)
+	version.LessonCount = int(lessonCount)
+	version.PinnedEnrollments = int(pinned)
+	if lessons != nil {
+		if err := json.Unmarshal([]byte(*lessons), &version.Lessons); err != nil {
+			return nil, fmt.Errorf("failed to read version lessons: %w", err)
+		}
+	}
+	return &version, nil
+}
+
+// publishCourseVersion snapshots the working copy of a course as its next version, makes it the version new
+// enrollments are pinned to, and pins the enrollments that predate versioning to it, since the snapshot is what they
+// have been seeing. The course must be locked by tx, so that its versions are numbered one at a time.
+func publishCourseVersion(tx *pgx.Tx, courseId, note, createdBy string) (int, error) {
+	query := `INSERT INTO course_versions (course_id, number, title, description, category_id, tags, subject, grade_level,
+			lessons, note, created_by)
+		SELECT c.id, COALESCE((SELECT max(number) FROM course_versions WHERE course_id = c.id), 0) + 1, c.title,
+			c.description, c.category_id, c.tags, c.subject, c.grade_level,
+			COALESCE((SELECT jsonb_agg(jsonb_build_object('id', l.id::text, 'position', l.position, 'title', l.title,
+				'content', l.content, 'releaseAfterDays', l.release_after_days) ORDER BY l.position)
+				FROM course_lessons l WHERE l.course_id = c.id AND l.removed_at IS NULL), '[]'::jsonb),
+			$2::text, $3::text
+		FROM courses c WHERE c.id = $1
+		RETURNING number`
+	var number int32
+	if err := tx.QueryRow(query, courseId, note, createdBy).Scan(&number); err != nil {
+		return 0, fmt.Errorf("failed to publish course version: %w", err)
+	}
+
+	if _, err := tx.Exec("UPDATE courses SET current_version = $2 WHERE id = $1", courseId, number); err != nil {
+		return 0, fmt.Errorf("failed to update current version: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE course_enrollments SET version = $2 WHERE id = $1 AND version IS NULL", courseId, number); err != nil {
+		return 0, fmt.Errorf("failed to pin enrollments: %w", err)
+	}
+	return int(number), nil
+}
+
+// PublishCourseVersion snapshots the working copy of a course visible to the tenant as a new version, which new
+// enrollments are pinned to
+func (db *PostgresDatabase) PublishCourseVersion(tenant, courseId, note, createdBy string) (*models.CourseVersion, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockCourse(tx, tenant, courseId); err != nil {
+		return nil, err
+	}
+	number, err := publishCourseVersion(tx, courseId, note, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return db.GetCourseVersion(courseId, number)
+}
+
+// RollbackCourseVersion restores the working copy of a course visible to the tenant to an earlier version and
+// publishes it as a new version. Lessons added since are removed from the working copy, and lessons removed since are
+// restored.
+func (db *PostgresDatabase) RollbackCourseVersion(tenant, courseId string, number int, note, createdBy string) (*models.CourseVersion, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := lockCourse(tx, tenant, courseId); err != nil {
+		return nil, err
+	}
+
+	tag, err := tx.Exec(`UPDATE courses c SET title = v.title, description = v.description,
+			category_id = (SELECT k.id FROM course_categories k WHERE k.id = v.category_id),
+			tags = v.tags, subject = v.subject, grade_level = v.grade_level
+		FROM course_versions v WHERE c.id = $1 AND v.course_id = c.id AND v.number = $2`, courseId, int32(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore course details: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("version %d of course '%s' does not exist", number, courseId)
+	}
+
+	// Every lesson is removed before the version's lessons are restored, so that restored positions cannot collide
+	// with those of lessons being removed
+	if _, err := tx.Exec("UPDATE course_lessons SET removed_at = now() WHERE course_id = $1 AND removed_at IS NULL", courseId); err != nil {
+		return nil, fmt.Errorf("failed to clear lessons: %w", err)
+	}
+	_, err = tx.Exec(`UPDATE course_lessons l SET removed_at = NULL, position = (s->>'position')::int, title = s->>'title',
+			content = s->>'content', release_after_days = (s->>'releaseAfterDays')::int
+		FROM course_versions v, jsonb_array_elements(v.lessons) s
+		WHERE v.course_id = $1 AND v.number = $2 AND l.course_id = $1 AND l.id = (s->>'id')::bigint`, courseId, int32(number))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore lessons: %w", err)
+	}
+
+	published, err := publishCourseVersion(tx, courseId, note, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return db.GetCourseVersion(courseId, published)
+}
+
+// GetCourseVersions lists the versions of a course, newest first, without their lessons
+func (db *PostgresDatabase) GetCourseVersions(courseId string) ([]models.CourseVersion, error) {
+	rows, err := db.conn.Query("SELECT "+courseVersionColumns+", NULL::text FROM course_versions v WHERE v.course_id = $1 ORDER BY v.number DESC", courseId)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.CourseVersion{}
+	for rows.Next() {
+		version, err := scanCourseVersion(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		versions = append(versions, *version)
+	}
+	return versions, rows.Err()
+}
+
+// GetCourseVersion retrieves a version of a course with its lessons
+func (db *PostgresDatabase) GetCourseVersion(courseId string, number int) (*models.CourseVersion, error) {
+	query := "SELECT " + courseVersionColumns + ", v.lessons::text FROM course_versions v WHERE v.course_id = $1 AND v.number = $2"
+	version, err := scanCourseVersion(db.conn.QueryRow(query, courseId, int32(number)))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("version %d of course '%s' does not exist", number, courseId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching course version: %w", err)
+	}
+	return version, nil
+}
+
+// GetEnrollmentVersion retrieves the version the user's enrollment in a course is pinned to, along with the course's
+// current version
+func (db *PostgresDatabase) GetEnrollmentVersion(username, courseId string) (*models.EnrollmentVersion, error) {
+	query := `SELECT e.version, c.current_version FROM course_enrollments e JOIN courses c ON c.id = e.id
+		WHERE e.username = $1 AND e.id = $2`
+	var pinned, current *int32
+	err := db.conn.QueryRow(query, username, courseId).Scan(&pinned, &current)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("user '%s' is not enrolled in course '%s'", username, courseId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching enrollment version: %w", err)
+	}
+	version := models.EnrollmentVersion{Pinned: intFromNullable(pinned), Current: intFromNullable(current)}
+	version.UpgradeAvailable = current != nil && (pinned == nil || *pinned < *current)
+	return &version, nil
+}
+
+// UpgradeEnrollmentVersion pins the user's enrollment in a course to the course's current version
+func (db *PostgresDatabase) UpgradeEnrollmentVersion(username, courseId string) error {
+	tag, err := db.conn.Exec(`UPDATE course_enrollments e SET version = c.current_version FROM courses c
+		WHERE c.id = e.id AND e.username = $1 AND e.id = $2 AND c.current_version IS NOT NULL`, username, courseId)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade enrollment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user '%s' is not enrolled in course '%s', or it has no published version", username, courseId)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+// lockCourse locks a course visible to the tenant for the rest of the transaction and returns its capacity.
+// Holding the lock serialises enrollments in the course, so two learners can never take the same seat.
+func lockCourse(tx *pgx.Tx, tenant, courseId string) (*int32, error) {
+	course, err := lockCourseRow(tx, tenant, courseId)
+	if err != nil {
+		return nil, err
+	}
+	return nullableFromInt(course.Capacity), nil
+}
+
+// lockCourseRow locks a course visible to the tenant like lockCourse does and returns the locked course
+func lockCourseRow(tx *pgx.Tx, tenant, courseId string) (*models.CoursePostgres, error) {
+	query := "SELECT " + courseColumns + " FROM courses c WHERE c.id = $2 AND " + courseVisibleToTenant(1) + " FOR UPDATE"
+	course, err := scanCourse(tx.QueryRow(query, tenant, courseId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("course with ID '%s' does not exist", courseId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error locking course: %w", err)
+	}
+	return course, nil
+}
+
+// seatAvailable reports whether a locked course, and the cohort when one is given, can take another learner
+func seatAvailable(tx *pgx.Tx, courseId, cohortId string, courseCapacity *int32) (bool, error) {
+	if courseCapacity != nil {
+		var enrolled int64
+		if err := tx.QueryRow("SELECT count(*) FROM course_enrollments WHERE id = $1", courseId).Scan(&enrolled); err != nil {
+			return false, err
+		}
+		if enrolled >= int64(*courseCapacity) {
+			return false, nil
+		}
+	}
+	if cohortId == "" {
+		return true, nil
+	}
+
+	var cohortCapacity *int32
+	err := tx.QueryRow("SELECT capacity FROM cohorts WHERE id = $1 AND course_id = $2 AND ends_at > now()",
+		cohortId, courseId).Scan(&cohortCapacity)
+	if err == pgx.ErrNoRows {
+		return false, fmt.Errorf("cohort '%s' of course '%s' is closed", cohortId, courseId)
+	}
+	if err != nil {
+		return false, err
+	}
+	if cohortCapacity == nil {
+		return true, nil
+	}
+	var enrolled int64
+	if err := tx.QueryRow("SELECT count(*) FROM course_enrollments WHERE cohort_id = $1", cohortId).Scan(&enrolled); err != nil {
+		return false, err
+	}
+	return enrolled < int64(*cohortCapacity), nil
+}
+
+// enrollOrWaitlist enrolls a user in a locked course when a seat is free, otherwise putting them on its waitlist.
+// It returns the user's waitlist position, or 0 once they are enrolled.
+func enrollOrWaitlist(tx *pgx.Tx, organizationId, username, courseId, cohortId string, courseCapacity *int32) (int, error) {
+	hasSeat, err := seatAvailable(tx, courseId, cohortId, courseCapacity)
+	if err != nil {
+		return 0, err
+	}
+	if !hasSeat {
+		return joinWaitlist(tx, organizationId, username, courseId, cohortId)
+	}
+
+	tag, err := tx.Exec(`INSERT INTO course_enrollments (username, id, organization_id, cohort_id)
+		VALUES ($1, $2, NULLIF($3, '')::bigint, NULLIF($4, '')::bigint)
+		ON CONFLICT (username, id) DO NOTHING`, username, courseId, organizationId, cohortId)
+	if err != nil {
+		log.Println("Insert error:", err)
+		return 0, err
+	}
+	if tag.RowsAffected() > 0 {
+		if err := addEnrollmentWebhookEvent(tx, organizationId, models.WebhookEventEnrollmentCreated, username, courseId); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := tx.Exec("DELETE FROM course_waitlist WHERE course_id = $1 AND username = $2", courseId, username); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// joinWaitlist adds a user to the end of a course's waitlist, unless they are already on it, and returns their
+// position among the learners waiting for the same cohort
+func joinWaitlist(tx *pgx.Tx, organizationId, username, courseId, cohortId string) (int, error) {
+	_, err := tx.Exec(`INSERT INTO course_waitlist (course_id, cohort_id, username, organization_id)
+		VALUES ($1, NULLIF($2, '')::bigint, $3, NULLIF($4, '')::bigint)
+		ON CONFLICT (course_id, username) DO NOTHING`, courseId, cohortId, username, organizationId)
+	if err != nil {
+		log.Println("Insert error:", err)
+		return 0, fmt.Errorf("failed to join waitlist: %w", err)
+	}
+
+	query := `SELECT count(*) FROM course_waitlist w
+		JOIN course_waitlist mine ON mine.course_id = w.course_id AND mine.username = $2
+		WHERE w.course_id = $1 AND w.cohort_id IS NOT DISTINCT FROM mine.cohort_id AND w.id <= mine.id`
+	var position int64
+	if err := tx.QueryRow(query, courseId, username).Scan(&position); err != nil {
+		return 0, fmt.Errorf("error fetching waitlist position: %w", err)
+	}
+	return int(position), nil
+}
+
+type waitlistEntry struct {
+	id             int64
+	username       string
+	cohortId       string
+	organizationId string
+}
+
+// promoteFromWaitlist enrolls the longest waiting learner of a locked course who fits in a free seat and returns
+// their username, or "" if nobody fits
+func promoteFromWaitlist(tx *pgx.Tx, courseId string, courseCapacity *int32) (string, error) {
+	rows, err := tx.Query(`SELECT id, username, COALESCE(cohort_id::text, ''), COALESCE(organization_id::text, '')
+		FROM course_waitlist WHERE course_id = $1 ORDER BY id`, courseId)
+	if err != nil {
+		return "", err
+	}
+	var entries []waitlistEntry
+	for rows.Next() {
+		var entry waitlistEntry
+		if err := rows.Scan(&entry.id, &entry.username, &entry.cohortId, &entry.organizationId); err != nil {
+			rows.Close()
+			return "", err
+		}
+		entries = append(entries, entry)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		hasSeat, err := seatAvailable(tx, courseId, entry.cohortId, courseCapacity)
+		if err != nil {
+			// The cohort has ended since the learner joined its waitlist
+			continue
+		}
+		if !hasSeat {
+			continue
+		}
+
+		_, err = tx.Exec(`INSERT INTO course_enrollments (username, id, organization_id, cohort_id)
+			VALUES ($1, $2, NULLIF($3, '')::bigint, NULLIF($4, '')::bigint)`,
+			entry.username, courseId, entry.organizationId, entry.cohortId)
+		if err != nil {
+			return "", fmt.Errorf("failed to promote from waitlist: %w", err)
+		}
+		if _, err := tx.Exec("DELETE FROM course_waitlist WHERE id = $1", entry.id); err != nil {
+			return "", fmt.Errorf("failed to promote from waitlist: %w", err)
+		}
+		err = addEnrollmentWebhookEvent(tx, entry.organizationId, models.WebhookEventEnrollmentCreated, entry.username, courseId)
+		if err != nil {
+			return "", err
+		}
+		return entry.username, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,382 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+)
+
+const webhookColumns = "id, url, events, description, active, created_by, created_at"
+
+const webhookDeliveryColumns = `id, webhook_id, COALESCE(event_id::text, ''), event_type, status, attempts, response_status,
+	response_body, last_error, created_at, last_attempt_at, delivered_at`
+
+// webhookOfTenant matches webhooks belonging to the tenant in parameter $n, the platform itself when it is empty
+func webhookOfTenant(n int) string {
+	return fmt.Sprintf("organization_id IS NOT DISTINCT FROM NULLIF($%d, '')::bigint AND deleted_at IS NULL", n)
+}
+
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var id int64
+	err := row.Scan(&id, &webhook.URL, &webhook.Events, &webhook.Description, &webhook.Active, &webhook.CreatedBy, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Id = strconv.FormatInt(id, 10)
+	return &webhook, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var id, webhookId int64
+	var attempts, responseStatus int32
+	err := row.Scan(&id, &webhookId, &delivery.EventId, &delivery.EventType, &delivery.Status, &attempts, &responseStatus,
+		&delivery.ResponseBody, &delivery.LastError, &delivery.CreatedAt, &delivery.LastAttemptAt, &delivery.DeliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Id = strconv.FormatInt(id, 10)
+	delivery.WebhookId = strconv.FormatInt(webhookId, 10)
+	delivery.Attempts = int(attempts)
+	delivery.ResponseStatus = int(responseStatus)
+	return &delivery, nil
+}
+
+// enrollmentWebhookData is the data of a webhook event about the enrollment of user u in course c
+const enrollmentWebhookData = `jsonb_build_object(
+			'courseId', replace(c.id::text, '-', ''),
+			'courseTitle', c.title,
+			'username', u.username,
+			'email', u.email
+		)`
+
+// addEnrollmentWebhookEvent writes an event about a user's enrollment in a course to the webhook outbox, so that
+// it is sent if and only if tx commits
+func addEnrollmentWebhookEvent(tx *pgx.Tx, organizationId, eventType, username, courseId string) error {
+	_, err := tx.Exec(`INSERT INTO webhook_events (organization_id, type, data)
+		SELECT NULLIF($1, '')::bigint, $2, `+enrollmentWebhookData+`
+		FROM courses c, users u WHERE c.id = $3 AND u.username = $4`, organizationId, eventType, courseId, username)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook event: %w", err)
+	}
+	return nil
+}
+
+// removeEnrollments removes all of a user's enrollments, writing an enrollment.removed event to the webhook outbox
+// for each
+func removeEnrollments(tx *pgx.Tx, username string) error {
+	_, err := tx.Exec(`WITH removed AS (
+			DELETE FROM course_enrollments WHERE username = $1 RETURNING id, organization_id
+		)
+		INSERT INTO webhook_events (organization_id, type, data)
+		SELECT r.organization_id, $2, `+enrollmentWebhookData+`
+		FROM removed r JOIN courses c ON c.id = r.id JOIN users u ON u.username = $1`,
+		username, models.WebhookEventEnrollmentRemoved)
+	return err
+}
+
+// RelayWebhookEvents moves up to limit events out of the outbox, creating a delivery for every active webhook of
+// the event's tenant that subscribes to it and enqueueing a job of the given kind, with the delivery's id as
+// deliveryId in its payload, to send each. Replicas relaying at the same time take different events.
+// It returns how many events and deliveries there were.
+func (db *PostgresDatabase) RelayWebhookEvents(limit int, queue, kind string, maxAttempts int) (int, int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`UPDATE webhook_events SET relayed_at = now() WHERE id IN (
+			SELECT id FROM webhook_events WHERE relayed_at IS NULL ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED
+		) RETURNING id`, limit)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error taking webhook events: %w", err)
+	}
+	var eventIds []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		eventIds = append(eventIds, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+	if len(eventIds) == 0 {
+		return 0, 0, nil
+	}
+
+	rows, err = tx.Query(`INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT w.id, e.id, e.type, jsonb_build_object(
+			'id', e.id::text,
+			'type', e.type,
+			'createdAt', e.created_at,
+			'organizationId', COALESCE(e.organization_id::text, ''),
+			'data', e.data
+		)::text
+		FROM webhook_events e JOIN webhooks w ON w.organization_id IS NOT DISTINCT FROM e.organization_id
+			AND e.type = ANY (w.events) AND w.active AND w.deleted_at IS NULL
+		WHERE e.id = ANY ($1)
+		ORDER BY e.id, w.id
+		RETURNING id`, eventIds)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error creating webhook deliveries: %w", err)
+	}
+	var deliveryIds []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		deliveryIds = append(deliveryIds, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, id := range deliveryIds {
+		if err := enqueueWebhookDelivery(tx, id, queue, kind, maxAttempts); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return len(eventIds), len(deliveryIds), nil
+}
+
+func enqueueWebhookDelivery(tx *pgx.Tx, deliveryId int64, queue, kind string, maxAttempts int) error {
+	payload, err := json.Marshal(map[string]string{"deliveryId": strconv.FormatInt(deliveryId, 10)})
+	if err != nil {
+		return err
+	}
+	_, err = enqueueJob(tx, queue, kind, payload, time.Now(), maxAttempts)
+	return err
+}
+
+// CreateWebhook adds a webhook for the tenant, the platform itself when empty, returning it with its secret
+func (db *PostgresDatabase) CreateWebhook(tenant, createdBy, secret string, add models.AddWebhook) (*models.Webhook, error) {
+	query := `INSERT INTO webhooks (organization_id, url, secret, events, description, created_by)
+		VALUES (NULLIF($1, '')::bigint, $2, $3, $4, $5, $6) RETURNING ` + webhookColumns
+	webhook, err := scanWebhook(db.conn.QueryRow(query, tenant, add.URL, secret, add.Events, add.Description, createdBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	webhook.Secret = secret
+	return webhook, nil
+}
+
+// GetWebhooks retrieves the tenant's webhooks, oldest first
+func (db *PostgresDatabase) GetWebhooks(tenant string) ([]models.Webhook, error) {
+	rows, err := db.conn.Query("SELECT "+webhookColumns+" FROM webhooks WHERE "+webhookOfTenant(1)+" ORDER BY id", tenant)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// GetWebhook retrieves one of the tenant's webhooks
+func (db *PostgresDatabase) GetWebhook(tenant, webhookId string) (*models.Webhook, error) {
+	if _, err := strconv.ParseInt(webhookId, 10, 64); err != nil {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	query := "SELECT " + webhookColumns + " FROM webhooks WHERE id = $2 AND " + webhookOfTenant(1)
+	webhook, err := scanWebhook(db.conn.QueryRow(query, tenant, webhookId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// GetWebhookSecret retrieves the secret signing one of the tenant's webhooks
+func (db *PostgresDatabase) GetWebhookSecret(tenant, webhookId string) (string, error) {
+	var secret string
+	err := db.conn.QueryRow("SELECT secret FROM webhooks WHERE id = $2 AND "+webhookOfTenant(1), tenant, webhookId).Scan(&secret)
+	if err == pgx.ErrNoRows {
+		return "", fmt.Errorf("webhook not found")
+	}
+	return secret, err
+}
+
+// UpdateWebhook changes one of the tenant's webhooks
+func (db *PostgresDatabase) UpdateWebhook(tenant, webhookId string, update models.UpdateWebhook) (*models.Webhook, error) {
+	if _, err := strconv.ParseInt(webhookId, 10, 64); err != nil {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	query := `UPDATE webhooks SET url = $3, events = $4, description = $5, active = $6
+		WHERE id = $2 AND ` + webhookOfTenant(1) + ` RETURNING ` + webhookColumns
+	webhook, err := scanWebhook(db.conn.QueryRow(query, tenant, webhookId, update.URL, update.Events, update.Description, update.Active))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("webhook not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// DeleteWebhook stops a webhook of the tenant receiving events. Its delivery log is kept.
+func (db *PostgresDatabase) DeleteWebhook(tenant, webhookId string) error {
+	if _, err := strconv.ParseInt(webhookId, 10, 64); err != nil {
+		return fmt.Errorf("webhook not found")
+	}
+	tag, err := db.conn.Exec("UPDATE webhooks SET deleted_at = now(), active = false WHERE id = $2 AND "+webhookOfTenant(1), tenant, webhookId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// GetWebhookDeliveries retrieves a page of a webhook's delivery log, newest first, along with the total match count
+func (db *PostgresDatabase) GetWebhookDeliveries(webhookId string, filter models.WebhookDeliverySearch) ([]models.WebhookDelivery, int, error) {
+	where := "webhook_id = $1"
+	args := []interface{}{webhookId}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += " AND status = $2"
+	}
+
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM webhook_deliveries WHERE "+where, args...).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM webhook_deliveries WHERE %s ORDER BY id DESC LIMIT %d OFFSET %d",
+		webhookDeliveryColumns, where, filter.Limit(), filter.Offset())
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, total, rows.Err()
+}
+
+// RedeliverWebhook sends a delivery's payload to its webhook again as a new delivery, enqueueing a job of the
+// given kind to send it
+func (db *PostgresDatabase) RedeliverWebhook(webhookId, deliveryId, queue, kind string, maxAttempts int) (*models.WebhookDelivery, error) {
+	if _, err := strconv.ParseInt(deliveryId, 10, 64); err != nil {
+		return nil, fmt.Errorf("delivery not found")
+	}
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT webhook_id, event_id, event_type, payload FROM webhook_deliveries WHERE id = $2 AND webhook_id = $1
+		RETURNING ` + webhookDeliveryColumns
+	delivery, err := scanWebhookDelivery(tx.QueryRow(query, webhookId, deliveryId))
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("delivery not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to redeliver: %w", err)
+	}
+
+	id, _ := strconv.ParseInt(delivery.Id, 10, 64)
+	if err := enqueueWebhookDelivery(tx, id, queue, kind, maxAttempts); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// AddWebhookDelivery records a delivery of a payload that is not from the outbox, such as a test ping.
+// The caller sends it.
+func (db *PostgresDatabase) AddWebhookDelivery(webhookId, eventType, payload string) (*models.WebhookDelivery, error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_type, payload) VALUES ($1, $2, $3)
+		RETURNING ` + webhookDeliveryColumns
+	delivery, err := scanWebhookDelivery(db.conn.QueryRow(query, webhookId, eventType, payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// GetWebhookDeliveryTarget retrieves a delivery along with its payload and the webhook to send it to. The webhook
+// comes back inactive if it has been deleted.
+func (db *PostgresDatabase) GetWebhookDeliveryTarget(deliveryId string) (*models.WebhookDelivery, string, *models.Webhook, error) {
+	query := `SELECT d.id, d.webhook_id, COALESCE(d.event_id::text, ''), d.event_type, d.status, d.attempts,
+			d.response_status, d.response_body, d.last_error, d.created_at, d.last_attempt_at, d.delivered_at,
+			d.payload, w.url, w.secret, w.active AND w.deleted_at IS NULL
+		FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id WHERE d.id = $1`
+	var delivery models.WebhookDelivery
+	var webhook models.Webhook
+	var id, webhookId int64
+	var attempts, responseStatus int32
+	var payload string
+	err := db.conn.QueryRow(query, deliveryId).Scan(&id, &webhookId, &delivery.EventId, &delivery.EventType, &delivery.Status,
+		&attempts, &responseStatus, &delivery.ResponseBody, &delivery.LastError, &delivery.CreatedAt, &delivery.LastAttemptAt,
+		&delivery.DeliveredAt, &payload, &webhook.URL, &webhook.Secret, &webhook.Active)
+	if err == pgx.ErrNoRows {
+		return nil, "", nil, fmt.Errorf("delivery not found")
+	}
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error fetching delivery: %w", err)
+	}
+	delivery.Id = strconv.FormatInt(id, 10)
+	delivery.WebhookId = strconv.FormatInt(webhookId, 10)
+	delivery.Attempts = int(attempts)
+	delivery.ResponseStatus = int(responseStatus)
+	webhook.Id = delivery.WebhookId
+	return &delivery, payload, &webhook, nil
+}
+
+// RecordWebhookAttempt records the outcome of an attempt to send a delivery and returns the updated delivery
+func (db *PostgresDatabase) RecordWebhookAttempt(deliveryId, status string, responseStatus int, responseBody, lastError string) (*models.WebhookDelivery, error) {
+	query := `UPDATE webhook_deliveries SET status = $2, attempts = attempts + 1, response_status = $3,
+			response_body = $4, last_error = $5, last_attempt_at = now(),
+			delivered_at = CASE WHEN $2 = 'succeeded' THEN now() END
+		WHERE id = $1 RETURNING ` + webhookDeliveryColumns
+	delivery, err := scanWebhookDelivery(db.conn.QueryRow(query, deliveryId, status, responseStatus, responseBody, lastError))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record delivery attempt: %w", err)
+	}
+	return delivery, nil
+}
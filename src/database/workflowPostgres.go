@@ -0,0 +1,162 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+
+	models "orkidslearning/src/models/database"
+
+	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
+)
+
+const courseWorkflowEventColumns = "id, course_id, action, from_status, to_status, actor, comment, publish_at, created_at"
+
+func scanCourseWorkflowEvent(row rowScanner) (*models.CourseWorkflowEvent, error) {
+	var event models.CourseWorkflowEvent
+	var id int64
+	var courseId pgtype.UUID
+	err := row.Scan(&id, &courseId, &event.Action, &event.FromStatus, &event.ToStatus, &event.Actor, &event.Comment,
+		&event.PublishAt, &event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	event.Id = strconv.FormatInt(id, 10)
+	event.CourseId = fmt.Sprintf("%x", courseId.Bytes)
+	return &event, nil
+}
+
+// GetCourseForReview retrieves a course whatever its organization, for reviewers who work across organizations
+func (db *PostgresDatabase) GetCourseForReview(courseId string) (*models.CoursePostgres, error) {
+	course, err := scanCourse(db.conn.QueryRow("SELECT "+courseColumns+" FROM courses c WHERE c.id = $1", courseId))
+	if err != nil {
+		log.Println("QueryRow error:", err)
+		return nil, fmt.Errorf("course with ID '%s' does not exist", courseId)
+	}
+	return course, nil
+}
+
+// GetCoursesInReview retrieves a page of the courses awaiting review, longest waiting first, along with the total
+// count
+func (db *PostgresDatabase) GetCoursesInReview(page models.Pagination) ([]models.CoursePostgres, int, error) {
+	var total int
+	if err := db.conn.QueryRow("SELECT count(*) FROM courses WHERE status = $1", models.CourseStatusInReview).Scan(&total); err != nil {
+		log.Println("Count error:", err)
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM courses c WHERE c.status = $1
+		ORDER BY (SELECT max(w.created_at) FROM course_workflow_events w WHERE w.course_id = c.id AND w.action = $2), c.id
+		LIMIT %d OFFSET %d`, courseColumns, page.Limit(), page.Offset())
+	rows, err := db.conn.Query(query, models.CourseStatusInReview, models.WorkflowSubmit)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	courses := []models.CoursePostgres{}
+	for rows.Next() {
+		course, err := scanCourse(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, 0, err
+		}
+		courses = append(courses, *course)
+	}
+	return courses, total, rows.Err()
+}
+
+// TransitionCourse moves a course to event.ToStatus, provided its current status is one of allowed, and records
+// the step in its workflow history. The course's publish time becomes event.PublishAt.
+func (db *PostgresDatabase) TransitionCourse(courseId string, allowed []string, event models.CourseWorkflowEvent) (*models.CourseWorkflowEvent, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRow("SELECT status FROM courses WHERE id = $1 FOR UPDATE", courseId).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return nil, fmt.Errorf("course with ID '%s' does not exist", courseId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(allowed, status) {
+		return nil, fmt.Errorf("cannot %s a course that is %s", event.Action, status)
+	}
+
+	_, err = tx.Exec(`UPDATE courses SET status = $2, publish_at = $3,
+			published_at = CASE WHEN $2 = 'published' THEN now() ELSE published_at END
+		WHERE id = $1`, courseId, event.ToStatus, event.PublishAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update course status: %w", err)
+	}
+
+	query := `INSERT INTO course_workflow_events (course_id, action, from_status, to_status, actor, comment, publish_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING ` + courseWorkflowEventColumns
+	recorded, err := scanCourseWorkflowEvent(tx.QueryRow(query, courseId, event.Action, status, event.ToStatus, event.Actor,
+		event.Comment, event.PublishAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to record workflow step: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// GetCourseWorkflow retrieves the workflow history of a course, oldest step first
+func (db *PostgresDatabase) GetCourseWorkflow(courseId string) ([]models.CourseWorkflowEvent, error) {
+	rows, err := db.conn.Query("SELECT "+courseWorkflowEventColumns+" FROM course_workflow_events WHERE course_id = $1 ORDER BY created_at, id", courseId)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.CourseWorkflowEvent{}
+	for rows.Next() {
+		event, err := scanCourseWorkflowEvent(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
+
+// PublishDueCourses publishes the approved courses whose publish time has come, recording actor as having
+// published them, and returns the steps recorded
+func (db *PostgresDatabase) PublishDueCourses(actor string) ([]models.CourseWorkflowEvent, error) {
+	query := `WITH due AS (
+			UPDATE courses SET status = 'published', published_at = now(), publish_at = NULL
+			WHERE status = 'approved' AND publish_at <= now()
+			RETURNING id
+		)
+		INSERT INTO course_workflow_events (course_id, action, from_status, to_status, actor)
+		SELECT id, $1, 'approved', 'published', $2 FROM due
+		RETURNING ` + courseWorkflowEventColumns
+	rows, err := db.conn.Query(query, models.WorkflowPublish, actor)
+	if err != nil {
+		log.Println("Query error:", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.CourseWorkflowEvent{}
+	for rows.Next() {
+		event, err := scanCourseWorkflowEvent(rows)
+		if err != nil {
+			log.Println("Row scan error:", err)
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Data export states
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+// DataExport tracks an asynchronous export of everything stored about a user
+type DataExport struct {
+	Id          string     `json:"id"`
+	Username    string     `json:"username"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	RequestedAt time.Time  `json:"requestedAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+}
+
+// UserProfile is the personal data held on a user, without credentials
+type UserProfile struct {
+	Id                  string     `json:"id"`
+	Username            string     `json:"username"`
+	Email               string     `json:"email"`
+	CreatedAt           time.Time  `json:"createdAt"`
+	DeletionRequestedAt *time.Time `json:"deletionRequestedAt"`
+}
+
+// Enrollment is a single course a user is enrolled in
+type Enrollment struct {
+	CourseId   string    `json:"courseId"`
+	Title      string    `json:"title"`
+	EnrolledAt time.Time `json:"enrolledAt"`
+}
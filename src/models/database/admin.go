@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// UserAccount is the administrative view of a user
+type UserAccount struct {
+	Id                    string     `json:"id"`
+	Username              string     `json:"username"`
+	Email                 string     `json:"email"`
+	Role                  string     `json:"role"`
+	Status                string     `json:"status"`
+	StatusReason          string     `json:"statusReason"`
+	PasswordResetRequired bool       `json:"passwordResetRequired"`
+	CreatedAt             time.Time  `json:"createdAt"`
+	DeletionRequestedAt   *time.Time `json:"deletionRequestedAt"`
+	MergedInto            string     `json:"mergedInto,omitempty"`
+}
+
+// UserSearch filters the admin user listing. Text filters match case-insensitively on substrings.
+type UserSearch struct {
+	Email          string     `form:"email"`
+	Username       string     `form:"username"`
+	Role           string     `form:"role"`
+	Status         string     `form:"status"`
+	SignedUpAfter  *time.Time `form:"signedUpAfter" time_format:"2006-01-02"`
+	SignedUpBefore *time.Time `form:"signedUpBefore" time_format:"2006-01-02"`
+	Pagination
+}
+
+type SuspendUser struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type ChangeUserRole struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type MergeUsers struct {
+	DuplicateId string `json:"duplicateId" binding:"required"`
+}
+
+type ResetPassword struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Assignment is work learners hand in. It is due DueAfterDays after the learner's cohort starts,
+// or after the learner enrolled in self-paced courses.
+type Assignment struct {
+	Id           string     `json:"id"`
+	CourseId     string     `json:"courseId"`
+	Title        string     `json:"title"`
+	Instructions string     `json:"instructions"`
+	DueAfterDays int        `json:"dueAfterDays"`
+	DueAt        *time.Time `json:"dueAt"`
+	SubmittedAt  *time.Time `json:"submittedAt"`
+	Overdue      bool       `json:"overdue"`
+}
+
+type AddAssignment struct {
+	Title        string `json:"title" binding:"required"`
+	Instructions string `json:"instructions"`
+	DueAfterDays int    `json:"dueAfterDays" binding:"min=0"`
+}
+
+type SubmitAssignment struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// AssignmentSubmission is the work a user handed in for an assignment
+type AssignmentSubmission struct {
+	CourseId        string    `json:"courseId"`
+	AssignmentId    string    `json:"assignmentId"`
+	AssignmentTitle string    `json:"assignmentTitle"`
+	Content         string    `json:"content"`
+	SubmittedAt     time.Time `json:"submittedAt"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit target types
+const (
+	AuditTargetUser          = "user"
+	AuditTargetCourse        = "course"
+	AuditTargetEnrollment    = "enrollment"
+	AuditTargetOrganization  = "organization"
+	AuditTargetUserImport    = "user_import"
+	AuditTargetJob           = "job"
+	AuditTargetEmail         = "email"
+	AuditTargetWebhook       = "webhook"
+	AuditTargetDiscussion    = "discussion"
+	AuditTargetContentReport = "content_report"
+	AuditTargetWordFilter    = "word_filter"
+	AuditTargetCategory      = "category"
+)
+
+// AuditEvent is a single entry of the append-only audit log
+type AuditEvent struct {
+	Id         string          `json:"id"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"targetType"`
+	TargetId   string          `json:"targetId"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"userAgent"`
+	TraceId    string          `json:"traceId"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+}
+
+// AuditSearch filters the audit log. Times are RFC 3339; From is inclusive and To exclusive.
+type AuditSearch struct {
+	Actor      string     `form:"actor"`
+	Action     string     `form:"action"`
+	TargetType string     `form:"targetType"`
+	TargetId   string     `form:"targetId"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Pagination
+}
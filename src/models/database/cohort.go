@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Cohort is a group of learners taking a course together between two dates
+type Cohort struct {
+	Id          string    `json:"id"`
+	CourseId    string    `json:"courseId"`
+	Name        string    `json:"name"`
+	StartsAt    time.Time `json:"startsAt"`
+	EndsAt      time.Time `json:"endsAt"`
+	Facilitator string    `json:"facilitator"`
+	Capacity    *int      `json:"capacity"`
+	Enrolled    int       `json:"enrolled"`
+}
+
+type AddCohort struct {
+	Name        string    `json:"name" binding:"required"`
+	StartsAt    time.Time `json:"startsAt" binding:"required"`
+	EndsAt      time.Time `json:"endsAt" binding:"required,gtfield=StartsAt"`
+	Facilitator string    `json:"facilitator"`
+	Capacity    *int      `json:"capacity" binding:"omitempty,min=1"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "go.mongodb.org/mongo-driver/bson/primitive"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 type Course struct {
 	Id            primitive.ObjectID `bson:"_id" json:"_id"`
@@ -10,14 +14,67 @@ type Course struct {
 }
 
 type CoursePostgres struct {
-	Id            string   `json:"id"`
-	Title         string   `json:"title"`
-	Description   string   `json:"description"`
-	EnrolledUsers []string `json:"enrolledUsers"`
+	Id             string `json:"id"`
+	Title          string `json:"title"`
+	Description    string `json:"description"`
+	OrganizationId string `json:"organizationId"`
+	Visibility     string `json:"visibility"`
+	CohortBased    bool   `json:"cohortBased"`
+	Capacity       *int   `json:"capacity"`
+
+	EnrollmentPolicy   string     `json:"enrollmentPolicy"`
+	EnrollmentOpensAt  *time.Time `json:"enrollmentOpensAt"`
+	EnrollmentClosesAt *time.Time `json:"enrollmentClosesAt"`
+
+	CategoryId string   `json:"categoryId"`
+	Tags       []string `json:"tags"`
+	Subject    string   `json:"subject"`
+	GradeLevel string   `json:"gradeLevel"`
+
+	// Status is where the course is in the publishing workflow; PublishAt is when an approved course is scheduled
+	// to be published
+	Status      string     `json:"status"`
+	PublishAt   *time.Time `json:"publishAt"`
+	PublishedAt *time.Time `json:"publishedAt"`
+
+	// CurrentVersion is the content version new enrollments are pinned to, nil until a version is published
+	CurrentVersion *int `json:"currentVersion"`
+
+	// AverageRating is nil until the course has been rated
+	AverageRating *float64 `json:"averageRating"`
+	RatingCount   int      `json:"ratingCount"`
 }
 
 type AddCourse struct {
 	Title         string   `json:"title"`
 	Description   string   `json:"description"`
 	EnrolledUsers []string `json:"enrolledUsers"`
+	Visibility    string   `json:"visibility"`
+	CohortBased   bool     `json:"cohortBased"`
+	Capacity      *int     `json:"capacity" binding:"omitempty,min=1"`
+
+	EnrollmentPolicy   string     `json:"enrollmentPolicy"`
+	EnrollmentOpensAt  *time.Time `json:"enrollmentOpensAt"`
+	EnrollmentClosesAt *time.Time `json:"enrollmentClosesAt"`
+
+	CategoryId string   `json:"categoryId"`
+	Tags       []string `json:"tags" binding:"max=10,dive,max=30"`
+	Subject    string   `json:"subject"`
+	GradeLevel string   `json:"gradeLevel"`
+}
+
+// Course visibility. Public courses appear in every catalogue, organization courses only to members of their organization.
+const (
+	CourseVisibilityPublic       = "public"
+	CourseVisibilityOrganization = "organization"
+)
+
+// CourseSearch filters and orders the catalogue. A category matches its subcategories' courses too. Sort is title,
+// the default, or rating, which puts the best rated courses first and unrated ones last.
+type CourseSearch struct {
+	Category   string `form:"category"`
+	Tag        string `form:"tag"`
+	Subject    string `form:"subject"`
+	GradeLevel string `form:"gradeLevel"`
+	Sort       string `form:"sort" binding:"omitempty,oneof=title rating"`
 }
@@ -0,0 +1,114 @@
+package models
+
+import "time"
+
+// CourseArchiveFormat identifies course archives, and CourseArchiveFormatVersion is the layout this build writes and
+// reads. The version is raised whenever a change to the layout would make older readers misread an archive.
+const (
+	CourseArchiveFormat        = "orkidslearning.course"
+	CourseArchiveFormatVersion = 1
+)
+
+// Files of a course archive. The media file is informational and is recomputed from the content on import.
+const (
+	CourseArchiveManifestFile    = "manifest.json"
+	CourseArchiveCourseFile      = "course.json"
+	CourseArchiveLessonsFile     = "lessons.json"
+	CourseArchiveAssignmentsFile = "assignments.json"
+	CourseArchiveMediaFile       = "media.json"
+)
+
+// CourseArchiveManifest describes where and when an archive was exported and what it holds
+type CourseArchiveManifest struct {
+	Format               string    `json:"format"`
+	FormatVersion        int       `json:"formatVersion"`
+	ExportedAt           time.Time `json:"exportedAt"`
+	ExportedBy           string    `json:"exportedBy"`
+	SourceCourseId       string    `json:"sourceCourseId"`
+	SourceOrganizationId string    `json:"sourceOrganizationId"`
+	SourceVersion        *int      `json:"sourceVersion"`
+	LessonCount          int       `json:"lessonCount"`
+	AssignmentCount      int       `json:"assignmentCount"`
+	MediaCount           int       `json:"mediaCount"`
+}
+
+// CourseArchiveCourse holds the details of an archived course. The category is referred to by its slug, since
+// category ids differ between installations.
+type CourseArchiveCourse struct {
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	CohortBased      bool     `json:"cohortBased"`
+	Capacity         *int     `json:"capacity"`
+	EnrollmentPolicy string   `json:"enrollmentPolicy"`
+	CategorySlug     string   `json:"categorySlug"`
+	Tags             []string `json:"tags"`
+	Subject          string   `json:"subject"`
+	GradeLevel       string   `json:"gradeLevel"`
+}
+
+// CourseArchiveLesson is a lesson of an archived course. Ref identifies it within the archive; the lesson gets a new
+// id when imported.
+type CourseArchiveLesson struct {
+	Ref              string `json:"ref"`
+	Position         int    `json:"position"`
+	Title            string `json:"title"`
+	Content          string `json:"content"`
+	ReleaseAfterDays int    `json:"releaseAfterDays"`
+}
+
+// CourseArchiveAssignment is an assignment of an archived course, identified within the archive by Ref
+type CourseArchiveAssignment struct {
+	Ref          string `json:"ref"`
+	Title        string `json:"title"`
+	Instructions string `json:"instructions"`
+	DueAfterDays int    `json:"dueAfterDays"`
+}
+
+// CourseArchiveMedia is a media file or link referenced by the content of an archived course. Media are referenced,
+// not embedded, so they must be reachable from wherever the archive is imported. UsedBy lists the refs of the
+// lessons and assignments referencing it.
+type CourseArchiveMedia struct {
+	URL    string   `json:"url"`
+	UsedBy []string `json:"usedBy"`
+}
+
+// CourseArchive is a course exported to a portable archive, with one JSON file per part
+type CourseArchive struct {
+	Manifest    CourseArchiveManifest     `json:"manifest"`
+	Course      CourseArchiveCourse       `json:"course"`
+	Lessons     []CourseArchiveLesson     `json:"lessons"`
+	Assignments []CourseArchiveAssignment `json:"assignments"`
+	Media       []CourseArchiveMedia      `json:"media"`
+}
+
+// Kinds of conflicts between an archive and the installation it is imported into
+const (
+	CourseImportConflictTitle            = "title"
+	CourseImportConflictCategory         = "category"
+	CourseImportConflictSubject          = "subject"
+	CourseImportConflictGradeLevel       = "grade_level"
+	CourseImportConflictEnrollmentPolicy = "enrollment_policy"
+	CourseImportConflictMedia            = "media"
+)
+
+// CourseImportConflict is something in an archive that could not be imported as is, and how it was resolved. Ref
+// names the part of the archive concerned.
+type CourseImportConflict struct {
+	Kind    string `json:"kind"`
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+}
+
+// CourseImportResult reports an import. IdMap maps the refs of the archive's lessons and assignments, and "course",
+// to the ids they were given; it is empty on a dry run, where Course is what would have been created.
+type CourseImportResult struct {
+	DryRun    bool                   `json:"dryRun"`
+	Manifest  CourseArchiveManifest  `json:"manifest"`
+	Course    CoursePostgres         `json:"course"`
+	IdMap     map[string]string      `json:"idMap"`
+	Conflicts []CourseImportConflict `json:"conflicts"`
+}
+
+type CourseImportOptions struct {
+	DryRun bool `form:"dryRun"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// States of a course on the learner's dashboard. Archived courses were put aside by the learner, whatever their progress.
+const (
+	CourseStateInProgress = "in_progress"
+	CourseStateCompleted  = "completed"
+	CourseStateArchived   = "archived"
+)
+
+// CourseStates lists every state the dashboard can be filtered by
+var CourseStates = []string{CourseStateInProgress, CourseStateCompleted, CourseStateArchived}
+
+// MyCourse is a course the learner is enrolled in, as shown on their dashboard
+type MyCourse struct {
+	Course         CoursePostgres `json:"course"`
+	CohortId       string         `json:"cohortId"`
+	State          string         `json:"state"`
+	EnrolledAt     time.Time      `json:"enrolledAt"`
+	LastAccessedAt *time.Time     `json:"lastAccessedAt"`
+	CompletedAt    *time.Time     `json:"completedAt"`
+	ArchivedAt     *time.Time     `json:"archivedAt"`
+	Progress       CourseProgress `json:"progress"`
+	NextLesson     *Lesson        `json:"nextLesson"`
+	DueAssignments []Assignment   `json:"dueAssignments"`
+
+	// ReleaseStart is when the learner's lessons and assignment deadlines start counting from
+	ReleaseStart time.Time `json:"-"`
+}
+
+// CourseProgress counts the lessons a learner has completed
+type CourseProgress struct {
+	CompletedLessons int `json:"completedLessons"`
+	TotalLessons     int `json:"totalLessons"`
+	Percent          int `json:"percent"`
+}
+
+// MyCoursesSearch filters and orders the dashboard. Sort is one of lastAccessed, enrolled, title or progress.
+type MyCoursesSearch struct {
+	State string `form:"state"`
+	Sort  string `form:"sort" binding:"omitempty,oneof=lastAccessed enrolled title progress"`
+	Order string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Pagination
+}
+
+type ArchiveCourse struct {
+	Archived bool `json:"archived"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// Reactions learners can add to a discussion post
+const (
+	DiscussionReactionLike       = "like"
+	DiscussionReactionThanks     = "thanks"
+	DiscussionReactionInsightful = "insightful"
+	DiscussionReactionQuestion   = "question"
+)
+
+// DiscussionReactions lists every reaction a post can receive
+var DiscussionReactions = []string{DiscussionReactionLike, DiscussionReactionThanks, DiscussionReactionInsightful, DiscussionReactionQuestion}
+
+// DiscussionThread is a conversation about a course, or about one of its lessons when LessonId is set. Its opening
+// post is the thread's first post. Hidden threads are only shown to moderators.
+type DiscussionThread struct {
+	Id             string    `json:"id"`
+	CourseId       string    `json:"courseId"`
+	LessonId       string    `json:"lessonId"`
+	Title          string    `json:"title"`
+	Author         string    `json:"author"`
+	Pinned         bool      `json:"pinned"`
+	AnswerPostId   string    `json:"answerPostId"`
+	Hidden         bool      `json:"hidden"`
+	ReplyCount     int       `json:"replyCount"`
+	CreatedAt      time.Time `json:"createdAt"`
+	LastActivityAt time.Time `json:"lastActivityAt"`
+}
+
+// DiscussionPost is the opening post of a thread, or a reply to another post in it when ParentId is set.
+// The body of a hidden post is only shown to moderators, and that of a deleted post is gone.
+type DiscussionPost struct {
+	Id          string         `json:"id"`
+	ThreadId    string         `json:"threadId"`
+	ParentId    string         `json:"parentId"`
+	Author      string         `json:"author"`
+	Body        string         `json:"body"`
+	Hidden      bool           `json:"hidden"`
+	Deleted     bool           `json:"deleted"`
+	Reactions   map[string]int `json:"reactions"`
+	MyReactions []string       `json:"myReactions"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	EditedAt    *time.Time     `json:"editedAt"`
+}
+
+// DiscussionPostRevision is an earlier body of a post, along with the edit that replaced it. PostId is only set
+// when revisions of several posts are listed together.
+type DiscussionPostRevision struct {
+	PostId   string    `json:"postId,omitempty"`
+	Body     string    `json:"body"`
+	EditedBy string    `json:"editedBy"`
+	EditedAt time.Time `json:"editedAt"`
+}
+
+// DiscussionReaction is a reaction a user added to a post
+type DiscussionReaction struct {
+	PostId    string    `json:"postId"`
+	Reaction  string    `json:"reaction"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AddDiscussionThread struct {
+	LessonId string `json:"lessonId"`
+	Title    string `json:"title" binding:"required,max=200"`
+	Body     string `json:"body" binding:"required,max=20000"`
+}
+
+type AddDiscussionPost struct {
+	ParentId string `json:"parentId"`
+	Body     string `json:"body" binding:"required,max=20000"`
+}
+
+type EditDiscussionPost struct {
+	Body string `json:"body" binding:"required,max=20000"`
+}
+
+type DiscussionThreadSearch struct {
+	LessonId string `form:"lessonId"`
+	Pagination
+}
+
+type SetDiscussionPinned struct {
+	Pinned bool `json:"pinned"`
+}
+
+type SetDiscussionHidden struct {
+	Hidden bool `json:"hidden"`
+}
+
+// SetDiscussionAnswer marks a reply as the answer to its thread, or clears the answer when PostId is empty
+type SetDiscussionAnswer struct {
+	PostId string `json:"postId"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// Outbox email states
+const (
+	EmailQueued     = "queued"
+	EmailSent       = "sent"
+	EmailSuppressed = "suppressed"
+	EmailFailed     = "failed"
+)
+
+// Reasons an address is suppressed
+const (
+	SuppressionBounce    = "bounce"
+	SuppressionComplaint = "complaint"
+	SuppressionManual    = "manual"
+)
+
+// OutboxEmail is an email waiting to be delivered, or the record of one that was. Bodies are not exposed and
+// are discarded once the email is delivered, as they may hold secrets such as invitation links.
+type OutboxEmail struct {
+	Id        string     `json:"id"`
+	To        string     `json:"to"`
+	Template  string     `json:"template"`
+	Locale    string     `json:"locale"`
+	Subject   string     `json:"subject"`
+	Status    string     `json:"status"`
+	LastError string     `json:"lastError"`
+	CreatedAt time.Time  `json:"createdAt"`
+	SentAt    *time.Time `json:"sentAt"`
+}
+
+// EmailSearch filters the outbox for administrators
+type EmailSearch struct {
+	To       string `form:"to"`
+	Template string `form:"template"`
+	Status   string `form:"status" binding:"omitempty,oneof=queued sent suppressed failed"`
+	Pagination
+}
+
+// EmailSuppression stops any email being sent to an address
+type EmailSuppression struct {
+	Email     string    `json:"email"`
+	Reason    string    `json:"reason"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AddEmailSuppression struct {
+	Email  string `json:"email" binding:"required,email"`
+	Reason string `json:"reason" binding:"required,oneof=bounce complaint manual"`
+	Detail string `json:"detail"`
+}
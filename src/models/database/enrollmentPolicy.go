@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Course enrollment policies
+const (
+	EnrollmentPolicyOpen     = "open"
+	EnrollmentPolicyApproval = "approval"
+	EnrollmentPolicyInvite   = "invite"
+)
+
+// EnrollmentPolicies lists every policy a course can use
+var EnrollmentPolicies = []string{EnrollmentPolicyOpen, EnrollmentPolicyApproval, EnrollmentPolicyInvite}
+
+// Enrollment request states
+const (
+	EnrollmentRequestPending  = "pending"
+	EnrollmentRequestApproved = "approved"
+	EnrollmentRequestRejected = "rejected"
+)
+
+// EnrollmentRequest is a learner asking to join a course that requires instructor approval
+type EnrollmentRequest struct {
+	Id          string     `json:"id"`
+	CourseId    string     `json:"courseId"`
+	CohortId    string     `json:"cohortId"`
+	Username    string     `json:"username"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason"`
+	RequestedAt time.Time  `json:"requestedAt"`
+	DecidedAt   *time.Time `json:"decidedAt"`
+	DecidedBy   string     `json:"decidedBy"`
+}
+
+type EnrollmentRequestSearch struct {
+	Status string `form:"status"`
+	Pagination
+}
+
+type RejectEnrollmentRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// InviteCode lets learners enroll in a course whatever its policy. Codes without MaxUses or ExpiresAt have no such limit.
+type InviteCode struct {
+	Code      string     `json:"code"`
+	CourseId  string     `json:"courseId"`
+	MaxUses   *int       `json:"maxUses"`
+	Uses      int        `json:"uses"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	CreatedBy string     `json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type AddInviteCode struct {
+	MaxUses   *int       `json:"maxUses" binding:"omitempty,min=1"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type UpdateEnrollmentPolicy struct {
+	EnrollmentPolicy   string     `json:"enrollmentPolicy" binding:"required"`
+	EnrollmentOpensAt  *time.Time `json:"enrollmentOpensAt"`
+	EnrollmentClosesAt *time.Time `json:"enrollmentClosesAt"`
+}
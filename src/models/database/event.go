@@ -0,0 +1,65 @@
+package models
+
+// Domain event names
+const (
+	EventUserSignedUp    = "user.signed_up"
+	EventCourseCreated   = "course.created"
+	EventEnrolled        = "enrollment.enrolled"
+	EventUnenrolled      = "enrollment.unenrolled"
+	EventLessonCompleted = "lesson.completed"
+)
+
+// Event is something that happened in the service, published on the event bus once it has been stored
+type Event interface {
+	EventName() string
+}
+
+// UserSignedUp is published when someone creates an account through the signup form
+type UserSignedUp struct {
+	User UserPostgres
+}
+
+func (UserSignedUp) EventName() string { return EventUserSignedUp }
+
+// CourseCreated is published when a course is added. ClonedFrom is the course or template it was copied from, if any,
+// and ImportedFrom the manifest of the archive it was imported from.
+type CourseCreated struct {
+	Course       CoursePostgres
+	ClonedFrom   string
+	ImportedFrom *CourseArchiveManifest
+}
+
+func (CourseCreated) EventName() string { return EventCourseCreated }
+
+// Enrolled is published when a learner gets a seat in a course: by enrolling, by having their request approved, by
+// being imported, or from the waitlist when Promoted is set
+type Enrolled struct {
+	Username    string
+	CourseId    string
+	CourseTitle string
+	CohortId    string
+	InviteCode  string
+	Promoted    bool
+}
+
+func (Enrolled) EventName() string { return EventEnrolled }
+
+// Unenrolled is published when a learner leaves a course, or its waitlist when Waitlisted is set
+type Unenrolled struct {
+	Username   string
+	CourseId   string
+	Waitlisted bool
+}
+
+func (Unenrolled) EventName() string { return EventUnenrolled }
+
+// LessonCompleted is published when a learner completes a lesson. CourseCompleted is set when it was the last
+// lesson they had left.
+type LessonCompleted struct {
+	Username        string
+	CourseId        string
+	LessonId        string
+	CourseCompleted bool
+}
+
+func (LessonCompleted) EventName() string { return EventLessonCompleted }
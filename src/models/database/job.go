@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job states. Failed jobs go back to pending until they run out of attempts, then they are dead.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobDead      = "dead"
+)
+
+// JobStatuses lists every state a job can be in
+var JobStatuses = []string{JobPending, JobRunning, JobCompleted, JobDead}
+
+// Job queues. Each queue has its own workers, so slow work in one does not hold up another.
+const (
+	JobQueueDefault  = "default"
+	JobQueueExports  = "exports"
+	JobQueueImports  = "imports"
+	JobQueueEmail    = "email"
+	JobQueueWebhooks = "webhooks"
+)
+
+// Job is a unit of background work. Payload is handed to the handler registered for Kind.
+type Job struct {
+	Id          string          `json:"id"`
+	Queue       string          `json:"queue"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	RunAt       time.Time       `json:"runAt"`
+	LastError   string          `json:"lastError"`
+	LockedBy    string          `json:"lockedBy"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	StartedAt   *time.Time      `json:"startedAt"`
+	CompletedAt *time.Time      `json:"completedAt"`
+}
+
+// JobSearch filters the job list for administrators
+type JobSearch struct {
+	Queue  string `form:"queue"`
+	Kind   string `form:"kind"`
+	Status string `form:"status" binding:"omitempty,oneof=pending running completed dead"`
+	Pagination
+}
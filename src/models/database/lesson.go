@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Lesson is a unit of course content. Lessons are released ReleaseAfterDays after the learner's cohort starts,
+// or after the learner enrolled in self-paced courses.
+type Lesson struct {
+	Id               string     `json:"id"`
+	CourseId         string     `json:"courseId"`
+	Position         int        `json:"position"`
+	Title            string     `json:"title"`
+	Content          string     `json:"content"`
+	ReleaseAfterDays int        `json:"releaseAfterDays"`
+	AvailableAt      *time.Time `json:"availableAt"`
+	Locked           bool       `json:"locked"`
+	Completed        bool       `json:"completed"`
+}
+
+type AddLesson struct {
+	Title            string `json:"title" binding:"required"`
+	Content          string `json:"content"`
+	Position         int    `json:"position" binding:"min=0"`
+	ReleaseAfterDays int    `json:"releaseAfterDays" binding:"min=0"`
+}
+
+// UpdateLesson edits a lesson of a course's working copy
+type UpdateLesson struct {
+	Title            string `json:"title" binding:"required"`
+	Content          string `json:"content"`
+	Position         int    `json:"position" binding:"required,min=1"`
+	ReleaseAfterDays int    `json:"releaseAfterDays" binding:"min=0"`
+}
+
+// LessonProgress records a lesson a user completed
+type LessonProgress struct {
+	CourseId    string    `json:"courseId"`
+	LessonId    string    `json:"lessonId"`
+	LessonTitle string    `json:"lessonTitle"`
+	CompletedAt time.Time `json:"completedAt"`
+}
@@ -1,10 +1,32 @@
 package models
 
+// EnrollInCourse holds the options of an enrollment. The learner is always the authenticated user.
 type EnrollInCourse struct {
-	Username        string `json:"username"`
 	CheckEnrollment bool   `json:"checkEnrollment" default:"false"`
+	CohortId        string `json:"cohortId"`
+	InviteCode      string `json:"inviteCode"`
 }
 
-type UnenrollFromCourse struct {
-	Username string `json:"username"`
+// Outcomes of an enrollment attempt
+const (
+	EnrollmentStatusEnrolled       = "enrolled"
+	EnrollmentStatusWaitlisted     = "waitlisted"
+	EnrollmentStatusPending        = "pending_approval"
+	EnrollmentStatusRejected       = "rejected"
+	EnrollmentStatusNotOpen        = "not_open"
+	EnrollmentStatusClosed         = "closed"
+	EnrollmentStatusInviteRequired = "invite_required"
+	EnrollmentStatusInviteInvalid  = "invite_invalid"
+)
+
+// EnrollmentResult is the outcome of an enrollment attempt. Learners who find the course or cohort full are
+// put on its waitlist instead, at WaitlistPosition counting from 1. Reason explains outcomes other than enrolled.
+// Changed is false when the attempt found the user already enrolled or waiting.
+type EnrollmentResult struct {
+	Status           string `json:"status"`
+	Reason           string `json:"reason"`
+	CohortId         string `json:"cohortId"`
+	WaitlistPosition int    `json:"waitlistPosition"`
+	RequestId        string `json:"requestId"`
+	Changed          bool   `json:"changed"`
 }
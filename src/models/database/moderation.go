@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Content that can be reported
+const (
+	ReportContentDiscussionPost = "discussion_post"
+)
+
+// Reasons for reporting content. Reports with ReportReasonWordFilter are filed automatically when a post matches
+// a flagging word filter.
+const (
+	ReportReasonSpam          = "spam"
+	ReportReasonHarassment    = "harassment"
+	ReportReasonInappropriate = "inappropriate"
+	ReportReasonOther         = "other"
+	ReportReasonWordFilter    = "word_filter"
+)
+
+// Content report states
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+// Actions a moderator can take on reported content
+const (
+	ModerationActionHide    = "hide"
+	ModerationActionDelete  = "delete"
+	ModerationActionWarn    = "warn"
+	ModerationActionSuspend = "suspend"
+	ModerationActionDismiss = "dismiss"
+)
+
+// What happens to posts matching a word filter: blocked posts are refused, flagged posts are accepted and reported
+const (
+	WordFilterBlock = "block"
+	WordFilterFlag  = "flag"
+)
+
+// ContentReport is a complaint about a piece of user-generated content, kept with an excerpt of the content as it
+// was reported. OpenReports counts the open reports about the same content.
+type ContentReport struct {
+	Id          string     `json:"id"`
+	ContentType string     `json:"contentType"`
+	ContentId   string     `json:"contentId"`
+	CourseId    string     `json:"courseId"`
+	ThreadId    string     `json:"threadId"`
+	Author      string     `json:"author"`
+	Excerpt     string     `json:"excerpt"`
+	Reporter    string     `json:"reporter"`
+	Reason      string     `json:"reason"`
+	Details     string     `json:"details"`
+	Status      string     `json:"status"`
+	Resolution  string     `json:"resolution"`
+	Note        string     `json:"note"`
+	ResolvedBy  *string    `json:"resolvedBy"`
+	ResolvedAt  *time.Time `json:"resolvedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	OpenReports int        `json:"openReports"`
+}
+
+type AddContentReport struct {
+	Reason  string `json:"reason" binding:"required,oneof=spam harassment inappropriate other"`
+	Details string `json:"details" binding:"max=2000"`
+}
+
+type ContentReportSearch struct {
+	Status string `form:"status" binding:"omitempty,oneof=open resolved dismissed"`
+	Pagination
+}
+
+// ModerationAction is what a moderator does about a report. The note is shown to the author when warning them and
+// recorded as the reason when suspending them.
+type ModerationAction struct {
+	Action string `json:"action" binding:"required,oneof=hide delete warn suspend dismiss"`
+	Note   string `json:"note" binding:"max=2000"`
+}
+
+// WordFilter matches a word or phrase, ignoring case, in posts as they are written
+type WordFilter struct {
+	Id        string    `json:"id"`
+	Pattern   string    `json:"pattern"`
+	Action    string    `json:"action"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type AddWordFilter struct {
+	Pattern string `json:"pattern" binding:"required,max=100"`
+	Action  string `json:"action" binding:"required,oneof=block flag"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Notification is an entry in a user's notification feed
+type Notification struct {
+	Id        string     `json:"id"`
+	Type      string     `json:"type"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// NotificationSearch pages through a user's feed, newest first
+type NotificationSearch struct {
+	Unread bool `form:"unread"`
+	Pagination
+}
+
+// NotificationPreference chooses the channels a type of notification is delivered on
+type NotificationPreference struct {
+	Type  string `json:"type" binding:"required"`
+	InApp bool   `json:"inApp"`
+	Email bool   `json:"email"`
+}
+
+type UpdateNotificationPreferences struct {
+	Preferences []NotificationPreference `json:"preferences" binding:"required,min=1,dive"`
+}
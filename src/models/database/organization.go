@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// Organization roles, from most to least privileged
+const (
+	OrgRoleOwner      = "owner"
+	OrgRoleAdmin      = "admin"
+	OrgRoleInstructor = "instructor"
+	OrgRoleMember     = "member"
+)
+
+// OrgRoles lists every role a member can hold within an organization
+var OrgRoles = []string{OrgRoleOwner, OrgRoleAdmin, OrgRoleInstructor, OrgRoleMember}
+
+// Organization is a school or district whose members share private courses
+type Organization struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// OrganizationMembership is an organization as seen by one of its members
+type OrganizationMembership struct {
+	Organization
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// OrganizationMember is a user as seen by the organization they belong to
+type OrganizationMember struct {
+	UserId   string    `json:"userId"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+type AddOrganization struct {
+	Name          string `json:"name" binding:"required"`
+	Slug          string `json:"slug" binding:"required,max=64"`
+	OwnerUsername string `json:"ownerUsername" binding:"required"`
+}
+
+type AddOrganizationMember struct {
+	Username string `json:"username" binding:"required"`
+	Role     string `json:"role" binding:"required"`
+}
+
+type ChangeOrganizationRole struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type SwitchOrganization struct {
+	OrganizationId string `json:"organizationId"`
+}
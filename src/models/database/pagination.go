@@ -0,0 +1,30 @@
+package models
+
+// DefaultPageSize is used when a listing request does not ask for a page size
+const DefaultPageSize = 20
+
+// Pagination selects one page of a listing. Pages are numbered from 1.
+type Pagination struct {
+	Page     int `form:"page" json:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"pageSize" json:"pageSize" binding:"omitempty,min=1,max=100"`
+}
+
+// Normalize fills in the first page and the default page size where they were not given
+func (p *Pagination) Normalize() {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 {
+		p.PageSize = DefaultPageSize
+	}
+}
+
+// Limit returns the number of rows on the page
+func (p Pagination) Limit() int {
+	return p.PageSize
+}
+
+// Offset returns the number of rows before the page
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
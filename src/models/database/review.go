@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// CourseReview is a learner's rating of a course, from 1 to 5, with an optional review and the public reply of one
+// of the course's instructors
+type CourseReview struct {
+	Id        string     `json:"id"`
+	CourseId  string     `json:"courseId"`
+	Username  string     `json:"username"`
+	Rating    int        `json:"rating"`
+	Body      string     `json:"body"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+	Reply     string     `json:"reply"`
+	RepliedBy *string    `json:"repliedBy"`
+	RepliedAt *time.Time `json:"repliedAt"`
+}
+
+type SetCourseReview struct {
+	Rating int    `json:"rating" binding:"required,min=1,max=5"`
+	Body   string `json:"body" binding:"max=5000"`
+}
+
+// SetReviewReply replies to a review, or removes the reply when Body is empty
+type SetReviewReply struct {
+	Body string `json:"body" binding:"max=5000"`
+}
+
+// CourseReviewSearch filters and orders a course's reviews. Sort is recent, the default, highest or lowest.
+type CourseReviewSearch struct {
+	Rating int    `form:"rating" binding:"omitempty,min=1,max=5"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=recent highest lowest"`
+	Pagination
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// RosterEntry is a learner enrolled in a course, as seen by the course's instructors.
+// LastActivityAt is the latest of the learner opening the course, completing a lesson or submitting an assignment.
+type RosterEntry struct {
+	Username       string         `json:"username"`
+	Email          string         `json:"email"`
+	CohortId       string         `json:"cohortId"`
+	EnrolledAt     time.Time      `json:"enrolledAt"`
+	LastActivityAt *time.Time     `json:"lastActivityAt"`
+	CompletedAt    *time.Time     `json:"completedAt"`
+	Progress       CourseProgress `json:"progress"`
+}
+
+// RosterSearch narrows the roster to one cohort and to learners whose username or email contains Search
+type RosterSearch struct {
+	CohortId string `form:"cohortId"`
+	Search   string `form:"search"`
+	Pagination
+}
+
+type BulkUnenroll struct {
+	Usernames []string `json:"usernames" binding:"required,min=1,max=100"`
+}
+
+type MessageLearners struct {
+	Usernames []string `json:"usernames" binding:"required,min=1,max=100"`
+	Message   string   `json:"message" binding:"required,max=2000"`
+}
+
+// BulkResult reports what a bulk roster action did for one learner
+type BulkResult struct {
+	Username string `json:"username"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
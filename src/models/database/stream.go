@@ -0,0 +1,15 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StreamEvent is a change pushed to a user's connected clients. Ids increase, so a client that reconnects with the
+// last id it saw is sent what it missed.
+type StreamEvent struct {
+	Id        string          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
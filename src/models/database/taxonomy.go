@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Subjects a course can teach
+const (
+	SubjectLiteracy          = "literacy"
+	SubjectMathematics       = "mathematics"
+	SubjectScience           = "science"
+	SubjectLanguages         = "languages"
+	SubjectSocialStudies     = "social_studies"
+	SubjectArts              = "arts"
+	SubjectMusic             = "music"
+	SubjectPhysicalEducation = "physical_education"
+	SubjectTechnology        = "technology"
+	SubjectLifeSkills        = "life_skills"
+)
+
+// Subjects lists every subject a course can be given
+var Subjects = []string{SubjectLiteracy, SubjectMathematics, SubjectScience, SubjectLanguages, SubjectSocialStudies,
+	SubjectArts, SubjectMusic, SubjectPhysicalEducation, SubjectTechnology, SubjectLifeSkills}
+
+// GradeLevels lists every grade level a course can be aimed at, youngest first
+var GradeLevels = []string{"pre_k", "kindergarten", "grade_1", "grade_2", "grade_3", "grade_4", "grade_5", "grade_6",
+	"grade_7", "grade_8", "grade_9", "grade_10", "grade_11", "grade_12"}
+
+// CourseCategory is a node of the category tree, such as Phonics under Early Years. Path names the category and
+// its ancestors from the root, e.g. "Early Years > Phonics".
+type CourseCategory struct {
+	Id        string    `json:"id"`
+	ParentId  string    `json:"parentId"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	Position  int       `json:"position"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AddCourseCategory creates a category, at the root of the tree unless ParentId is set
+type AddCourseCategory struct {
+	ParentId string `json:"parentId"`
+	Name     string `json:"name" binding:"required,max=100"`
+	Slug     string `json:"slug" binding:"required,max=100"`
+	Position int    `json:"position"`
+}
+
+// Taxonomy is everything courses can be classified by
+type Taxonomy struct {
+	Categories  []CourseCategory `json:"categories"`
+	Subjects    []string         `json:"subjects"`
+	GradeLevels []string         `json:"gradeLevels"`
+}
+
+// SetCourseTaxonomy classifies an existing course. Empty values clear the classification.
+type SetCourseTaxonomy struct {
+	CategoryId string   `json:"categoryId"`
+	Tags       []string `json:"tags" binding:"max=10,dive,max=30"`
+	Subject    string   `json:"subject"`
+	GradeLevel string   `json:"gradeLevel"`
+}
+
+// FacetCount is how many catalogue courses have a value of a facet
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// CatalogueFacets counts the courses matching the catalogue filters for each value of each facet. The counts of a
+// facet ignore the filter on that facet, so they show what choosing another value would give. Category counts
+// include the courses of subcategories.
+type CatalogueFacets struct {
+	Categories  []FacetCount `json:"categories"`
+	Tags        []FacetCount `json:"tags"`
+	Subjects    []FacetCount `json:"subjects"`
+	GradeLevels []FacetCount `json:"gradeLevels"`
+}
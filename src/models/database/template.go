@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// CourseTemplate is a course admins have added to the template library, which instructors can start new courses
+// from
+type CourseTemplate struct {
+	Course          CoursePostgres `json:"course"`
+	Note            string         `json:"note"`
+	LessonCount     int            `json:"lessonCount"`
+	AssignmentCount int            `json:"assignmentCount"`
+	AddedBy         string         `json:"addedBy"`
+	AddedAt         time.Time      `json:"addedAt"`
+}
+
+type AddCourseTemplate struct {
+	CourseId string `json:"courseId" binding:"required"`
+	Note     string `json:"note" binding:"max=2000"`
+}
+
+// CourseTemplateSearch filters the template library
+type CourseTemplateSearch struct {
+	Subject    string `form:"subject"`
+	GradeLevel string `form:"gradeLevel"`
+}
+
+// CloneCourse names the course created by cloning another one or a template. Without a title, clones are called
+// "Copy of" their source and courses created from a template take its title.
+type CloneCourse struct {
+	Title string `json:"title" binding:"max=200"`
+}
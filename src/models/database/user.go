@@ -11,12 +11,33 @@ type User struct {
 }
 
 type UserPostgres struct {
-	Id       string `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"` // Hashed password
+	Id                    string `json:"id"`
+	Username              string `json:"username"`
+	Email                 string `json:"email"`
+	Password              string `json:"password"` // Hashed password
+	Role                  string `json:"role"`
+	Status                string `json:"status"`
+	PasswordResetRequired bool   `json:"passwordResetRequired"`
 }
 
+// User roles
+const (
+	RoleLearner    = "learner"
+	RoleInstructor = "instructor"
+	RoleReviewer   = "reviewer" // Reviews courses submitted for publication
+	RoleAdmin      = "admin"
+)
+
+// Roles lists every role a user can hold
+var Roles = []string{RoleLearner, RoleInstructor, RoleReviewer, RoleAdmin}
+
+// User account states
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusMerged    = "merged"
+)
+
 type AddUser struct {
 	Username string `json:"username" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// User import states
+const (
+	UserImportRunning   = "running"
+	UserImportCompleted = "completed"
+	UserImportFailed    = "failed"
+)
+
+// UserImportColumns are the columns an import CSV must have in its header row, in any order.
+// course_ids lists course ids separated by semicolons and cohort optionally names the cohort to join in cohort-based courses.
+var UserImportColumns = []string{"email", "name", "role", "course_ids", "cohort"}
+
+// UserImportLocaleColumn is an optional column choosing the language of each user's invitation email
+const UserImportLocaleColumn = "locale"
+
+// UserImportRow is one account to create or reuse, and the courses to enroll it in. Line is the row's line in the CSV.
+type UserImportRow struct {
+	Line      int      `json:"line"`
+	Email     string   `json:"email"`
+	Name      string   `json:"name"`
+	Role      string   `json:"role"`
+	CourseIds []string `json:"courseIds"`
+	CohortId  string   `json:"cohortId"`
+	Locale    string   `json:"locale,omitempty"`
+}
+
+// UserImportError explains why a row of an import was rejected or failed
+type UserImportError struct {
+	Line   int      `json:"line"`
+	Errors []string `json:"errors"`
+}
+
+// UserImportValidation is the result of checking every row of an import CSV
+type UserImportValidation struct {
+	TotalRows int               `json:"totalRows"`
+	Valid     bool              `json:"valid"`
+	Errors    []UserImportError `json:"errors"`
+}
+
+// UserImport tracks a bulk import running in the background
+type UserImport struct {
+	Id             string            `json:"id"`
+	RequestedBy    string            `json:"requestedBy"`
+	OrganizationId string            `json:"organizationId"`
+	Status         string            `json:"status"`
+	TotalRows      int               `json:"totalRows"`
+	ProcessedRows  int               `json:"processedRows"`
+	CreatedUsers   int               `json:"createdUsers"`
+	Enrollments    int               `json:"enrollments"`
+	Errors         []UserImportError `json:"errors"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	CompletedAt    *time.Time        `json:"completedAt"`
+}
+
+type UserImportOptions struct {
+	DryRun bool `form:"dryRun"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// CourseVersionLesson is a lesson as it was when a course version was published. Lesson ids stay the same across
+// versions, so learner progress carries over between them.
+type CourseVersionLesson struct {
+	Id               string `json:"id"`
+	Position         int    `json:"position"`
+	Title            string `json:"title"`
+	Content          string `json:"content"`
+	ReleaseAfterDays int    `json:"releaseAfterDays"`
+}
+
+// CourseVersion is an immutable snapshot of a course's metadata and lessons. Lessons are left out of version lists.
+type CourseVersion struct {
+	CourseId          string                `json:"courseId"`
+	Number            int                   `json:"number"`
+	Title             string                `json:"title"`
+	Description       string                `json:"description"`
+	CategoryId        string                `json:"categoryId"`
+	Tags              []string              `json:"tags"`
+	Subject           string                `json:"subject"`
+	GradeLevel        string                `json:"gradeLevel"`
+	Lessons           []CourseVersionLesson `json:"lessons,omitempty"`
+	LessonCount       int                   `json:"lessonCount"`
+	Note              string                `json:"note"`
+	CreatedBy         string                `json:"createdBy"`
+	CreatedAt         time.Time             `json:"createdAt"`
+	PinnedEnrollments int                   `json:"pinnedEnrollments"`
+}
+
+type PublishCourseVersion struct {
+	Note string `json:"note" binding:"max=5000"`
+}
+
+// CourseVersionDiffQuery compares version From with version To, or with the working copy when To is 0
+type CourseVersionDiffQuery struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"min=0"`
+}
+
+// FieldChange is a field whose value differs between two versions
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// LessonChange lists the fields of a lesson that differ between two versions
+type LessonChange struct {
+	LessonId string        `json:"lessonId"`
+	Title    string        `json:"title"`
+	Changes  []FieldChange `json:"changes"`
+}
+
+// CourseVersionDiff describes how the course changed from one version to another. To is 0 for the working copy.
+type CourseVersionDiff struct {
+	From           int                   `json:"from"`
+	To             int                   `json:"to"`
+	Changes        []FieldChange         `json:"changes"`
+	LessonsAdded   []CourseVersionLesson `json:"lessonsAdded"`
+	LessonsRemoved []CourseVersionLesson `json:"lessonsRemoved"`
+	LessonsChanged []LessonChange        `json:"lessonsChanged"`
+}
+
+// EnrollmentVersion is the version a learner's enrollment is pinned to and the course's current version. Either is
+// nil while the course has no published version.
+type EnrollmentVersion struct {
+	Pinned           *int `json:"pinned"`
+	Current          *int `json:"current"`
+	UpgradeAvailable bool `json:"upgradeAvailable"`
+}
+
+// UpdateCourseDetails edits the title and description of a course's working copy
+type UpdateCourseDetails struct {
+	Title       string `json:"title" binding:"required,max=200"`
+	Description string `json:"description" binding:"max=10000"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Webhook event types
+const (
+	WebhookEventUserSignedUp      = "user.signed_up"
+	WebhookEventEnrollmentCreated = "enrollment.created"
+	WebhookEventEnrollmentRemoved = "enrollment.removed"
+	WebhookEventCourseCompleted   = "course.completed"
+	// WebhookEventPing is only sent by the test ping and cannot be subscribed to
+	WebhookEventPing = "ping"
+)
+
+// WebhookEventTypes are the events a webhook can subscribe to
+var WebhookEventTypes = []string{WebhookEventUserSignedUp, WebhookEventEnrollmentCreated, WebhookEventEnrollmentRemoved, WebhookEventCourseCompleted}
+
+// Webhook delivery states
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook sends a tenant's events to an HTTP endpoint. The secret signing its deliveries is only shown when the
+// webhook is created.
+type Webhook struct {
+	Id          string    `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedBy   string    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type AddWebhook struct {
+	URL         string   `json:"url" binding:"required,url,max=2000"`
+	Events      []string `json:"events" binding:"required,min=1,dive,oneof=user.signed_up enrollment.created enrollment.removed course.completed"`
+	Description string   `json:"description" binding:"max=500"`
+}
+
+type UpdateWebhook struct {
+	URL         string   `json:"url" binding:"required,url,max=2000"`
+	Events      []string `json:"events" binding:"required,min=1,dive,oneof=user.signed_up enrollment.created enrollment.removed course.completed"`
+	Description string   `json:"description" binding:"max=500"`
+	Active      bool     `json:"active"`
+}
+
+// WebhookDelivery is one event sent, or being sent, to a webhook, along with the outcome of its latest attempt
+type WebhookDelivery struct {
+	Id             string     `json:"id"`
+	WebhookId      string     `json:"webhookId"`
+	EventId        string     `json:"eventId"`
+	EventType      string     `json:"eventType"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"responseStatus"`
+	ResponseBody   string     `json:"responseBody"`
+	LastError      string     `json:"lastError"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	LastAttemptAt  *time.Time `json:"lastAttemptAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+}
+
+type WebhookDeliverySearch struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending succeeded failed"`
+	Pagination
+}
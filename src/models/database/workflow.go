@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Publication states of a course. Only published courses appear in the catalogue and accept enrollments; approved
+// courses with a publish time are published by the scheduler when it comes.
+const (
+	CourseStatusDraft            = "draft"
+	CourseStatusInReview         = "in_review"
+	CourseStatusChangesRequested = "changes_requested"
+	CourseStatusApproved         = "approved"
+	CourseStatusPublished        = "published"
+	CourseStatusUnpublished      = "unpublished"
+)
+
+// Steps of the publishing workflow
+const (
+	WorkflowSubmit         = "submit"
+	WorkflowApprove        = "approve"
+	WorkflowRequestChanges = "request_changes"
+	WorkflowSchedule       = "schedule"
+	WorkflowPublish        = "publish"
+	WorkflowUnpublish      = "unpublish"
+)
+
+// CourseWorkflowEvent is a step a course took through the publishing workflow, with the comment left on it
+type CourseWorkflowEvent struct {
+	Id         string     `json:"id"`
+	CourseId   string     `json:"courseId"`
+	Action     string     `json:"action"`
+	FromStatus string     `json:"fromStatus"`
+	ToStatus   string     `json:"toStatus"`
+	Actor      string     `json:"actor"`
+	Comment    string     `json:"comment"`
+	PublishAt  *time.Time `json:"publishAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+type SubmitCourse struct {
+	Comment string `json:"comment" binding:"max=5000"`
+}
+
+type ApproveCourse struct {
+	Comment string `json:"comment" binding:"max=5000"`
+}
+
+// RequestCourseChanges sends a course back to its authors with the reviewer's comments
+type RequestCourseChanges struct {
+	Comment string `json:"comment" binding:"required,max=5000"`
+}
+
+// PublishCourse publishes an approved course now, or schedules it when PublishAt is in the future
+type PublishCourse struct {
+	PublishAt *time.Time `json:"publishAt"`
+}
+
+type UnpublishCourse struct {
+	Comment string `json:"comment" binding:"max=5000"`
+}
@@ -0,0 +1,21 @@
+package response
+
+import (
+	"time"
+
+	models "orkidslearning/src/models/database"
+)
+
+type DataExportResponse struct {
+	Message     string            `json:"message"`
+	Error       string            `json:"error"`
+	Export      models.DataExport `json:"export"`
+	DownloadURL string            `json:"downloadUrl"`
+}
+
+type AccountDeletionResponse struct {
+	Message  string    `json:"message"`
+	Error    string    `json:"error"`
+	Username string    `json:"username"`
+	PurgeAt  time.Time `json:"purgeAt"`
+}
@@ -0,0 +1,54 @@
+package response
+
+import (
+	"time"
+
+	models "orkidslearning/src/models/database"
+)
+
+type UserSearchResponse struct {
+	Message  string               `json:"message"`
+	Error    string               `json:"error"`
+	Users    []models.UserAccount `json:"users"`
+	Total    int                  `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"pageSize"`
+}
+
+type UserAccountResponse struct {
+	Message string              `json:"message"`
+	Error   string              `json:"error"`
+	User    models.UserAccount  `json:"user"`
+	History []models.AuditEvent `json:"history"`
+}
+
+type UserEnrollmentsResponse struct {
+	Message     string              `json:"message"`
+	Error       string              `json:"error"`
+	UserId      string              `json:"userId"`
+	Enrollments []models.Enrollment `json:"enrollments"`
+}
+
+type AdminActionResponse struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+	UserId  string `json:"userId"`
+	Done    bool   `json:"done" default:"false"`
+}
+
+type PasswordResetResponse struct {
+	Message    string    `json:"message"`
+	Error      string    `json:"error"`
+	UserId     string    `json:"userId"`
+	ResetToken string    `json:"resetToken"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
+
+type AuditSearchResponse struct {
+	Message  string              `json:"message"`
+	Error    string              `json:"error"`
+	Events   []models.AuditEvent `json:"events"`
+	Total    int                 `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"pageSize"`
+}
@@ -5,8 +5,9 @@ import (
 )
 
 type AuthResponse struct {
-	Message string              `json:"message"`
-	User    models.UserPostgres `json:"user"`
-	Token   string              `json:"token"`
-	Error   string              `json:"error"`
+	Message        string              `json:"message"`
+	User           models.UserPostgres `json:"user"`
+	Token          string              `json:"token"`
+	OrganizationId string              `json:"organizationId"`
+	Error          string              `json:"error"`
 }
@@ -0,0 +1,35 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type CohortsResponse struct {
+	Message string          `json:"message"`
+	Error   string          `json:"error"`
+	Cohorts []models.Cohort `json:"cohorts"`
+}
+
+type AddCohortResponse struct {
+	Message string        `json:"message"`
+	Error   string        `json:"error"`
+	Cohort  models.Cohort `json:"cohort"`
+}
+
+type LessonsResponse struct {
+	Message string          `json:"message"`
+	Error   string          `json:"error"`
+	Lessons []models.Lesson `json:"lessons"`
+}
+
+type AddLessonResponse struct {
+	Message string        `json:"message"`
+	Error   string        `json:"error"`
+	Lesson  models.Lesson `json:"lesson"`
+}
+
+type LessonResponse struct {
+	Message string         `json:"message"`
+	Error   string         `json:"error"`
+	Lesson  *models.Lesson `json:"lesson"`
+}
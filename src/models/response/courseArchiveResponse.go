@@ -0,0 +1,16 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type CourseArchiveResponse struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
+
+type CourseImportResponse struct {
+	Message string                     `json:"message"`
+	Error   string                     `json:"error"`
+	Import  *models.CourseImportResult `json:"import"`
+}
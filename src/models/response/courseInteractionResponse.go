@@ -6,6 +6,13 @@ type EnrollInCourseResponse struct {
 	Enrolled bool   `json:"enrolled" default:"false"`
 	Username string `json:"username"`
 	CourseId string `json:"courseId"`
+	CohortId string `json:"cohortId"`
+
+	Status           string `json:"status"`
+	Reason           string `json:"reason"`
+	WaitlistPosition int    `json:"waitlistPosition"`
+	RequestId        string `json:"requestId"`
+	Changed          bool   `json:"changed"`
 }
 
 type UnenrollFromCourseResponse struct {
@@ -14,4 +21,5 @@ type UnenrollFromCourseResponse struct {
 	Unenrolled bool   `json:"unenrolled" default:"false"`
 	Username   string `json:"username"`
 	CourseId   string `json:"courseId"`
+	Changed    bool   `json:"changed"`
 }
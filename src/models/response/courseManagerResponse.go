@@ -8,6 +8,7 @@ type GetCoursesResponse struct {
 	Message string                  `json:"message"`
 	Error   string                  `json:"error"`
 	Courses []models.CoursePostgres `json:"courses"`
+	Facets  *models.CatalogueFacets `json:"facets"`
 }
 
 type GetCourseResponse struct {
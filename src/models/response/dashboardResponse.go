@@ -0,0 +1,48 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+	"time"
+)
+
+type MyCoursesResponse struct {
+	Message  string            `json:"message"`
+	Error    string            `json:"error"`
+	Courses  []models.MyCourse `json:"courses"`
+	Total    int               `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"pageSize"`
+}
+
+type ArchiveCourseResponse struct {
+	Message  string `json:"message"`
+	Error    string `json:"error"`
+	CourseId string `json:"courseId"`
+	Archived bool   `json:"archived"`
+}
+
+type CompleteLessonResponse struct {
+	Message         string `json:"message"`
+	Error           string `json:"error"`
+	LessonId        string `json:"lessonId"`
+	CourseCompleted bool   `json:"courseCompleted"`
+}
+
+type AssignmentsResponse struct {
+	Message     string              `json:"message"`
+	Error       string              `json:"error"`
+	Assignments []models.Assignment `json:"assignments"`
+}
+
+type AddAssignmentResponse struct {
+	Message    string            `json:"message"`
+	Error      string            `json:"error"`
+	Assignment models.Assignment `json:"assignment"`
+}
+
+type SubmitAssignmentResponse struct {
+	Message      string    `json:"message"`
+	Error        string    `json:"error"`
+	AssignmentId string    `json:"assignmentId"`
+	SubmittedAt  time.Time `json:"submittedAt"`
+}
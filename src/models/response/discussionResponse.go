@@ -0,0 +1,41 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type DiscussionThreadsResponse struct {
+	Message  string                    `json:"message"`
+	Error    string                    `json:"error"`
+	Threads  []models.DiscussionThread `json:"threads"`
+	Total    int                       `json:"total"`
+	Page     int                       `json:"page"`
+	PageSize int                       `json:"pageSize"`
+}
+
+type DiscussionThreadResponse struct {
+	Message  string                  `json:"message"`
+	Error    string                  `json:"error"`
+	Thread   models.DiscussionThread `json:"thread"`
+	Posts    []models.DiscussionPost `json:"posts"`
+	Total    int                     `json:"total"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"pageSize"`
+}
+
+type DiscussionPostResponse struct {
+	Message string                `json:"message"`
+	Error   string                `json:"error"`
+	Post    models.DiscussionPost `json:"post"`
+}
+
+type DiscussionPostHistoryResponse struct {
+	Message   string                          `json:"message"`
+	Error     string                          `json:"error"`
+	Revisions []models.DiscussionPostRevision `json:"revisions"`
+}
+
+type DiscussionModerationResponse struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
@@ -0,0 +1,28 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type EmailSearchResponse struct {
+	Message  string               `json:"message"`
+	Error    string               `json:"error"`
+	Emails   []models.OutboxEmail `json:"emails"`
+	Total    int                  `json:"total"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"pageSize"`
+}
+
+type EmailSuppressionsResponse struct {
+	Message      string                    `json:"message"`
+	Error        string                    `json:"error"`
+	Suppressions []models.EmailSuppression `json:"suppressions"`
+	Total        int                       `json:"total"`
+	Page         int                       `json:"page"`
+	PageSize     int                       `json:"pageSize"`
+}
+
+type EmailSuppressionResponse struct {
+	Message string `json:"message"`
+	Error   string `json:"error"`
+}
@@ -0,0 +1,44 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type EnrollmentPolicyResponse struct {
+	Message string                        `json:"message"`
+	Error   string                        `json:"error"`
+	Policy  models.UpdateEnrollmentPolicy `json:"policy"`
+}
+
+type EnrollmentRequestsResponse struct {
+	Message  string                     `json:"message"`
+	Error    string                     `json:"error"`
+	Requests []models.EnrollmentRequest `json:"requests"`
+	Total    int                        `json:"total"`
+	Page     int                        `json:"page"`
+	PageSize int                        `json:"pageSize"`
+}
+
+type EnrollmentDecisionResponse struct {
+	Message string                  `json:"message"`
+	Error   string                  `json:"error"`
+	Result  models.EnrollmentResult `json:"result"`
+}
+
+type RejectEnrollmentResponse struct {
+	Message string                   `json:"message"`
+	Error   string                   `json:"error"`
+	Request models.EnrollmentRequest `json:"request"`
+}
+
+type InviteCodeResponse struct {
+	Message string            `json:"message"`
+	Error   string            `json:"error"`
+	Invite  models.InviteCode `json:"invite"`
+}
+
+type InviteCodesResponse struct {
+	Message string              `json:"message"`
+	Error   string              `json:"error"`
+	Invites []models.InviteCode `json:"invites"`
+}
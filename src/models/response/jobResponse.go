@@ -0,0 +1,20 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type JobSearchResponse struct {
+	Message  string       `json:"message"`
+	Error    string       `json:"error"`
+	Jobs     []models.Job `json:"jobs"`
+	Total    int          `json:"total"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"pageSize"`
+}
+
+type JobResponse struct {
+	Message string     `json:"message"`
+	Error   string     `json:"error"`
+	Job     models.Job `json:"job"`
+}
@@ -0,0 +1,32 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type ContentReportsResponse struct {
+	Message  string                 `json:"message"`
+	Error    string                 `json:"error"`
+	Reports  []models.ContentReport `json:"reports"`
+	Total    int                    `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"pageSize"`
+}
+
+type ContentReportResponse struct {
+	Message string               `json:"message"`
+	Error   string               `json:"error"`
+	Report  models.ContentReport `json:"report"`
+}
+
+type WordFiltersResponse struct {
+	Message string              `json:"message"`
+	Error   string              `json:"error"`
+	Filters []models.WordFilter `json:"filters"`
+}
+
+type WordFilterResponse struct {
+	Message string            `json:"message"`
+	Error   string            `json:"error"`
+	Filter  models.WordFilter `json:"filter"`
+}
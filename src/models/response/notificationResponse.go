@@ -0,0 +1,34 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type NotificationsResponse struct {
+	Message       string                `json:"message"`
+	Error         string                `json:"error"`
+	Notifications []models.Notification `json:"notifications"`
+	UnreadCount   int                   `json:"unreadCount"`
+	Total         int                   `json:"total"`
+	Page          int                   `json:"page"`
+	PageSize      int                   `json:"pageSize"`
+}
+
+type UnreadNotificationsResponse struct {
+	Message     string `json:"message"`
+	Error       string `json:"error"`
+	UnreadCount int    `json:"unreadCount"`
+}
+
+type MarkNotificationsReadResponse struct {
+	Message     string `json:"message"`
+	Error       string `json:"error"`
+	Marked      int    `json:"marked"`
+	UnreadCount int    `json:"unreadCount"`
+}
+
+type NotificationPreferencesResponse struct {
+	Message     string                          `json:"message"`
+	Error       string                          `json:"error"`
+	Preferences []models.NotificationPreference `json:"preferences"`
+}
@@ -0,0 +1,41 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type OrganizationResponse struct {
+	Message      string              `json:"message"`
+	Error        string              `json:"error"`
+	Organization models.Organization `json:"organization"`
+}
+
+type OrganizationsResponse struct {
+	Message       string                          `json:"message"`
+	Error         string                          `json:"error"`
+	Organizations []models.OrganizationMembership `json:"organizations"`
+}
+
+type SwitchOrganizationResponse struct {
+	Message        string `json:"message"`
+	Error          string `json:"error"`
+	OrganizationId string `json:"organizationId"`
+	Token          string `json:"token"`
+}
+
+type OrganizationMembersResponse struct {
+	Message  string                      `json:"message"`
+	Error    string                      `json:"error"`
+	Members  []models.OrganizationMember `json:"members"`
+	Total    int                         `json:"total"`
+	Page     int                         `json:"page"`
+	PageSize int                         `json:"pageSize"`
+}
+
+type OrganizationMemberResponse struct {
+	Message        string `json:"message"`
+	Error          string `json:"error"`
+	OrganizationId string `json:"organizationId"`
+	Username       string `json:"username"`
+	Role           string `json:"role"`
+}
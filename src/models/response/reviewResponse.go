@@ -0,0 +1,20 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type CourseReviewsResponse struct {
+	Message  string                `json:"message"`
+	Error    string                `json:"error"`
+	Reviews  []models.CourseReview `json:"reviews"`
+	Total    int                   `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"pageSize"`
+}
+
+type CourseReviewResponse struct {
+	Message string               `json:"message"`
+	Error   string               `json:"error"`
+	Review  *models.CourseReview `json:"review"`
+}
@@ -0,0 +1,20 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type RosterResponse struct {
+	Message     string               `json:"message"`
+	Error       string               `json:"error"`
+	Enrollments []models.RosterEntry `json:"enrollments"`
+	Total       int                  `json:"total"`
+	Page        int                  `json:"page"`
+	PageSize    int                  `json:"pageSize"`
+}
+
+type BulkRosterResponse struct {
+	Message string              `json:"message"`
+	Error   string              `json:"error"`
+	Results []models.BulkResult `json:"results"`
+}
@@ -0,0 +1,23 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type TaxonomyResponse struct {
+	Message  string          `json:"message"`
+	Error    string          `json:"error"`
+	Taxonomy models.Taxonomy `json:"taxonomy"`
+}
+
+type CourseCategoryResponse struct {
+	Message  string                `json:"message"`
+	Error    string                `json:"error"`
+	Category models.CourseCategory `json:"category"`
+}
+
+type CourseTaxonomyResponse struct {
+	Message string                `json:"message"`
+	Error   string                `json:"error"`
+	Course  models.CoursePostgres `json:"course"`
+}
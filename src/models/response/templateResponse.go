@@ -0,0 +1,23 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type CourseTemplatesResponse struct {
+	Message   string                  `json:"message"`
+	Error     string                  `json:"error"`
+	Templates []models.CourseTemplate `json:"templates"`
+}
+
+type CourseTemplateResponse struct {
+	Message  string                 `json:"message"`
+	Error    string                 `json:"error"`
+	Template *models.CourseTemplate `json:"template"`
+}
+
+type CloneCourseResponse struct {
+	Message string                 `json:"message"`
+	Error   string                 `json:"error"`
+	Course  *models.CoursePostgres `json:"course"`
+}
@@ -0,0 +1,18 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type UserImportValidationResponse struct {
+	Message    string                      `json:"message"`
+	Error      string                      `json:"error"`
+	Validation models.UserImportValidation `json:"validation"`
+}
+
+type UserImportResponse struct {
+	Message   string            `json:"message"`
+	Error     string            `json:"error"`
+	Import    models.UserImport `json:"import"`
+	StatusURL string            `json:"statusUrl"`
+}
@@ -0,0 +1,35 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type CourseDetailsResponse struct {
+	Message string                 `json:"message"`
+	Error   string                 `json:"error"`
+	Course  *models.CoursePostgres `json:"course"`
+}
+
+type CourseVersionsResponse struct {
+	Message  string                 `json:"message"`
+	Error    string                 `json:"error"`
+	Versions []models.CourseVersion `json:"versions"`
+}
+
+type CourseVersionResponse struct {
+	Message string                `json:"message"`
+	Error   string                `json:"error"`
+	Version *models.CourseVersion `json:"version"`
+}
+
+type CourseVersionDiffResponse struct {
+	Message string                    `json:"message"`
+	Error   string                    `json:"error"`
+	Diff    *models.CourseVersionDiff `json:"diff"`
+}
+
+type EnrollmentVersionResponse struct {
+	Message string                    `json:"message"`
+	Error   string                    `json:"error"`
+	Version *models.EnrollmentVersion `json:"version"`
+}
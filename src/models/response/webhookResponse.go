@@ -0,0 +1,32 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type WebhookResponse struct {
+	Message string         `json:"message"`
+	Error   string         `json:"error"`
+	Webhook models.Webhook `json:"webhook"`
+}
+
+type WebhooksResponse struct {
+	Message  string           `json:"message"`
+	Error    string           `json:"error"`
+	Webhooks []models.Webhook `json:"webhooks"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Message    string                   `json:"message"`
+	Error      string                   `json:"error"`
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+	Total      int                      `json:"total"`
+	Page       int                      `json:"page"`
+	PageSize   int                      `json:"pageSize"`
+}
+
+type WebhookDeliveryResponse struct {
+	Message  string                 `json:"message"`
+	Error    string                 `json:"error"`
+	Delivery models.WebhookDelivery `json:"delivery"`
+}
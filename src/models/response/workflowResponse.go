@@ -0,0 +1,27 @@
+package response
+
+import (
+	models "orkidslearning/src/models/database"
+)
+
+type CourseWorkflowStepResponse struct {
+	Message string                      `json:"message"`
+	Error   string                      `json:"error"`
+	Step    *models.CourseWorkflowEvent `json:"step"`
+}
+
+type CourseWorkflowResponse struct {
+	Message string                       `json:"message"`
+	Error   string                       `json:"error"`
+	Course  *models.CoursePostgres       `json:"course"`
+	Steps   []models.CourseWorkflowEvent `json:"steps"`
+}
+
+type CourseReviewQueueResponse struct {
+	Message  string                  `json:"message"`
+	Error    string                  `json:"error"`
+	Courses  []models.CoursePostgres `json:"courses"`
+	Total    int                     `json:"total"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"pageSize"`
+}
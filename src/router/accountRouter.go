@@ -0,0 +1,152 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func ExportAccountData(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ExportAccountData")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+	username := c.GetString("username")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	export, err := controller.GetOrStartDataExport(ctx, contextService, username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DataExportResponse{
+			Message: "Failed to export account data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if export.Status != models.DataExportReady {
+		c.JSON(http.StatusAccepted, response.DataExportResponse{
+			Message: "Data export is being prepared",
+			Export:  *export,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DataExportResponse{
+		Message:     "Data export is ready",
+		Export:      *export,
+		DownloadURL: fmt.Sprintf("/api/me/export/%s/download", export.Id),
+	})
+}
+
+func DownloadAccountData(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "DownloadAccountData")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+	username := c.GetString("username")
+
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, response.DataExportResponse{
+			Message: "id is required",
+			Error:   "id is required",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	archive, err := controller.DownloadDataExport(ctx, contextService, username, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.DataExportResponse{
+			Message: "Failed to download account data",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=orkids-export-%s.zip", id))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+func DeleteAccount(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteAccount")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+	username := c.GetString("username")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	purgeAt, err := controller.RequestAccountDeletion(ctx, contextService, username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AccountDeletionResponse{
+			Message: "Failed to delete account",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.AccountDeletionResponse{
+		Message:  "Account scheduled for deletion",
+		Username: username,
+		PurgeAt:  purgeAt,
+	})
+}
+
+func RestoreAccount(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "RestoreAccount")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+	username := c.GetString("username")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.CancelAccountDeletion(ctx, contextService, username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AccountDeletionResponse{
+			Message: "Failed to restore account",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AccountDeletionResponse{
+		Message:  "Account deletion cancelled",
+		Username: username,
+	})
+}
@@ -0,0 +1,305 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func SearchUsers(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SearchUsers")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.UserSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.UserSearchResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	users, total, err := controller.SearchUsers(ctx, contextService, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.UserSearchResponse{
+			Message: "Failed to search users",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.UserSearchResponse{
+		Message:  "Users retrieved successfully",
+		Users:    users,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	})
+}
+
+func GetUser(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetUser")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	account, history, err := controller.GetUserAccount(ctx, contextService, id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.UserAccountResponse{
+			Message: "Failed to get user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.UserAccountResponse{
+		Message: "User retrieved successfully",
+		User:    *account,
+		History: history,
+	})
+}
+
+func GetUserEnrollments(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetUserEnrollments")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	enrollments, err := controller.GetUserEnrollments(ctx, contextService, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.UserEnrollmentsResponse{
+			Message: "Failed to get user enrollments",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.UserEnrollmentsResponse{
+		Message:     "User enrollments retrieved successfully",
+		UserId:      id,
+		Enrollments: enrollments,
+	})
+}
+
+func SuspendUser(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SuspendUser")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var suspendUser models.SuspendUser
+	if err := c.ShouldBindJSON(&suspendUser); err != nil {
+		c.JSON(http.StatusBadRequest, response.AdminActionResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.SuspendUser(ctx, contextService, c.GetString("username"), id, suspendUser.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AdminActionResponse{
+			Message: "Failed to suspend user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AdminActionResponse{
+		Message: "User suspended",
+		UserId:  id,
+		Done:    true,
+	})
+}
+
+func ReactivateUser(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ReactivateUser")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.ReactivateUser(ctx, contextService, c.GetString("username"), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AdminActionResponse{
+			Message: "Failed to reactivate user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AdminActionResponse{
+		Message: "User reactivated",
+		UserId:  id,
+		Done:    true,
+	})
+}
+
+func ForcePasswordReset(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ForcePasswordReset")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	token, expiresAt, err := controller.ForcePasswordReset(ctx, contextService, c.GetString("username"), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.PasswordResetResponse{
+			Message: "Failed to force password reset",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.PasswordResetResponse{
+		Message:    "Password reset required",
+		UserId:     id,
+		ResetToken: token,
+		ExpiresAt:  expiresAt,
+	})
+}
+
+func ChangeUserRole(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ChangeUserRole")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var changeUserRole models.ChangeUserRole
+	if err := c.ShouldBindJSON(&changeUserRole); err != nil {
+		c.JSON(http.StatusBadRequest, response.AdminActionResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.ChangeUserRole(ctx, contextService, c.GetString("username"), id, changeUserRole.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AdminActionResponse{
+			Message: "Failed to change user role",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AdminActionResponse{
+		Message: "User role changed",
+		UserId:  id,
+		Done:    true,
+	})
+}
+
+func MergeUsers(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "MergeUsers")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var mergeUsers models.MergeUsers
+	if err := c.ShouldBindJSON(&mergeUsers); err != nil {
+		c.JSON(http.StatusBadRequest, response.AdminActionResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.MergeUsers(ctx, contextService, c.GetString("username"), id, mergeUsers.DuplicateId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AdminActionResponse{
+			Message: "Failed to merge users",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AdminActionResponse{
+		Message: "Users merged",
+		UserId:  id,
+		Done:    true,
+	})
+}
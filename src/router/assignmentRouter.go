@@ -0,0 +1,121 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetCourseAssignments(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseAssignments")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	assignments, err := controller.GetCourseAssignments(ctx, contextService, c.GetString("username"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, response.AssignmentsResponse{
+			Message: "Failed to get assignments",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AssignmentsResponse{
+		Message:     "Assignments retrieved successfully",
+		Assignments: assignments,
+	})
+}
+
+func AddAssignment(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "AddAssignment")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var assignment models.AddAssignment
+	if err := c.ShouldBindJSON(&assignment); err != nil {
+		c.JSON(http.StatusBadRequest, response.AddAssignmentResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.AddAssignment(ctx, contextService, c.Param("id"), assignment)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AddAssignmentResponse{
+			Message: "Failed to add assignment",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AddAssignmentResponse{
+		Message:    "Assignment added successfully",
+		Assignment: *added,
+	})
+}
+
+func SubmitAssignment(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SubmitAssignment")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var submission models.SubmitAssignment
+	if err := c.ShouldBindJSON(&submission); err != nil {
+		c.JSON(http.StatusBadRequest, response.SubmitAssignmentResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+	assignmentId := c.Param("assignmentId")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	submittedAt, err := controller.SubmitAssignment(ctx, contextService, c.GetString("username"), c.Param("id"), assignmentId, submission.Content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.SubmitAssignmentResponse{
+			Message: "Failed to submit assignment",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SubmitAssignmentResponse{
+		Message:      "Assignment submitted",
+		AssignmentId: assignmentId,
+		SubmittedAt:  submittedAt,
+	})
+}
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func SearchAuditEvents(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SearchAuditEvents")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.AuditSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.AuditSearchResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	events, total, err := controller.SearchAuditEvents(ctx, contextService, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AuditSearchResponse{
+			Message: "Failed to search audit events",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AuditSearchResponse{
+		Message:  "Audit events retrieved successfully",
+		Events:   events,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	})
+}
+
+func ExportAuditEvents(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ExportAuditEvents")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.AuditSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.AuditSearchResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Exports stream rows as they are read, so they get the full write timeout rather than the usual 5 seconds
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", "attachment; filename=audit-events.csv")
+	c.Status(http.StatusOK)
+
+	if err := controller.ExportAuditEvents(ctx, contextService, filter, c.Writer); err != nil {
+		// Headers are already sent, so the truncated export can only be reported in the logs
+		log.Println("Error exporting audit events: ", err)
+	}
+}
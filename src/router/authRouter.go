@@ -49,7 +49,8 @@ func SignupHandler(c *gin.Context) {
 		return
 	}
 
-	token, err := contextService.GetJWTService().GenerateToken(addedUser.Username)
+	// New users belong to no organization yet
+	token, err := contextService.GetJWTService().GenerateToken(addedUser.Username, "")
 	if err != nil {
 		log.Println("Error generating token: ", err)
 		c.JSON(http.StatusInternalServerError, response.AuthResponse{
@@ -101,7 +102,17 @@ func LoginHandler(c *gin.Context) {
 		return
 	}
 
-	token, err := contextService.GetJWTService().GenerateToken(loggedInUser.Username)
+	organizationId, err := controller.GetDefaultOrganization(ctx, contextService, loggedInUser.Username)
+	if err != nil {
+		log.Println("Error getting default organization: ", err)
+		c.JSON(http.StatusInternalServerError, response.AuthResponse{
+			Message: "Failed to login user",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	token, err := contextService.GetJWTService().GenerateToken(loggedInUser.Username, organizationId)
 	if err != nil {
 		log.Println("Error generating token: ", err)
 		c.JSON(http.StatusInternalServerError, response.AuthResponse{
@@ -112,8 +123,49 @@ func LoginHandler(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, response.AuthResponse{
-		Message: "User logged in successfully",
-		User:    *loggedInUser,
-		Token:   token,
+		Message:        "User logged in successfully",
+		User:           *loggedInUser,
+		Token:          token,
+		OrganizationId: organizationId,
+	})
+}
+
+func ResetPasswordHandler(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ResetPasswordHandler")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	// Parse input
+	var reset models.ResetPassword
+	if err := c.ShouldBindJSON(&reset); err != nil {
+		log.Println("Error binding JSON: ", err)
+		c.JSON(http.StatusBadRequest, response.AuthResponse{
+			Message: "Error binding JSON",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.ResetPassword(ctx, contextService, reset)
+	if err != nil {
+		log.Println("Error resetting password: ", err)
+		c.JSON(http.StatusBadRequest, response.AuthResponse{
+			Message: "Failed to reset password",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AuthResponse{
+		Message: "Password reset successfully",
 	})
 }
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetCourseCohorts(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseCohorts")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cohorts, err := controller.GetCourseCohorts(ctx, contextService, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CohortsResponse{
+			Message: "Failed to get cohorts",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CohortsResponse{
+		Message: "Cohorts retrieved successfully",
+		Cohorts: cohorts,
+	})
+}
+
+func AddCohort(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "AddCohort")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var cohort models.AddCohort
+	if err := c.ShouldBindJSON(&cohort); err != nil {
+		c.JSON(http.StatusBadRequest, response.AddCohortResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.AddCohort(ctx, contextService, c.Param("id"), cohort)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AddCohortResponse{
+			Message: "Failed to add cohort",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AddCohortResponse{
+		Message: "Cohort added successfully",
+		Cohort:  *added,
+	})
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+// maxCourseArchiveSize bounds the archive accepted by a course import
+const maxCourseArchiveSize = 20 << 20
+
+// ExportCourse downloads a course as a course archive
+func ExportCourse(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ExportCourse")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+	courseId := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	archive, err := controller.ExportCourse(ctx, contextService, courseId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseArchiveResponse{
+			Message: "Failed to export course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=orkids-course-%s.zip", courseId))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// ImportCourse accepts a course archive either as the "file" field of a multipart form or as the raw request body.
+// With dryRun=true the archive is validated and its conflicts reported without creating anything.
+func ImportCourse(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ImportCourse")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var options models.CourseImportOptions
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseImportResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCourseArchiveSize)
+	var archiveFile io.Reader = c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		opened, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.CourseImportResponse{
+				Message: "Invalid upload",
+				Error:   err.Error(),
+			})
+			return
+		}
+		defer opened.Close()
+		archiveFile = opened
+	}
+
+	data, err := io.ReadAll(archiveFile)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseImportResponse{
+			Message: "Invalid upload",
+			Error:   err.Error(),
+		})
+		return
+	}
+	archive, err := controller.ParseCourseArchive(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseImportResponse{
+			Message: "Invalid course archive",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Every lesson and assignment is written in one transaction, so imports get the full write timeout
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	result, err := controller.ImportCourse(ctx, contextService, archive, options.DryRun)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseImportResponse{
+			Message: "Failed to import course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	message := "Course imported successfully"
+	if options.DryRun {
+		message = "Import validated"
+	}
+	c.JSON(http.StatusOK, response.CourseImportResponse{
+		Message: message,
+		Import:  result,
+	})
+}
@@ -44,7 +44,8 @@ func EnrollInCourse(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	err := controller.EnrollInCourse(ctx, contextService, enrollInCourse.Username, id)
+	username := c.GetString("username")
+	result, err := controller.EnrollInCourse(ctx, contextService, username, id, enrollInCourse.CohortId, enrollInCourse.InviteCode)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.EnrollInCourseResponse{
 			Message: "Failed to enroll in course",
@@ -53,14 +54,43 @@ func EnrollInCourse(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response.EnrollInCourseResponse{
-		Message:  "Enrolled in course",
-		Enrolled: true,
-		Username: enrollInCourse.Username,
-		CourseId: id,
+	c.JSON(enrollmentStatusCode(result.Status), response.EnrollInCourseResponse{
+		Message:          enrollmentMessages[result.Status],
+		Enrolled:         result.Status == models.EnrollmentStatusEnrolled,
+		Username:         username,
+		CourseId:         id,
+		CohortId:         result.CohortId,
+		Status:           result.Status,
+		Reason:           result.Reason,
+		WaitlistPosition: result.WaitlistPosition,
+		RequestId:        result.RequestId,
+		Changed:          result.Changed,
 	})
 }
 
+var enrollmentMessages = map[string]string{
+	models.EnrollmentStatusEnrolled:       "Enrolled in course",
+	models.EnrollmentStatusWaitlisted:     "Course is full, added to waitlist",
+	models.EnrollmentStatusPending:        "Enrollment is awaiting approval",
+	models.EnrollmentStatusRejected:       "Enrollment was rejected",
+	models.EnrollmentStatusNotOpen:        "Enrollment has not opened yet",
+	models.EnrollmentStatusClosed:         "Enrollment has closed",
+	models.EnrollmentStatusInviteRequired: "An invite code is required",
+	models.EnrollmentStatusInviteInvalid:  "Invite code is not valid",
+}
+
+// enrollmentStatusCode maps the outcome of an enrollment attempt onto an HTTP status
+func enrollmentStatusCode(status string) int {
+	switch status {
+	case models.EnrollmentStatusEnrolled:
+		return http.StatusOK
+	case models.EnrollmentStatusWaitlisted, models.EnrollmentStatusPending:
+		return http.StatusAccepted
+	default:
+		return http.StatusForbidden
+	}
+}
+
 func UnenrollFromCourse(c *gin.Context) {
 	tracer := otel.Tracer("router")
 	ctx, span := tracer.Start(c.Request.Context(), "UnenrollFromCourse")
@@ -72,14 +102,7 @@ func UnenrollFromCourse(c *gin.Context) {
 		return
 	}
 
-	var unenrollFromCourse models.UnenrollFromCourse
-	if err := c.ShouldBindJSON(&unenrollFromCourse); err != nil {
-		c.JSON(http.StatusBadRequest, response.UnenrollFromCourseResponse{
-			Message: "Invalid request body",
-			Error:   err.Error(),
-		})
-		return
-	}
+	username := c.GetString("username")
 	id := c.Param("id")
 	if id == "" {
 		c.JSON(http.StatusBadRequest, response.UnenrollFromCourseResponse{
@@ -92,7 +115,7 @@ func UnenrollFromCourse(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	err := controller.UnenrollFromCourse(ctx, contextService, unenrollFromCourse.Username, id)
+	changed, err := controller.UnenrollFromCourse(ctx, contextService, username, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.UnenrollFromCourseResponse{
 			Message: "Failed to unenroll from course",
@@ -101,10 +124,15 @@ func UnenrollFromCourse(c *gin.Context) {
 		return
 	}
 
+	message := "Unenrolled from course"
+	if !changed {
+		message = "User was not enrolled in course"
+	}
 	c.JSON(http.StatusOK, response.UnenrollFromCourseResponse{
-		Message:    "Unenrolled from course",
+		Message:    message,
 		Unenrolled: true,
-		Username:   unenrollFromCourse.Username,
+		Username:   username,
 		CourseId:   id,
+		Changed:    changed,
 	})
 }
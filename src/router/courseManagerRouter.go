@@ -21,10 +21,19 @@ func GetAllCourses(c *gin.Context) {
 		return
 	}
 
+	var filter models.CourseSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.GetCoursesResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	coursesPostgres, err := controller.GetAllCourses(ctx, contextService)
+	coursesPostgres, facets, err := controller.GetAllCourses(ctx, contextService, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, response.GetCoursesResponse{
 			Message: "Failed to get courses",
@@ -35,6 +44,7 @@ func GetAllCourses(c *gin.Context) {
 	c.JSON(http.StatusOK, response.GetCoursesResponse{
 		Message: "Courses retrieved successfully",
 		Courses: coursesPostgres,
+		Facets:  facets,
 	})
 }
 
@@ -80,7 +90,7 @@ func GetCourseById(c *gin.Context) {
 
 	var isEnrolled bool = false
 	if enrollInCourse.CheckEnrollment {
-		isEnrolled, err = controller.IsUserEnrolledInCourse(ctx, contextService, enrollInCourse.Username, id)
+		isEnrolled, err = controller.IsUserEnrolledInCourse(ctx, contextService, c.GetString("username"), id)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, response.GetCourseResponse{
 				Message: "Failed to enroll in course",
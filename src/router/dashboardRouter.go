@@ -0,0 +1,100 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetMyCourses(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetMyCourses")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.MyCoursesSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.MyCoursesResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	courses, total, err := controller.GetMyCourses(ctx, contextService, c.GetString("username"), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.MyCoursesResponse{
+			Message: "Failed to get courses",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.MyCoursesResponse{
+		Message:  "Courses retrieved successfully",
+		Courses:  courses,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	})
+}
+
+func ArchiveMyCourse(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ArchiveMyCourse")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var archive models.ArchiveCourse
+	if err := c.ShouldBindJSON(&archive); err != nil {
+		c.JSON(http.StatusBadRequest, response.ArchiveCourseResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.ArchiveMyCourse(ctx, contextService, c.GetString("username"), id, archive.Archived)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ArchiveCourseResponse{
+			Message: "Failed to archive course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	message := "Course archived"
+	if !archive.Archived {
+		message = "Course restored from archive"
+	}
+	c.JSON(http.StatusOK, response.ArchiveCourseResponse{
+		Message:  message,
+		CourseId: id,
+		Archived: archive.Archived,
+	})
+}
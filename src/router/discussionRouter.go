@@ -0,0 +1,442 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetDiscussionThreads(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetDiscussionThreads")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.DiscussionThreadSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.DiscussionThreadsResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	threads, total, err := controller.GetDiscussionThreads(ctx, contextService, c.GetString("username"), c.Param("id"), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionThreadsResponse{
+			Message: "Failed to get discussion threads",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionThreadsResponse{
+		Message:  "Discussion threads retrieved successfully",
+		Threads:  threads,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	})
+}
+
+func StartDiscussionThread(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "StartDiscussionThread")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var thread models.AddDiscussionThread
+	if err := c.ShouldBindJSON(&thread); err != nil {
+		c.JSON(http.StatusBadRequest, response.DiscussionThreadResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.StartDiscussionThread(ctx, contextService, c.GetString("username"), c.Param("id"), thread)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionThreadResponse{
+			Message: "Failed to start discussion thread",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionThreadResponse{
+		Message: "Discussion thread started successfully",
+		Thread:  *added,
+	})
+}
+
+func GetDiscussionThread(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetDiscussionThread")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var page models.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, response.DiscussionThreadResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	page.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	thread, posts, total, err := controller.GetDiscussionThread(ctx, contextService, c.GetString("username"), c.Param("id"), c.Param("threadId"), page)
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.DiscussionThreadResponse{
+			Message: "Failed to get discussion thread",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionThreadResponse{
+		Message:  "Discussion thread retrieved successfully",
+		Thread:   *thread,
+		Posts:    posts,
+		Total:    total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+	})
+}
+
+func ReplyToDiscussion(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ReplyToDiscussion")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var post models.AddDiscussionPost
+	if err := c.ShouldBindJSON(&post); err != nil {
+		c.JSON(http.StatusBadRequest, response.DiscussionPostResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.ReplyToDiscussion(ctx, contextService, c.GetString("username"), c.Param("id"), c.Param("threadId"), post)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionPostResponse{
+			Message: "Failed to reply to discussion",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionPostResponse{
+		Message: "Reply posted successfully",
+		Post:    *added,
+	})
+}
+
+func EditDiscussionPost(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "EditDiscussionPost")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var edit models.EditDiscussionPost
+	if err := c.ShouldBindJSON(&edit); err != nil {
+		c.JSON(http.StatusBadRequest, response.DiscussionPostResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	edited, err := controller.EditDiscussionPost(ctx, contextService, c.GetString("username"), c.Param("id"), c.Param("threadId"), c.Param("postId"), edit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionPostResponse{
+			Message: "Failed to edit discussion post",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionPostResponse{
+		Message: "Discussion post edited successfully",
+		Post:    *edited,
+	})
+}
+
+func GetDiscussionPostHistory(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetDiscussionPostHistory")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	revisions, err := controller.GetDiscussionPostHistory(ctx, contextService, c.GetString("username"), c.Param("id"), c.Param("threadId"), c.Param("postId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.DiscussionPostHistoryResponse{
+			Message: "Failed to get discussion post history",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionPostHistoryResponse{
+		Message:   "Discussion post history retrieved successfully",
+		Revisions: revisions,
+	})
+}
+
+func AddDiscussionReaction(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "AddDiscussionReaction")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.ReactToDiscussionPost(ctx, contextService, c.GetString("username"), c.Param("id"), c.Param("threadId"),
+		c.Param("postId"), c.Param("reaction"), true); err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionModerationResponse{
+			Message: "Failed to update reaction",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionModerationResponse{
+		Message: "Reaction added",
+	})
+}
+
+func RemoveDiscussionReaction(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "RemoveDiscussionReaction")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.ReactToDiscussionPost(ctx, contextService, c.GetString("username"), c.Param("id"), c.Param("threadId"),
+		c.Param("postId"), c.Param("reaction"), false); err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionModerationResponse{
+			Message: "Failed to update reaction",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionModerationResponse{
+		Message: "Reaction removed",
+	})
+}
+
+func PinDiscussionThread(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "PinDiscussionThread")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var pin models.SetDiscussionPinned
+	if err := c.ShouldBindJSON(&pin); err != nil {
+		c.JSON(http.StatusBadRequest, response.DiscussionModerationResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.PinDiscussionThread(ctx, contextService, c.Param("id"), c.Param("threadId"), pin.Pinned); err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionModerationResponse{
+			Message: "Failed to pin discussion thread",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionModerationResponse{
+		Message: "Discussion thread updated",
+	})
+}
+
+func MarkDiscussionAnswer(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "MarkDiscussionAnswer")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var answer models.SetDiscussionAnswer
+	if err := c.ShouldBindJSON(&answer); err != nil {
+		c.JSON(http.StatusBadRequest, response.DiscussionModerationResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.MarkDiscussionAnswer(ctx, contextService, c.Param("id"), c.Param("threadId"), answer.PostId); err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionModerationResponse{
+			Message: "Failed to mark discussion answer",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionModerationResponse{
+		Message: "Discussion answer updated",
+	})
+}
+
+func HideDiscussionThread(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "HideDiscussionThread")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var hide models.SetDiscussionHidden
+	if err := c.ShouldBindJSON(&hide); err != nil {
+		c.JSON(http.StatusBadRequest, response.DiscussionModerationResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.HideDiscussionThread(ctx, contextService, c.Param("id"), c.Param("threadId"), hide.Hidden); err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionModerationResponse{
+			Message: "Failed to hide discussion thread",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionModerationResponse{
+		Message: "Discussion thread updated",
+	})
+}
+
+func HideDiscussionPost(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "HideDiscussionPost")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var hide models.SetDiscussionHidden
+	if err := c.ShouldBindJSON(&hide); err != nil {
+		c.JSON(http.StatusBadRequest, response.DiscussionModerationResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.HideDiscussionPost(ctx, contextService, c.Param("id"), c.Param("threadId"), c.Param("postId"), hide.Hidden); err != nil {
+		c.JSON(http.StatusInternalServerError, response.DiscussionModerationResponse{
+			Message: "Failed to hide discussion post",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.DiscussionModerationResponse{
+		Message: "Discussion post updated",
+	})
+}
@@ -0,0 +1,161 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func SearchOutboxEmails(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SearchOutboxEmails")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.EmailSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.EmailSearchResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	emails, total, err := controller.SearchOutboxEmails(ctx, contextService, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.EmailSearchResponse{
+			Message: "Failed to search emails",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.EmailSearchResponse{
+		Message:  "Emails retrieved successfully",
+		Emails:   emails,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	})
+}
+
+func GetEmailSuppressions(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetEmailSuppressions")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var page models.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, response.EmailSuppressionsResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	page.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	suppressions, total, err := controller.GetEmailSuppressions(ctx, contextService, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.EmailSuppressionsResponse{
+			Message: "Failed to get email suppressions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.EmailSuppressionsResponse{
+		Message:      "Email suppressions retrieved successfully",
+		Suppressions: suppressions,
+		Total:        total,
+		Page:         page.Page,
+		PageSize:     page.PageSize,
+	})
+}
+
+func SuppressEmail(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SuppressEmail")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var suppression models.AddEmailSuppression
+	if err := c.ShouldBindJSON(&suppression); err != nil {
+		c.JSON(http.StatusBadRequest, response.EmailSuppressionResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.SuppressEmail(ctx, contextService, suppression); err != nil {
+		c.JSON(http.StatusInternalServerError, response.EmailSuppressionResponse{
+			Message: "Failed to suppress email address",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.EmailSuppressionResponse{
+		Message: "Email address suppressed successfully",
+	})
+}
+
+func RemoveEmailSuppression(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "RemoveEmailSuppression")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.RemoveEmailSuppression(ctx, contextService, c.Param("email")); err != nil {
+		c.JSON(http.StatusNotFound, response.EmailSuppressionResponse{
+			Message: "Failed to remove email suppression",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.EmailSuppressionResponse{
+		Message: "Email suppression removed successfully",
+	})
+}
@@ -0,0 +1,228 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func SetEnrollmentPolicy(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SetEnrollmentPolicy")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var policy models.UpdateEnrollmentPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, response.EnrollmentPolicyResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.SetEnrollmentPolicy(ctx, contextService, c.Param("id"), policy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.EnrollmentPolicyResponse{
+			Message: "Failed to update enrollment policy",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.EnrollmentPolicyResponse{
+		Message: "Enrollment policy updated",
+		Policy:  policy,
+	})
+}
+
+func GetEnrollmentRequests(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetEnrollmentRequests")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.EnrollmentRequestSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.EnrollmentRequestsResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	requests, total, err := controller.GetEnrollmentRequests(ctx, contextService, c.Param("id"), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.EnrollmentRequestsResponse{
+			Message: "Failed to get enrollment requests",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.EnrollmentRequestsResponse{
+		Message:  "Enrollment requests retrieved successfully",
+		Requests: requests,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	})
+}
+
+func ApproveEnrollmentRequest(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ApproveEnrollmentRequest")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	result, err := controller.ApproveEnrollmentRequest(ctx, contextService, c.Param("id"), c.Param("requestId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.EnrollmentDecisionResponse{
+			Message: "Failed to approve enrollment request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.EnrollmentDecisionResponse{
+		Message: "Enrollment request approved",
+		Result:  *result,
+	})
+}
+
+func RejectEnrollmentRequest(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "RejectEnrollmentRequest")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var reject models.RejectEnrollmentRequest
+	if err := c.ShouldBindJSON(&reject); err != nil {
+		c.JSON(http.StatusBadRequest, response.RejectEnrollmentResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	request, err := controller.RejectEnrollmentRequest(ctx, contextService, c.Param("id"), c.Param("requestId"), reject.Reason)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.RejectEnrollmentResponse{
+			Message: "Failed to reject enrollment request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.RejectEnrollmentResponse{
+		Message: "Enrollment request rejected",
+		Request: *request,
+	})
+}
+
+func CreateInviteCode(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "CreateInviteCode")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var invite models.AddInviteCode
+	if err := c.ShouldBindJSON(&invite); err != nil {
+		c.JSON(http.StatusBadRequest, response.InviteCodeResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.CreateInviteCode(ctx, contextService, c.Param("id"), invite)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.InviteCodeResponse{
+			Message: "Failed to create invite code",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.InviteCodeResponse{
+		Message: "Invite code created",
+		Invite:  *added,
+	})
+}
+
+func GetInviteCodes(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetInviteCodes")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	invites, err := controller.GetInviteCodes(ctx, contextService, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.InviteCodesResponse{
+			Message: "Failed to get invite codes",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.InviteCodesResponse{
+		Message: "Invite codes retrieved successfully",
+		Invites: invites,
+	})
+}
@@ -0,0 +1,178 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetCourseLessons(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseLessons")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	lessons, err := controller.GetCourseLessons(ctx, contextService, c.GetString("username"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, response.LessonsResponse{
+			Message: "Failed to get lessons",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.LessonsResponse{
+		Message: "Lessons retrieved successfully",
+		Lessons: lessons,
+	})
+}
+
+func AddLesson(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "AddLesson")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var lesson models.AddLesson
+	if err := c.ShouldBindJSON(&lesson); err != nil {
+		c.JSON(http.StatusBadRequest, response.AddLessonResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.AddLesson(ctx, contextService, c.Param("id"), lesson)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.AddLessonResponse{
+			Message: "Failed to add lesson",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AddLessonResponse{
+		Message: "Lesson added successfully",
+		Lesson:  *added,
+	})
+}
+
+func CompleteLesson(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "CompleteLesson")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	lessonId := c.Param("lessonId")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	courseCompleted, err := controller.CompleteLesson(ctx, contextService, c.GetString("username"), c.Param("id"), lessonId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.CompleteLessonResponse{
+			Message: "Failed to complete lesson",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CompleteLessonResponse{
+		Message:         "Lesson completed",
+		LessonId:        lessonId,
+		CourseCompleted: courseCompleted,
+	})
+}
+
+func UpdateLesson(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateLesson")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var lesson models.UpdateLesson
+	if err := c.ShouldBindJSON(&lesson); err != nil {
+		c.JSON(http.StatusBadRequest, response.LessonResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	updated, err := controller.UpdateLesson(ctx, contextService, c.Param("id"), c.Param("lessonId"), lesson)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.LessonResponse{
+			Message: "Failed to update lesson",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.LessonResponse{
+		Message: "Lesson updated successfully",
+		Lesson:  updated,
+	})
+}
+
+func RemoveLesson(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "RemoveLesson")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.RemoveLesson(ctx, contextService, c.Param("id"), c.Param("lessonId")); err != nil {
+		c.JSON(http.StatusNotFound, response.LessonResponse{
+			Message: "Failed to remove lesson",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.LessonResponse{
+		Message: "Lesson removed successfully",
+	})
+}
@@ -0,0 +1,226 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func ReportDiscussionPost(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ReportDiscussionPost")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var report models.AddContentReport
+	if err := c.ShouldBindJSON(&report); err != nil {
+		c.JSON(http.StatusBadRequest, response.ContentReportResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.ReportDiscussionPost(ctx, contextService, c.GetString("username"), c.Param("id"), c.Param("threadId"), c.Param("postId"), report)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ContentReportResponse{
+			Message: "Failed to report post",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.ContentReportResponse{
+		Message: "Post reported successfully",
+		Report:  *added,
+	})
+}
+
+func GetContentReports(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetContentReports")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.ContentReportSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.ContentReportsResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	reports, total, err := controller.GetContentReports(ctx, contextService, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ContentReportsResponse{
+			Message: "Failed to get content reports",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.ContentReportsResponse{
+		Message:  "Content reports retrieved successfully",
+		Reports:  reports,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	})
+}
+
+func ActOnContentReport(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ActOnContentReport")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var action models.ModerationAction
+	if err := c.ShouldBindJSON(&action); err != nil {
+		c.JSON(http.StatusBadRequest, response.ContentReportResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	report, err := controller.ActOnContentReport(ctx, contextService, c.GetString("username"), c.Param("id"), action)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.ContentReportResponse{
+			Message: "Failed to act on content report",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.ContentReportResponse{
+		Message: "Content report resolved successfully",
+		Report:  *report,
+	})
+}
+
+func GetWordFilters(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetWordFilters")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	filters, err := controller.GetWordFilters(ctx, contextService)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.WordFiltersResponse{
+			Message: "Failed to get word filters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WordFiltersResponse{
+		Message: "Word filters retrieved successfully",
+		Filters: filters,
+	})
+}
+
+func AddWordFilter(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "AddWordFilter")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.AddWordFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.WordFilterResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.AddWordFilter(ctx, contextService, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.WordFilterResponse{
+			Message: "Failed to add word filter",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WordFilterResponse{
+		Message: "Word filter added successfully",
+		Filter:  *added,
+	})
+}
+
+func DeleteWordFilter(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteWordFilter")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.DeleteWordFilter(ctx, contextService, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, response.WordFilterResponse{
+			Message: "Failed to delete word filter",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WordFilterResponse{
+		Message: "Word filter deleted successfully",
+	})
+}
@@ -0,0 +1,212 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetNotifications(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetNotifications")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.NotificationSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.NotificationsResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	notifications, total, unread, err := controller.GetNotifications(ctx, contextService, c.GetString("username"), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NotificationsResponse{
+			Message: "Failed to get notifications",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NotificationsResponse{
+		Message:       "Notifications retrieved successfully",
+		Notifications: notifications,
+		UnreadCount:   unread,
+		Total:         total,
+		Page:          filter.Page,
+		PageSize:      filter.PageSize,
+	})
+}
+
+func GetUnreadNotificationCount(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetUnreadNotificationCount")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	unread, err := controller.CountUnreadNotifications(ctx, contextService, c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.UnreadNotificationsResponse{
+			Message: "Failed to count unread notifications",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.UnreadNotificationsResponse{
+		Message:     "Unread notifications counted successfully",
+		UnreadCount: unread,
+	})
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "MarkNotificationRead")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	unread, err := controller.MarkNotificationRead(ctx, contextService, c.GetString("username"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.MarkNotificationsReadResponse{
+			Message: "Failed to mark notification read",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.MarkNotificationsReadResponse{
+		Message:     "Notification marked read",
+		Marked:      1,
+		UnreadCount: unread,
+	})
+}
+
+func MarkAllNotificationsRead(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "MarkAllNotificationsRead")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	marked, err := controller.MarkAllNotificationsRead(ctx, contextService, c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.MarkNotificationsReadResponse{
+			Message: "Failed to mark notifications read",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.MarkNotificationsReadResponse{
+		Message: "Notifications marked read",
+		Marked:  marked,
+	})
+}
+
+func GetNotificationPreferences(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetNotificationPreferences")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	preferences, err := controller.GetNotificationPreferences(ctx, contextService, c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.NotificationPreferencesResponse{
+			Message: "Failed to get notification preferences",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NotificationPreferencesResponse{
+		Message:     "Notification preferences retrieved successfully",
+		Preferences: preferences,
+	})
+}
+
+func SetNotificationPreferences(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SetNotificationPreferences")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var update models.UpdateNotificationPreferences
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, response.NotificationPreferencesResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	preferences, err := controller.SetNotificationPreferences(ctx, contextService, c.GetString("username"), update)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.NotificationPreferencesResponse{
+			Message: "Failed to save notification preferences",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.NotificationPreferencesResponse{
+		Message:     "Notification preferences saved successfully",
+		Preferences: preferences,
+	})
+}
@@ -0,0 +1,289 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func CreateOrganization(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "CreateOrganization")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var organization models.AddOrganization
+	if err := c.ShouldBindJSON(&organization); err != nil {
+		c.JSON(http.StatusBadRequest, response.OrganizationResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.CreateOrganization(ctx, contextService, organization)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.OrganizationResponse{
+			Message: "Failed to create organization",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrganizationResponse{
+		Message:      "Organization created successfully",
+		Organization: *added,
+	})
+}
+
+func GetMyOrganizations(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetMyOrganizations")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	organizations, err := controller.GetMyOrganizations(ctx, contextService, c.GetString("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.OrganizationsResponse{
+			Message: "Failed to get organizations",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrganizationsResponse{
+		Message:       "Organizations retrieved successfully",
+		Organizations: organizations,
+	})
+}
+
+func SwitchOrganization(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SwitchOrganization")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var switchOrganization models.SwitchOrganization
+	if err := c.ShouldBindJSON(&switchOrganization); err != nil {
+		c.JSON(http.StatusBadRequest, response.SwitchOrganizationResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+	username := c.GetString("username")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.SwitchOrganization(ctx, contextService, username, switchOrganization.OrganizationId)
+	if err != nil {
+		c.JSON(http.StatusForbidden, response.SwitchOrganizationResponse{
+			Message: "Failed to switch organization",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	token, err := contextService.GetJWTService().GenerateToken(username, switchOrganization.OrganizationId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.SwitchOrganizationResponse{
+			Message: "Failed to generate token",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SwitchOrganizationResponse{
+		Message:        "Organization switched successfully",
+		OrganizationId: switchOrganization.OrganizationId,
+		Token:          token,
+	})
+}
+
+func GetOrganizationMembers(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetOrganizationMembers")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var page models.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, response.OrganizationMembersResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	page.Normalize()
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	members, total, err := controller.GetOrganizationMembers(ctx, contextService, c.GetString("username"), id, page)
+	if err != nil {
+		c.JSON(http.StatusForbidden, response.OrganizationMembersResponse{
+			Message: "Failed to get organization members",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrganizationMembersResponse{
+		Message:  "Organization members retrieved successfully",
+		Members:  members,
+		Total:    total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+	})
+}
+
+func AddOrganizationMember(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "AddOrganizationMember")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var member models.AddOrganizationMember
+	if err := c.ShouldBindJSON(&member); err != nil {
+		c.JSON(http.StatusBadRequest, response.OrganizationMemberResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.AddOrganizationMember(ctx, contextService, c.GetString("username"), id, member)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.OrganizationMemberResponse{
+			Message: "Failed to add organization member",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrganizationMemberResponse{
+		Message:        "Organization member added",
+		OrganizationId: id,
+		Username:       member.Username,
+		Role:           member.Role,
+	})
+}
+
+func ChangeOrganizationMemberRole(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ChangeOrganizationMemberRole")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var changeRole models.ChangeOrganizationRole
+	if err := c.ShouldBindJSON(&changeRole); err != nil {
+		c.JSON(http.StatusBadRequest, response.OrganizationMemberResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+	id := c.Param("id")
+	username := c.Param("username")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.ChangeOrganizationMemberRole(ctx, contextService, c.GetString("username"), id, username, changeRole.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.OrganizationMemberResponse{
+			Message: "Failed to change organization role",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrganizationMemberResponse{
+		Message:        "Organization role changed",
+		OrganizationId: id,
+		Username:       username,
+		Role:           changeRole.Role,
+	})
+}
+
+func RemoveOrganizationMember(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "RemoveOrganizationMember")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	id := c.Param("id")
+	username := c.Param("username")
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := controller.RemoveOrganizationMember(ctx, contextService, c.GetString("username"), id, username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.OrganizationMemberResponse{
+			Message: "Failed to remove organization member",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.OrganizationMemberResponse{
+		Message:        "Organization member removed",
+		OrganizationId: id,
+		Username:       username,
+	})
+}
@@ -0,0 +1,188 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetCourseReviews(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseReviews")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.CourseReviewSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseReviewsResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	reviews, total, err := controller.GetCourseReviews(ctx, contextService, c.Param("id"), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseReviewsResponse{
+			Message: "Failed to get course reviews",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseReviewsResponse{
+		Message:  "Course reviews retrieved successfully",
+		Reviews:  reviews,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	})
+}
+
+func GetMyCourseReview(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetMyCourseReview")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	review, err := controller.GetMyCourseReview(ctx, contextService, c.GetString("username"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseReviewResponse{
+			Message: "Failed to get your review",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseReviewResponse{
+		Message: "Review retrieved successfully",
+		Review:  review,
+	})
+}
+
+func ReviewCourse(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ReviewCourse")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var review models.SetCourseReview
+	if err := c.ShouldBindJSON(&review); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseReviewResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	saved, err := controller.ReviewCourse(ctx, contextService, c.GetString("username"), c.Param("id"), review)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseReviewResponse{
+			Message: "Failed to review course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseReviewResponse{
+		Message: "Review saved successfully",
+		Review:  saved,
+	})
+}
+
+func DeleteMyCourseReview(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteMyCourseReview")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.DeleteMyCourseReview(ctx, contextService, c.GetString("username"), c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, response.CourseReviewResponse{
+			Message: "Failed to delete your review",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseReviewResponse{
+		Message: "Review deleted successfully",
+	})
+}
+
+func ReplyToCourseReview(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ReplyToCourseReview")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var reply models.SetReviewReply
+	if err := c.ShouldBindJSON(&reply); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseReviewResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	review, err := controller.ReplyToCourseReview(ctx, contextService, c.Param("id"), c.Param("reviewId"), reply)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseReviewResponse{
+			Message: "Failed to reply to review",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseReviewResponse{
+		Message: "Reply saved successfully",
+		Review:  review,
+	})
+}
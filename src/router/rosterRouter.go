@@ -0,0 +1,179 @@
+package router
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetCourseRoster(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseRoster")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.RosterSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.RosterResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	roster, total, err := controller.GetCourseRoster(ctx, contextService, c.Param("id"), filter)
+	if err != nil {
+		c.JSON(http.StatusForbidden, response.RosterResponse{
+			Message: "Failed to get enrollments",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.RosterResponse{
+		Message:     "Enrollments retrieved successfully",
+		Enrollments: roster,
+		Total:       total,
+		Page:        filter.Page,
+		PageSize:    filter.PageSize,
+	})
+}
+
+func ExportCourseRoster(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ExportCourseRoster")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.RosterSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.RosterResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// Exports stream rows as they are read, so they get the full write timeout rather than the usual 5 seconds
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	started := false
+	err := controller.ExportCourseRoster(ctx, contextService, c.Param("id"), filter, func() io.Writer {
+		started = true
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", "attachment; filename=enrollments.csv")
+		c.Status(http.StatusOK)
+		return c.Writer
+	})
+	if err != nil {
+		if !started {
+			c.JSON(http.StatusForbidden, response.RosterResponse{
+				Message: "Failed to export enrollments",
+				Error:   err.Error(),
+			})
+			return
+		}
+		// Headers are already sent, so the truncated export can only be reported in the logs
+		log.Println("Error exporting enrollments: ", err)
+	}
+}
+
+func BulkUnenroll(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "BulkUnenroll")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var unenroll models.BulkUnenroll
+	if err := c.ShouldBindJSON(&unenroll); err != nil {
+		c.JSON(http.StatusBadRequest, response.BulkRosterResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	results, err := controller.BulkUnenroll(ctx, contextService, c.Param("id"), unenroll.Usernames)
+	if err != nil {
+		c.JSON(http.StatusForbidden, response.BulkRosterResponse{
+			Message: "Failed to unenroll learners",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.BulkRosterResponse{
+		Message: "Learners unenrolled",
+		Results: results,
+	})
+}
+
+func MessageLearners(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "MessageLearners")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var message models.MessageLearners
+	if err := c.ShouldBindJSON(&message); err != nil {
+		c.JSON(http.StatusBadRequest, response.BulkRosterResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	results, err := controller.MessageLearners(ctx, contextService, c.Param("id"), message.Usernames, message.Message)
+	if err != nil {
+		c.JSON(http.StatusForbidden, response.BulkRosterResponse{
+			Message: "Failed to message learners",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.BulkRosterResponse{
+		Message: "Learners messaged",
+		Results: results,
+	})
+}
@@ -0,0 +1,155 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/services"
+	"orkidslearning/src/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+// sseRetry tells EventSource clients how long to wait before reconnecting, in milliseconds
+const sseRetry = 5000
+
+// StreamEvents pushes the user's notifications and enrollment changes as Server-Sent Events, or over a WebSocket
+// for clients that ask to upgrade. Reconnecting clients pass the last event id they saw in the Last-Event-ID header
+// or the lastEventId query parameter.
+func StreamEvents(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "StreamEvents")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	lastEventId := c.GetHeader("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = c.Query("lastEventId")
+	}
+
+	if utils.IsWebSocketUpgrade(c.Request) {
+		streamOverWebSocket(ctx, c, contextService, lastEventId)
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Println("Error clearing write deadline", err)
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry)
+	c.Writer.Flush()
+
+	if err := controller.StreamEvents(ctx, contextService, c.GetString("username"), lastEventId, sseWriter{c.Writer}); err != nil {
+		log.Println("Event stream ended", err)
+	}
+}
+
+// CreateStreamTicket issues a short-lived ticket that opens the user's event stream when passed in the ticket query
+// parameter, for clients that cannot send the Authorization header
+func CreateStreamTicket(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	_, span := tracer.Start(c.Request.Context(), "CreateStreamTicket")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ticket, lifetime, err := contextService.GetJWTService().GenerateStreamTicket(c.GetString("username"), c.GetString("organizationId"))
+	if err != nil {
+		log.Println("Error generating stream ticket", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate stream ticket"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ticket": ticket, "expiresIn": int(lifetime.Seconds())})
+}
+
+func streamOverWebSocket(ctx context.Context, c *gin.Context, contextService *services.ContextService, lastEventId string) {
+	ws, err := utils.UpgradeWebSocket(c.Writer, c.Request, contextService.GetStreamService().AllowedOrigins())
+	if errors.Is(err, utils.ErrWebSocketOrigin) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer ws.Close()
+
+	// The request context outlives a hijacked connection, so stop when the client closes it
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ws.Closed():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := controller.StreamEvents(ctx, contextService, c.GetString("username"), lastEventId, webSocketWriter{ws}); err != nil {
+		log.Println("Event stream ended", err)
+	}
+}
+
+// sseWriter sends events in the text/event-stream format, each event's data being the whole event as JSON
+type sseWriter struct {
+	w gin.ResponseWriter
+}
+
+func (s sseWriter) WriteEvent(event models.StreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return nil
+}
+
+func (s sseWriter) WriteHeartbeat() error {
+	if _, err := fmt.Fprint(s.w, ": heartbeat\n\n"); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return nil
+}
+
+// webSocketWriter sends each event as a JSON text message
+type webSocketWriter struct {
+	ws *utils.WebSocket
+}
+
+func (w webSocketWriter) WriteEvent(event models.StreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return w.ws.WriteText(data)
+}
+
+func (w webSocketWriter) WriteHeartbeat() error {
+	return w.ws.Ping()
+}
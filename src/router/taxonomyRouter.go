@@ -0,0 +1,184 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetTaxonomy(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetTaxonomy")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	taxonomy, err := controller.GetTaxonomy(ctx, contextService)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.TaxonomyResponse{
+			Message: "Failed to get taxonomy",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.TaxonomyResponse{
+		Message:  "Taxonomy retrieved successfully",
+		Taxonomy: *taxonomy,
+	})
+}
+
+func AddCourseCategory(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "AddCourseCategory")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var category models.AddCourseCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseCategoryResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.AddCourseCategory(ctx, contextService, category)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseCategoryResponse{
+			Message: "Failed to add category",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseCategoryResponse{
+		Message:  "Category added successfully",
+		Category: *added,
+	})
+}
+
+func UpdateCourseCategory(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateCourseCategory")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var category models.AddCourseCategory
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseCategoryResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	updated, err := controller.UpdateCourseCategory(ctx, contextService, c.Param("id"), category)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseCategoryResponse{
+			Message: "Failed to update category",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseCategoryResponse{
+		Message:  "Category updated successfully",
+		Category: *updated,
+	})
+}
+
+func DeleteCourseCategory(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteCourseCategory")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.DeleteCourseCategory(ctx, contextService, c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseCategoryResponse{
+			Message: "Failed to delete category",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseCategoryResponse{
+		Message: "Category deleted successfully",
+	})
+}
+
+func SetCourseTaxonomy(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SetCourseTaxonomy")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var taxonomy models.SetCourseTaxonomy
+	if err := c.ShouldBindJSON(&taxonomy); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseTaxonomyResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	course, err := controller.SetCourseTaxonomy(ctx, contextService, c.Param("id"), taxonomy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseTaxonomyResponse{
+			Message: "Failed to classify course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseTaxonomyResponse{
+		Message: "Course classified successfully",
+		Course:  *course,
+	})
+}
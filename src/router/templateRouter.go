@@ -0,0 +1,193 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func CloneCourse(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "CloneCourse")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var clone models.CloneCourse
+	if err := c.ShouldBindJSON(&clone); err != nil {
+		c.JSON(http.StatusBadRequest, response.CloneCourseResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	course, err := controller.CloneCourse(ctx, contextService, c.Param("id"), clone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CloneCourseResponse{
+			Message: "Failed to clone course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CloneCourseResponse{
+		Message: "Course cloned successfully",
+		Course:  course,
+	})
+}
+
+func GetCourseTemplates(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseTemplates")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.CourseTemplateSearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseTemplatesResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	templates, err := controller.GetCourseTemplates(ctx, contextService, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseTemplatesResponse{
+			Message: "Failed to get course templates",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseTemplatesResponse{
+		Message:   "Course templates retrieved successfully",
+		Templates: templates,
+	})
+}
+
+func CreateCourseFromTemplate(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "CreateCourseFromTemplate")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var clone models.CloneCourse
+	if err := c.ShouldBindJSON(&clone); err != nil {
+		c.JSON(http.StatusBadRequest, response.CloneCourseResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	course, err := controller.CreateCourseFromTemplate(ctx, contextService, c.Param("id"), clone)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CloneCourseResponse{
+			Message: "Failed to create course from template",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CloneCourseResponse{
+		Message: "Course created from template successfully",
+		Course:  course,
+	})
+}
+
+func AddCourseTemplate(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "AddCourseTemplate")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var template models.AddCourseTemplate
+	if err := c.ShouldBindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseTemplateResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.AddCourseTemplate(ctx, contextService, template)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseTemplateResponse{
+			Message: "Failed to add course template",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseTemplateResponse{
+		Message:  "Course template added successfully",
+		Template: added,
+	})
+}
+
+func DeleteCourseTemplate(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteCourseTemplate")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.DeleteCourseTemplate(ctx, contextService, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, response.CourseTemplateResponse{
+			Message: "Failed to remove course template",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseTemplateResponse{
+		Message: "Course template removed successfully",
+	})
+}
@@ -0,0 +1,138 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+// maxImportSize bounds the CSV accepted by an import
+const maxImportSize = 5 << 20
+
+// StartUserImport accepts an import CSV either as the "file" field of a multipart form or as the raw request body.
+// With dryRun=true every row is validated and the report returned without importing anything.
+func StartUserImport(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "StartUserImport")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var options models.UserImportOptions
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.JSON(http.StatusBadRequest, response.UserImportValidationResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	var csvFile io.Reader = c.Request.Body
+	if file, err := c.FormFile("file"); err == nil {
+		opened, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, response.UserImportValidationResponse{
+				Message: "Invalid upload",
+				Error:   err.Error(),
+			})
+			return
+		}
+		defer opened.Close()
+		csvFile = opened
+	}
+
+	rows, err := controller.ParseUserImport(csvFile)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.UserImportValidationResponse{
+			Message: "Invalid CSV file",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	validation, err := controller.ValidateUserImport(ctx, contextService, rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.UserImportValidationResponse{
+			Message: "Failed to validate import",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if options.DryRun || !validation.Valid {
+		status := http.StatusOK
+		message := "Import validated"
+		if !validation.Valid {
+			message = "Import has invalid rows"
+			if !options.DryRun {
+				status = http.StatusUnprocessableEntity
+			}
+		}
+		c.JSON(status, response.UserImportValidationResponse{
+			Message:    message,
+			Validation: validation,
+		})
+		return
+	}
+
+	userImport, err := controller.StartUserImport(ctx, contextService, c.GetString("username"), rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.UserImportResponse{
+			Message: "Failed to start import",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response.UserImportResponse{
+		Message:   "Import started",
+		Import:    *userImport,
+		StatusURL: fmt.Sprintf("/api/admin/user-imports/%s", userImport.Id),
+	})
+}
+
+func GetUserImport(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetUserImport")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	userImport, err := controller.GetUserImport(ctx, contextService, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.UserImportResponse{
+			Message: "Failed to get import",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.UserImportResponse{
+		Message:   "Import retrieved successfully",
+		Import:    *userImport,
+		StatusURL: fmt.Sprintf("/api/admin/user-imports/%s", userImport.Id),
+	})
+}
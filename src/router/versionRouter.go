@@ -0,0 +1,301 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func UpdateCourseDetails(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateCourseDetails")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var details models.UpdateCourseDetails
+	if err := c.ShouldBindJSON(&details); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseDetailsResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	course, err := controller.UpdateCourseDetails(ctx, contextService, c.Param("id"), details)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseDetailsResponse{
+			Message: "Failed to update course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseDetailsResponse{
+		Message: "Course updated successfully",
+		Course:  course,
+	})
+}
+
+func GetCourseVersions(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseVersions")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	versions, err := controller.GetCourseVersions(ctx, contextService, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseVersionsResponse{
+			Message: "Failed to get course versions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseVersionsResponse{
+		Message:  "Course versions retrieved successfully",
+		Versions: versions,
+	})
+}
+
+func GetCourseVersion(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseVersion")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseVersionResponse{
+			Message: "Invalid version number",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	version, err := controller.GetCourseVersion(ctx, contextService, c.Param("id"), number)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseVersionResponse{
+			Message: "Failed to get course version",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseVersionResponse{
+		Message: "Course version retrieved successfully",
+		Version: version,
+	})
+}
+
+func PublishCourseVersion(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "PublishCourseVersion")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var publish models.PublishCourseVersion
+	if err := c.ShouldBindJSON(&publish); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseVersionResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	version, err := controller.PublishCourseVersion(ctx, contextService, c.Param("id"), publish)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseVersionResponse{
+			Message: "Failed to publish course version",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseVersionResponse{
+		Message: "Course version published successfully",
+		Version: version,
+	})
+}
+
+func RollbackCourseVersion(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "RollbackCourseVersion")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseVersionResponse{
+			Message: "Invalid version number",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var publish models.PublishCourseVersion
+	if err := c.ShouldBindJSON(&publish); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseVersionResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	version, err := controller.RollbackCourseVersion(ctx, contextService, c.Param("id"), number, publish)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseVersionResponse{
+			Message: "Failed to roll back course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseVersionResponse{
+		Message: "Course rolled back successfully",
+		Version: version,
+	})
+}
+
+func DiffCourseVersions(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "DiffCourseVersions")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var query models.CourseVersionDiffQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseVersionDiffResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	diff, err := controller.DiffCourseVersions(ctx, contextService, c.Param("id"), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseVersionDiffResponse{
+			Message: "Failed to compare course versions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseVersionDiffResponse{
+		Message: "Course versions compared successfully",
+		Diff:    diff,
+	})
+}
+
+func GetEnrollmentVersion(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetEnrollmentVersion")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	version, err := controller.GetEnrollmentVersion(ctx, contextService, c.GetString("username"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.EnrollmentVersionResponse{
+			Message: "Failed to get enrollment version",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.EnrollmentVersionResponse{
+		Message: "Enrollment version retrieved successfully",
+		Version: version,
+	})
+}
+
+func UpgradeEnrollmentVersion(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "UpgradeEnrollmentVersion")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	version, err := controller.UpgradeEnrollmentVersion(ctx, contextService, c.GetString("username"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.EnrollmentVersionResponse{
+			Message: "Failed to upgrade enrollment",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.EnrollmentVersionResponse{
+		Message: "Enrollment upgraded successfully",
+		Version: version,
+	})
+}
@@ -0,0 +1,276 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func GetWebhooks(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetWebhooks")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	webhooks, err := controller.GetWebhooks(ctx, contextService)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.WebhooksResponse{
+			Message: "Failed to get webhooks",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WebhooksResponse{
+		Message:  "Webhooks retrieved successfully",
+		Webhooks: webhooks,
+	})
+}
+
+func GetWebhook(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetWebhook")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	webhook, err := controller.GetWebhook(ctx, contextService, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.WebhookResponse{
+			Message: "Failed to get webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WebhookResponse{
+		Message: "Webhook retrieved successfully",
+		Webhook: *webhook,
+	})
+}
+
+func CreateWebhook(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "CreateWebhook")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var webhook models.AddWebhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		c.JSON(http.StatusBadRequest, response.WebhookResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	added, err := controller.CreateWebhook(ctx, contextService, webhook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.WebhookResponse{
+			Message: "Failed to create webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WebhookResponse{
+		Message: "Webhook created successfully",
+		Webhook: *added,
+	})
+}
+
+func UpdateWebhook(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "UpdateWebhook")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var webhook models.UpdateWebhook
+	if err := c.ShouldBindJSON(&webhook); err != nil {
+		c.JSON(http.StatusBadRequest, response.WebhookResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	updated, err := controller.UpdateWebhook(ctx, contextService, c.Param("id"), webhook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.WebhookResponse{
+			Message: "Failed to update webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WebhookResponse{
+		Message: "Webhook updated successfully",
+		Webhook: *updated,
+	})
+}
+
+func DeleteWebhook(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "DeleteWebhook")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := controller.DeleteWebhook(ctx, contextService, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, response.WebhookResponse{
+			Message: "Failed to delete webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WebhookResponse{
+		Message: "Webhook deleted successfully",
+	})
+}
+
+func GetWebhookDeliveries(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetWebhookDeliveries")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var filter models.WebhookDeliverySearch
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, response.WebhookDeliveriesResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	filter.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	deliveries, total, err := controller.GetWebhookDeliveries(ctx, contextService, c.Param("id"), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.WebhookDeliveriesResponse{
+			Message: "Failed to get webhook deliveries",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WebhookDeliveriesResponse{
+		Message:    "Webhook deliveries retrieved successfully",
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       filter.Page,
+		PageSize:   filter.PageSize,
+	})
+}
+
+func RedeliverWebhook(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "RedeliverWebhook")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	delivery, err := controller.RedeliverWebhook(ctx, contextService, c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.WebhookDeliveryResponse{
+			Message: "Failed to redeliver webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WebhookDeliveryResponse{
+		Message:  "Webhook redelivery queued",
+		Delivery: *delivery,
+	})
+}
+
+func PingWebhook(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "PingWebhook")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	// The ping waits for the endpoint to answer
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	delivery, err := controller.PingWebhook(ctx, contextService, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.WebhookDeliveryResponse{
+			Message: "Failed to ping webhook",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.WebhookDeliveryResponse{
+		Message:  "Webhook pinged",
+		Delivery: *delivery,
+	})
+}
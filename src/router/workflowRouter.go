@@ -0,0 +1,276 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"orkidslearning/src/controller"
+	models "orkidslearning/src/models/database"
+	"orkidslearning/src/models/response"
+	"orkidslearning/src/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+)
+
+func SubmitCourseForReview(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "SubmitCourseForReview")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var submit models.SubmitCourse
+	if err := c.ShouldBindJSON(&submit); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseWorkflowStepResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	step, err := controller.SubmitCourseForReview(ctx, contextService, c.Param("id"), submit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseWorkflowStepResponse{
+			Message: "Failed to submit course for review",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseWorkflowStepResponse{
+		Message: "Course submitted for review successfully",
+		Step:    step,
+	})
+}
+
+func ApproveCourse(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "ApproveCourse")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var approve models.ApproveCourse
+	if err := c.ShouldBindJSON(&approve); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseWorkflowStepResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	step, err := controller.ApproveCourse(ctx, contextService, c.Param("id"), approve)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseWorkflowStepResponse{
+			Message: "Failed to approve course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseWorkflowStepResponse{
+		Message: "Course approved successfully",
+		Step:    step,
+	})
+}
+
+func RequestCourseChanges(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "RequestCourseChanges")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var request models.RequestCourseChanges
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseWorkflowStepResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	step, err := controller.RequestCourseChanges(ctx, contextService, c.Param("id"), request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseWorkflowStepResponse{
+			Message: "Failed to request course changes",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseWorkflowStepResponse{
+		Message: "Course changes requested successfully",
+		Step:    step,
+	})
+}
+
+func PublishCourse(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "PublishCourse")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var publish models.PublishCourse
+	if err := c.ShouldBindJSON(&publish); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseWorkflowStepResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	step, err := controller.PublishCourse(ctx, contextService, c.Param("id"), publish)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseWorkflowStepResponse{
+			Message: "Failed to publish course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseWorkflowStepResponse{
+		Message: "Course publication updated successfully",
+		Step:    step,
+	})
+}
+
+func UnpublishCourse(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "UnpublishCourse")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var unpublish models.UnpublishCourse
+	if err := c.ShouldBindJSON(&unpublish); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseWorkflowStepResponse{
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	step, err := controller.UnpublishCourse(ctx, contextService, c.Param("id"), unpublish)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseWorkflowStepResponse{
+			Message: "Failed to unpublish course",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseWorkflowStepResponse{
+		Message: "Course unpublished successfully",
+		Step:    step,
+	})
+}
+
+func GetCourseWorkflow(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseWorkflow")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	course, steps, err := controller.GetCourseWorkflow(ctx, contextService, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseWorkflowResponse{
+			Message: "Failed to get course workflow",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseWorkflowResponse{
+		Message: "Course workflow retrieved successfully",
+		Course:  course,
+		Steps:   steps,
+	})
+}
+
+func GetCourseReviewQueue(c *gin.Context) {
+	tracer := otel.Tracer("router")
+	ctx, span := tracer.Start(c.Request.Context(), "GetCourseReviewQueue")
+	defer span.End()
+
+	contextService, exists := c.MustGet("contextService").(*services.ContextService)
+	if !exists {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load contextService"})
+		return
+	}
+
+	var page models.Pagination
+	if err := c.ShouldBindQuery(&page); err != nil {
+		c.JSON(http.StatusBadRequest, response.CourseReviewQueueResponse{
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+	page.Normalize()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	courses, total, err := controller.GetCourseReviewQueue(ctx, contextService, page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.CourseReviewQueueResponse{
+			Message: "Failed to get courses in review",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.CourseReviewQueueResponse{
+		Message:  "Courses in review retrieved successfully",
+		Courses:  courses,
+		Total:    total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+	})
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"log"
+	"time"
+)
+
+// AccountService holds the retention rules for account data
+type AccountService struct {
+	deletionGracePeriod time.Duration
+	exportLifetime      time.Duration
+}
+
+func NewAccountService(deletionGracePeriodString string, exportLifetimeString string) *AccountService {
+	deletionGracePeriod, err := time.ParseDuration(deletionGracePeriodString)
+	if err != nil {
+		log.Fatal("Invalid account deletion grace period:", err)
+	}
+	exportLifetime, err := time.ParseDuration(exportLifetimeString)
+	if err != nil {
+		log.Fatal("Invalid data export lifetime:", err)
+	}
+	return &AccountService{deletionGracePeriod: deletionGracePeriod, exportLifetime: exportLifetime}
+}
+
+// PurgeTime returns when an account whose deletion was requested at requestedAt will be purged
+func (s *AccountService) PurgeTime(requestedAt time.Time) time.Time {
+	return requestedAt.Add(s.deletionGracePeriod)
+}
+
+// PurgeCutoff returns the latest deletion request time that is due for purging now
+func (s *AccountService) PurgeCutoff() time.Time {
+	return time.Now().Add(-s.deletionGracePeriod)
+}
+
+// ExportExpiry returns when an export completed now stops being downloadable
+func (s *AccountService) ExportExpiry() time.Time {
+	return time.Now().Add(s.exportLifetime)
+}
@@ -8,6 +8,13 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenPurposeStream marks a stream ticket, a short-lived token that only opens event streams
+const TokenPurposeStream = "stream"
+
+// streamTicketLifetime is how long a stream ticket can be used to open a stream. Tickets are passed in the URL, where
+// they end up in traces and proxy logs, so they expire long before anyone reading those could use them.
+const streamTicketLifetime = 30 * time.Second
+
 type JWTService struct {
 	secretKey      string
 	expirationTime time.Duration
@@ -21,10 +28,11 @@ func NewJWTService(secretKey string, expirationDurationString string) *JWTServic
 	return &JWTService{secretKey: secretKey, expirationTime: expirationDuration}
 }
 
-// GenerateToken creates a new JWT token
-func (s *JWTService) GenerateToken(username string) (string, error) {
+// GenerateToken creates a new JWT token scoped to an organization, or to none when organizationId is empty
+func (s *JWTService) GenerateToken(username string, organizationId string) (string, error) {
 	claims := jwt.MapClaims{
 		"username": username,
+		"org":      organizationId,
 		"exp":      time.Now().Add(s.expirationTime).Unix(),
 	}
 
@@ -32,6 +40,21 @@ func (s *JWTService) GenerateToken(username string) (string, error) {
 	return token.SignedString([]byte(s.secretKey))
 }
 
+// GenerateStreamTicket creates a stream ticket for clients that cannot set headers, such as EventSource and WebSocket
+// in browsers. It is not accepted in place of the token it was issued for.
+func (s *JWTService) GenerateStreamTicket(username string, organizationId string) (string, time.Duration, error) {
+	claims := jwt.MapClaims{
+		"username": username,
+		"org":      organizationId,
+		"purpose":  TokenPurposeStream,
+		"exp":      time.Now().Add(streamTicketLifetime).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(s.secretKey))
+	return signed, streamTicketLifetime, err
+}
+
 // ValidateToken validates a JWT token
 func (s *JWTService) ValidateToken(tokenString string) (*jwt.Token, error) {
 	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
@@ -6,14 +6,21 @@ import (
 
 // ContextService is a service that provides a context
 type ContextService struct {
-	db         *database.Database
-	jwtService *JWTService
-	postgres   *database.PostgresDatabase
+	db                *database.Database
+	jwtService        *JWTService
+	postgres          *database.PostgresDatabase
+	accountService    *AccountService
+	jobService        *JobService
+	mailService       *MailService
+	streamService     *StreamService
+	webhookService    *WebhookService
+	eventBus          *EventBus
+	moderationService *ModerationService
 }
 
 // NewContextService creates a new ContextService
-func NewContextService(db *database.Database, jwtService *JWTService, postgres *database.PostgresDatabase) *ContextService {
-	return &ContextService{db: db, jwtService: jwtService, postgres: postgres}
+func NewContextService(db *database.Database, jwtService *JWTService, postgres *database.PostgresDatabase, accountService *AccountService, jobService *JobService, mailService *MailService, streamService *StreamService, webhookService *WebhookService, eventBus *EventBus, moderationService *ModerationService) *ContextService {
+	return &ContextService{db: db, jwtService: jwtService, postgres: postgres, accountService: accountService, jobService: jobService, mailService: mailService, streamService: streamService, webhookService: webhookService, eventBus: eventBus, moderationService: moderationService}
 }
 
 // GetDB returns the database
@@ -30,3 +37,38 @@ func (s *ContextService) GetJWTService() *JWTService {
 func (s *ContextService) GetPostgres() *database.PostgresDatabase {
 	return s.postgres
 }
+
+// GetAccountService returns the account service
+func (s *ContextService) GetAccountService() *AccountService {
+	return s.accountService
+}
+
+// GetJobService returns the background job service
+func (s *ContextService) GetJobService() *JobService {
+	return s.jobService
+}
+
+// GetMailService returns the email service
+func (s *ContextService) GetMailService() *MailService {
+	return s.mailService
+}
+
+// GetStreamService returns the service pushing events to connected clients
+func (s *ContextService) GetStreamService() *StreamService {
+	return s.streamService
+}
+
+// GetWebhookService returns the service sending webhook deliveries
+func (s *ContextService) GetWebhookService() *WebhookService {
+	return s.webhookService
+}
+
+// GetEventBus returns the bus domain events are published on
+func (s *ContextService) GetEventBus() *EventBus {
+	return s.eventBus
+}
+
+// GetModerationService returns the service applying word filters and posting limits
+func (s *ContextService) GetModerationService() *ModerationService {
+	return s.moderationService
+}
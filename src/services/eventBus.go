@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	models "orkidslearning/src/models/database"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EventHandler reacts to a published event. The change behind the event is already stored, so errors are logged
+// rather than failing the publisher.
+type EventHandler func(ctx context.Context, event models.Event) error
+
+const (
+	// eventQueueSize is how many events can wait for the asynchronous subscribers before Publish blocks
+	eventQueueSize = 256
+	// eventHandlerTimeout bounds each asynchronous subscriber call
+	eventHandlerTimeout = 30 * time.Second
+)
+
+// queuedEvent is an event waiting for the asynchronous subscribers
+type queuedEvent struct {
+	ctx   context.Context
+	event models.Event
+}
+
+// EventBus delivers domain events to the parts of the service that react to them. Synchronous subscribers run
+// in the publisher's request, in the order they subscribed; asynchronous subscribers run on background workers
+// after Publish returns. Events only reach subscribers in this process.
+type EventBus struct {
+	mu            sync.RWMutex
+	handlers      map[string][]EventHandler
+	asyncHandlers map[string][]EventHandler
+
+	queue     chan queuedEvent
+	workers   int
+	running   sync.WaitGroup
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewEventBus(workers int) *EventBus {
+	return &EventBus{
+		handlers:      map[string][]EventHandler{},
+		asyncHandlers: map[string][]EventHandler{},
+		queue:         make(chan queuedEvent, eventQueueSize),
+		workers:       workers,
+		done:          make(chan struct{}),
+	}
+}
+
+// Subscribe calls handler in the publisher's request for every event with the given name
+func (b *EventBus) Subscribe(name string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// SubscribeAsync calls handler on a background worker for every event with the given name. The handler keeps the
+// values of the publisher's context, such as the actor and tenant, but not its deadline.
+func (b *EventBus) SubscribeAsync(name string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.asyncHandlers[name] = append(b.asyncHandlers[name], handler)
+}
+
+// Publish runs the synchronous subscribers of the event and queues it for the asynchronous ones. If the queue is
+// full it waits for room until ctx ends, dropping the event for the asynchronous subscribers then. Once Drain has
+// been called, events are dropped for the asynchronous subscribers straight away.
+func (b *EventBus) Publish(ctx context.Context, event models.Event) {
+	tracer := otel.Tracer("services")
+	ctx, span := tracer.Start(ctx, "PublishEvent", trace.WithAttributes(attribute.String("event.name", event.EventName())))
+	defer span.End()
+
+	b.mu.RLock()
+	handlers := b.handlers[event.EventName()]
+	async := len(b.asyncHandlers[event.EventName()]) > 0
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.call(ctx, handler, event)
+	}
+	if !async {
+		return
+	}
+
+	select {
+	case <-b.done:
+		log.Println("Dropped event for asynchronous subscribers", event.EventName(), "the event bus is draining")
+		return
+	default:
+	}
+	select {
+	case b.queue <- queuedEvent{ctx: context.WithoutCancel(ctx), event: event}:
+	case <-b.done:
+		log.Println("Dropped event for asynchronous subscribers", event.EventName(), "the event bus is draining")
+	case <-ctx.Done():
+		log.Println("Dropped event for asynchronous subscribers", event.EventName(), ctx.Err())
+	}
+}
+
+// Start runs the workers calling asynchronous subscribers until Drain
+func (b *EventBus) Start() {
+	for i := 0; i < b.workers; i++ {
+		b.running.Add(1)
+		go func() {
+			defer b.running.Done()
+			for {
+				select {
+				case queued := <-b.queue:
+					b.dispatch(queued)
+				case <-b.done:
+					b.dispatchQueued()
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Drain stops accepting events and waits for the queued ones to reach their asynchronous subscribers. Events
+// published afterwards only reach the synchronous subscribers. If ctx ends first the remaining events are abandoned.
+func (b *EventBus) Drain(ctx context.Context) error {
+	b.closeOnce.Do(func() { close(b.done) })
+	done := make(chan struct{})
+	go func() {
+		b.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dispatchQueued dispatches the events still queued when the bus started draining
+func (b *EventBus) dispatchQueued() {
+	for {
+		select {
+		case queued := <-b.queue:
+			b.dispatch(queued)
+		default:
+			return
+		}
+	}
+}
+
+// dispatch calls every asynchronous subscriber of a queued event
+func (b *EventBus) dispatch(queued queuedEvent) {
+	b.mu.RLock()
+	handlers := b.asyncHandlers[queued.event.EventName()]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		ctx, cancel := context.WithTimeout(queued.ctx, eventHandlerTimeout)
+		b.call(ctx, handler, queued.event)
+		cancel()
+	}
+}
+
+// call runs one subscriber, logging its error and turning a panic into one so a bad subscriber cannot break the
+// publisher or a worker
+func (b *EventBus) call(ctx context.Context, handler EventHandler, event models.Event) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Println("Event subscriber failed", event.EventName(), fmt.Errorf("subscriber panicked: %v", recovered))
+		}
+	}()
+	if err := handler(ctx, event); err != nil {
+		log.Println("Event subscriber failed", event.EventName(), err)
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	models "orkidslearning/src/models/database"
+)
+
+// Publishing while and after the bus drains must not panic, and events queued before Drain still reach the
+// asynchronous subscribers
+func TestEventBusPublishDuringDrain(t *testing.T) {
+	bus := NewEventBus(2)
+	var delivered atomic.Int64
+	bus.SubscribeAsync(models.EventUserSignedUp, func(ctx context.Context, event models.Event) error {
+		delivered.Add(1)
+		return nil
+	})
+	bus.Start()
+
+	const queued = 10
+	for i := 0; i < queued; i++ {
+		bus.Publish(context.Background(), models.UserSignedUp{})
+	}
+
+	var publishers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		publishers.Add(1)
+		go func() {
+			defer publishers.Done()
+			for j := 0; j < 100; j++ {
+				bus.Publish(context.Background(), models.UserSignedUp{})
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bus.Drain(ctx); err != nil {
+		t.Fatalf("draining: %v", err)
+	}
+	publishers.Wait()
+	bus.Publish(context.Background(), models.UserSignedUp{})
+
+	if got := delivered.Load(); got < queued {
+		t.Errorf("%d events delivered, want at least the %d queued before draining", got, queued)
+	}
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"orkidslearning/src/database"
+	models "orkidslearning/src/models/database"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// JobHandler runs one job. Returning an error fails the attempt, which is retried with backoff until the job
+// runs out of attempts.
+type JobHandler func(ctx context.Context, job models.Job) error
+
+// QueueConfig sets how many jobs of a queue run at once, how long each may take and how often it is attempted
+type QueueConfig struct {
+	Name        string
+	Concurrency int
+	Timeout     time.Duration
+	MaxAttempts int
+}
+
+const (
+	// jobPollInterval is how often idle workers look for jobs enqueued by other replicas or whose delay has passed
+	jobPollInterval = 2 * time.Second
+	// jobRetryBase and jobRetryMax bound the exponential backoff between attempts
+	jobRetryBase = 30 * time.Second
+	jobRetryMax  = time.Hour
+)
+
+// JobService runs background jobs stored in Postgres. Any number of replicas can work the same queues.
+type JobService struct {
+	postgres *database.PostgresDatabase
+	worker   string
+	queues   map[string]QueueConfig
+	wake     map[string]chan struct{}
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+
+	running    sync.WaitGroup
+	cancelJobs context.CancelFunc
+}
+
+func NewJobService(postgres *database.PostgresDatabase, queues []QueueConfig) *JobService {
+	hostname, _ := os.Hostname()
+	s := &JobService{
+		postgres: postgres,
+		worker:   fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		queues:   map[string]QueueConfig{},
+		wake:     map[string]chan struct{}{},
+		handlers: map[string]JobHandler{},
+	}
+	for _, queue := range queues {
+		s.queues[queue.Name] = queue
+		s.wake[queue.Name] = make(chan struct{}, 1)
+	}
+	return s
+}
+
+// Handle registers the handler for a kind of job
+func (s *JobService) Handle(kind string, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = handler
+}
+
+// Enqueue adds a job to run as soon as a worker of the queue is free
+func (s *JobService) Enqueue(queue, kind string, payload interface{}) (*models.Job, error) {
+	return s.EnqueueAt(queue, kind, payload, time.Now())
+}
+
+// EnqueueAt adds a job that does not run before runAt
+func (s *JobService) EnqueueAt(queue, kind string, payload interface{}, runAt time.Time) (*models.Job, error) {
+	config, ok := s.queues[queue]
+	if !ok {
+		return nil, fmt.Errorf("unknown job queue '%s'", queue)
+	}
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	job, err := s.postgres.EnqueueJob(queue, kind, encoded, runAt, config.MaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	s.Wake(queue)
+	return job, nil
+}
+
+// MaxAttempts returns how often jobs of the queue are attempted, for code that inserts jobs itself
+func (s *JobService) MaxAttempts(queue string) int {
+	return s.queues[queue].MaxAttempts
+}
+
+// Wake lets an idle worker of the queue look for jobs straight away instead of at its next poll
+func (s *JobService) Wake(queue string) {
+	select {
+	case s.wake[queue] <- struct{}{}:
+	default:
+	}
+}
+
+// Start runs the workers of every queue until ctx is cancelled. Jobs already running are left to finish; see Drain.
+func (s *JobService) Start(ctx context.Context) {
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	s.cancelJobs = cancelJobs
+
+	for _, queue := range s.queues {
+		for i := 0; i < queue.Concurrency; i++ {
+			s.running.Add(1)
+			go func() {
+				defer s.running.Done()
+				s.work(ctx, jobCtx, queue)
+			}()
+		}
+		go s.releaseStale(ctx, queue)
+	}
+}
+
+// Drain waits for running jobs to finish once Start's context is cancelled. If ctx ends first the jobs are cancelled,
+// and whatever they did not finish is retried later.
+func (s *JobService) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancelJobs()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work claims and runs jobs of one queue, one at a time, until ctx is cancelled
+func (s *JobService) work(ctx, jobCtx context.Context, queue QueueConfig) {
+	for ctx.Err() == nil {
+		job, err := s.postgres.ClaimJob(queue.Name, s.worker)
+		if err != nil {
+			log.Println("Error claiming job", err)
+		}
+		if job != nil {
+			s.run(jobCtx, queue, *job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-s.wake[queue.Name]:
+		case <-time.After(jobPollInterval):
+		}
+	}
+}
+
+// run executes a claimed job and records its outcome
+func (s *JobService) run(ctx context.Context, queue QueueConfig, job models.Job) {
+	tracer := otel.Tracer("services")
+	ctx, span := tracer.Start(ctx, "RunJob", trace.WithAttributes(
+		attribute.String("job.id", job.Id),
+		attribute.String("job.queue", job.Queue),
+		attribute.String("job.kind", job.Kind),
+		attribute.Int("job.attempt", job.Attempts),
+	))
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, queue.Timeout)
+	defer cancel()
+
+	err := s.handle(ctx, job)
+	if err == nil {
+		if err := s.postgres.CompleteJob(job.Id, s.worker, job.Attempts); err != nil {
+			log.Println("Error completing job", job.Id, err)
+		}
+		return
+	}
+
+	span.RecordError(err)
+	status, failErr := s.postgres.FailJob(job.Id, s.worker, job.Attempts, err.Error(), time.Now().Add(retryBackoff(job.Attempts)))
+	if failErr != nil {
+		log.Println("Error failing job", job.Id, failErr)
+		return
+	}
+	log.Printf("Job %s (%s) attempt %d/%d failed, now %s: %v", job.Id, job.Kind, job.Attempts, job.MaxAttempts, status, err)
+}
+
+// handle calls the job's handler, turning a panic into an error so one bad job cannot stop the worker
+func (s *JobService) handle(ctx context.Context, job models.Job) (err error) {
+	s.mu.RLock()
+	handler, ok := s.handlers[job.Kind]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler for job kind '%s'", job.Kind)
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// releaseStale periodically hands jobs whose worker disappeared, for instance because its replica crashed,
+// back to the queue. A job is considered abandoned once it has run for twice the queue's timeout.
+func (s *JobService) releaseStale(ctx context.Context, queue QueueConfig) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		released, err := s.postgres.ReleaseStaleJobs(queue.Name, time.Now().Add(-2*queue.Timeout))
+		if err != nil {
+			log.Println("Error releasing stale jobs", err)
+		} else if released > 0 {
+			log.Printf("Released %d stale jobs of queue %s", released, queue.Name)
+			s.Wake(queue.Name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// retryBackoff returns how long to wait before the attempt after the given one, doubling from jobRetryBase up to
+// jobRetryMax, with up to 10% jitter so that jobs which failed together do not all retry together
+func retryBackoff(attempt int) time.Duration {
+	backoff := jobRetryBase
+	for i := 1; i < attempt && backoff < jobRetryMax; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, jobRetryMax)
+	return backoff + rand.N(backoff/10+1)
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		backoff time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{1000, time.Hour},
+	}
+	for _, test := range tests {
+		// The jitter adds up to a tenth of the backoff, never more and never less than nothing
+		spread := false
+		for i := 0; i < 100; i++ {
+			got := retryBackoff(test.attempt)
+			if got < test.backoff || got > test.backoff+test.backoff/10 {
+				t.Fatalf("retryBackoff(%d) = %v, want between %v and %v", test.attempt, got, test.backoff, test.backoff+test.backoff/10)
+			}
+			spread = spread || got != test.backoff
+		}
+		if !spread {
+			t.Errorf("retryBackoff(%d) never added jitter", test.attempt)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/email
+var emailTemplates embed.FS
+
+// defaultLocale is used when no template exists for the recipient's language
+const defaultLocale = "en"
+
+// MailService renders email templates and hands the result to a Mailer.
+//
+// Each email has a plain-text template, templates/email/<name>.<locale>.txt, defining a "subject" and a "text"
+// block, and optionally an HTML template, templates/email/<name>.<locale>.html. A locale such as pt-BR falls back
+// to pt and then to en.
+type MailService struct {
+	mailer Mailer
+	appURL string
+}
+
+func NewMailService(mailer Mailer, appURL string) *MailService {
+	return &MailService{mailer: mailer, appURL: appURL}
+}
+
+// AppURL returns the address of the frontend, for links in emails
+func (s *MailService) AppURL() string {
+	return s.appURL
+}
+
+// Send delivers a rendered email
+func (s *MailService) Send(ctx context.Context, message EmailMessage) error {
+	return s.mailer.Send(ctx, message)
+}
+
+// Render fills in the template with data in the best available variant for locale and returns the rendered email
+// together with the locale that was used
+func (s *MailService) Render(to, name, locale string, data interface{}) (EmailMessage, string, error) {
+	for _, candidate := range localeFallbacks(locale) {
+		textSource, err := fs.ReadFile(emailTemplates, fmt.Sprintf("templates/email/%s.%s.txt", name, candidate))
+		if err != nil {
+			continue
+		}
+
+		message := EmailMessage{To: to}
+		text, err := texttemplate.New(name).Parse(string(textSource))
+		if err != nil {
+			return EmailMessage{}, "", fmt.Errorf("invalid email template '%s.%s': %w", name, candidate, err)
+		}
+		var subject, body bytes.Buffer
+		if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+			return EmailMessage{}, "", err
+		}
+		if err := text.ExecuteTemplate(&body, "text", data); err != nil {
+			return EmailMessage{}, "", err
+		}
+		message.Subject = strings.TrimSpace(subject.String())
+		message.Text = strings.TrimSpace(body.String()) + "\n"
+
+		if htmlSource, err := fs.ReadFile(emailTemplates, fmt.Sprintf("templates/email/%s.%s.html", name, candidate)); err == nil {
+			html, err := htmltemplate.New(name).Parse(string(htmlSource))
+			if err != nil {
+				return EmailMessage{}, "", fmt.Errorf("invalid email template '%s.%s': %w", name, candidate, err)
+			}
+			var htmlBody bytes.Buffer
+			if err := html.Execute(&htmlBody, data); err != nil {
+				return EmailMessage{}, "", err
+			}
+			message.HTML = htmlBody.String()
+		}
+		return message, candidate, nil
+	}
+	return EmailMessage{}, "", fmt.Errorf("unknown email template '%s'", name)
+}
+
+// localeFallbacks lists the locales to try for a template, most specific first
+func localeFallbacks(locale string) []string {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	var locales []string
+	if locale != "" {
+		locales = append(locales, locale)
+		if language, _, found := strings.Cut(locale, "-"); found {
+			locales = append(locales, language)
+		}
+	}
+	return append(locales, defaultLocale)
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// EmailMessage is a rendered email ready to be delivered
+type EmailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers email
+type Mailer interface {
+	Send(ctx context.Context, message EmailMessage) error
+}
+
+// ErrPermanentDelivery marks a failure retrying will not fix, such as the receiving server rejecting the address
+var ErrPermanentDelivery = errors.New("email was permanently rejected")
+
+// NewMailer creates the mailer selected by driver: smtp, file (one .eml file per email in dir) or log
+func NewMailer(driver, from, host, port, username, password, dir string) Mailer {
+	switch driver {
+	case "smtp":
+		return &SMTPMailer{from: from, host: host, port: port, username: username, password: password}
+	case "file":
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			log.Fatal("Invalid mail directory:", err)
+		}
+		return &FileMailer{from: from, dir: dir}
+	case "log":
+		return &LogMailer{from: from}
+	}
+	log.Fatalf("Unknown mail driver %q", driver)
+	return nil
+}
+
+// SMTPMailer delivers email through an SMTP server, authenticating when a username is set
+type SMTPMailer struct {
+	from     string
+	host     string
+	port     string
+	username string
+	password string
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message EmailMessage) error {
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	body, err := buildEmail(m.from, message)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	err = smtp.SendMail(net.JoinHostPort(m.host, m.port), auth, sender.Address, []string{message.To}, body)
+
+	// 5xx replies are permanent: the server will keep refusing this message
+	var protocolErr *textproto.Error
+	if errors.As(err, &protocolErr) && protocolErr.Code >= 500 {
+		return fmt.Errorf("%w: %v", ErrPermanentDelivery, err)
+	}
+	return err
+}
+
+// FileMailer writes each email to its own .eml file, which is handy in development and tests
+type FileMailer struct {
+	from string
+	dir  string
+}
+
+func (m *FileMailer) Send(ctx context.Context, message EmailMessage) error {
+	body, err := buildEmail(m.from, message)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), randomHex(4))
+	return os.WriteFile(filepath.Join(m.dir, name), body, 0o644)
+}
+
+// LogMailer records each email in the service log without sending it. Only the recipient and subject are logged,
+// since bodies carry invitation and password reset links; use the file driver to read them during development.
+type LogMailer struct {
+	from string
+}
+
+func (m *LogMailer) Send(ctx context.Context, message EmailMessage) error {
+	log.Printf("Email from %s to %s: %s", m.from, message.To, message.Subject)
+	return nil
+}
+
+// buildEmail encodes a message as a MIME email with plain-text and HTML alternatives
+func buildEmail(from string, message EmailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	headers := []struct{ name, value string }{
+		{"From", from},
+		{"To", message.To},
+		{"Subject", mime.QEncoding.Encode("utf-8", message.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@orkidslearning>", randomHex(16))},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", body.Boundary())},
+	}
+	var email bytes.Buffer
+	for _, header := range headers {
+		fmt.Fprintf(&email, "%s: %s\r\n", header.name, header.value)
+	}
+	email.WriteString("\r\n")
+
+	parts := []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", message.Text},
+		{"text/html; charset=utf-8", message.HTML},
+	}
+	for _, part := range parts {
+		if part.content == "" {
+			continue
+		}
+		writer, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	email.Write(buf.Bytes())
+	return email.Bytes(), nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
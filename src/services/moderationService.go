@@ -0,0 +1,59 @@
+package services
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	models "orkidslearning/src/models/database"
+)
+
+// ModerationService holds the limits on how fast learners may post, and matches posts against word filters
+type ModerationService struct {
+	postRateLimit  int
+	postRateWindow time.Duration
+}
+
+func NewModerationService(rateLimitString string, rateWindowString string) *ModerationService {
+	rateLimit, err := strconv.Atoi(rateLimitString)
+	if err != nil || rateLimit < 1 {
+		log.Fatal("Invalid post rate limit:", rateLimitString)
+	}
+	rateWindow, err := time.ParseDuration(rateWindowString)
+	if err != nil || rateWindow <= 0 {
+		log.Fatal("Invalid post rate window:", rateWindowString)
+	}
+	return &ModerationService{postRateLimit: rateLimit, postRateWindow: rateWindow}
+}
+
+// PostRateLimit returns how many posts a learner may write within the returned window
+func (s *ModerationService) PostRateLimit() (int, time.Duration) {
+	return s.postRateLimit, s.postRateWindow
+}
+
+// MatchWordFilters checks text against word filters, matching each pattern as a whole word or phrase regardless
+// of case. It reports whether a blocking filter matched, and which flagging patterns did.
+func (s *ModerationService) MatchWordFilters(text string, filters []models.WordFilter) (bool, []string) {
+	flagged := []string{}
+	for _, filter := range filters {
+		pattern := regexp.QuoteMeta(strings.TrimSpace(filter.Pattern))
+		if pattern == "" {
+			continue
+		}
+		matcher, err := regexp.Compile(`(?i)(^|\W)` + pattern + `($|\W)`)
+		if err != nil {
+			log.Println("Invalid word filter:", filter.Pattern, err)
+			continue
+		}
+		if !matcher.MatchString(text) {
+			continue
+		}
+		if filter.Action == models.WordFilterBlock {
+			return true, nil
+		}
+		flagged = append(flagged, filter.Pattern)
+	}
+	return false, flagged
+}
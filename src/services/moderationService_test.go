@@ -0,0 +1,41 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	models "orkidslearning/src/models/database"
+)
+
+func TestMatchWordFilters(t *testing.T) {
+	filters := []models.WordFilter{
+		{Pattern: "spam", Action: models.WordFilterFlag},
+		{Pattern: "buy now", Action: models.WordFilterFlag},
+		{Pattern: "c++", Action: models.WordFilterFlag},
+		{Pattern: "  ", Action: models.WordFilterBlock},
+		{Pattern: "scam", Action: models.WordFilterBlock},
+	}
+	tests := []struct {
+		text    string
+		blocked bool
+		flagged []string
+	}{
+		{"A question about fractions", false, []string{}},
+		{"This is spam", false, []string{"spam"}},
+		{"SPAM!", false, []string{"spam"}},
+		{"spammer and antispam are other words", false, []string{}},
+		{"Buy  now", false, []string{}},
+		{"Please buy now, it's cheap", false, []string{"buy now"}},
+		{"Regexp characters are matched as typed: c++", false, []string{"c++"}},
+		{"cpp is not c++-like", false, []string{"c++"}},
+		{"spam and a scam", true, nil},
+		{"Scam", true, nil},
+	}
+	service := &ModerationService{}
+	for _, test := range tests {
+		blocked, flagged := service.MatchWordFilters(test.text, filters)
+		if blocked != test.blocked || !reflect.DeepEqual(flagged, test.flagged) {
+			t.Errorf("MatchWordFilters(%q) = %v, %q, want %v, %q", test.text, blocked, flagged, test.blocked, test.flagged)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type requestInfoKey struct{}
+type actorKey struct{}
+
+// RequestInfo describes where a request came from
+type RequestInfo struct {
+	Actor     string
+	IP        string
+	UserAgent string
+	TraceId   string
+}
+
+// WithRequestOrigin stores the client address and user agent of a request in ctx
+func WithRequestOrigin(ctx context.Context, ip, userAgent string) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, RequestInfo{IP: ip, UserAgent: userAgent})
+}
+
+// WithActor stores the authenticated user making a request in ctx
+func WithActor(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, actorKey{}, username)
+}
+
+// RequestInfoFromContext collects the request origin, actor and trace id stored in ctx
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	info.Actor, _ = ctx.Value(actorKey{}).(string)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		info.TraceId = spanContext.TraceID().String()
+	}
+	return info
+}
+
+type tenantKey struct{}
+
+type tenant struct {
+	organizationId string
+	role           string
+}
+
+// WithTenant stores the organization a request acts within, and the caller's role in it, in ctx
+func WithTenant(ctx context.Context, organizationId, role string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant{organizationId: organizationId, role: role})
+}
+
+// TenantFromContext returns the organization a request acts within, or "" outside any organization
+func TenantFromContext(ctx context.Context) string {
+	t, _ := ctx.Value(tenantKey{}).(tenant)
+	return t.organizationId
+}
+
+// TenantRoleFromContext returns the caller's role in the organization the request acts within
+func TenantRoleFromContext(ctx context.Context) string {
+	t, _ := ctx.Value(tenantKey{}).(tenant)
+	return t.role
+}
+
+type roleKey struct{}
+
+// WithRole stores the platform role of the authenticated user in ctx
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// RoleFromContext returns the platform role of the authenticated user, or "" for unauthenticated requests
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey{}).(string)
+	return role
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"orkidslearning/src/database"
+	models "orkidslearning/src/models/database"
+)
+
+const (
+	// streamEventRetention is how long a reconnecting client can catch up on events it missed
+	streamEventRetention = 24 * time.Hour
+	// streamReconnectDelay is how long to wait before listening again after losing the database connection
+	streamReconnectDelay = 5 * time.Second
+	// streamBuffer is how many events a subscriber may fall behind before it is dropped
+	streamBuffer = 64
+)
+
+// StreamSubscription receives the events of one user for one connected client
+type StreamSubscription struct {
+	username string
+	events   chan models.StreamEvent
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Events delivers the user's events as they happen
+func (s *StreamSubscription) Events() <-chan models.StreamEvent {
+	return s.events
+}
+
+// Done is closed when the subscription ends because the server is shutting down, the client fell too far behind
+// or events may have been missed. The client should reconnect with the last event id it saw.
+func (s *StreamSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *StreamSubscription) end() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// StreamService fans events out to connected clients. Events are stored in Postgres and announced with
+// LISTEN/NOTIFY, so a client receives its events whichever replica it is connected to.
+type StreamService struct {
+	postgres       *database.PostgresDatabase
+	allowedOrigins []string
+
+	mu          sync.Mutex
+	subscribers map[string]map[*StreamSubscription]struct{}
+	closed      bool
+}
+
+// NewStreamService creates the stream service. Browsers may only open WebSocket streams from pages served from one
+// of allowedOrigins.
+func NewStreamService(postgres *database.PostgresDatabase, allowedOrigins []string) *StreamService {
+	return &StreamService{postgres: postgres, allowedOrigins: allowedOrigins, subscribers: map[string]map[*StreamSubscription]struct{}{}}
+}
+
+// AllowedOrigins returns the origins of the pages that may open WebSocket streams
+func (s *StreamService) AllowedOrigins() []string {
+	return s.allowedOrigins
+}
+
+// Publish records an event for a user and pushes it to their connected clients on every replica
+func (s *StreamService) Publish(username, eventType string, data interface{}) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return s.postgres.AddStreamEvent(username, eventType, encoded)
+}
+
+// Subscribe starts receiving a user's events. Subscriptions made after Close are already done.
+func (s *StreamService) Subscribe(username string) *StreamSubscription {
+	subscription := &StreamSubscription{
+		username: username,
+		events:   make(chan models.StreamEvent, streamBuffer),
+		done:     make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		subscription.end()
+		return subscription
+	}
+	if s.subscribers[username] == nil {
+		s.subscribers[username] = map[*StreamSubscription]struct{}{}
+	}
+	s.subscribers[username][subscription] = struct{}{}
+	return subscription
+}
+
+// Unsubscribe stops receiving events
+func (s *StreamService) Unsubscribe(subscription *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscribers[subscription.username], subscription)
+	if len(s.subscribers[subscription.username]) == 0 {
+		delete(s.subscribers, subscription.username)
+	}
+	subscription.end()
+}
+
+// Start listens for events and prunes old ones until ctx is cancelled
+func (s *StreamService) Start(ctx context.Context) {
+	go s.listen(ctx)
+	go s.prune(ctx)
+}
+
+// Close ends every subscription so that streaming requests return and the server can shut down
+func (s *StreamService) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.endAll()
+}
+
+// endAll ends every subscription. The caller must hold s.mu.
+func (s *StreamService) endAll() {
+	for _, subscriptions := range s.subscribers {
+		for subscription := range subscriptions {
+			subscription.end()
+		}
+	}
+	s.subscribers = map[string]map[*StreamSubscription]struct{}{}
+}
+
+func (s *StreamService) listen(ctx context.Context) {
+	for {
+		err := s.postgres.ListenStreamEvents(ctx, s.dispatch)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Println("Lost stream event listener", err)
+
+		// Events announced while no one was listening are lost, so clients reconnect and catch up
+		s.mu.Lock()
+		s.endAll()
+		s.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(streamReconnectDelay):
+		}
+	}
+}
+
+func (s *StreamService) dispatch(notice database.StreamEventNotice) {
+	s.mu.Lock()
+	subscriptions := make([]*StreamSubscription, 0, len(s.subscribers[notice.Username]))
+	for subscription := range s.subscribers[notice.Username] {
+		subscriptions = append(subscriptions, subscription)
+	}
+	s.mu.Unlock()
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	event, err := s.postgres.GetStreamEvent(notice.Id)
+	if err != nil {
+		log.Println("Error getting stream event", err)
+		return
+	}
+	for _, subscription := range subscriptions {
+		select {
+		case subscription.events <- *event:
+		default:
+			// A client this far behind catches up by reconnecting
+			s.Unsubscribe(subscription)
+		}
+	}
+}
+
+func (s *StreamService) prune(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		if _, err := s.postgres.PruneStreamEvents(time.Now().Add(-streamEventRetention)); err != nil {
+			log.Println("Error pruning stream events", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
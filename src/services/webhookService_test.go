@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestSignWebhook(t *testing.T) {
+	payload := []byte(`{"type":"enrollment.created"}`)
+	signature := SignWebhook("whsec_test", 1700000000, payload)
+	if want := "sha256=faf949585a2d480e1f90c8c72ff307eac0d4e28da9259c6b3d9bb4cded406cf4"; signature != want {
+		t.Fatalf("signature = %s, want %s", signature, want)
+	}
+
+	// Changing any signed part changes the signature
+	for name, other := range map[string]string{
+		"secret":    SignWebhook("whsec_other", 1700000000, payload),
+		"timestamp": SignWebhook("whsec_test", 1700000001, payload),
+		"payload":   SignWebhook("whsec_test", 1700000000, []byte(`{"type":"enrollment.removed"}`)),
+	} {
+		if other == signature {
+			t.Errorf("changing the %s kept the signature", name)
+		}
+	}
+}
+
+func TestRefusePrivateAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:4700:4700::1111]:443", true},
+		{"127.0.0.1:80", false},
+		{"127.1.2.3:80", false},
+		{"[::1]:80", false},
+		{"10.0.0.1:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:80", false},
+		{"[fd00::1]:80", false},
+		{"0.0.0.0:80", false},
+		{"[::]:80", false},
+		{"224.0.0.1:80", false},
+		{"[ff02::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"[::ffff:10.0.0.1]:80", false},
+		{"localhost:80", false},
+		{"93.184.216.34", false},
+	}
+	for _, test := range tests {
+		err := refusePrivateAddress("tcp", test.address, nil)
+		if (err == nil) != test.allowed {
+			t.Errorf("%s: allowed = %v, want %v (%v)", test.address, err == nil, test.allowed, err)
+		}
+	}
+}
+
+// The test server listens on loopback, so it can only be reached when private networks are allowed
+func TestWebhookServiceSend(t *testing.T) {
+	payload := []byte(`{"type":"enrollment.created"}`)
+	received := make(chan *http.Request, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != string(payload) {
+			t.Errorf("body = %s, want %s", body, payload)
+		}
+		received <- r
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	if _, _, err := NewWebhookService("false").Send(context.Background(), server.URL, "whsec_test", "1", "enrollment.created", payload); err == nil {
+		t.Fatalf("a loopback endpoint was reached with private networks refused")
+	}
+
+	status, body, err := NewWebhookService("true").Send(context.Background(), server.URL, "whsec_test", "1", "enrollment.created", payload)
+	if err != nil || status != http.StatusOK || body != "ok" {
+		t.Fatalf("send = %d %q %v, want 200 \"ok\"", status, body, err)
+	}
+	request := <-received
+	timestamp, err := strconv.ParseInt(request.Header.Get("X-Webhook-Timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid timestamp header: %v", err)
+	}
+	if signature := request.Header.Get("X-Webhook-Signature"); signature != SignWebhook("whsec_test", timestamp, payload) {
+		t.Errorf("signature header %s does not match the payload", signature)
+	}
+	if event := request.Header.Get("X-Webhook-Event"); event != "enrollment.created" {
+		t.Errorf("event header = %s", event)
+	}
+}
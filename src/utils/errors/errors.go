@@ -8,6 +8,9 @@ import (
 // Environment errors
 var ErrNoEnvFile = errors.New("no .env file found, relying on system environment variables")
 
+// Enrollment errors
+var ErrInviteCodeInvalid = errors.New("invite code is invalid, expired or used up")
+
 func EnvVariableNotSet(variableName string) error {
 	return fmt.Errorf("environment variable %s is required but not set", variableName)
 }
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket opcodes and close codes used by the server
+const (
+	webSocketContinuation = 0x0
+	webSocketText         = 0x1
+	webSocketBinary       = 0x2
+	webSocketClose        = 0x8
+	webSocketPing         = 0x9
+	webSocketPong         = 0xA
+
+	webSocketGoingAway     = 1001
+	webSocketProtocolError = 1002
+	webSocketTooBig        = 1009
+)
+
+const (
+	webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	// webSocketMaxMessage bounds messages read from clients, all fragments included. Clients have nothing to send
+	// but control frames, so anything bigger is a misbehaving client.
+	webSocketMaxMessage = 64 * 1024
+	// webSocketMaxControl is the largest payload of a control frame
+	webSocketMaxControl = 125
+	// webSocketWriteTimeout bounds each write so a stalled client cannot hold a handler forever
+	webSocketWriteTimeout = 10 * time.Second
+)
+
+// WebSocket is the server side of a WebSocket connection (RFC 6455) that only pushes text messages. It answers the
+// client's pings and close handshake and discards anything else the client sends.
+type WebSocket struct {
+	conn      net.Conn
+	rw        *bufio.ReadWriter
+	mu        sync.Mutex
+	closed    chan struct{}
+	once      sync.Once
+	sentClose bool
+}
+
+// ErrWebSocketOrigin is returned when a browser opens a WebSocket from a page whose origin is not allowed
+var ErrWebSocketOrigin = errors.New("websocket origin not allowed")
+
+// IsWebSocketUpgrade reports whether the request asks to switch to the WebSocket protocol
+func IsWebSocketUpgrade(r *http.Request) bool {
+	return headerHasToken(r.Header, "Connection", "upgrade") && headerHasToken(r.Header, "Upgrade", "websocket")
+}
+
+// UpgradeWebSocket completes the opening handshake and takes over the connection. Nothing may have been written
+// to w yet; on error a response has not been written either.
+//
+// Browsers let any page open a WebSocket to any site, sending the page's origin along, so requests with an Origin
+// header must come from one of allowedOrigins. Clients other than browsers send no Origin and are let through.
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request, allowedOrigins []string) (*WebSocket, error) {
+	if r.Method != http.MethodGet || !IsWebSocketUpgrade(r) {
+		return nil, errors.New("not a websocket upgrade request")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, errors.New("unsupported websocket version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, errors.New("missing websocket key")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !originAllowed(origin, allowedOrigins) {
+		return nil, ErrWebSocketOrigin
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, err
+	}
+	// The server's read and write timeouts no longer apply
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	accept := sha1.Sum([]byte(key + webSocketGUID))
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(accept[:]) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	ws := &WebSocket{conn: conn, rw: rw, closed: make(chan struct{})}
+	go ws.read()
+	return ws, nil
+}
+
+// WriteText sends a text message
+func (ws *WebSocket) WriteText(message []byte) error {
+	return ws.writeFrame(webSocketText, message)
+}
+
+// Ping checks the client is still there; a client that has gone away makes the write fail
+func (ws *WebSocket) Ping() error {
+	return ws.writeFrame(webSocketPing, nil)
+}
+
+// Closed is closed once the connection is closed by either side
+func (ws *WebSocket) Closed() <-chan struct{} {
+	return ws.closed
+}
+
+// Close tells the client the server is going away and closes the connection
+func (ws *WebSocket) Close() error {
+	var err error
+	ws.once.Do(func() {
+		code := binary.BigEndian.AppendUint16(nil, webSocketGoingAway)
+		ws.writeFrame(webSocketClose, code)
+		err = ws.conn.Close()
+		close(ws.closed)
+	})
+	return err
+}
+
+func (ws *WebSocket) writeFrame(opcode byte, payload []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.sentClose {
+		return net.ErrClosed
+	}
+	if opcode == webSocketClose {
+		ws.sentClose = true
+	}
+
+	header := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		header = append(header, byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 126)
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header = append(header, 127)
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	if err := ws.conn.SetWriteDeadline(time.Now().Add(webSocketWriteTimeout)); err != nil {
+		return err
+	}
+	if _, err := ws.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := ws.rw.Write(payload); err != nil {
+		return err
+	}
+	return ws.rw.Flush()
+}
+
+// read answers control frames from the client until it closes the connection or breaks the protocol. Data messages
+// are checked and discarded; a client breaking the protocol or sending too big a message is told why before the
+// connection is closed.
+func (ws *WebSocket) read() {
+	defer ws.Close()
+	fragmented := false
+	var messageLength uint64
+	for {
+		var head [2]byte
+		if _, err := io.ReadFull(ws.rw, head[:]); err != nil {
+			return
+		}
+		final := head[0]&0x80 != 0
+		opcode := head[0] & 0x0F
+		length := uint64(head[1] & 0x7F)
+		switch length {
+		case 126:
+			var extended [2]byte
+			if _, err := io.ReadFull(ws.rw, extended[:]); err != nil {
+				return
+			}
+			length = uint64(binary.BigEndian.Uint16(extended[:]))
+		case 127:
+			var extended [8]byte
+			if _, err := io.ReadFull(ws.rw, extended[:]); err != nil {
+				return
+			}
+			length = binary.BigEndian.Uint64(extended[:])
+		}
+
+		// No extension is negotiated, so the reserved bits must be clear, and clients must mask their frames
+		if head[0]&0x70 != 0 || head[1]&0x80 == 0 {
+			ws.fail(webSocketProtocolError)
+			return
+		}
+		switch opcode {
+		case webSocketClose, webSocketPing, webSocketPong:
+			// Control frames may come between the fragments of a message but are never fragmented themselves
+			if !final || length > webSocketMaxControl {
+				ws.fail(webSocketProtocolError)
+				return
+			}
+		case webSocketText, webSocketBinary, webSocketContinuation:
+			// A message is a first frame followed by continuation frames up to the final one
+			if (opcode == webSocketContinuation) != fragmented {
+				ws.fail(webSocketProtocolError)
+				return
+			}
+			if opcode != webSocketContinuation {
+				messageLength = 0
+			}
+			if length > webSocketMaxMessage-messageLength {
+				ws.fail(webSocketTooBig)
+				return
+			}
+			messageLength += length
+			fragmented = !final
+		default:
+			ws.fail(webSocketProtocolError)
+			return
+		}
+
+		var mask [4]byte
+		if _, err := io.ReadFull(ws.rw, mask[:]); err != nil {
+			return
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(ws.rw, payload); err != nil {
+			return
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+
+		switch opcode {
+		case webSocketClose:
+			// Echo the client's close code to complete the closing handshake
+			if len(payload) > 2 {
+				payload = payload[:2]
+			}
+			ws.writeFrame(webSocketClose, payload)
+			return
+		case webSocketPing:
+			ws.writeFrame(webSocketPong, payload)
+		}
+	}
+}
+
+// fail starts the closing handshake with a close code saying why the client's frames were rejected
+func (ws *WebSocket) fail(code uint16) {
+	ws.writeFrame(webSocketClose, binary.BigEndian.AppendUint16(nil, code))
+}
+
+// originAllowed reports whether an Origin header names one of the allowed origins, ignoring case and a trailing slash
+func originAllowed(origin string, allowedOrigins []string) bool {
+	for _, allowed := range allowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(origin, "/"), strings.TrimSuffix(allowed, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerHasToken reports whether a comma-separated header contains token, ignoring case
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,301 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testOrigin = "https://app.example.com"
+
+// webSocketServer upgrades every request, allowing pages from testOrigin, and hands the connections to the test
+func webSocketServer(t *testing.T) (*httptest.Server, chan *WebSocket) {
+	t.Helper()
+	upgraded := make(chan *WebSocket, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := UpgradeWebSocket(w, r, []string{testOrigin})
+		if errors.Is(err, ErrWebSocketOrigin) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		upgraded <- ws
+	}))
+	t.Cleanup(server.Close)
+	return server, upgraded
+}
+
+// dialWebSocket sends an opening handshake with the given headers on top of the usual ones and returns the response
+// along with the connection, whose frames are read from the returned reader
+func dialWebSocket(t *testing.T, server *httptest.Server, headers map[string]string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	request := map[string]string{
+		"Upgrade":               "websocket",
+		"Connection":            "Upgrade",
+		"Sec-WebSocket-Key":     "dGhlIHNhbXBsZSBub25jZQ==",
+		"Sec-WebSocket-Version": "13",
+	}
+	for name, value := range headers {
+		request[name] = value
+	}
+	var b strings.Builder
+	b.WriteString("GET /stream HTTP/1.1\r\nHost: example.com\r\n")
+	for name, value := range request {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\r\n", name, value)
+		}
+	}
+	b.WriteString("\r\n")
+	if _, err := io.WriteString(conn, b.String()); err != nil {
+		t.Fatalf("writing handshake: %v", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("reading handshake response: %v", err)
+	}
+	return conn, reader, response
+}
+
+// openWebSocket completes a handshake from an allowed origin and returns the client connection and the server side
+func openWebSocket(t *testing.T) (net.Conn, *bufio.Reader, *WebSocket) {
+	t.Helper()
+	server, upgraded := webSocketServer(t)
+	conn, reader, response := dialWebSocket(t, server, map[string]string{"Origin": testOrigin})
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want %d", response.StatusCode, http.StatusSwitchingProtocols)
+	}
+	ws := <-upgraded
+	t.Cleanup(func() { ws.Close() })
+	return conn, reader, ws
+}
+
+// writeFrame writes a frame as a client would, masked unless told otherwise. first holds the FIN and reserved bits
+// and the opcode.
+func writeFrame(t *testing.T, conn net.Conn, first byte, payload []byte, masked bool) {
+	t.Helper()
+	frame := []byte{first}
+	maskBit := byte(0)
+	if masked {
+		maskBit = 0x80
+	}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+	if masked {
+		mask := []byte{0x12, 0x34, 0x56, 0x78}
+		frame = append(frame, mask...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("writing frame: %v", err)
+	}
+}
+
+// readFrame reads an unmasked frame sent by the server and returns its first byte and payload
+func readFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(reader, head[:]); err != nil {
+		t.Fatalf("reading frame: %v", err)
+	}
+	if head[1]&0x80 != 0 {
+		t.Fatalf("server frames must not be masked")
+	}
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		io.ReadFull(reader, extended[:])
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		io.ReadFull(reader, extended[:])
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatalf("reading payload: %v", err)
+	}
+	return head[0], payload
+}
+
+// expectClose reads the server's close frame, checks its code and waits for the server side to close
+func expectClose(t *testing.T, reader *bufio.Reader, ws *WebSocket, code uint16) {
+	t.Helper()
+	first, payload := readFrame(t, reader)
+	if first != 0x80|webSocketClose {
+		t.Fatalf("got frame %#x, want a close frame", first)
+	}
+	if len(payload) < 2 || binary.BigEndian.Uint16(payload) != code {
+		t.Fatalf("close payload = %v, want code %d", payload, code)
+	}
+	select {
+	case <-ws.Closed():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the server did not close the connection")
+	}
+}
+
+func TestUpgradeWebSocketHandshake(t *testing.T) {
+	server, upgraded := webSocketServer(t)
+	_, reader, response := dialWebSocket(t, server, map[string]string{"Origin": testOrigin})
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want %d", response.StatusCode, http.StatusSwitchingProtocols)
+	}
+	// The example from RFC 6455, section 1.3
+	if accept := response.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Sec-WebSocket-Accept = %q", accept)
+	}
+
+	ws := <-upgraded
+	defer ws.Close()
+	if err := ws.WriteText([]byte("hello")); err != nil {
+		t.Fatalf("writing: %v", err)
+	}
+	first, payload := readFrame(t, reader)
+	if first != 0x80|webSocketText || string(payload) != "hello" {
+		t.Errorf("got frame %#x %q, want a final text frame \"hello\"", first, payload)
+	}
+}
+
+func TestUpgradeWebSocketChecksRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+	}{
+		{"allowed origin", map[string]string{"Origin": testOrigin}, http.StatusSwitchingProtocols},
+		{"allowed origin written differently", map[string]string{"Origin": "HTTPS://APP.EXAMPLE.COM/"}, http.StatusSwitchingProtocols},
+		{"no origin", nil, http.StatusSwitchingProtocols},
+		{"other origin", map[string]string{"Origin": "https://evil.example.com"}, http.StatusForbidden},
+		{"lookalike origin", map[string]string{"Origin": testOrigin + ".evil.example.com"}, http.StatusForbidden},
+		{"unsupported version", map[string]string{"Sec-WebSocket-Version": "8"}, http.StatusBadRequest},
+		{"missing key", map[string]string{"Sec-WebSocket-Key": ""}, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, upgraded := webSocketServer(t)
+			_, _, response := dialWebSocket(t, server, test.headers)
+			if response.StatusCode != test.status {
+				t.Fatalf("status = %d, want %d", response.StatusCode, test.status)
+			}
+			if test.status == http.StatusSwitchingProtocols {
+				(<-upgraded).Close()
+			}
+		})
+	}
+}
+
+func TestWebSocketAnswersPingAndClose(t *testing.T) {
+	conn, reader, ws := openWebSocket(t)
+
+	writeFrame(t, conn, 0x80|webSocketPing, []byte("are you there"), true)
+	first, payload := readFrame(t, reader)
+	if first != 0x80|webSocketPong || string(payload) != "are you there" {
+		t.Fatalf("got frame %#x %q, want a pong echoing the ping", first, payload)
+	}
+
+	writeFrame(t, conn, 0x80|webSocketClose, binary.BigEndian.AppendUint16(nil, 1000), true)
+	expectClose(t, reader, ws, 1000)
+}
+
+func TestWebSocketAcceptsFragmentedMessages(t *testing.T) {
+	conn, reader, ws := openWebSocket(t)
+
+	writeFrame(t, conn, webSocketText, []byte("hel"), true)
+	// Control frames may come between fragments
+	writeFrame(t, conn, 0x80|webSocketPing, []byte("1"), true)
+	writeFrame(t, conn, webSocketContinuation, []byte("l"), true)
+	writeFrame(t, conn, 0x80|webSocketContinuation, []byte("o"), true)
+	writeFrame(t, conn, 0x80|webSocketPing, []byte("2"), true)
+
+	for _, want := range []string{"1", "2"} {
+		first, payload := readFrame(t, reader)
+		if first != 0x80|webSocketPong || string(payload) != want {
+			t.Fatalf("got frame %#x %q, want pong %q", first, payload, want)
+		}
+	}
+	select {
+	case <-ws.Closed():
+		t.Fatalf("the server closed a connection that followed the protocol")
+	default:
+	}
+}
+
+func TestWebSocketRejectsBadFrames(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(t *testing.T, conn net.Conn)
+		code  uint16
+	}{
+		{"unmasked frame", func(t *testing.T, conn net.Conn) {
+			writeFrame(t, conn, 0x80|webSocketText, []byte("hi"), false)
+		}, webSocketProtocolError},
+		{"reserved bits set", func(t *testing.T, conn net.Conn) {
+			writeFrame(t, conn, 0x80|0x40|webSocketText, []byte("hi"), true)
+		}, webSocketProtocolError},
+		{"unknown opcode", func(t *testing.T, conn net.Conn) {
+			writeFrame(t, conn, 0x80|0x3, []byte("hi"), true)
+		}, webSocketProtocolError},
+		{"continuation without a message", func(t *testing.T, conn net.Conn) {
+			writeFrame(t, conn, 0x80|webSocketContinuation, []byte("hi"), true)
+		}, webSocketProtocolError},
+		{"new message before the last one ended", func(t *testing.T, conn net.Conn) {
+			writeFrame(t, conn, webSocketText, []byte("hi"), true)
+			writeFrame(t, conn, 0x80|webSocketText, []byte("there"), true)
+		}, webSocketProtocolError},
+		{"fragmented control frame", func(t *testing.T, conn net.Conn) {
+			writeFrame(t, conn, webSocketPing, []byte("hi"), true)
+		}, webSocketProtocolError},
+		{"control frame too long", func(t *testing.T, conn net.Conn) {
+			writeFrame(t, conn, 0x80|webSocketPing, make([]byte, webSocketMaxControl+1), true)
+		}, webSocketProtocolError},
+		{"frame too big", func(t *testing.T, conn net.Conn) {
+			// Only the header is sent; the server must not wait for, or allocate, the announced payload
+			header := []byte{0x80 | webSocketBinary, 0x80 | 127}
+			conn.Write(binary.BigEndian.AppendUint64(header, 1<<40))
+		}, webSocketTooBig},
+		{"message too big", func(t *testing.T, conn net.Conn) {
+			writeFrame(t, conn, webSocketBinary, make([]byte, webSocketMaxMessage/2+1), true)
+			writeFrame(t, conn, 0x80|webSocketContinuation, make([]byte, webSocketMaxMessage/2+1), true)
+		}, webSocketTooBig},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conn, reader, ws := openWebSocket(t)
+			test.write(t, conn)
+			expectClose(t, reader, ws, test.code)
+		})
+	}
+}